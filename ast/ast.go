@@ -0,0 +1,1420 @@
+// Package ast declares the types used to represent syntax trees of Scala
+// source files. Every node records where it starts and where it ends, so
+// that tools can map any part of the tree back to the source.
+package ast
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// ----------------------------------------------------------------------------
+// Positions
+
+// Pos is a position in a source file. Like go/token it is the byte offset
+// plus one, so that the zero value NoPos means "no position".
+type Pos int
+
+const NoPos Pos = 0
+
+// PosOf returns the Pos of a byte offset, such as parser.Token.Pos.
+func PosOf(offset int) Pos {
+	return Pos(offset + 1)
+}
+
+// IsValid reports whether p is a known position.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// Offset returns the byte offset of p, or -1 for NoPos.
+func (p Pos) Offset() int {
+	return int(p) - 1
+}
+
+// ----------------------------------------------------------------------------
+// Interfaces
+//
+// There are five main classes of nodes: expressions, patterns, types,
+// statements and enumerators. Expressions are statements too, since any
+// expression may appear in a block or template body. Identifiers,
+// selections and literals appear in more than one role and implement
+// every interface that applies.
+
+// All node types implement the Node interface.
+type Node interface {
+	Pos() Pos // position of first character belonging to the node
+	End() Pos // position of first character immediately after the node
+}
+
+// All expression nodes implement the Expr interface.
+type Expr interface {
+	Stat
+	exprNode()
+}
+
+// All pattern nodes implement the Pattern interface.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// All type nodes implement the Type interface.
+type Type interface {
+	Node
+	typeNode()
+}
+
+// All statement nodes implement the Stat interface: definitions,
+// declarations, imports, package clauses and expressions.
+type Stat interface {
+	Node
+	statNode()
+}
+
+// All for-comprehension enumerators implement the Enumerator interface.
+type Enumerator interface {
+	Node
+	enumeratorNode()
+}
+
+// ----------------------------------------------------------------------------
+// Identifiers, literals and paths
+
+type (
+	// An Ident is a simple name. It is an expression, a type and a
+	// pattern. The placeholder and wildcard "_" is an Ident too.
+	Ident struct {
+		NamePos    Pos    // identifier position
+		Name       string // identifier name, without backquotes
+		Backquoted bool   // written as `Name`
+	}
+
+	// A Select is a qualified name or member selection: X.Sel.
+	Select struct {
+		X   Expr
+		Sel *Ident
+	}
+
+	// A This is a reference to this or Qual.this.
+	This struct {
+		Qual    *Ident // or nil
+		ThisPos Pos    // position of "this"
+	}
+
+	// A Super is a reference to super, Qual.super or super[Mix].
+	Super struct {
+		Qual     *Ident // or nil
+		SuperPos Pos    // position of "super"
+		Mix      *Ident // or nil
+		Rbrack   Pos    // position of "]" after Mix, if any
+	}
+
+	// A Literal is a literal of basic type. Value is the literal as
+	// written in the source, including quotes and a leading minus sign
+	// for negative numeric patterns.
+	Literal struct {
+		ValuePos Pos
+		Kind     LitKind
+		Value    string
+	}
+
+	// An Interpolation is an interpolated string such as s"a $b ${c}".
+	// Parts holds the literal text between the arguments, so that
+	// len(Parts) == len(Args)+1.
+	Interpolation struct {
+		Id     *Ident
+		Parts  []string
+		Args   []Expr
+		EndPos Pos // position after the closing quote
+	}
+)
+
+// LitKind is the kind of a Literal.
+type LitKind int
+
+const (
+	IntLit LitKind = iota
+	LongLit
+	FloatLit
+	DoubleLit
+	CharLit
+	StringLit
+	SymbolLit
+	BooleanLit
+	NullLit
+)
+
+func (k LitKind) String() string {
+	switch k {
+	case IntLit:
+		return "Int"
+	case LongLit:
+		return "Long"
+	case FloatLit:
+		return "Float"
+	case DoubleLit:
+		return "Double"
+	case CharLit:
+		return "Char"
+	case StringLit:
+		return "String"
+	case SymbolLit:
+		return "Symbol"
+	case BooleanLit:
+		return "Boolean"
+	case NullLit:
+		return "Null"
+	}
+	return "LitKind?"
+}
+
+// QualifiedName returns the dotted name of the path x, such as
+// scala.collection.mutable or C.this.x, or "" if x is not made of
+// names, selections, this and super.
+func QualifiedName(x Expr) string {
+	switch x := x.(type) {
+	case *Ident:
+		return x.Name
+	case *This:
+		if x.Qual != nil {
+			return x.Qual.Name + ".this"
+		}
+		return "this"
+	case *Super:
+		name := "super"
+		if x.Qual != nil {
+			name = x.Qual.Name + "." + name
+		}
+		if x.Mix != nil {
+			name += "[" + x.Mix.Name + "]"
+		}
+		return name
+	case *Select:
+		if q := QualifiedName(x.X); q != "" {
+			return q + "." + x.Sel.Name
+		}
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+type (
+	// A BadExpr is a placeholder for an expression containing syntax
+	// errors for which a correct node could not be created.
+	BadExpr struct {
+		From, To Pos
+	}
+
+	// An ArgList is a parenthesized argument list, or a block argument
+	// written in braces, in which case Args holds a single *Block or
+	// *PartialFunction.
+	ArgList struct {
+		Lparen Pos // position of "(" or "{"
+		Args   []Expr
+		Rparen Pos // position of ")" or "}"
+	}
+
+	// An Apply is a function application: Fun(Args).
+	Apply struct {
+		Fun  Expr
+		Args *ArgList
+	}
+
+	// A TypeApply is an application to type arguments: Fun[Targs].
+	TypeApply struct {
+		Fun    Expr
+		Lbrack Pos
+		Targs  []Type
+		Rbrack Pos
+	}
+
+	// An InfixApply is an infix operation: X Op Y. Y is a *Tuple when the
+	// right operand is an argument list.
+	InfixApply struct {
+		X     Expr
+		Op    *Ident
+		Targs []Type // explicit type arguments of Op, or nil
+		Y     Expr
+	}
+
+	// A PrefixApply is a prefix operation: Op X, with Op one of + - ! ~.
+	PrefixApply struct {
+		Op *Ident
+		X  Expr
+	}
+
+	// A PostfixApply is a postfix operation: X Op.
+	PostfixApply struct {
+		X  Expr
+		Op *Ident
+	}
+
+	// An Assign is an assignment X = Rhs, also used for named arguments.
+	Assign struct {
+		X      Expr
+		TokPos Pos // position of "="
+		Rhs    Expr
+	}
+
+	// A Typed is a type ascription X: Type. A sequence argument xs: _*
+	// has a *RepeatedType whose element type is the wildcard "_".
+	Typed struct {
+		X     Expr
+		Colon Pos
+		Type  Type
+	}
+
+	// An Annotated is an annotated expression X: @a @b.
+	Annotated struct {
+		X           Expr
+		Colon       Pos
+		Annotations []*Annotation
+	}
+
+	// A Tuple is a tuple (Elts...), and () for the unit value. An
+	// assignment in parentheses, (x = 1), is a Tuple of one element so
+	// that it is not taken for a named argument.
+	Tuple struct {
+		Lparen Pos
+		Elts   []Expr
+		Rparen Pos
+	}
+
+	// A Block is a block expression { Stats }. The body of an anonymous
+	// function at the start of a block, { x => a; b }, is a Block without
+	// braces.
+	Block struct {
+		Lbrace Pos
+		Stats  []Stat
+		Rbrace Pos
+	}
+
+	// A Function is an anonymous function Params => Body.
+	Function struct {
+		Implicit Pos // position of "implicit", if any
+		Lparen   Pos // position of "(", or NoPos for a single bare parameter
+		Params   []*Param
+		Rparen   Pos
+		Arrow    Pos
+		Body     Expr
+	}
+
+	// A PartialFunction is a pattern matching anonymous function
+	// { case ... }.
+	PartialFunction struct {
+		Lbrace Pos
+		Cases  []*CaseClause
+		Rbrace Pos
+	}
+
+	// An If is an if expression. Else is nil if there is no else branch.
+	If struct {
+		If   Pos
+		Cond Expr
+		Then Expr
+		Else Expr
+	}
+
+	// A While is a while loop.
+	While struct {
+		While Pos
+		Cond  Expr
+		Body  Expr
+	}
+
+	// A DoWhile is a do ... while loop.
+	DoWhile struct {
+		Do     Pos
+		Body   Expr
+		Cond   Expr
+		EndPos Pos // position after the condition's closing parenthesis
+	}
+
+	// A For is a for loop or, if Yield is valid, a for comprehension.
+	For struct {
+		For   Pos
+		Enums []Enumerator
+		Yield Pos // position of "yield", or NoPos
+		Body  Expr
+	}
+
+	// A Try is a try expression. Cases holds the case clauses of a
+	// catch block; Catch holds a catch handler given as an expression.
+	Try struct {
+		Try        Pos
+		Body       Expr
+		CatchPos   Pos // position of "catch", or NoPos
+		Cases      []*CaseClause
+		Catch      Expr
+		FinallyPos Pos // position of "finally", or NoPos
+		Finally    Expr
+		EndPos     Pos // position after the closing brace of the catch cases
+	}
+
+	// A Throw is a throw expression.
+	Throw struct {
+		Throw Pos
+		X     Expr
+	}
+
+	// A Return is a return expression; X is nil for a bare return.
+	Return struct {
+		Return Pos
+		X      Expr
+	}
+
+	// A Match is a match expression X match { Cases }.
+	Match struct {
+		X      Expr
+		Match  Pos
+		Lbrace Pos
+		Cases  []*CaseClause
+		Rbrace Pos
+	}
+
+	// A New is an instance creation expression new Template.
+	New struct {
+		New      Pos
+		Template *Template
+	}
+
+	// A Quote is a Scala 3 quote: '{ Body }, '[ Body ] or 'x.
+	Quote struct {
+		Quote  Pos
+		Body   Node // Expr or Type
+		EndPos Pos
+	}
+
+	// A Splice is a Scala 3 splice ${ X }.
+	Splice struct {
+		Splice Pos
+		X      Expr
+		Rbrace Pos
+	}
+)
+
+// A CaseClause is a case Pat if Guard => Body clause of a match, a catch
+// block or a partial function.
+type CaseClause struct {
+	Case  Pos
+	Pat   Pattern
+	Guard Expr // or nil
+	Arrow Pos
+	Body  []Stat
+}
+
+// ----------------------------------------------------------------------------
+// Enumerators
+
+type (
+	// A Generator is a Pat <- Rhs enumerator.
+	Generator struct {
+		Pat   Pattern
+		Arrow Pos
+		Rhs   Expr
+	}
+
+	// A ValueEnum is a Pat = Rhs enumerator.
+	ValueEnum struct {
+		Pat    Pattern
+		TokPos Pos
+		Rhs    Expr
+	}
+
+	// A Guard is an if Cond enumerator.
+	Guard struct {
+		If   Pos
+		Cond Expr
+	}
+)
+
+// ----------------------------------------------------------------------------
+// Patterns
+
+type (
+	// A BadPattern is a placeholder for a pattern containing syntax
+	// errors.
+	BadPattern struct {
+		From, To Pos
+	}
+
+	// A Bind is a pattern binder Name @ Pat.
+	Bind struct {
+		Name *Ident
+		At   Pos
+		Pat  Pattern
+	}
+
+	// A TypedPattern is a typed pattern X: Type, X being a variable or _.
+	TypedPattern struct {
+		X     *Ident
+		Colon Pos
+		Type  Type
+	}
+
+	// An ExtractorPattern is a constructor or extractor pattern
+	// Fun[Targs](Args).
+	ExtractorPattern struct {
+		Fun    Expr // *Ident or *Select
+		Targs  []Type
+		Lparen Pos
+		Args   []Pattern
+		Rparen Pos
+	}
+
+	// A SeqWildcard is the _* pattern matching the rest of a sequence.
+	SeqWildcard struct {
+		Underscore Pos
+	}
+
+	// A TuplePattern is a tuple pattern (Elts...).
+	TuplePattern struct {
+		Lparen Pos
+		Elts   []Pattern
+		Rparen Pos
+	}
+
+	// An Alternative is a pattern alternative Alts[0] | Alts[1] | ...
+	Alternative struct {
+		Alts []Pattern
+	}
+
+	// An InfixPattern is an infix extractor pattern X Op Y, e.g. h :: t.
+	InfixPattern struct {
+		X  Pattern
+		Op *Ident
+		Y  Pattern
+	}
+
+	// An InterpolatedPattern is an interpolated string pattern, such as
+	// r"$a-$b".
+	InterpolatedPattern struct {
+		Id     *Ident
+		Parts  []string
+		Args   []Pattern
+		EndPos Pos
+	}
+)
+
+// IsVariable reports whether x, used as a simple pattern, is a variable
+// pattern: it starts with a lower case letter or _ and is not backquoted
+// (SLS 8.1.1). Any other name is a stable identifier pattern, such as
+// None or `x`, which matches the value it refers to.
+func (x *Ident) IsVariable() bool {
+	if x.Backquoted {
+		return false
+	}
+	c, _ := utf8.DecodeRuneInString(x.Name)
+	return c == '_' || unicode.IsLower(c)
+}
+
+// ----------------------------------------------------------------------------
+// Types
+
+type (
+	// A BadType is a placeholder for a type containing syntax errors.
+	BadType struct {
+		From, To Pos
+	}
+
+	// A SingletonType is a singleton type Ref.type.
+	SingletonType struct {
+		Ref     Expr
+		TypePos Pos // position of "type"
+	}
+
+	// A Projection is a type projection X#Sel.
+	Projection struct {
+		X   Type
+		Sel *Ident
+	}
+
+	// An AppliedType is a parameterized type Type[Args].
+	AppliedType struct {
+		Type   Type
+		Lbrack Pos
+		Args   []Type
+		Rbrack Pos
+	}
+
+	// A FunctionType is a function type (Params) => Result, or P => Result
+	// when Lparen is NoPos.
+	FunctionType struct {
+		Lparen Pos
+		Params []Type
+		Rparen Pos
+		Arrow  Pos
+		Result Type
+	}
+
+	// A ByNameType is a by-name parameter type => Type.
+	ByNameType struct {
+		Arrow Pos
+		Type  Type
+	}
+
+	// A RepeatedType is a repeated parameter type Type*.
+	RepeatedType struct {
+		Type Type
+		Star Pos
+	}
+
+	// A TupleType is a tuple type (Elts...).
+	TupleType struct {
+		Lparen Pos
+		Elts   []Type
+		Rparen Pos
+	}
+
+	// A CompoundType is T1 with T2 ... { Decls }. Lbrace is NoPos if there
+	// is no refinement; Types is empty for a bare refinement { Decls }.
+	CompoundType struct {
+		Types  []Type
+		Lbrace Pos
+		Decls  []Stat
+		Rbrace Pos
+	}
+
+	// An ExistentialType is Type forSome { Decls }.
+	ExistentialType struct {
+		Type    Type
+		ForSome Pos
+		Lbrace  Pos
+		Decls   []Stat
+		Rbrace  Pos
+	}
+
+	// An InfixType is an infix type X Op Y.
+	InfixType struct {
+		X  Type
+		Op *Ident
+		Y  Type
+	}
+
+	// An AnnotatedType is an annotated type Type @a @b.
+	AnnotatedType struct {
+		Type        Type
+		Annotations []*Annotation
+	}
+
+	// A WildcardType is a wildcard type _ >: Lo <: Hi.
+	WildcardType struct {
+		Underscore Pos
+		Lo, Hi     Type // bounds or nil
+	}
+)
+
+// ----------------------------------------------------------------------------
+// Modifiers, annotations and parameters
+
+type (
+	// A Modifier is a modifier keyword such as private[Qual] or case.
+	Modifier struct {
+		ModPos Pos
+		Name   string // keyword, e.g. "private", "override", "case"
+		Qual   Node   // *Ident or *This qualifier of an access modifier, or nil
+		Rbrack Pos    // position of "]" after Qual, if any
+	}
+
+	// An Init is a constructor invocation Type(Args)... as found in
+	// parent lists, new expressions and annotations.
+	Init struct {
+		Type Type
+		Args []*ArgList
+	}
+
+	// An Annotation is an annotation @Init.
+	Annotation struct {
+		At   Pos
+		Init *Init
+	}
+
+	// A Param is a method, class or function parameter.
+	Param struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		ValPos      Pos    // position of "val" or "var" of a class parameter
+		Keyword     string // "val", "var" or ""
+		Name        *Ident
+		Type        Type // or nil
+		Default     Expr // or nil
+	}
+
+	// A ParamClause is a parenthesized parameter list.
+	ParamClause struct {
+		Lparen   Pos
+		Implicit Pos // position of "implicit", if any
+		Params   []*Param
+		Rparen   Pos
+	}
+
+	// A TypeParam is a type parameter with its variance and bounds.
+	TypeParam struct {
+		Annotations   []*Annotation
+		Variance      string // "+", "-" or ""
+		VariancePos   Pos
+		Name          *Ident
+		TypeParams    []*TypeParam // parameters of a higher-kinded type
+		Lo, Hi        Type         // bounds or nil
+		ViewBounds    []Type
+		ContextBounds []Type
+		EndPos        Pos
+	}
+)
+
+// Name returns the name of the annotation class as written, such as
+// tailrec or scala.annotation.tailrec, without type arguments. It
+// returns "" for an annotation whose type is not a path.
+func (x *Annotation) Name() string {
+	t := x.Init.Type
+	if app, ok := t.(*AppliedType); ok {
+		t = app.Type
+	}
+	if ref, ok := t.(Expr); ok {
+		return QualifiedName(ref)
+	}
+	return ""
+}
+
+// ----------------------------------------------------------------------------
+// Definitions and other statements
+
+type (
+	// A BadStat is a placeholder for a statement containing syntax
+	// errors.
+	BadStat struct {
+		From, To Pos
+	}
+
+	// A ValDef is a val or var definition or declaration. Rhs is nil for
+	// a declaration.
+	ValDef struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		ValPos      Pos
+		Keyword     string // "val" or "var"
+		Pats        []Pattern
+		Type        Type // or nil
+		Rhs         Expr // or nil
+	}
+
+	// A DefDef is a method definition or declaration. Rhs is nil for a
+	// declaration. A constructor has the name "this".
+	DefDef struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		Def         Pos
+		Name        *Ident
+		TypeParams  []*TypeParam
+		Params      []*ParamClause
+		ResultType  Type // or nil
+		Rhs         Expr // or nil
+		Procedure   bool // written in procedure syntax, def f() { ... }, so of result type Unit
+		EndPos      Pos
+	}
+
+	// A TypeDef is a type alias or abstract type member.
+	TypeDef struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		TypePos     Pos
+		Name        *Ident
+		TypeParams  []*TypeParam
+		Lo, Hi      Type // bounds of an abstract type, or nil
+		Rhs         Type // aliased type, or nil
+		EndPos      Pos
+	}
+
+	// A ClassDef is a class definition, including case classes.
+	ClassDef struct {
+		Annotations     []*Annotation
+		Modifiers       []*Modifier
+		Class           Pos
+		Name            *Ident
+		TypeParams      []*TypeParam
+		CtorAnnotations []*Annotation // annotations of the primary constructor
+		CtorModifiers   []*Modifier   // access modifier of the primary constructor
+		Params          []*ParamClause
+		Template        *Template
+	}
+
+	// A TraitDef is a trait definition.
+	TraitDef struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		Trait       Pos
+		Name        *Ident
+		TypeParams  []*TypeParam
+		Template    *Template
+	}
+
+	// An ObjectDef is an object definition, including case objects and
+	// package objects.
+	ObjectDef struct {
+		Annotations []*Annotation
+		Modifiers   []*Modifier
+		Package     Pos // position of "package" of a package object
+		Object      Pos
+		Name        *Ident
+		Template    *Template
+	}
+
+	// An Import is an import clause with one or more importers.
+	Import struct {
+		Import    Pos
+		Importers []*Importer
+	}
+
+	// A PackageClause is a package clause. Stats holds every following
+	// statement of the file for a chained clause, or the contents of the
+	// braces for a packaging block.
+	PackageClause struct {
+		Package Pos
+		Name    Expr // *Ident or *Select
+		Lbrace  Pos  // NoPos unless this is a packaging block
+		Stats   []Stat
+		Rbrace  Pos
+	}
+)
+
+// An Importer is one path with its selectors in an import clause, e.g.
+// a.b.{c, d => e}.
+type Importer struct {
+	Path      Expr
+	Lbrace    Pos // NoPos if Selectors holds a single selector without braces
+	Selectors []*ImportSelector
+	Rbrace    Pos
+}
+
+// An ImportSelector imports Name, renaming it to Rename if that is set;
+// a Rename of "_" hides the name. A wildcard has Name "_" (or "*").
+type ImportSelector struct {
+	Given  Pos // position of "given" of a Scala 3 given selector
+	Name   *Ident
+	Arrow  Pos // position of "=>", or of "as" in Scala 3
+	Rename *Ident
+	Type   Type // type of a given selector, or nil
+}
+
+// IsWildcard reports whether x is the wildcard _ or * importing every
+// member of its importer's path that is not a given.
+func (x *ImportSelector) IsWildcard() bool {
+	return x.Name != nil && x.Rename == nil && !x.Name.Backquoted && (x.Name.Name == "_" || x.Name.Name == "*")
+}
+
+// IsHidden reports whether x hides its member from a wildcard that
+// follows, as in {a => _, _}.
+func (x *ImportSelector) IsHidden() bool {
+	return x.Rename != nil && x.Rename.Name == "_" && !x.Rename.Backquoted
+}
+
+// Alias returns the name under which x makes its member available: the
+// new name if x renames it, else its own name. Alias returns "" for
+// wildcards, given selectors and hidden names, which bring in no name of
+// their own.
+func (x *ImportSelector) Alias() string {
+	switch {
+	case x.Name == nil || x.IsWildcard() || x.IsHidden():
+		return ""
+	case x.Rename != nil:
+		return x.Rename.Name
+	}
+	return x.Name.Name
+}
+
+// A Template is the part of a class, trait, object or new expression
+// after its header: the parents, an optional self type and the body.
+type Template struct {
+	Extends   Pos    // position of "extends", or NoPos
+	EarlyDefs []Stat // early definitions extends { ... } with
+	Parents   []*Init
+	Lbrace    Pos // NoPos if there is no body
+	Self      *SelfType
+	Stats     []Stat
+	Rbrace    Pos
+}
+
+// A SelfType is a self type annotation Name: Type => at the start of a
+// template body.
+type SelfType struct {
+	Name  *Ident // name, "this" or "_"
+	Type  Type   // or nil
+	Arrow Pos
+}
+
+// A File is a parsed compilation unit or script.
+type File struct {
+	Name      string
+	FileStart Pos
+	Stats     []Stat // top level statements, including package clauses
+	FileEnd   Pos
+	Comments  []*CommentGroup // all comments in the file, in source order
+}
+
+// A TopLevelDef is a definition at the top level of a file, outside of
+// any class, trait or object, with the package it belongs to.
+type TopLevelDef struct {
+	Package string // fully qualified package, or "" for the empty package
+	Def     Stat   // *ClassDef, *TraitDef, *ObjectDef, *ValDef, *DefDef or *TypeDef
+}
+
+// TopLevelDefs returns the top level definitions of f in source order.
+// The package of a definition is made of all package clauses around it:
+// package a.b followed by package c, and a packaging block package c {
+// ... } inside package a.b, both put definitions in a.b.c. A package
+// object package object c in package a.b is itself in a.b.
+func (f *File) TopLevelDefs() []TopLevelDef {
+	var defs []TopLevelDef
+	var collect func(pkg string, stats []Stat)
+	collect = func(pkg string, stats []Stat) {
+		for _, stat := range stats {
+			switch x := stat.(type) {
+			case *PackageClause:
+				name := QualifiedName(x.Name)
+				if pkg != "" {
+					name = pkg + "." + name
+				}
+				collect(name, x.Stats)
+			case *ClassDef, *TraitDef, *ObjectDef, *ValDef, *DefDef, *TypeDef:
+				defs = append(defs, TopLevelDef{Package: pkg, Def: stat})
+			}
+		}
+	}
+	collect("", f.Stats)
+	return defs
+}
+
+// FullName returns the fully qualified name of d, such as a.b.C, or ""
+// for a value definition that does not define a single name. As on the
+// JVM, the package object of a.b.c is named a.b.c.package.
+func (d TopLevelDef) FullName() string {
+	var name string
+	switch x := d.Def.(type) {
+	case *ClassDef:
+		name = x.Name.Name
+	case *TraitDef:
+		name = x.Name.Name
+	case *ObjectDef:
+		name = x.Name.Name
+		if x.Package.IsValid() {
+			name += ".package"
+		}
+	case *DefDef:
+		name = x.Name.Name
+	case *TypeDef:
+		name = x.Name.Name
+	case *ValDef:
+		if len(x.Pats) != 1 {
+			return ""
+		}
+		id, ok := x.Pats[0].(*Ident)
+		if !ok {
+			return ""
+		}
+		name = id.Name
+	}
+	if d.Package == "" {
+		return name
+	}
+	return d.Package + "." + name
+}
+
+// ----------------------------------------------------------------------------
+// Pos and End implementations
+
+func (x *Ident) Pos() Pos { return x.NamePos }
+func (x *Ident) End() Pos {
+	if x.Backquoted {
+		return x.NamePos + Pos(len(x.Name)+2)
+	}
+	return x.NamePos + Pos(len(x.Name))
+}
+func (x *Select) Pos() Pos { return x.X.Pos() }
+func (x *Select) End() Pos { return x.Sel.End() }
+func (x *This) Pos() Pos {
+	if x.Qual != nil {
+		return x.Qual.Pos()
+	}
+	return x.ThisPos
+}
+func (x *This) End() Pos { return x.ThisPos + 4 }
+func (x *Super) Pos() Pos {
+	if x.Qual != nil {
+		return x.Qual.Pos()
+	}
+	return x.SuperPos
+}
+func (x *Super) End() Pos {
+	if x.Mix != nil {
+		return x.Rbrack + 1
+	}
+	return x.SuperPos + 5
+}
+func (x *Literal) Pos() Pos       { return x.ValuePos }
+func (x *Literal) End() Pos       { return x.ValuePos + Pos(len(x.Value)) }
+func (x *Interpolation) Pos() Pos { return x.Id.Pos() }
+func (x *Interpolation) End() Pos { return x.EndPos }
+
+func (x *BadExpr) Pos() Pos      { return x.From }
+func (x *BadExpr) End() Pos      { return x.To }
+func (x *ArgList) Pos() Pos      { return x.Lparen }
+func (x *ArgList) End() Pos      { return x.Rparen + 1 }
+func (x *Apply) Pos() Pos        { return x.Fun.Pos() }
+func (x *Apply) End() Pos        { return x.Args.End() }
+func (x *TypeApply) Pos() Pos    { return x.Fun.Pos() }
+func (x *TypeApply) End() Pos    { return x.Rbrack + 1 }
+func (x *InfixApply) Pos() Pos   { return x.X.Pos() }
+func (x *InfixApply) End() Pos   { return x.Y.End() }
+func (x *PrefixApply) Pos() Pos  { return x.Op.Pos() }
+func (x *PrefixApply) End() Pos  { return x.X.End() }
+func (x *PostfixApply) Pos() Pos { return x.X.Pos() }
+func (x *PostfixApply) End() Pos { return x.Op.End() }
+func (x *Assign) Pos() Pos       { return x.X.Pos() }
+func (x *Assign) End() Pos       { return x.Rhs.End() }
+func (x *Typed) Pos() Pos        { return x.X.Pos() }
+func (x *Typed) End() Pos        { return x.Type.End() }
+func (x *Annotated) Pos() Pos    { return x.X.Pos() }
+func (x *Annotated) End() Pos    { return x.Annotations[len(x.Annotations)-1].End() }
+func (x *Tuple) Pos() Pos        { return x.Lparen }
+func (x *Tuple) End() Pos        { return x.Rparen + 1 }
+func (x *Block) Pos() Pos {
+	if !x.Lbrace.IsValid() {
+		return x.Stats[0].Pos()
+	}
+	return x.Lbrace
+}
+func (x *Block) End() Pos {
+	if !x.Lbrace.IsValid() {
+		return x.Stats[len(x.Stats)-1].End()
+	}
+	return x.Rbrace + 1
+}
+func (x *PartialFunction) Pos() Pos { return x.Lbrace }
+func (x *PartialFunction) End() Pos { return x.Rbrace + 1 }
+func (x *Function) Pos() Pos {
+	switch {
+	case x.Implicit.IsValid():
+		return x.Implicit
+	case x.Lparen.IsValid():
+		return x.Lparen
+	case len(x.Params) == 0:
+		// the parameters were not valid
+		return x.Arrow
+	}
+	return x.Params[0].Pos()
+}
+func (x *Function) End() Pos { return x.Body.End() }
+func (x *If) Pos() Pos       { return x.If }
+func (x *If) End() Pos {
+	if x.Else != nil {
+		return x.Else.End()
+	}
+	return x.Then.End()
+}
+func (x *While) Pos() Pos   { return x.While }
+func (x *While) End() Pos   { return x.Body.End() }
+func (x *DoWhile) Pos() Pos { return x.Do }
+func (x *DoWhile) End() Pos { return x.EndPos }
+func (x *For) Pos() Pos     { return x.For }
+func (x *For) End() Pos     { return x.Body.End() }
+func (x *Try) Pos() Pos     { return x.Try }
+func (x *Try) End() Pos {
+	switch {
+	case x.Finally != nil:
+		return x.Finally.End()
+	case x.Catch != nil:
+		return x.Catch.End()
+	case x.EndPos.IsValid():
+		return x.EndPos
+	}
+	return x.Body.End()
+}
+func (x *Throw) Pos() Pos  { return x.Throw }
+func (x *Throw) End() Pos  { return x.X.End() }
+func (x *Return) Pos() Pos { return x.Return }
+func (x *Return) End() Pos {
+	if x.X != nil {
+		return x.X.End()
+	}
+	return x.Return + 6
+}
+func (x *Match) Pos() Pos  { return x.X.Pos() }
+func (x *Match) End() Pos  { return x.Rbrace + 1 }
+func (x *New) Pos() Pos    { return x.New }
+func (x *New) End() Pos    { return x.Template.End() }
+func (x *Quote) Pos() Pos  { return x.Quote }
+func (x *Quote) End() Pos  { return x.EndPos }
+func (x *Splice) Pos() Pos { return x.Splice }
+func (x *Splice) End() Pos { return x.Rbrace + 1 }
+
+func (x *CaseClause) Pos() Pos { return x.Case }
+func (x *CaseClause) End() Pos {
+	if len(x.Body) > 0 {
+		return x.Body[len(x.Body)-1].End()
+	}
+	return x.Arrow + 2
+}
+
+func (x *Generator) Pos() Pos { return x.Pat.Pos() }
+func (x *Generator) End() Pos { return x.Rhs.End() }
+func (x *ValueEnum) Pos() Pos { return x.Pat.Pos() }
+func (x *ValueEnum) End() Pos { return x.Rhs.End() }
+func (x *Guard) Pos() Pos     { return x.If }
+func (x *Guard) End() Pos     { return x.Cond.End() }
+
+func (x *BadPattern) Pos() Pos       { return x.From }
+func (x *BadPattern) End() Pos       { return x.To }
+func (x *Bind) Pos() Pos             { return x.Name.Pos() }
+func (x *Bind) End() Pos             { return x.Pat.End() }
+func (x *TypedPattern) Pos() Pos     { return x.X.Pos() }
+func (x *TypedPattern) End() Pos     { return x.Type.End() }
+func (x *ExtractorPattern) Pos() Pos { return x.Fun.Pos() }
+func (x *ExtractorPattern) End() Pos { return x.Rparen + 1 }
+func (x *SeqWildcard) Pos() Pos      { return x.Underscore }
+func (x *SeqWildcard) End() Pos      { return x.Underscore + 2 }
+func (x *TuplePattern) Pos() Pos     { return x.Lparen }
+func (x *TuplePattern) End() Pos     { return x.Rparen + 1 }
+func (x *Alternative) Pos() Pos      { return x.Alts[0].Pos() }
+func (x *Alternative) End() Pos      { return x.Alts[len(x.Alts)-1].End() }
+func (x *InfixPattern) Pos() Pos     { return x.X.Pos() }
+func (x *InfixPattern) End() Pos     { return x.Y.End() }
+func (x *InterpolatedPattern) Pos() Pos {
+	return x.Id.Pos()
+}
+func (x *InterpolatedPattern) End() Pos { return x.EndPos }
+
+func (x *BadType) Pos() Pos       { return x.From }
+func (x *BadType) End() Pos       { return x.To }
+func (x *SingletonType) Pos() Pos { return x.Ref.Pos() }
+func (x *SingletonType) End() Pos { return x.TypePos + 4 }
+func (x *Projection) Pos() Pos    { return x.X.Pos() }
+func (x *Projection) End() Pos    { return x.Sel.End() }
+func (x *AppliedType) Pos() Pos   { return x.Type.Pos() }
+func (x *AppliedType) End() Pos   { return x.Rbrack + 1 }
+func (x *FunctionType) Pos() Pos {
+	if x.Lparen.IsValid() {
+		return x.Lparen
+	}
+	return x.Params[0].Pos()
+}
+func (x *FunctionType) End() Pos { return x.Result.End() }
+func (x *ByNameType) Pos() Pos   { return x.Arrow }
+func (x *ByNameType) End() Pos   { return x.Type.End() }
+func (x *RepeatedType) Pos() Pos { return x.Type.Pos() }
+func (x *RepeatedType) End() Pos { return x.Star + 1 }
+func (x *TupleType) Pos() Pos    { return x.Lparen }
+func (x *TupleType) End() Pos    { return x.Rparen + 1 }
+func (x *CompoundType) Pos() Pos {
+	if len(x.Types) > 0 {
+		return x.Types[0].Pos()
+	}
+	return x.Lbrace
+}
+func (x *CompoundType) End() Pos {
+	if x.Lbrace.IsValid() {
+		return x.Rbrace + 1
+	}
+	return x.Types[len(x.Types)-1].End()
+}
+func (x *ExistentialType) Pos() Pos { return x.Type.Pos() }
+func (x *ExistentialType) End() Pos { return x.Rbrace + 1 }
+func (x *InfixType) Pos() Pos       { return x.X.Pos() }
+func (x *InfixType) End() Pos       { return x.Y.End() }
+func (x *AnnotatedType) Pos() Pos   { return x.Type.Pos() }
+func (x *AnnotatedType) End() Pos {
+	return x.Annotations[len(x.Annotations)-1].End()
+}
+func (x *WildcardType) Pos() Pos { return x.Underscore }
+func (x *WildcardType) End() Pos {
+	switch {
+	case x.Hi != nil:
+		return x.Hi.End()
+	case x.Lo != nil:
+		return x.Lo.End()
+	}
+	return x.Underscore + 1
+}
+
+func (x *Modifier) Pos() Pos { return x.ModPos }
+func (x *Modifier) End() Pos {
+	if x.Qual != nil {
+		return x.Rbrack + 1
+	}
+	return x.ModPos + Pos(len(x.Name))
+}
+func (x *Init) Pos() Pos { return x.Type.Pos() }
+func (x *Init) End() Pos {
+	if len(x.Args) > 0 {
+		return x.Args[len(x.Args)-1].End()
+	}
+	return x.Type.End()
+}
+func (x *Annotation) Pos() Pos { return x.At }
+func (x *Annotation) End() Pos { return x.Init.End() }
+func (x *Param) Pos() Pos {
+	switch {
+	case len(x.Annotations) > 0:
+		return x.Annotations[0].Pos()
+	case len(x.Modifiers) > 0:
+		return x.Modifiers[0].Pos()
+	case x.ValPos.IsValid():
+		return x.ValPos
+	}
+	return x.Name.Pos()
+}
+func (x *Param) End() Pos {
+	switch {
+	case x.Default != nil:
+		return x.Default.End()
+	case x.Type != nil:
+		return x.Type.End()
+	}
+	return x.Name.End()
+}
+func (x *ParamClause) Pos() Pos { return x.Lparen }
+func (x *ParamClause) End() Pos { return x.Rparen + 1 }
+func (x *TypeParam) Pos() Pos {
+	switch {
+	case len(x.Annotations) > 0:
+		return x.Annotations[0].Pos()
+	case x.VariancePos.IsValid():
+		return x.VariancePos
+	}
+	return x.Name.Pos()
+}
+func (x *TypeParam) End() Pos { return x.EndPos }
+
+func (x *BadStat) Pos() Pos { return x.From }
+func (x *BadStat) End() Pos { return x.To }
+func (x *ValDef) Pos() Pos  { return defPos(x.Annotations, x.Modifiers, x.ValPos) }
+func (x *ValDef) End() Pos {
+	switch {
+	case x.Rhs != nil:
+		return x.Rhs.End()
+	case x.Type != nil:
+		return x.Type.End()
+	}
+	return x.Pats[len(x.Pats)-1].End()
+}
+func (x *DefDef) Pos() Pos   { return defPos(x.Annotations, x.Modifiers, x.Def) }
+func (x *DefDef) End() Pos   { return x.EndPos }
+func (x *TypeDef) Pos() Pos  { return defPos(x.Annotations, x.Modifiers, x.TypePos) }
+func (x *TypeDef) End() Pos  { return x.EndPos }
+func (x *ClassDef) Pos() Pos { return defPos(x.Annotations, x.Modifiers, x.Class) }
+func (x *ClassDef) End() Pos {
+	switch {
+	case x.Template.End().IsValid():
+		return x.Template.End()
+	case len(x.Params) > 0:
+		return x.Params[len(x.Params)-1].End()
+	}
+	return x.Name.End()
+}
+func (x *TraitDef) Pos() Pos { return defPos(x.Annotations, x.Modifiers, x.Trait) }
+func (x *TraitDef) End() Pos {
+	if x.Template.End().IsValid() {
+		return x.Template.End()
+	}
+	return x.Name.End()
+}
+func (x *ObjectDef) Pos() Pos {
+	if x.Package.IsValid() {
+		return x.Package
+	}
+	return defPos(x.Annotations, x.Modifiers, x.Object)
+}
+func (x *ObjectDef) End() Pos {
+	if x.Template.End().IsValid() {
+		return x.Template.End()
+	}
+	return x.Name.End()
+}
+func (x *Import) Pos() Pos { return x.Import }
+func (x *Import) End() Pos { return x.Importers[len(x.Importers)-1].End() }
+func (x *PackageClause) Pos() Pos {
+	return x.Package
+}
+func (x *PackageClause) End() Pos {
+	switch {
+	case x.Lbrace.IsValid():
+		return x.Rbrace + 1
+	case len(x.Stats) > 0:
+		return x.Stats[len(x.Stats)-1].End()
+	}
+	return x.Name.End()
+}
+
+func (x *Importer) Pos() Pos { return x.Path.Pos() }
+func (x *Importer) End() Pos {
+	if x.Lbrace.IsValid() {
+		return x.Rbrace + 1
+	}
+	return x.Selectors[len(x.Selectors)-1].End()
+}
+func (x *ImportSelector) Pos() Pos {
+	if x.Given.IsValid() {
+		return x.Given
+	}
+	return x.Name.Pos()
+}
+func (x *ImportSelector) End() Pos {
+	switch {
+	case x.Rename != nil:
+		return x.Rename.End()
+	case x.Type != nil:
+		return x.Type.End()
+	case x.Name != nil:
+		return x.Name.End()
+	}
+	return x.Given + 5
+}
+func (x *Template) Pos() Pos {
+	switch {
+	case x.Extends.IsValid():
+		return x.Extends
+	case len(x.Parents) > 0:
+		return x.Parents[0].Pos()
+	}
+	return x.Lbrace
+}
+func (x *Template) End() Pos {
+	switch {
+	case x.Lbrace.IsValid():
+		return x.Rbrace + 1
+	case len(x.Parents) > 0:
+		return x.Parents[len(x.Parents)-1].End()
+	}
+	return x.Extends
+}
+func (x *SelfType) Pos() Pos { return x.Name.Pos() }
+func (x *SelfType) End() Pos { return x.Arrow + 2 }
+func (x *File) Pos() Pos     { return x.FileStart }
+func (x *File) End() Pos     { return x.FileEnd }
+
+// defPos returns the start of a definition: its first annotation or
+// modifier, or else its keyword.
+func defPos(annots []*Annotation, mods []*Modifier, keyword Pos) Pos {
+	pos := keyword
+	if len(mods) > 0 && mods[0].Pos() < pos {
+		pos = mods[0].Pos()
+	}
+	if len(annots) > 0 && annots[0].Pos() < pos {
+		pos = annots[0].Pos()
+	}
+	return pos
+}
+
+// ----------------------------------------------------------------------------
+// Marker methods
+//
+// exprNode() ensures that only expression nodes can be assigned to an
+// Expr, and likewise for the other interfaces.
+
+func (*Ident) exprNode()           {}
+func (*Select) exprNode()          {}
+func (*This) exprNode()            {}
+func (*Super) exprNode()           {}
+func (*Literal) exprNode()         {}
+func (*Interpolation) exprNode()   {}
+func (*BadExpr) exprNode()         {}
+func (*Apply) exprNode()           {}
+func (*TypeApply) exprNode()       {}
+func (*InfixApply) exprNode()      {}
+func (*PrefixApply) exprNode()     {}
+func (*PostfixApply) exprNode()    {}
+func (*Assign) exprNode()          {}
+func (*Typed) exprNode()           {}
+func (*Annotated) exprNode()       {}
+func (*Tuple) exprNode()           {}
+func (*Block) exprNode()           {}
+func (*Function) exprNode()        {}
+func (*PartialFunction) exprNode() {}
+func (*If) exprNode()              {}
+func (*While) exprNode()           {}
+func (*DoWhile) exprNode()         {}
+func (*For) exprNode()             {}
+func (*Try) exprNode()             {}
+func (*Throw) exprNode()           {}
+func (*Return) exprNode()          {}
+func (*Match) exprNode()           {}
+func (*New) exprNode()             {}
+func (*Quote) exprNode()           {}
+func (*Splice) exprNode()          {}
+
+func (*Ident) statNode()           {}
+func (*Select) statNode()          {}
+func (*This) statNode()            {}
+func (*Super) statNode()           {}
+func (*Literal) statNode()         {}
+func (*Interpolation) statNode()   {}
+func (*BadExpr) statNode()         {}
+func (*Apply) statNode()           {}
+func (*TypeApply) statNode()       {}
+func (*InfixApply) statNode()      {}
+func (*PrefixApply) statNode()     {}
+func (*PostfixApply) statNode()    {}
+func (*Assign) statNode()          {}
+func (*Typed) statNode()           {}
+func (*Annotated) statNode()       {}
+func (*Tuple) statNode()           {}
+func (*Block) statNode()           {}
+func (*Function) statNode()        {}
+func (*PartialFunction) statNode() {}
+func (*If) statNode()              {}
+func (*While) statNode()           {}
+func (*DoWhile) statNode()         {}
+func (*For) statNode()             {}
+func (*Try) statNode()             {}
+func (*Throw) statNode()           {}
+func (*Return) statNode()          {}
+func (*Match) statNode()           {}
+func (*New) statNode()             {}
+func (*Quote) statNode()           {}
+func (*Splice) statNode()          {}
+func (*BadStat) statNode()         {}
+func (*ValDef) statNode()          {}
+func (*DefDef) statNode()          {}
+func (*TypeDef) statNode()         {}
+func (*ClassDef) statNode()        {}
+func (*TraitDef) statNode()        {}
+func (*ObjectDef) statNode()       {}
+func (*Import) statNode()          {}
+func (*PackageClause) statNode()   {}
+
+func (*Ident) patternNode()               {}
+func (*Select) patternNode()              {}
+func (*Literal) patternNode()             {}
+func (*BadPattern) patternNode()          {}
+func (*Bind) patternNode()                {}
+func (*TypedPattern) patternNode()        {}
+func (*ExtractorPattern) patternNode()    {}
+func (*SeqWildcard) patternNode()         {}
+func (*TuplePattern) patternNode()        {}
+func (*Alternative) patternNode()         {}
+func (*InfixPattern) patternNode()        {}
+func (*InterpolatedPattern) patternNode() {}
+func (*Quote) patternNode()               {}
+
+func (*Ident) typeNode()           {}
+func (*Select) typeNode()          {}
+func (*BadType) typeNode()         {}
+func (*SingletonType) typeNode()   {}
+func (*Projection) typeNode()      {}
+func (*AppliedType) typeNode()     {}
+func (*FunctionType) typeNode()    {}
+func (*ByNameType) typeNode()      {}
+func (*RepeatedType) typeNode()    {}
+func (*TupleType) typeNode()       {}
+func (*CompoundType) typeNode()    {}
+func (*ExistentialType) typeNode() {}
+func (*InfixType) typeNode()       {}
+func (*AnnotatedType) typeNode()   {}
+func (*WildcardType) typeNode()    {}
+func (*Splice) typeNode()          {}
+
+func (*Generator) enumeratorNode() {}
+func (*ValueEnum) enumeratorNode() {}
+func (*Guard) enumeratorNode()     {}
@@ -0,0 +1,53 @@
+package ast
+
+import "testing"
+
+func TestPositions(t *testing.T) {
+	// source: def f(x: Int) = x + 1
+	x := &Ident{NamePos: 7, Name: "x"}
+	param := &Param{Name: x, Type: &Ident{NamePos: 10, Name: "Int"}}
+	rhs := &InfixApply{
+		X:  &Ident{NamePos: 17, Name: "x"},
+		Op: &Ident{NamePos: 19, Name: "+"},
+		Y:  &Literal{ValuePos: 21, Kind: IntLit, Value: "1"},
+	}
+	def := &DefDef{
+		Def:    1,
+		Name:   &Ident{NamePos: 5, Name: "f"},
+		Params: []*ParamClause{{Lparen: 6, Params: []*Param{param}, Rparen: 13}},
+		Rhs:    rhs,
+		EndPos: rhs.End(),
+	}
+	tests := []struct {
+		node       Node
+		start, end Pos
+	}{
+		{x, 7, 8},
+		{param, 7, 13},
+		{def.Params[0], 6, 14},
+		{rhs, 17, 22},
+		{def, 1, 22},
+		{&Ident{NamePos: 1, Name: "yield", Backquoted: true}, 1, 8},
+	}
+	for _, test := range tests {
+		if test.node.Pos() != test.start || test.node.End() != test.end {
+			t.Errorf("%T spans [%d, %d), Expected [%d, %d)", test.node, test.node.Pos(), test.node.End(), test.start, test.end)
+		}
+	}
+}
+
+func TestModifiersBeforeKeyword(t *testing.T) {
+	// source: @inline private def f = 1
+	def := &DefDef{
+		Annotations: []*Annotation{{At: 1, Init: &Init{Type: &Ident{NamePos: 2, Name: "inline"}}}},
+		Modifiers:   []*Modifier{{ModPos: 9, Name: "private"}},
+		Def:         17,
+		Name:        &Ident{NamePos: 21, Name: "f"},
+	}
+	if def.Pos() != 1 {
+		t.Errorf("DefDef.Pos() = %d, Expected 1", def.Pos())
+	}
+	if PosOf(0) != 1 || Pos(1).Offset() != 0 || NoPos.IsValid() {
+		t.Errorf("Pos does not follow the offset plus one convention")
+	}
+}
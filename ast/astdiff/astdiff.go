@@ -0,0 +1,792 @@
+// Package astdiff computes the structural difference between two syntax
+// trees, such as two versions of a file, in terms of the definitions and
+// expressions that were inserted, deleted, updated or moved.
+//
+// Nodes of the two trees are matched as in GumTree (Falleri et al., Fine-
+// grained and Accurate Source Code Differencing, ASE 2014): first the
+// largest identical subtrees, top down; then the nodes that contain many
+// matched nodes, bottom up; and last the children of matched nodes that
+// are alike. The edit script that turns one tree into the other follows
+// from the matching, and is reported by its outermost edits, so that a
+// method moved to another class is one change rather than one per node.
+package astdiff
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/printer"
+)
+
+// An Op is the kind of a change.
+type Op int
+
+const (
+	Insert Op = iota // New was inserted
+	Delete           // Old was deleted
+	Update           // the name or value of Old changed to that of New
+	Move             // Old was moved to where New is
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	case Move:
+		return "move"
+	}
+	return fmt.Sprintf("Op(%d)", int(op))
+}
+
+// A Change is an edit of the old tree that is part of turning it into
+// the new one.
+type Change struct {
+	Op  Op
+	Old ast.Node // the node of the old tree, or nil for an Insert
+	New ast.Node // the node of the new tree, or nil for a Delete
+
+	// OldScope and NewScope are the innermost definitions around Old and
+	// New, or the root of their trees if there is none; they are nil
+	// when Old and New are.
+	OldScope, NewScope ast.Node
+}
+
+// Diff returns the changes that turn the tree old into the tree new,
+// sorted by Op and then by the position of the node they describe: Old
+// for a Delete and New otherwise. Changes inside an inserted, deleted or
+// moved node are left out; the nodes of a moved node are only reported
+// if they are updated or moved themselves. Comments are ignored.
+func Diff(old, new ast.Node) []Change {
+	src, dst := newTree(old), newTree(new)
+	m := &matcher{src, dst}
+	m.topDown()
+	m.bottomUp()
+
+	var changes []Change
+	for _, s := range src.nodes {
+		if s.partner == nil && (s.parent == nil || s.parent.partner != nil) {
+			changes = append(changes, Change{Op: Delete, Old: s.n, OldScope: s.scope()})
+		}
+	}
+	for _, d := range dst.nodes {
+		s := d.partner
+		switch {
+		case s == nil:
+			if d.parent == nil || d.parent.partner != nil {
+				changes = append(changes, Change{Op: Insert, New: d.n, NewScope: d.scope()})
+			}
+			continue
+		case s.label != d.label:
+			changes = append(changes, Change{Update, s.n, d.n, s.scope(), d.scope()})
+		}
+		if s.parent != nil && d.parent != nil && s.parent.partner != d.parent {
+			changes = append(changes, Change{Move, s.n, d.n, s.scope(), d.scope()})
+		}
+		for _, c := range reordered(s, d) {
+			changes = append(changes, Change{Move, c.partner.n, c.n, c.partner.scope(), c.scope()})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Op != changes[j].Op {
+			return changes[i].Op < changes[j].Op
+		}
+		return changes[i].node().Pos() < changes[j].node().Pos()
+	})
+	return changes
+}
+
+// node returns the node c describes: Old for a Delete and New otherwise.
+func (c Change) node() ast.Node {
+	if c.Op == Delete {
+		return c.Old
+	}
+	return c.New
+}
+
+// ----------------------------------------------------------------------------
+// Trees
+
+// A tree holds the nodes of a syntax tree in pre-order.
+type tree struct {
+	nodes []*node
+	named map[string][]*node // definitions by kind and name
+}
+
+// A node is a node of a syntax tree with what matching needs to know.
+type node struct {
+	n        ast.Node
+	kind     string // name of the type of n
+	label    string // string, LitKind and bool fields of n
+	name     string // of a definition
+	parent   *node
+	children []*node
+	index    int    // in the pre-order of the tree
+	last     int    // index of the last node under n
+	height   int    // 1 for a leaf
+	hash     uint64 // of the kinds and labels of the subtree
+	partner  *node  // the matched node of the other tree
+}
+
+func newTree(root ast.Node) *tree {
+	t := &tree{named: map[string][]*node{}}
+	t.add(root, nil)
+	return t
+}
+
+func (t *tree) add(n ast.Node, parent *node) *node {
+	x := &node{n: n, kind: reflect.TypeOf(n).Elem().Name(), label: label(n), parent: parent, index: len(t.nodes), height: 1}
+	t.nodes = append(t.nodes, x)
+	if isDef(n) {
+		x.name = defText(n)
+		t.named[x.kind+"\x00"+x.name] = append(t.named[x.kind+"\x00"+x.name], x)
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s", x.kind, x.label)
+	for _, c := range children(n) {
+		child := t.add(c, x)
+		x.children = append(x.children, child)
+		if child.height >= x.height {
+			x.height = child.height + 1
+		}
+		fmt.Fprintf(h, "\x00%x", child.hash)
+	}
+	x.hash = h.Sum64()
+	x.last = len(t.nodes) - 1
+	return x
+}
+
+// children returns the children of the AST node n, without comments.
+func children(n ast.Node) []ast.Node {
+	var list []ast.Node
+	ast.Inspect(n, func(c ast.Node) bool {
+		switch c.(type) {
+		case nil, *ast.Comment, *ast.CommentGroup:
+			return false
+		}
+		if c != n {
+			list = append(list, c)
+			return false
+		}
+		return true
+	})
+	return list
+}
+
+// label returns the values of the fields of n that are not nodes or
+// positions, such as the name of an identifier or the keyword of a
+// ValDef; nodes that are alike but have different labels are updated
+// rather than replaced.
+func label(n ast.Node) string {
+	if _, ok := n.(*ast.File); ok {
+		return "" // the file name
+	}
+	v := reflect.ValueOf(n).Elem()
+	var parts []string
+	for i := 0; i < v.NumField(); i++ {
+		switch f := v.Field(i); f.Kind() {
+		case reflect.String:
+			parts = append(parts, f.String())
+		case reflect.Bool:
+			parts = append(parts, fmt.Sprint(f.Bool()))
+		case reflect.Int:
+			if f.Type() == reflect.TypeOf(ast.IntLit) {
+				parts = append(parts, fmt.Sprint(f.Interface()))
+			}
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// path returns the kinds and names of the definitions around x.
+func (x *node) path() string {
+	var b strings.Builder
+	for p := x.parent; p != nil; p = p.parent {
+		if p.name != "" {
+			fmt.Fprintf(&b, "%s %s\x00", p.kind, p.name)
+		}
+	}
+	return b.String()
+}
+
+// contains reports whether y is x or a node under it.
+func (x *node) contains(y *node) bool { return x.index <= y.index && y.index <= x.last }
+
+// scope returns the AST node of the innermost definition around x, or of
+// the root.
+func (x *node) scope() ast.Node {
+	for p := x.parent; p != nil; p = p.parent {
+		if p.name != "" || p.parent == nil {
+			return p.n
+		}
+	}
+	return x.n
+}
+
+// ----------------------------------------------------------------------------
+// Matching
+
+const (
+	minHeight = 2   // of the subtrees matched top down
+	minDice   = 0.5 // share of matched nodes of the nodes matched bottom up
+)
+
+type matcher struct {
+	src, dst *tree
+}
+
+func (m *matcher) match(s, d *node) {
+	s.partner, d.partner = d, s
+}
+
+// matchSubtree matches the isomorphic subtrees s and d.
+func (m *matcher) matchSubtree(s, d *node) {
+	m.match(s, d)
+	for i := range s.children {
+		m.matchSubtree(s.children[i], d.children[i])
+	}
+}
+
+// isomorphic reports whether the subtrees s and d are identical but for
+// positions.
+func isomorphic(s, d *node) bool {
+	if s.hash != d.hash || s.last-s.index != d.last-d.index {
+		return false
+	}
+	for i := range s.children {
+		if !isomorphic(s.children[i], d.children[i]) {
+			return false
+		}
+	}
+	return s.kind == d.kind && s.label == d.label
+}
+
+// topDown matches the highest isomorphic subtrees of the trees whose
+// height is at least minHeight. A subtree with a unique twin in the
+// other tree is matched with it; subtrees with several twins are matched
+// first with the twins in definitions of the same names, and then by how
+// alike their parents are.
+func (m *matcher) topDown() {
+	type candidate struct{ s, d *node }
+	var ambiguous []candidate
+	src, dst := []*node{m.src.nodes[0]}, []*node{m.dst.nodes[0]}
+	for len(src) > 0 && len(dst) > 0 {
+		hs, hd := maxHeight(src), maxHeight(dst)
+		if hs < minHeight || hd < minHeight {
+			break
+		}
+		if hs != hd {
+			if hs > hd {
+				src = open(src, hs)
+			} else {
+				dst = open(dst, hd)
+			}
+			continue
+		}
+		var s1, d1 []*node
+		for _, s := range src {
+			if s.height == hs {
+				s1 = append(s1, s)
+			}
+		}
+		for _, d := range dst {
+			if d.height == hd {
+				d1 = append(d1, d)
+			}
+		}
+		matched := map[*node]bool{}
+		for _, s := range s1 {
+			var twins []*node
+			for _, d := range d1 {
+				if isomorphic(s, d) {
+					twins = append(twins, d)
+				}
+			}
+			if len(twins) == 0 {
+				continue
+			}
+			matched[s] = true
+			for _, d := range twins {
+				matched[d] = true
+			}
+			unique := len(twins) == 1
+			for _, other := range s1 {
+				if other != s && isomorphic(other, twins[0]) {
+					unique = false
+				}
+			}
+			if unique {
+				m.matchSubtree(s, twins[0])
+				continue
+			}
+			for _, d := range twins {
+				ambiguous = append(ambiguous, candidate{s, d})
+			}
+		}
+		var s2, d2 []*node
+		for _, s := range src {
+			if !matched[s] || s.height != hs {
+				s2 = append(s2, s)
+			}
+		}
+		for _, d := range dst {
+			if !matched[d] || d.height != hd {
+				d2 = append(d2, d)
+			}
+		}
+		src, dst = open(s2, hs), open(d2, hd)
+	}
+
+	sort.SliceStable(ambiguous, func(i, j int) bool {
+		a, b := ambiguous[i], ambiguous[j]
+		if sa, sb := a.s.path() == a.d.path(), b.s.path() == b.d.path(); sa != sb {
+			return sa
+		}
+		return m.parentDice(a.s, a.d) > m.parentDice(b.s, b.d)
+	})
+	for _, c := range ambiguous {
+		if c.s.partner == nil && c.d.partner == nil {
+			m.matchSubtree(c.s, c.d)
+		}
+	}
+}
+
+// maxHeight returns the greatest height of the nodes of list.
+func maxHeight(list []*node) int {
+	h := 0
+	for _, x := range list {
+		if x.height > h {
+			h = x.height
+		}
+	}
+	return h
+}
+
+// open replaces the nodes of list of height h by their children.
+func open(list []*node, h int) []*node {
+	var res []*node
+	for _, x := range list {
+		if x.height == h {
+			res = append(res, x.children...)
+		} else {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
+func (m *matcher) parentDice(s, d *node) float64 {
+	if s.parent == nil || d.parent == nil {
+		return 0
+	}
+	return dice(s.parent, d.parent)
+}
+
+// dice returns the Dice coefficient of the nodes under s and d: twice
+// the number of nodes under s matched with nodes under d, divided by
+// the number of nodes under both.
+func dice(s, d *node) float64 {
+	n := (s.last - s.index) + (d.last - d.index)
+	if n == 0 {
+		return 0
+	}
+	common := 0
+	for _, x := range s.descendants() {
+		if x.partner != nil && d.contains(x.partner) && x.partner != d {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(n)
+}
+
+// descendants returns the nodes under x, but not x.
+func (x *node) descendants() []*node {
+	var list []*node
+	for _, c := range x.children {
+		list = append(list, c)
+		list = append(list, c.descendants()...)
+	}
+	return list
+}
+
+// bottomUp matches, in post-order, the unmatched inner nodes of the old
+// tree with the unmatched nodes of the same kind of the new tree that
+// contain most of the nodes their nodes are matched with, and then the
+// children of each such pair that are alike. The roots are matched in
+// any case.
+//
+// Definitions are matched by name first: with a definition of the same
+// name in definitions of the same names, whatever it contains, or else
+// elsewhere if the name is unique or the two are alike. A definition is
+// only matched with one of another name, as a rename, if neither name is
+// found in the other tree. The parts of a definition that are not
+// definitions themselves, such as the body of a class, are only matched
+// with the parts of the definition it is matched with.
+func (m *matcher) bottomUp() {
+	var visit func(s *node)
+	visit = func(s *node) {
+		for _, c := range s.children {
+			visit(c)
+		}
+		if s.partner != nil || len(s.children) == 0 || s.name == "" && s.parent != nil && s.parent.name != "" {
+			return
+		}
+		var best *node
+		bestDice := 0.0
+		if s.name != "" {
+			key := s.kind + "\x00" + s.name
+			samePath := false
+			for _, d := range m.dst.named[key] {
+				if d.partner != nil {
+					continue
+				}
+				dc := dice(s, d)
+				if same := s.path() == d.path(); best == nil || same && !samePath || same == samePath && dc > bestDice {
+					best, bestDice, samePath = d, dc, same
+				}
+			}
+			if best != nil && (samePath || bestDice >= minDice || len(m.src.named[key]) == 1 && len(m.dst.named[key]) == 1) {
+				m.match(s, best)
+				m.recover(s, best)
+				return
+			}
+			best, bestDice = nil, 0
+		}
+		for _, d := range m.candidates(s) {
+			if s.name != d.name && (len(m.dst.named[s.kind+"\x00"+s.name]) > 0 || len(m.src.named[d.kind+"\x00"+d.name]) > 0) {
+				continue // not a rename
+			}
+			if dc := dice(s, d); dc > bestDice {
+				best, bestDice = d, dc
+			}
+		}
+		if best != nil && bestDice >= minDice {
+			m.match(s, best)
+			m.recover(s, best)
+		}
+	}
+	visit(m.src.nodes[0])
+
+	s, d := m.src.nodes[0], m.dst.nodes[0]
+	if s.partner == nil && d.partner == nil && s.kind == d.kind {
+		m.match(s, d)
+	}
+	if s.partner == d {
+		m.recover(s, d)
+	}
+}
+
+// candidates returns the unmatched nodes of the new tree of the kind of
+// s that contain a node matched with a node under s.
+func (m *matcher) candidates(s *node) []*node {
+	var list []*node
+	seen := map[*node]bool{}
+	for _, x := range s.descendants() {
+		if x.partner == nil {
+			continue
+		}
+		for d := x.partner.parent; d != nil; d = d.parent {
+			if seen[d] {
+				break
+			}
+			seen[d] = true
+			if d.partner == nil && d.kind == s.kind {
+				list = append(list, d)
+			}
+		}
+	}
+	return list
+}
+
+// recover matches the unmatched children of the matched nodes s and d,
+// and then theirs: first the children with isomorphic subtrees, then
+// those of the same kind, label and name, and last those of the same
+// kind, in order, but for definitions of different names.
+func (m *matcher) recover(s, d *node) {
+	for i, alike := range []func(x, y *node) bool{
+		isomorphic,
+		func(x, y *node) bool { return x.kind == y.kind && x.label == y.label && x.name == y.name },
+		func(x, y *node) bool { return x.kind == y.kind && x.name == y.name },
+	} {
+		for _, x := range s.children {
+			if x.partner != nil {
+				continue
+			}
+			for _, y := range d.children {
+				if y.partner != nil || !alike(x, y) {
+					continue
+				}
+				if i == 0 {
+					m.matchSubtree(x, y)
+				} else {
+					m.match(x, y)
+				}
+				break
+			}
+		}
+	}
+	for _, x := range s.children {
+		if x.partner != nil && x.partner.parent == d {
+			m.recover(x, x.partner)
+		}
+	}
+}
+
+// reordered returns the children of d that are matched with children of
+// s but whose order changed: those outside a longest common subsequence
+// of the children of s and d matched with each other.
+func reordered(s, d *node) []*node {
+	var a, b []*node
+	for _, x := range s.children {
+		if x.partner != nil && x.partner.parent == d {
+			a = append(a, x)
+		}
+	}
+	for _, y := range d.children {
+		if y.partner != nil && y.partner.parent == s {
+			b = append(b, y)
+		}
+	}
+	// lcs[i][j] is the length of a longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].partner == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	inOrder := map[*node]bool{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].partner == b[j]:
+			inOrder[b[j]] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	var list []*node
+	for _, y := range b {
+		if !inOrder[y] {
+			list = append(list, y)
+		}
+	}
+	return list
+}
+
+// ----------------------------------------------------------------------------
+// Descriptions
+
+// String describes c in words, as in
+//
+//	method `foo` moved from class A to object B
+func (c Change) String() string {
+	switch c.Op {
+	case Insert:
+		return fmt.Sprintf("%s inserted %s", Describe(c.New), where("in", c.NewScope))
+	case Delete:
+		return fmt.Sprintf("%s deleted %s", Describe(c.Old), where("from", c.OldScope))
+	case Update:
+		if x, ok := c.New.(*ast.Ident); ok && isName(x, c.NewScope) {
+			return fmt.Sprintf("%s renamed to `%s`", Describe(renamed(c.OldScope, c.Old)), x.Name)
+		}
+		if isDef(c.New) {
+			return fmt.Sprintf("%s changed to %s %s", Describe(c.Old), Describe(c.New), where("in", c.NewScope))
+		}
+		return fmt.Sprintf("%s updated to `%s` %s", Describe(c.Old), snippet(c.New), where("in", c.NewScope))
+	case Move:
+		if scopeName(c.OldScope) == scopeName(c.NewScope) {
+			return fmt.Sprintf("%s moved within %s", Describe(c.New), scopeName(c.NewScope))
+		}
+		return fmt.Sprintf("%s moved from %s to %s", Describe(c.Old), scopeName(c.OldScope), scopeName(c.NewScope))
+	}
+	return c.Op.String()
+}
+
+// isName reports whether x is the name of the definition def.
+func isName(x *ast.Ident, def ast.Node) bool {
+	name, _ := defName(def)
+	return name == x
+}
+
+// renamed returns the definition def if x is its name, and x otherwise.
+func renamed(def, x ast.Node) ast.Node {
+	if name, _ := defName(def); name == x {
+		return def
+	}
+	return x
+}
+
+// Describe returns what n is and its source, as in "method `foo`",
+// "class `A`" or "expression `a + b`". Long sources are cut short.
+func Describe(n ast.Node) string {
+	noun := "node"
+	switch n := n.(type) {
+	case *ast.DefDef, *ast.ClassDef, *ast.TraitDef, *ast.ObjectDef, *ast.TypeDef, *ast.PackageClause, *ast.ValDef:
+		return fmt.Sprintf("%s `%s`", defNoun(n), defText(n))
+	case *ast.Import:
+		noun = "import"
+	case *ast.Param:
+		noun = "parameter"
+	case *ast.TypeParam:
+		noun = "type parameter"
+	case *ast.Modifier:
+		noun = "modifier"
+	case *ast.Annotation:
+		noun = "annotation"
+	case *ast.CaseClause:
+		noun = "case"
+	case *ast.ArgList:
+		noun = "arguments"
+	case *ast.ParamClause:
+		noun = "parameter list"
+	case *ast.Template:
+		noun = "template"
+	case *ast.Init:
+		noun = "parent"
+	case *ast.Importer, *ast.ImportSelector:
+		noun = "import selector"
+	case ast.Expr:
+		noun = "expression"
+	case ast.Type:
+		noun = "type"
+	case ast.Pattern:
+		noun = "pattern"
+	case ast.Enumerator:
+		noun = "enumerator"
+	case ast.Stat:
+		noun = "statement"
+	}
+	return fmt.Sprintf("%s `%s`", noun, snippet(n))
+}
+
+// isDef reports whether n is a definition, which has a name.
+func isDef(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.DefDef, *ast.ClassDef, *ast.TraitDef, *ast.ObjectDef, *ast.TypeDef, *ast.PackageClause, *ast.ValDef:
+		return true
+	}
+	return false
+}
+
+// defNoun returns the word for the definition n.
+func defNoun(n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.DefDef:
+		if n.Name != nil && n.Name.Name == "this" {
+			return "constructor"
+		}
+		return "method"
+	case *ast.ClassDef:
+		return "class"
+	case *ast.TraitDef:
+		return "trait"
+	case *ast.ObjectDef:
+		return "object"
+	case *ast.TypeDef:
+		return "type"
+	case *ast.PackageClause:
+		return "package"
+	case *ast.ValDef:
+		if n.Keyword == "var" {
+			return "variable"
+		}
+		return "value"
+	}
+	return "file"
+}
+
+// defName returns the identifier that names the definition n, if it
+// has one, and its name otherwise.
+func defName(n ast.Node) (*ast.Ident, string) {
+	switch n := n.(type) {
+	case *ast.DefDef:
+		return n.Name, ""
+	case *ast.ClassDef:
+		return n.Name, ""
+	case *ast.TraitDef:
+		return n.Name, ""
+	case *ast.ObjectDef:
+		return n.Name, ""
+	case *ast.TypeDef:
+		return n.Name, ""
+	case *ast.PackageClause:
+		return nil, snippet(n.Name)
+	case *ast.ValDef:
+		if len(n.Pats) == 1 {
+			if x, ok := n.Pats[0].(*ast.Ident); ok {
+				return x, ""
+			}
+		}
+		var names []string
+		for _, p := range n.Pats {
+			names = append(names, snippet(p))
+		}
+		return nil, strings.Join(names, ", ")
+	}
+	return nil, ""
+}
+
+// defText returns the name of the definition n as written.
+func defText(n ast.Node) string {
+	name, s := defName(n)
+	if name != nil {
+		return name.Name
+	}
+	return s
+}
+
+// scopeName names the scope n of a change, as in "class A", or "the top
+// level" for the root of a tree.
+func scopeName(n ast.Node) string {
+	if isDef(n) {
+		return defNoun(n) + " " + defText(n)
+	}
+	return "the top level"
+}
+
+// where returns the phrase for a change in the scope n, as in "in class
+// A" or "at the top level".
+func where(prep string, n ast.Node) string {
+	if s := scopeName(n); s != "the top level" || prep != "in" {
+		return prep + " " + s
+	}
+	return "at the top level"
+}
+
+// maxSnippet is the number of runes of source that descriptions show.
+const maxSnippet = 40
+
+// snippet returns the source of n on one line, cut short after
+// maxSnippet runes.
+func snippet(n ast.Node) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = reflect.TypeOf(n).Elem().Name()
+		}
+	}()
+	s = strings.Join(strings.Fields(printer.String(n)), " ")
+	if r := []rune(s); len(r) > maxSnippet {
+		s = string(r[:maxSnippet-3]) + "..."
+	}
+	return s
+}
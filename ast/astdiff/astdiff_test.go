@@ -0,0 +1,121 @@
+package astdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sundargates/scalaparser/parser"
+)
+
+var diffTests = []struct {
+	old, new string
+	expected []string
+}{
+	{
+		"class A { def f = 1 }",
+		"class A { def f = 1 }",
+		nil,
+	},
+	{
+		`class A {
+  def foo(x: Int): Int = {
+    val y = x * 2
+    y + 1
+  }
+  def bar = 1
+}
+
+object B {
+  val z = 3
+}`,
+		`class A {
+  def bar = 1
+  def qux = 2
+}
+
+object B {
+  val z = 4
+  def foo(x: Int): Int = {
+    val y = x * 2
+    y + 1
+  }
+}`,
+		[]string{
+			"insert method `qux` inserted in class A",
+			"update expression `3` updated to `4` in value z",
+			"move method `foo` moved from class A to object B",
+		},
+	},
+	{
+		`object O {
+  def compute(xs: List[Int]): Int = xs.map(x => x * 2).filter(_ > 3).sum + xs.length
+  def a = f(1, 2)
+  def b = g(x, y)
+  val limit = 10
+  def c = if (ok) 1 else 2
+}`,
+		`object O {
+  def c = if (ok) 1 else 3
+  def calculate(xs: List[Int]): Int = xs.map(x => x * 2).filter(_ > 3).sum + xs.size
+  def a = f(1, 2, 3)
+  def b = g(y, x)
+  var limit = 10
+}`,
+		[]string{
+			"insert expression `3` inserted in method a",
+			"update expression `2` updated to `3` in method c",
+			"update method `compute` renamed to `calculate`",
+			"update expression `length` updated to `size` in method calculate",
+			"update value `limit` changed to variable `limit` in object O",
+			"move method `c` moved within object O",
+			"move expression `x` moved within method b",
+		},
+	},
+	{
+		"package p\n\nimport a.b\n\ntrait T\n\nclass C extends T",
+		"package p\n\nclass C extends T {\n  def run(): Unit = println(\"run\")\n}\n\nobject T",
+		[]string{
+			"insert method `run` inserted in class C",
+			"insert object `T` inserted in package p",
+			"delete import `import a.b` deleted from package p",
+			"delete trait `T` deleted from package p",
+		},
+	},
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range diffTests {
+		old, err := parser.ParseFile("old.scala", test.old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		new, err := parser.ParseFile("new.scala", test.new)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var changes []string
+		for _, c := range Diff(old, new) {
+			changes = append(changes, c.Op.String()+" "+c.String())
+		}
+		if s, expected := strings.Join(changes, "\n"), strings.Join(test.expected, "\n"); s != expected {
+			t.Errorf("Diff(%q, %q) = \n%s\nExpected = \n%s", test.old, test.new, s, expected)
+		}
+	}
+}
+
+func TestDiffPositions(t *testing.T) {
+	const src = "object O {\n  def f = 1\n  def g = 2\n}\n"
+	old, _ := parser.ParseFile("old.scala", src)
+	new, _ := parser.ParseFile("new.scala", strings.Replace(src, "def g = 2", "def g = 2\n  def h = g", 1))
+	changes := Diff(old, new)
+	if len(changes) != 1 {
+		t.Fatalf("Diff = %v, Expected = 1 change", changes)
+	}
+	c := changes[0]
+	if c.Op != Insert || c.Old != nil || c.OldScope != nil || c.NewScope != new.Stats[0] {
+		t.Errorf("Diff = %+v, Expected = an insert in object O", c)
+	}
+	if offset, expected := c.New.Pos().Offset(), strings.Index(src, "}"); offset != expected+2 {
+		t.Errorf("inserted node at %d, Expected = %d", offset, expected+2)
+	}
+}
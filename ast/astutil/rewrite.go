@@ -0,0 +1,569 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file is adapted from golang.org/x/tools/go/ast/astutil/rewrite.go
+// to the Scala syntax tree.
+
+// Package astutil contains utilities for working with the Scala AST.
+package astutil
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// An ApplyFunc is invoked by Apply for each node n, even if n is nil,
+// before and/or after the node's children, using a Cursor describing
+// the current node and providing operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal.
+// See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root,
+// and calling pre and post for each node as described below.
+// Apply returns the syntax tree, possibly modified.
+//
+// If pre is not nil, it is called for each node before the node's
+// children are traversed (pre-order). If pre returns false, no
+// children are traversed, and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false,
+// post is called for each node after its children are traversed
+// (post-order). If post returns false, traversal is terminated and
+// Apply returns immediately.
+//
+// Only fields that refer to AST nodes are considered children; that
+// is, positions, names and literal values are ignored. Children are
+// traversed in the order in which they appear in the respective
+// node's struct definition. Optional children that are absent are
+// visited too, with a nil Node, so that they can be filled in with
+// Cursor.Replace.
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &struct{ ast.Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// A Cursor describes a node encountered during Apply.
+// Information about the node and its parent is available
+// from the Node, Parent, Name, and Index methods.
+//
+// If p is a variable of type and value of the current parent node
+// c.Parent(), and f is the field identifier with name c.Name(),
+// the following invariants hold:
+//
+//	p.f            == c.Node()  if c.Index() <  0
+//	p.f[c.Index()] == c.Node()  if c.Index() >= 0
+//
+// The methods Replace, Delete, InsertBefore, and InsertAfter
+// can be used to change the AST without disrupting Apply.
+type Cursor struct {
+	parent ast.Node
+	name   string
+	iter   *iterator // valid if non-nil
+	node   ast.Node
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() ast.Node { return c.node }
+
+// Parent returns the parent of the current Node.
+func (c *Cursor) Parent() ast.Node { return c.parent }
+
+// Name returns the name of the parent Node field that contains the
+// current Node. If the parent is the root passed to Apply, Name
+// returns "Node".
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of
+// Nodes that contains it, or a value < 0 if the current Node is not
+// part of a slice. The index of the current node changes if
+// InsertBefore is called while processing the current node.
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+	return -1
+}
+
+// field returns the current node's parent field value.
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current Node with n. The replacement node is
+// not walked by Apply. Replace panics if n does not fit the field
+// holding the current Node; n may be nil only for optional fields.
+func (c *Cursor) Replace(n ast.Node) {
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+	if n == nil {
+		v.Set(reflect.Zero(v.Type()))
+	} else {
+		v.Set(reflect.ValueOf(n))
+	}
+	c.node = n
+}
+
+// Delete deletes the current Node from its containing slice.
+// If the current Node is not part of a slice, Delete panics.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		panic("Delete node not contained in slice")
+	}
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current Node in its containing
+// slice. If the current Node is not part of a slice, InsertAfter
+// panics. Apply does not walk n.
+func (c *Cursor) InsertAfter(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+2, l), v.Slice(i+1, l))
+	v.Index(i + 1).Set(reflect.ValueOf(n))
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current Node in its containing
+// slice. If the current Node is not part of a slice, InsertBefore
+// panics. Apply will not walk n.
+func (c *Cursor) InsertBefore(n ast.Node) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore node not contained in slice")
+	}
+	v := c.field()
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+1, l), v.Slice(i, l))
+	v.Index(i).Set(reflect.ValueOf(n))
+	c.iter.index++
+}
+
+// application carries all the shared data so we can pass it around cheaply.
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+// An iterator controls iteration over a slice of nodes.
+type iterator struct {
+	index, step int
+}
+
+func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
+	// convert typed nil into untyped nil
+	if v := reflect.ValueOf(n); v.Kind() == reflect.Ptr && v.IsNil() {
+		n = nil
+	}
+
+	// avoid heap-allocating a new cursor for each apply call; reuse a.cursor instead
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	// walk children
+	// (the order of the cases matches the order of the corresponding node types in ast.go)
+	switch n := a.cursor.node.(type) {
+	case nil:
+		// nothing to do
+
+	// Identifiers, literals and paths
+	case *ast.Ident, *ast.Literal:
+		// nothing to do
+
+	case *ast.Select:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Sel", nil, n.Sel)
+
+	case *ast.This:
+		a.apply(n, "Qual", nil, n.Qual)
+
+	case *ast.Super:
+		a.apply(n, "Qual", nil, n.Qual)
+		a.apply(n, "Mix", nil, n.Mix)
+
+	case *ast.Interpolation:
+		a.apply(n, "Id", nil, n.Id)
+		a.applyList(n, "Args")
+
+	// Expressions
+	case *ast.BadExpr:
+		// nothing to do
+
+	case *ast.ArgList:
+		a.applyList(n, "Args")
+
+	case *ast.Apply:
+		a.apply(n, "Fun", nil, n.Fun)
+		a.apply(n, "Args", nil, n.Args)
+
+	case *ast.TypeApply:
+		a.apply(n, "Fun", nil, n.Fun)
+		a.applyList(n, "Targs")
+
+	case *ast.InfixApply:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Op", nil, n.Op)
+		a.applyList(n, "Targs")
+		a.apply(n, "Y", nil, n.Y)
+
+	case *ast.PrefixApply:
+		a.apply(n, "Op", nil, n.Op)
+		a.apply(n, "X", nil, n.X)
+
+	case *ast.PostfixApply:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Op", nil, n.Op)
+
+	case *ast.Assign:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.Typed:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.Annotated:
+		a.apply(n, "X", nil, n.X)
+		a.applyList(n, "Annotations")
+
+	case *ast.Tuple:
+		a.applyList(n, "Elts")
+
+	case *ast.Block:
+		a.applyList(n, "Stats")
+
+	case *ast.Function:
+		a.applyList(n, "Params")
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.PartialFunction:
+		a.applyList(n, "Cases")
+
+	case *ast.If:
+		a.apply(n, "Cond", nil, n.Cond)
+		a.apply(n, "Then", nil, n.Then)
+		a.apply(n, "Else", nil, n.Else)
+
+	case *ast.While:
+		a.apply(n, "Cond", nil, n.Cond)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.DoWhile:
+		a.apply(n, "Body", nil, n.Body)
+		a.apply(n, "Cond", nil, n.Cond)
+
+	case *ast.For:
+		a.applyList(n, "Enums")
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.Try:
+		a.apply(n, "Body", nil, n.Body)
+		a.applyList(n, "Cases")
+		a.apply(n, "Catch", nil, n.Catch)
+		a.apply(n, "Finally", nil, n.Finally)
+
+	case *ast.Throw:
+		a.apply(n, "X", nil, n.X)
+
+	case *ast.Return:
+		a.apply(n, "X", nil, n.X)
+
+	case *ast.Match:
+		a.apply(n, "X", nil, n.X)
+		a.applyList(n, "Cases")
+
+	case *ast.New:
+		a.apply(n, "Template", nil, n.Template)
+
+	case *ast.Quote:
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.Splice:
+		a.apply(n, "X", nil, n.X)
+
+	case *ast.CaseClause:
+		a.apply(n, "Pat", nil, n.Pat)
+		a.apply(n, "Guard", nil, n.Guard)
+		a.applyList(n, "Body")
+
+	// Enumerators
+	case *ast.Generator:
+		a.apply(n, "Pat", nil, n.Pat)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.ValueEnum:
+		a.apply(n, "Pat", nil, n.Pat)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.Guard:
+		a.apply(n, "Cond", nil, n.Cond)
+
+	// Patterns
+	case *ast.BadPattern, *ast.SeqWildcard:
+		// nothing to do
+
+	case *ast.Bind:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Pat", nil, n.Pat)
+
+	case *ast.TypedPattern:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.ExtractorPattern:
+		a.apply(n, "Fun", nil, n.Fun)
+		a.applyList(n, "Targs")
+		a.applyList(n, "Args")
+
+	case *ast.TuplePattern:
+		a.applyList(n, "Elts")
+
+	case *ast.Alternative:
+		a.applyList(n, "Alts")
+
+	case *ast.InfixPattern:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Op", nil, n.Op)
+		a.apply(n, "Y", nil, n.Y)
+
+	case *ast.InterpolatedPattern:
+		a.apply(n, "Id", nil, n.Id)
+		a.applyList(n, "Args")
+
+	// Types
+	case *ast.BadType:
+		// nothing to do
+
+	case *ast.SingletonType:
+		a.apply(n, "Ref", nil, n.Ref)
+
+	case *ast.Projection:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Sel", nil, n.Sel)
+
+	case *ast.AppliedType:
+		a.apply(n, "Type", nil, n.Type)
+		a.applyList(n, "Args")
+
+	case *ast.FunctionType:
+		a.applyList(n, "Params")
+		a.apply(n, "Result", nil, n.Result)
+
+	case *ast.ByNameType:
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.RepeatedType:
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.TupleType:
+		a.applyList(n, "Elts")
+
+	case *ast.CompoundType:
+		a.applyList(n, "Types")
+		a.applyList(n, "Decls")
+
+	case *ast.ExistentialType:
+		a.apply(n, "Type", nil, n.Type)
+		a.applyList(n, "Decls")
+
+	case *ast.InfixType:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Op", nil, n.Op)
+		a.apply(n, "Y", nil, n.Y)
+
+	case *ast.AnnotatedType:
+		a.apply(n, "Type", nil, n.Type)
+		a.applyList(n, "Annotations")
+
+	case *ast.WildcardType:
+		a.apply(n, "Lo", nil, n.Lo)
+		a.apply(n, "Hi", nil, n.Hi)
+
+	// Modifiers, annotations and parameters
+	case *ast.Modifier:
+		a.apply(n, "Qual", nil, n.Qual)
+
+	case *ast.Init:
+		a.apply(n, "Type", nil, n.Type)
+		a.applyList(n, "Args")
+
+	case *ast.Annotation:
+		a.apply(n, "Init", nil, n.Init)
+
+	case *ast.Param:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Type", nil, n.Type)
+		a.apply(n, "Default", nil, n.Default)
+
+	case *ast.ParamClause:
+		a.applyList(n, "Params")
+
+	case *ast.TypeParam:
+		a.applyList(n, "Annotations")
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParams")
+		a.apply(n, "Lo", nil, n.Lo)
+		a.apply(n, "Hi", nil, n.Hi)
+		a.applyList(n, "ViewBounds")
+		a.applyList(n, "ContextBounds")
+
+	// Definitions and other statements
+	case *ast.BadStat:
+		// nothing to do
+
+	case *ast.ValDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.applyList(n, "Pats")
+		a.apply(n, "Type", nil, n.Type)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.DefDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParams")
+		a.applyList(n, "Params")
+		a.apply(n, "ResultType", nil, n.ResultType)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.TypeDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParams")
+		a.apply(n, "Lo", nil, n.Lo)
+		a.apply(n, "Hi", nil, n.Hi)
+		a.apply(n, "Rhs", nil, n.Rhs)
+
+	case *ast.ClassDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParams")
+		a.applyList(n, "CtorAnnotations")
+		a.applyList(n, "CtorModifiers")
+		a.applyList(n, "Params")
+		a.apply(n, "Template", nil, n.Template)
+
+	case *ast.TraitDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "TypeParams")
+		a.apply(n, "Template", nil, n.Template)
+
+	case *ast.ObjectDef:
+		a.applyList(n, "Annotations")
+		a.applyList(n, "Modifiers")
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Template", nil, n.Template)
+
+	case *ast.Import:
+		a.applyList(n, "Importers")
+
+	case *ast.PackageClause:
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Stats")
+
+	case *ast.Importer:
+		a.apply(n, "Path", nil, n.Path)
+		a.applyList(n, "Selectors")
+
+	case *ast.ImportSelector:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Rename", nil, n.Rename)
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.Template:
+		a.applyList(n, "EarlyDefs")
+		a.applyList(n, "Parents")
+		a.apply(n, "Self", nil, n.Self)
+		a.applyList(n, "Stats")
+
+	case *ast.SelfType:
+		a.apply(n, "Name", nil, n.Name)
+		a.apply(n, "Type", nil, n.Type)
+
+	case *ast.File:
+		a.applyList(n, "Stats")
+
+	case *ast.Comment:
+		// nothing to do
+
+	case *ast.CommentGroup:
+		a.applyList(n, "List")
+
+	default:
+		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+func (a *application) applyList(parent ast.Node, name string) {
+	// avoid heap-allocating a new iterator for each applyList call; reuse a.iter instead
+	saved := a.iter
+	a.iter.index = 0
+	for {
+		// must reload parent.name each time, since cursor modifications might change it
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if a.iter.index >= v.Len() {
+			break
+		}
+
+		// element x may be nil in a bad AST - be cautious
+		var x ast.Node
+		if e := v.Index(a.iter.index); e.IsValid() && !(e.Kind() == reflect.Interface && e.IsNil()) {
+			x = e.Interface().(ast.Node)
+		}
+
+		a.iter.step = 1
+		a.apply(parent, name, &a.iter, x)
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
@@ -0,0 +1,233 @@
+package astutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+const rewriteFile = `package p
+
+import a.b
+import c.{d => e, _}
+
+class C[T](x: Int = 1) extends B(x) with D {
+  def f(y: Int) = {
+    g(x, 1)
+    h(y)
+    k
+  }
+  (x: Int, y) match { case (1, z) if z > 0 => z }
+}
+`
+
+func parse(t *testing.T) *ast.File {
+	f, err := parser.ParseFile("rewrite.scala", rewriteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// idents returns the names of all identifiers under n, in source order.
+func idents(n ast.Node) string {
+	var names []string
+	ast.Inspect(n, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	return strings.Join(names, " ")
+}
+
+func TestApplyOrder(t *testing.T) {
+	f := parse(t)
+	var walked []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n != nil {
+			walked = append(walked, n)
+		}
+		return true
+	})
+	var pre, post []ast.Node
+	Apply(f, func(c *Cursor) bool {
+		if c.Node() != nil {
+			pre = append(pre, c.Node())
+		}
+		return true
+	}, func(c *Cursor) bool {
+		if c.Node() != nil {
+			post = append(post, c.Node())
+		}
+		return true
+	})
+	if len(pre) != len(walked) || len(post) != len(walked) {
+		t.Fatalf("Apply visited %d/%d nodes, Expected = %d", len(pre), len(post), len(walked))
+	}
+	for i := range walked {
+		if pre[i] != walked[i] {
+			t.Fatalf("pre node %d is %T, Expected = %T", i, pre[i], walked[i])
+		}
+	}
+	if post[len(post)-1] != f {
+		t.Errorf("last post node is %T, Expected = *ast.File", post[len(post)-1])
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	f := parse(t)
+	Apply(f, func(c *Cursor) bool {
+		if id, ok := c.Node().(*ast.Ident); ok && id.Name == "z" {
+			if _, ok := c.Parent().(*ast.TuplePattern); ok {
+				if c.Name() != "Elts" || c.Index() != 1 {
+					t.Errorf("pattern z at %s[%d], Expected = Elts[1]", c.Name(), c.Index())
+				}
+			}
+		}
+		if _, ok := c.Node().(*ast.File); ok {
+			if c.Name() != "Node" || c.Index() >= 0 {
+				t.Errorf("root at %s[%d], Expected = Node", c.Name(), c.Index())
+			}
+		}
+		return true
+	}, nil)
+}
+
+func TestApplyReplace(t *testing.T) {
+	f := parse(t)
+	res := Apply(f, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.Ident:
+			if n.Name == "x" {
+				c.Replace(&ast.Ident{NamePos: n.NamePos, Name: "x0"})
+			}
+		case *ast.Literal:
+			// literals are expressions and patterns alike
+			c.Replace(&ast.Ident{NamePos: n.ValuePos, Name: "one"})
+		}
+		return true
+	}, nil)
+	if res != f {
+		t.Fatalf("Apply returned a new root")
+	}
+	want := "p a b c d e _ C T x0 Int one B x0 D f y Int g x0 one h y k x0 Int y one z z > one z"
+	if s := idents(f); s != want {
+		t.Errorf("idents = %s, Expected = %s", s, want)
+	}
+}
+
+func TestApplyReplaceRoot(t *testing.T) {
+	x, err := parser.ParseExpr("f(a)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Apply(x, func(c *Cursor) bool {
+		if _, ok := c.Node().(*ast.Apply); ok {
+			c.Replace(&ast.Ident{Name: "g"})
+			return false
+		}
+		return true
+	}, nil)
+	if s := idents(res); s != "g" {
+		t.Errorf("Apply = %s, Expected = g", s)
+	}
+}
+
+func TestApplyFillOptional(t *testing.T) {
+	f := parse(t)
+	Apply(f, nil, func(c *Cursor) bool {
+		if _, ok := c.Parent().(*ast.DefDef); ok && c.Name() == "ResultType" && c.Node() == nil {
+			c.Replace(&ast.Ident{Name: "Unit"})
+		}
+		return true
+	})
+	def := f.Stats[0].(*ast.PackageClause).Stats[2].(*ast.ClassDef).Template.Stats[0].(*ast.DefDef)
+	if id, ok := def.ResultType.(*ast.Ident); !ok || id.Name != "Unit" {
+		t.Errorf("ResultType = %#v, Expected = Unit", def.ResultType)
+	}
+}
+
+func TestApplyDelete(t *testing.T) {
+	f := parse(t)
+	sawK := false
+	Apply(f, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.Import:
+			c.Delete()
+		case *ast.Apply:
+			if _, ok := c.Parent().(*ast.Block); ok {
+				c.Delete()
+			}
+		case *ast.Ident:
+			sawK = sawK || n.Name == "k"
+		}
+		return true
+	}, nil)
+	if !sawK {
+		t.Errorf("statement after deleted statements not visited")
+	}
+	want := "p C T x Int B x D f y Int k x Int y z z > z"
+	if s := idents(f); s != want {
+		t.Errorf("idents = %s, Expected = %s", s, want)
+	}
+}
+
+func TestApplyInsert(t *testing.T) {
+	f := parse(t)
+	var visited []string
+	Apply(f, func(c *Cursor) bool {
+		if id, ok := c.Node().(*ast.Ident); ok {
+			visited = append(visited, id.Name)
+			if _, ok := c.Parent().(*ast.Block); ok && id.Name == "k" {
+				c.InsertBefore(&ast.Ident{Name: "before"})
+				c.InsertAfter(&ast.Ident{Name: "after"})
+				if c.Index() != 3 {
+					t.Errorf("Index after InsertBefore = %d, Expected = 3", c.Index())
+				}
+			}
+		}
+		return true
+	}, nil)
+	for _, name := range visited {
+		if name == "before" || name == "after" {
+			t.Errorf("Apply walked inserted node %s", name)
+		}
+	}
+	def := f.Stats[0].(*ast.PackageClause).Stats[2].(*ast.ClassDef).Template.Stats[0].(*ast.DefDef)
+	if s := idents(def.Rhs); s != "g x h y before k after" {
+		t.Errorf("block = %s, Expected = g x h y before k after", s)
+	}
+}
+
+func TestApplyAbort(t *testing.T) {
+	f := parse(t)
+	var names []string
+	Apply(f, nil, func(c *Cursor) bool {
+		if id, ok := c.Node().(*ast.Ident); ok {
+			names = append(names, id.Name)
+			return id.Name != "b"
+		}
+		return true
+	})
+	if s := strings.Join(names, " "); s != "p a b" {
+		t.Errorf("Apply visited %s, Expected = p a b", s)
+	}
+}
+
+func TestApplyPanics(t *testing.T) {
+	f := parse(t)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Delete of a field that is no slice element did not panic")
+		}
+	}()
+	Apply(f, func(c *Cursor) bool {
+		if _, ok := c.Node().(*ast.Template); ok {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
@@ -0,0 +1,240 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+)
+
+// A Comment is a // line comment or a /* block */ comment. Text holds
+// the comment with its delimiters but without the newline that ends a
+// line comment.
+type Comment struct {
+	Slash Pos // position of the first "/"
+	Text  string
+}
+
+func (c *Comment) Pos() Pos { return c.Slash }
+func (c *Comment) End() Pos { return c.Slash + Pos(len(c.Text)) }
+
+// IsDoc reports whether c is a Scaladoc comment, /** ... */.
+func (c *Comment) IsDoc() bool {
+	return strings.HasPrefix(c.Text, "/**") && c.Text != "/**/"
+}
+
+// IsLine reports whether c is a // comment, which runs to the end of the
+// line.
+func (c *Comment) IsLine() bool {
+	return strings.HasPrefix(c.Text, "//")
+}
+
+// A CommentGroup is a sequence of comments with no tokens and no blank
+// lines between them.
+type CommentGroup struct {
+	List []*Comment // len(List) > 0
+}
+
+func (g *CommentGroup) Pos() Pos { return g.List[0].Pos() }
+func (g *CommentGroup) End() Pos { return g.List[len(g.List)-1].End() }
+
+// Text returns the text of the comments of g without their delimiters,
+// the leading * of the lines of block comments and leading and trailing
+// blank lines, with one comment line per line.
+func (g *CommentGroup) Text() string {
+	var lines []string
+	for _, c := range g.List {
+		text := c.Text
+		if c.IsLine() {
+			lines = append(lines, strings.TrimRight(text[2:], " \t\r"))
+			continue
+		}
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		for i, line := range strings.Split(text, "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			if i == 0 {
+				line = strings.TrimPrefix(line, "*") // a doc comment
+			} else if t := strings.TrimLeft(line, " \t"); strings.HasPrefix(t, "*") {
+				line = t[1:]
+			}
+			lines = append(lines, line)
+		}
+	}
+	for i, line := range lines {
+		// a single space after the delimiter is not part of the text
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ----------------------------------------------------------------------------
+// Comment maps
+
+// The NodeComments of a node are the comment groups attached to it.
+type NodeComments struct {
+	Leading  []*CommentGroup // before the node, after the end of the previous one
+	Trailing []*CommentGroup // after the node, on the line it ends on
+	Inner    []*CommentGroup // inside the node, after its last child
+}
+
+func (c *NodeComments) empty() bool {
+	return len(c.Leading)+len(c.Trailing)+len(c.Inner) == 0
+}
+
+// A CommentMap maps AST nodes to the comment groups attached to them,
+// so that tools that change the tree can keep its comments. Like the
+// tree itself, the map is updated by hand: a rewrite that replaces a
+// node should call Update.
+type CommentMap map[Node]*NodeComments
+
+// NewCommentMap attaches each of the comment groups comments of src,
+// usually the Comments of a File, to a node of the tree node. A group is
+// attached to the innermost node around it, to the child of that node
+// it is between if any:
+//
+//   - as a trailing comment of the previous child if only spaces and
+//     semicolons separate them;
+//   - otherwise as a leading comment of the next child if only
+//     whitespace and comments separate them;
+//   - otherwise as an inner comment of the node.
+//
+// Groups outside of node are ignored.
+func NewCommentMap(src string, node Node, comments []*CommentGroup) CommentMap {
+	children := map[Node][]Node{}
+	var stack []Node
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if len(stack) > 0 && n.Pos().IsValid() {
+			parent := stack[len(stack)-1]
+			children[parent] = append(children[parent], n)
+		}
+		stack = append(stack, n)
+		return true
+	})
+
+	cmap := CommentMap{}
+	for _, g := range comments {
+		if g.Pos() < node.Pos() || g.End() > node.End() {
+			continue
+		}
+		n := node
+	descend:
+		for {
+			for _, c := range children[n] {
+				if c.Pos() <= g.Pos() && g.End() <= c.End() {
+					n = c
+					continue descend
+				}
+			}
+			break
+		}
+		var prev, next Node
+		for _, c := range children[n] {
+			if c.End() <= g.Pos() && (prev == nil || c.End() > prev.End()) {
+				prev = c
+			}
+			if c.Pos() >= g.End() && (next == nil || c.Pos() < next.Pos()) {
+				next = c
+			}
+		}
+		switch {
+		case prev != nil && strings.Trim(src[prev.End().Offset():g.Pos().Offset()], " \t;") == "":
+			cmap.get(prev).Trailing = append(cmap.get(prev).Trailing, g)
+		case next != nil && blank(src[g.End().Offset():next.Pos().Offset()]):
+			cmap.get(next).Leading = append(cmap.get(next).Leading, g)
+		default:
+			cmap.get(n).Inner = append(cmap.get(n).Inner, g)
+		}
+	}
+	return cmap
+}
+
+// blank reports whether s holds nothing but whitespace and comments.
+func blank(s string) bool {
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "//"):
+			i := strings.IndexByte(s, '\n')
+			if i < 0 {
+				return true
+			}
+			s = s[i:]
+		case strings.HasPrefix(s, "/*"):
+			i := strings.Index(s, "*/")
+			if i < 0 {
+				return false
+			}
+			s = s[i+2:]
+		case strings.IndexByte(" \t\r\n\f", s[0]) >= 0:
+			s = s[1:]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the comments of n, adding an entry for n if there is none.
+func (cmap CommentMap) get(n Node) *NodeComments {
+	c := cmap[n]
+	if c == nil {
+		c = &NodeComments{}
+		cmap[n] = c
+	}
+	return c
+}
+
+// Update replaces the node old by new in cmap: the comments of old are
+// attached to new, after the ones new may already have. It returns new,
+// so that a rewrite can replace and update at once, as in
+//
+//	c.Replace(cmap.Update(c.Node(), replacement))
+//
+// in an astutil.ApplyFunc.
+func (cmap CommentMap) Update(old, new Node) Node {
+	if c := cmap[old]; c != nil {
+		delete(cmap, old)
+		if new != nil {
+			nc := cmap.get(new)
+			nc.Leading = append(nc.Leading, c.Leading...)
+			nc.Trailing = append(nc.Trailing, c.Trailing...)
+			nc.Inner = append(nc.Inner, c.Inner...)
+		}
+	}
+	return new
+}
+
+// Filter returns a new comment map with the entries of cmap for node and
+// the nodes under it.
+func (cmap CommentMap) Filter(node Node) CommentMap {
+	res := CommentMap{}
+	Inspect(node, func(n Node) bool {
+		if c := cmap[n]; c != nil && !c.empty() {
+			res[n] = c
+		}
+		return true
+	})
+	return res
+}
+
+// Comments returns the comment groups of cmap, in source order.
+func (cmap CommentMap) Comments() []*CommentGroup {
+	var list []*CommentGroup
+	for _, c := range cmap {
+		list = append(list, c.Leading...)
+		list = append(list, c.Trailing...)
+		list = append(list, c.Inner...)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Pos() < list[j].Pos() })
+	return list
+}
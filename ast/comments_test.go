@@ -0,0 +1,114 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/ast/astutil"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+const commentMapFile = `// about O
+object O {
+  /** f */
+  def f = 1 // one
+  val x = { // two
+    g
+    // three
+  }
+  try a catch {
+    case _ => b
+    // four
+  } finally c
+}
+// five
+`
+
+func TestCommentMap(t *testing.T) {
+	f, err := parser.ParseFile("test.scala", commentMapFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap := ast.NewCommentMap(commentMapFile, f, f.Comments)
+	o := f.Stats[0].(*ast.ObjectDef)
+	def := o.Template.Stats[0]
+	val := o.Template.Stats[1].(*ast.ValDef)
+	block := val.Rhs.(*ast.Block)
+	try := o.Template.Stats[2]
+	tests := []struct {
+		node                     ast.Node
+		leading, trailing, inner string
+	}{
+		{o, "about O\n", "", ""},
+		{def, "f\n", "one\n", ""},
+		{block.Stats[0], "two\n", "", ""},
+		{block, "", "", "three\n"},
+		{try, "", "", "four\n"},
+		{f, "", "", "five\n"},
+	}
+	text := func(list []*ast.CommentGroup) string {
+		s := ""
+		for _, g := range list {
+			s += g.Text()
+		}
+		return s
+	}
+	for _, test := range tests {
+		c := cmap[test.node]
+		if c == nil {
+			t.Errorf("%T has no comments", test.node)
+			continue
+		}
+		if s := text(c.Leading); s != test.leading {
+			t.Errorf("%T leading = %q, Expected = %q", test.node, s, test.leading)
+		}
+		if s := text(c.Trailing); s != test.trailing {
+			t.Errorf("%T trailing = %q, Expected = %q", test.node, s, test.trailing)
+		}
+		if s := text(c.Inner); s != test.inner {
+			t.Errorf("%T inner = %q, Expected = %q", test.node, s, test.inner)
+		}
+	}
+	if n := len(cmap.Comments()); n != len(f.Comments) {
+		t.Errorf("len(Comments()) = %d, Expected = %d", n, len(f.Comments))
+	}
+	if n := len(cmap.Filter(o.Template).Comments()); n != 5 {
+		t.Errorf("len(Filter(template).Comments()) = %d, Expected = 5", n)
+	}
+
+	// Replacing the definition of f keeps its comments.
+	replacement := &ast.DefDef{Name: &ast.Ident{Name: "f"}, Rhs: &ast.Literal{Value: "2"}}
+	astutil.Apply(f, func(c *astutil.Cursor) bool {
+		if c.Node() == def {
+			c.Replace(cmap.Update(c.Node(), replacement))
+		}
+		return true
+	}, nil)
+	if cmap[def] != nil {
+		t.Errorf("Update left the comments of the old node")
+	}
+	if c := cmap[replacement]; c == nil || text(c.Leading) != "f\n" || text(c.Trailing) != "one\n" {
+		t.Errorf("Update did not move the comments to the new node")
+	}
+}
+
+func TestCommentGroupText(t *testing.T) {
+	tests := []struct {
+		list     []string
+		expected string
+	}{
+		{[]string{"// a", "//  b"}, "a\n b\n"},
+		{[]string{"/** Doc.\n  *\n  * More.\n  */"}, "Doc.\n\nMore.\n"},
+		{[]string{"/* x */"}, "x\n"},
+		{[]string{"//", "/**/"}, ""},
+	}
+	for _, test := range tests {
+		g := &ast.CommentGroup{}
+		for _, text := range test.list {
+			g.List = append(g.List, &ast.Comment{Text: text})
+		}
+		if s := g.Text(); s != test.expected {
+			t.Errorf("Text(%q) = %q, Expected = %q", test.list, s, test.expected)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ----------------------------------------------------------------------------
+// JSON encoding
+//
+// Every node encodes as a JSON object
+//
+//	{"kind": "Ident", "span": {"start": 4, "end": 7}, "fields": {"NamePos": 4, "Name": "x"}}
+//
+// Kind is the name of the type of the node. Span holds the byte offsets
+// of the start and the end of the node, as given by Pos and End; it is
+// left out if the node has no position. Fields holds the fields of the
+// node by their Go names: positions as byte offsets, nodes as objects of
+// this form, lists as arrays, a LitKind by name and strings and booleans
+// as such. Fields with zero values, such as nil, empty lists and NoPos,
+// are left out. Decoding ignores spans. The schema is documented in
+// schema/ast.schema.json at the root of the module.
+
+// nodeTypes maps kinds to node types.
+var nodeTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, n := range []Node{
+		(*Ident)(nil), (*Select)(nil), (*This)(nil), (*Super)(nil), (*Literal)(nil),
+		(*Interpolation)(nil), (*BadExpr)(nil), (*ArgList)(nil), (*Apply)(nil),
+		(*TypeApply)(nil), (*InfixApply)(nil), (*PrefixApply)(nil),
+		(*PostfixApply)(nil), (*Assign)(nil), (*Typed)(nil), (*Annotated)(nil),
+		(*Tuple)(nil), (*Block)(nil), (*Function)(nil), (*PartialFunction)(nil),
+		(*If)(nil), (*While)(nil), (*DoWhile)(nil), (*For)(nil), (*Try)(nil),
+		(*Throw)(nil), (*Return)(nil), (*Match)(nil), (*New)(nil), (*Quote)(nil),
+		(*Splice)(nil), (*CaseClause)(nil), (*Generator)(nil), (*ValueEnum)(nil),
+		(*Guard)(nil), (*BadPattern)(nil), (*Bind)(nil), (*TypedPattern)(nil),
+		(*ExtractorPattern)(nil), (*SeqWildcard)(nil), (*TuplePattern)(nil),
+		(*Alternative)(nil), (*InfixPattern)(nil), (*InterpolatedPattern)(nil),
+		(*BadType)(nil), (*SingletonType)(nil), (*Projection)(nil),
+		(*AppliedType)(nil), (*FunctionType)(nil), (*ByNameType)(nil),
+		(*RepeatedType)(nil), (*TupleType)(nil), (*CompoundType)(nil),
+		(*ExistentialType)(nil), (*InfixType)(nil), (*AnnotatedType)(nil),
+		(*WildcardType)(nil), (*Modifier)(nil), (*Init)(nil), (*Annotation)(nil),
+		(*Param)(nil), (*ParamClause)(nil), (*TypeParam)(nil), (*BadStat)(nil),
+		(*ValDef)(nil), (*DefDef)(nil), (*TypeDef)(nil), (*ClassDef)(nil),
+		(*TraitDef)(nil), (*ObjectDef)(nil), (*Import)(nil), (*PackageClause)(nil),
+		(*Importer)(nil), (*ImportSelector)(nil), (*Template)(nil),
+		(*SelfType)(nil), (*File)(nil), (*Comment)(nil), (*CommentGroup)(nil),
+	} {
+		t := reflect.TypeOf(n).Elem()
+		nodeTypes[t.Name()] = t
+	}
+}
+
+var (
+	nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+	posType  = reflect.TypeOf(NoPos)
+)
+
+func (x *Ident) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Ident) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Select) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *Select) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *This) MarshalJSON() ([]byte, error)                   { return marshalNode(x) }
+func (x *This) UnmarshalJSON(data []byte) error                { return unmarshalNode(data, x) }
+func (x *Super) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Super) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Literal) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *Literal) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *Interpolation) MarshalJSON() ([]byte, error)          { return marshalNode(x) }
+func (x *Interpolation) UnmarshalJSON(data []byte) error       { return unmarshalNode(data, x) }
+func (x *BadExpr) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *BadExpr) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *ArgList) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *ArgList) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *Apply) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Apply) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *TypeApply) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *TypeApply) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *InfixApply) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *InfixApply) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *PrefixApply) MarshalJSON() ([]byte, error)            { return marshalNode(x) }
+func (x *PrefixApply) UnmarshalJSON(data []byte) error         { return unmarshalNode(data, x) }
+func (x *PostfixApply) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *PostfixApply) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *Assign) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *Assign) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *Typed) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Typed) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Annotated) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *Annotated) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *Tuple) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Tuple) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Block) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Block) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Function) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *Function) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *PartialFunction) MarshalJSON() ([]byte, error)        { return marshalNode(x) }
+func (x *PartialFunction) UnmarshalJSON(data []byte) error     { return unmarshalNode(data, x) }
+func (x *If) MarshalJSON() ([]byte, error)                     { return marshalNode(x) }
+func (x *If) UnmarshalJSON(data []byte) error                  { return unmarshalNode(data, x) }
+func (x *While) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *While) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *DoWhile) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *DoWhile) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *For) MarshalJSON() ([]byte, error)                    { return marshalNode(x) }
+func (x *For) UnmarshalJSON(data []byte) error                 { return unmarshalNode(data, x) }
+func (x *Try) MarshalJSON() ([]byte, error)                    { return marshalNode(x) }
+func (x *Try) UnmarshalJSON(data []byte) error                 { return unmarshalNode(data, x) }
+func (x *Throw) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Throw) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Return) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *Return) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *Match) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Match) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *New) MarshalJSON() ([]byte, error)                    { return marshalNode(x) }
+func (x *New) UnmarshalJSON(data []byte) error                 { return unmarshalNode(data, x) }
+func (x *Quote) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Quote) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *Splice) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *Splice) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *CaseClause) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *CaseClause) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *Generator) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *Generator) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *ValueEnum) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *ValueEnum) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *Guard) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Guard) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *BadPattern) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *BadPattern) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *Bind) MarshalJSON() ([]byte, error)                   { return marshalNode(x) }
+func (x *Bind) UnmarshalJSON(data []byte) error                { return unmarshalNode(data, x) }
+func (x *TypedPattern) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *TypedPattern) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *ExtractorPattern) MarshalJSON() ([]byte, error)       { return marshalNode(x) }
+func (x *ExtractorPattern) UnmarshalJSON(data []byte) error    { return unmarshalNode(data, x) }
+func (x *SeqWildcard) MarshalJSON() ([]byte, error)            { return marshalNode(x) }
+func (x *SeqWildcard) UnmarshalJSON(data []byte) error         { return unmarshalNode(data, x) }
+func (x *TuplePattern) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *TuplePattern) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *Alternative) MarshalJSON() ([]byte, error)            { return marshalNode(x) }
+func (x *Alternative) UnmarshalJSON(data []byte) error         { return unmarshalNode(data, x) }
+func (x *InfixPattern) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *InfixPattern) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *InterpolatedPattern) MarshalJSON() ([]byte, error)    { return marshalNode(x) }
+func (x *InterpolatedPattern) UnmarshalJSON(data []byte) error { return unmarshalNode(data, x) }
+func (x *BadType) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *BadType) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *SingletonType) MarshalJSON() ([]byte, error)          { return marshalNode(x) }
+func (x *SingletonType) UnmarshalJSON(data []byte) error       { return unmarshalNode(data, x) }
+func (x *Projection) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *Projection) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *AppliedType) MarshalJSON() ([]byte, error)            { return marshalNode(x) }
+func (x *AppliedType) UnmarshalJSON(data []byte) error         { return unmarshalNode(data, x) }
+func (x *FunctionType) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *FunctionType) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *ByNameType) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *ByNameType) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *RepeatedType) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *RepeatedType) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *TupleType) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *TupleType) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *CompoundType) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *CompoundType) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *ExistentialType) MarshalJSON() ([]byte, error)        { return marshalNode(x) }
+func (x *ExistentialType) UnmarshalJSON(data []byte) error     { return unmarshalNode(data, x) }
+func (x *InfixType) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *InfixType) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *AnnotatedType) MarshalJSON() ([]byte, error)          { return marshalNode(x) }
+func (x *AnnotatedType) UnmarshalJSON(data []byte) error       { return unmarshalNode(data, x) }
+func (x *WildcardType) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *WildcardType) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+func (x *Modifier) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *Modifier) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *Init) MarshalJSON() ([]byte, error)                   { return marshalNode(x) }
+func (x *Init) UnmarshalJSON(data []byte) error                { return unmarshalNode(data, x) }
+func (x *Annotation) MarshalJSON() ([]byte, error)             { return marshalNode(x) }
+func (x *Annotation) UnmarshalJSON(data []byte) error          { return unmarshalNode(data, x) }
+func (x *Param) MarshalJSON() ([]byte, error)                  { return marshalNode(x) }
+func (x *Param) UnmarshalJSON(data []byte) error               { return unmarshalNode(data, x) }
+func (x *ParamClause) MarshalJSON() ([]byte, error)            { return marshalNode(x) }
+func (x *ParamClause) UnmarshalJSON(data []byte) error         { return unmarshalNode(data, x) }
+func (x *TypeParam) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *TypeParam) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *BadStat) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *BadStat) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *ValDef) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *ValDef) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *DefDef) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *DefDef) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *TypeDef) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *TypeDef) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *ClassDef) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *ClassDef) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *TraitDef) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *TraitDef) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *ObjectDef) MarshalJSON() ([]byte, error)              { return marshalNode(x) }
+func (x *ObjectDef) UnmarshalJSON(data []byte) error           { return unmarshalNode(data, x) }
+func (x *Import) MarshalJSON() ([]byte, error)                 { return marshalNode(x) }
+func (x *Import) UnmarshalJSON(data []byte) error              { return unmarshalNode(data, x) }
+func (x *PackageClause) MarshalJSON() ([]byte, error)          { return marshalNode(x) }
+func (x *PackageClause) UnmarshalJSON(data []byte) error       { return unmarshalNode(data, x) }
+func (x *Importer) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *Importer) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *ImportSelector) MarshalJSON() ([]byte, error)         { return marshalNode(x) }
+func (x *ImportSelector) UnmarshalJSON(data []byte) error      { return unmarshalNode(data, x) }
+func (x *Template) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *Template) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *SelfType) MarshalJSON() ([]byte, error)               { return marshalNode(x) }
+func (x *SelfType) UnmarshalJSON(data []byte) error            { return unmarshalNode(data, x) }
+func (x *File) MarshalJSON() ([]byte, error)                   { return marshalNode(x) }
+func (x *File) UnmarshalJSON(data []byte) error                { return unmarshalNode(data, x) }
+func (x *Comment) MarshalJSON() ([]byte, error)                { return marshalNode(x) }
+func (x *Comment) UnmarshalJSON(data []byte) error             { return unmarshalNode(data, x) }
+func (x *CommentGroup) MarshalJSON() ([]byte, error)           { return marshalNode(x) }
+func (x *CommentGroup) UnmarshalJSON(data []byte) error        { return unmarshalNode(data, x) }
+
+// UnmarshalNode decodes a node of any kind from its JSON encoding.
+func UnmarshalNode(data []byte) (Node, error) {
+	var n struct{ Kind string }
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	t := nodeTypes[n.Kind]
+	if t == nil {
+		return nil, fmt.Errorf("ast: unknown node kind %q", n.Kind)
+	}
+	x := reflect.New(t).Interface().(Node)
+	if err := unmarshalNode(data, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func marshalNode(x Node) ([]byte, error) {
+	var buf bytes.Buffer
+	v := reflect.ValueOf(x).Elem()
+	fmt.Fprintf(&buf, `{"kind":"%s"`, v.Type().Name())
+	if start, end, ok := span(x); ok {
+		fmt.Fprintf(&buf, `,"span":{"start":%d,"end":%d}`, start.Offset(), end.Offset())
+	}
+	buf.WriteString(`,"fields":{`)
+	first := true
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.IsZero() || f.Kind() == reflect.Slice && f.Len() == 0 {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(v.Type().Field(i).Name))
+		buf.WriteByte(':')
+		if f.Type() == posType {
+			buf.WriteString(strconv.Itoa(Pos(f.Int()).Offset()))
+			continue
+		}
+		// Scala is full of < and >, which json.Marshal would escape.
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(f.Interface()); err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1) // the newline Encode adds
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// span returns the extent of x. Nodes built by hand may lack the fields
+// that Pos and End need, which then panic.
+func span(x Node) (start, end Pos, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	start, end = x.Pos(), x.End()
+	return start, end, start.IsValid() && end.IsValid()
+}
+
+func unmarshalNode(data []byte, x Node) error {
+	var n struct {
+		Kind   string
+		Fields map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(x).Elem()
+	if n.Kind != v.Type().Name() {
+		return fmt.Errorf("ast: cannot decode a %s node into a %s", n.Kind, v.Type().Name())
+	}
+	v.Set(reflect.Zero(v.Type()))
+	for name, raw := range n.Fields {
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			return fmt.Errorf("ast: %s has no field %s", n.Kind, name)
+		}
+		if err := decodeValue(f, raw); err != nil {
+			return fmt.Errorf("ast: %s.%s: %v", n.Kind, name, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes the field value v from raw.
+func decodeValue(v reflect.Value, raw json.RawMessage) error {
+	t := v.Type()
+	switch {
+	case t == posType:
+		var offset int
+		if err := json.Unmarshal(raw, &offset); err != nil {
+			return err
+		}
+		v.SetInt(int64(PosOf(offset)))
+	case t.Implements(nodeType):
+		if string(raw) == "null" {
+			return nil
+		}
+		n, err := UnmarshalNode(raw)
+		if err != nil {
+			return err
+		}
+		nv := reflect.ValueOf(n)
+		if !nv.Type().AssignableTo(t) {
+			return fmt.Errorf("a %s node is not a %s", nv.Elem().Type().Name(), t.Name())
+		}
+		v.Set(nv)
+	case t.Kind() == reflect.Slice && t.Elem().Implements(nodeType):
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(t, len(list), len(list))
+		for i, raw := range list {
+			if err := decodeValue(s.Index(i), raw); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+	return nil
+}
+
+// MarshalText encodes k by name, as in a JSON encoding of a Literal.
+func (k LitKind) MarshalText() ([]byte, error) {
+	if k < IntLit || k > NullLit {
+		return nil, fmt.Errorf("ast: invalid LitKind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *LitKind) UnmarshalText(text []byte) error {
+	for kind := IntLit; kind <= NullLit; kind++ {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("ast: unknown LitKind %q", text)
+}
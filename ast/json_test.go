@@ -0,0 +1,81 @@
+package ast_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	x, err := parser.ParseExpr(`f(a < "b")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(x); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"kind":"Apply","span":{"start":0,"end":10},"fields":{` +
+		`"Fun":{"kind":"Ident","span":{"start":0,"end":1},"fields":{"NamePos":0,"Name":"f"}},` +
+		`"Args":{"kind":"ArgList","span":{"start":1,"end":10},"fields":{"Lparen":1,"Args":[` +
+		`{"kind":"InfixApply","span":{"start":2,"end":9},"fields":{` +
+		`"X":{"kind":"Ident","span":{"start":2,"end":3},"fields":{"NamePos":2,"Name":"a"}},` +
+		`"Op":{"kind":"Ident","span":{"start":4,"end":5},"fields":{"NamePos":4,"Name":"<"}},` +
+		`"Y":{"kind":"Literal","span":{"start":6,"end":9},"fields":{"ValuePos":6,"Kind":"String","Value":"\"b\""}}}}],` +
+		`"Rparen":9}}}}` + "\n"
+	if s := buf.String(); s != expected {
+		t.Errorf("Encode = %s, Expected = %s", s, expected)
+	}
+}
+
+func TestUnmarshalNode(t *testing.T) {
+	f, err := parser.ParseFile("walk.scala", walkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ast.UnmarshalNode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := n.(*ast.File); !ok {
+		t.Fatalf("UnmarshalNode = %T, Expected = *ast.File", n)
+	}
+	if n.Pos() != f.Pos() || n.End() != f.End() {
+		t.Errorf("UnmarshalNode spans %d..%d, Expected = %d..%d", n.Pos(), n.End(), f.Pos(), f.End())
+	}
+	again, err := json.Marshal(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("Marshal(UnmarshalNode(data)) = %s, Expected = %s", again, data)
+	}
+
+	var ident ast.Ident
+	if err := json.Unmarshal([]byte(`{"kind":"Ident","fields":{"Name":"x","NamePos":3}}`), &ident); err != nil {
+		t.Fatal(err)
+	}
+	if ident.Name != "x" || ident.NamePos.Offset() != 3 {
+		t.Errorf("Unmarshal = %+v, Expected = x at 3", ident)
+	}
+
+	for _, bad := range []string{
+		`{"kind":"Nothing"}`,
+		`{"kind":"Ident","fields":{"Nope":1}}`,
+		`{"kind":"Typed","fields":{"Type":{"kind":"Block","fields":{}}}}`,
+		`{"kind":"Literal","fields":{"Kind":"BigLit"}}`,
+	} {
+		if _, err := ast.UnmarshalNode([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalNode(%s): Expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite schema/ast.schema.json")
+
+type object = map[string]interface{}
+
+// schema returns the JSON schema of the JSON encoding of nodes.
+func schema() object {
+	var kinds []string
+	for kind := range nodeTypes {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	ref := func(name string) object { return object{"$ref": "#/$defs/" + name} }
+	// oneOf returns the schema of a node of interface type t.
+	oneOf := func(t reflect.Type) object {
+		var list []object
+		for _, kind := range kinds {
+			if reflect.PtrTo(nodeTypes[kind]).Implements(t) {
+				list = append(list, ref(kind))
+			}
+		}
+		return object{"oneOf": list}
+	}
+	var field func(t reflect.Type) object
+	field = func(t reflect.Type) object {
+		switch {
+		case t == posType:
+			return ref("pos")
+		case t == reflect.TypeOf(IntLit):
+			return ref("LitKind")
+		case t.Kind() == reflect.Interface:
+			return ref(t.Name())
+		case t.Kind() == reflect.Ptr:
+			return ref(t.Elem().Name())
+		case t.Kind() == reflect.Slice:
+			return object{"type": "array", "items": field(t.Elem())}
+		case t.Kind() == reflect.String:
+			return object{"type": "string"}
+		case t.Kind() == reflect.Bool:
+			return object{"type": "boolean"}
+		}
+		panic("no schema for " + t.String())
+	}
+
+	var litKinds []string
+	for k := IntLit; k <= NullLit; k++ {
+		litKinds = append(litKinds, k.String())
+	}
+	defs := object{
+		"pos": object{"description": "a byte offset in the source", "type": "integer", "minimum": 0},
+		"span": object{
+			"description":          "the byte offsets of the start and the end of a node, which is exclusive",
+			"type":                 "object",
+			"required":             []string{"start", "end"},
+			"properties":           object{"start": ref("pos"), "end": ref("pos")},
+			"additionalProperties": false,
+		},
+		"LitKind": object{"enum": litKinds},
+	}
+	for _, t := range []reflect.Type{nodeType, exprType, patternType, typeType, statType, enumeratorType} {
+		defs[t.Name()] = oneOf(t)
+	}
+	for _, kind := range kinds {
+		t := nodeTypes[kind]
+		fields := object{}
+		for i := 0; i < t.NumField(); i++ {
+			fields[t.Field(i).Name] = field(t.Field(i).Type)
+		}
+		defs[kind] = object{
+			"type":     "object",
+			"required": []string{"kind", "fields"},
+			"properties": object{
+				"kind": object{"const": kind},
+				"span": ref("span"),
+				"fields": object{
+					"type":                 "object",
+					"properties":           fields,
+					"additionalProperties": false,
+				},
+			},
+			"additionalProperties": false,
+		}
+	}
+	return object{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         "https://github.com/sundargates/scalaparser/schema/ast.schema.json",
+		"title":       "Scala syntax tree",
+		"description": "A node of the syntax tree of a Scala source file, as encoded by the MarshalJSON methods of package ast. Fields are named as in Go and left out when they are zero: nil, empty, false, \"\" or no position.",
+		"$ref":        "#/$defs/Node",
+		"$defs":       defs,
+	}
+}
+
+var (
+	exprType       = reflect.TypeOf((*Expr)(nil)).Elem()
+	patternType    = reflect.TypeOf((*Pattern)(nil)).Elem()
+	typeType       = reflect.TypeOf((*Type)(nil)).Elem()
+	statType       = reflect.TypeOf((*Stat)(nil)).Elem()
+	enumeratorType = reflect.TypeOf((*Enumerator)(nil)).Elem()
+)
+
+func TestSchema(t *testing.T) {
+	data, err := json.MarshalIndent(schema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+	filename := filepath.Join("..", "schema", "ast.schema.json")
+	if *update {
+		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old, data) {
+		t.Errorf("%s is out of date; run go test ./ast -run TestSchema -update", filename)
+	}
+}
@@ -0,0 +1,486 @@
+package ast
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+func walkIdent(v Visitor, x *Ident) {
+	if x != nil {
+		Walk(v, x)
+	}
+}
+
+func walkExprs(v Visitor, list []Expr) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+func walkStats(v Visitor, list []Stat) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+func walkTypes(v Visitor, list []Type) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+func walkPatterns(v Visitor, list []Pattern) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+func walkCases(v Visitor, list []*CaseClause) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+// walkMods walks the annotations and modifiers of a definition.
+func walkMods(v Visitor, annots []*Annotation, mods []*Modifier) {
+	for _, x := range annots {
+		Walk(v, x)
+	}
+	for _, x := range mods {
+		Walk(v, x)
+	}
+}
+
+func walkTypeParams(v Visitor, list []*TypeParam) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+func walkParamClauses(v Visitor, list []*ParamClause) {
+	for _, x := range list {
+		Walk(v, x)
+	}
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor w
+// for each of the non-nil children of node, in source order, followed by
+// a call of w.Visit(nil).
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// Identifiers, literals and paths
+	case *Ident, *Literal:
+		// nothing to do
+
+	case *Select:
+		Walk(v, n.X)
+		Walk(v, n.Sel)
+
+	case *This:
+		walkIdent(v, n.Qual)
+
+	case *Super:
+		walkIdent(v, n.Qual)
+		walkIdent(v, n.Mix)
+
+	case *Interpolation:
+		Walk(v, n.Id)
+		walkExprs(v, n.Args)
+
+	// Expressions
+	case *BadExpr:
+		// nothing to do
+
+	case *ArgList:
+		walkExprs(v, n.Args)
+
+	case *Apply:
+		Walk(v, n.Fun)
+		Walk(v, n.Args)
+
+	case *TypeApply:
+		Walk(v, n.Fun)
+		walkTypes(v, n.Targs)
+
+	case *InfixApply:
+		Walk(v, n.X)
+		Walk(v, n.Op)
+		walkTypes(v, n.Targs)
+		Walk(v, n.Y)
+
+	case *PrefixApply:
+		Walk(v, n.Op)
+		Walk(v, n.X)
+
+	case *PostfixApply:
+		Walk(v, n.X)
+		Walk(v, n.Op)
+
+	case *Assign:
+		Walk(v, n.X)
+		Walk(v, n.Rhs)
+
+	case *Typed:
+		Walk(v, n.X)
+		Walk(v, n.Type)
+
+	case *Annotated:
+		Walk(v, n.X)
+		walkMods(v, n.Annotations, nil)
+
+	case *Tuple:
+		walkExprs(v, n.Elts)
+
+	case *Block:
+		walkStats(v, n.Stats)
+
+	case *Function:
+		for _, x := range n.Params {
+			Walk(v, x)
+		}
+		Walk(v, n.Body)
+
+	case *PartialFunction:
+		walkCases(v, n.Cases)
+
+	case *If:
+		Walk(v, n.Cond)
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+
+	case *While:
+		Walk(v, n.Cond)
+		Walk(v, n.Body)
+
+	case *DoWhile:
+		Walk(v, n.Body)
+		Walk(v, n.Cond)
+
+	case *For:
+		for _, x := range n.Enums {
+			Walk(v, x)
+		}
+		Walk(v, n.Body)
+
+	case *Try:
+		Walk(v, n.Body)
+		walkCases(v, n.Cases)
+		if n.Catch != nil {
+			Walk(v, n.Catch)
+		}
+		if n.Finally != nil {
+			Walk(v, n.Finally)
+		}
+
+	case *Throw:
+		Walk(v, n.X)
+
+	case *Return:
+		if n.X != nil {
+			Walk(v, n.X)
+		}
+
+	case *Match:
+		Walk(v, n.X)
+		walkCases(v, n.Cases)
+
+	case *New:
+		Walk(v, n.Template)
+
+	case *Quote:
+		Walk(v, n.Body)
+
+	case *Splice:
+		Walk(v, n.X)
+
+	case *CaseClause:
+		Walk(v, n.Pat)
+		if n.Guard != nil {
+			Walk(v, n.Guard)
+		}
+		walkStats(v, n.Body)
+
+	// Enumerators
+	case *Generator:
+		Walk(v, n.Pat)
+		Walk(v, n.Rhs)
+
+	case *ValueEnum:
+		Walk(v, n.Pat)
+		Walk(v, n.Rhs)
+
+	case *Guard:
+		Walk(v, n.Cond)
+
+	// Patterns
+	case *BadPattern, *SeqWildcard:
+		// nothing to do
+
+	case *Bind:
+		Walk(v, n.Name)
+		Walk(v, n.Pat)
+
+	case *TypedPattern:
+		Walk(v, n.X)
+		Walk(v, n.Type)
+
+	case *ExtractorPattern:
+		Walk(v, n.Fun)
+		walkTypes(v, n.Targs)
+		walkPatterns(v, n.Args)
+
+	case *TuplePattern:
+		walkPatterns(v, n.Elts)
+
+	case *Alternative:
+		walkPatterns(v, n.Alts)
+
+	case *InfixPattern:
+		Walk(v, n.X)
+		Walk(v, n.Op)
+		Walk(v, n.Y)
+
+	case *InterpolatedPattern:
+		Walk(v, n.Id)
+		walkPatterns(v, n.Args)
+
+	// Types
+	case *BadType:
+		// nothing to do
+
+	case *SingletonType:
+		Walk(v, n.Ref)
+
+	case *Projection:
+		Walk(v, n.X)
+		Walk(v, n.Sel)
+
+	case *AppliedType:
+		Walk(v, n.Type)
+		walkTypes(v, n.Args)
+
+	case *FunctionType:
+		walkTypes(v, n.Params)
+		Walk(v, n.Result)
+
+	case *ByNameType:
+		Walk(v, n.Type)
+
+	case *RepeatedType:
+		Walk(v, n.Type)
+
+	case *TupleType:
+		walkTypes(v, n.Elts)
+
+	case *CompoundType:
+		walkTypes(v, n.Types)
+		walkStats(v, n.Decls)
+
+	case *ExistentialType:
+		Walk(v, n.Type)
+		walkStats(v, n.Decls)
+
+	case *InfixType:
+		Walk(v, n.X)
+		Walk(v, n.Op)
+		Walk(v, n.Y)
+
+	case *AnnotatedType:
+		Walk(v, n.Type)
+		walkMods(v, n.Annotations, nil)
+
+	case *WildcardType:
+		if n.Lo != nil {
+			Walk(v, n.Lo)
+		}
+		if n.Hi != nil {
+			Walk(v, n.Hi)
+		}
+
+	// Modifiers, annotations and parameters
+	case *Modifier:
+		if n.Qual != nil {
+			Walk(v, n.Qual)
+		}
+
+	case *Init:
+		Walk(v, n.Type)
+		for _, x := range n.Args {
+			Walk(v, x)
+		}
+
+	case *Annotation:
+		Walk(v, n.Init)
+
+	case *Param:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		if n.Default != nil {
+			Walk(v, n.Default)
+		}
+
+	case *ParamClause:
+		for _, x := range n.Params {
+			Walk(v, x)
+		}
+
+	case *TypeParam:
+		walkMods(v, n.Annotations, nil)
+		Walk(v, n.Name)
+		walkTypeParams(v, n.TypeParams)
+		if n.Lo != nil {
+			Walk(v, n.Lo)
+		}
+		if n.Hi != nil {
+			Walk(v, n.Hi)
+		}
+		walkTypes(v, n.ViewBounds)
+		walkTypes(v, n.ContextBounds)
+
+	// Definitions and other statements
+	case *BadStat:
+		// nothing to do
+
+	case *ValDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		walkPatterns(v, n.Pats)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+		if n.Rhs != nil {
+			Walk(v, n.Rhs)
+		}
+
+	case *DefDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		walkTypeParams(v, n.TypeParams)
+		walkParamClauses(v, n.Params)
+		if n.ResultType != nil {
+			Walk(v, n.ResultType)
+		}
+		if n.Rhs != nil {
+			Walk(v, n.Rhs)
+		}
+
+	case *TypeDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		walkTypeParams(v, n.TypeParams)
+		if n.Lo != nil {
+			Walk(v, n.Lo)
+		}
+		if n.Hi != nil {
+			Walk(v, n.Hi)
+		}
+		if n.Rhs != nil {
+			Walk(v, n.Rhs)
+		}
+
+	case *ClassDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		walkTypeParams(v, n.TypeParams)
+		walkMods(v, n.CtorAnnotations, n.CtorModifiers)
+		walkParamClauses(v, n.Params)
+		Walk(v, n.Template)
+
+	case *TraitDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		walkTypeParams(v, n.TypeParams)
+		Walk(v, n.Template)
+
+	case *ObjectDef:
+		walkMods(v, n.Annotations, n.Modifiers)
+		Walk(v, n.Name)
+		Walk(v, n.Template)
+
+	case *Import:
+		for _, x := range n.Importers {
+			Walk(v, x)
+		}
+
+	case *PackageClause:
+		Walk(v, n.Name)
+		walkStats(v, n.Stats)
+
+	case *Importer:
+		Walk(v, n.Path)
+		for _, x := range n.Selectors {
+			Walk(v, x)
+		}
+
+	case *ImportSelector:
+		walkIdent(v, n.Name)
+		walkIdent(v, n.Rename)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+
+	case *Template:
+		walkStats(v, n.EarlyDefs)
+		for _, x := range n.Parents {
+			Walk(v, x)
+		}
+		if n.Self != nil {
+			Walk(v, n.Self)
+		}
+		walkStats(v, n.Stats)
+
+	case *SelfType:
+		Walk(v, n.Name)
+		if n.Type != nil {
+			Walk(v, n.Type)
+		}
+
+	case *File:
+		walkStats(v, n.Stats)
+		// the comments are not children of the file
+
+	case *Comment:
+		// nothing to do
+
+	case *CommentGroup:
+		for _, c := range n.List {
+			Walk(v, c)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
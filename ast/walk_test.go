@@ -0,0 +1,186 @@
+package ast_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// walkFile uses every kind of node the parser produces.
+const walkFile = `package a.b
+package c
+
+import x.y.{z => w, q => _, _}
+import m.{given Ordering[Int], *}
+
+@deprecated("x") private[c] abstract class C[+T <: AnyRef : Ordering, F[_]] @inject() protected (val x: Int = 1, ys: String*)(implicit z: => Int)
+    extends { val early = 1 } with B(1)(2) with D { self: E with G { def g: Int } =>
+  type U >: Null <: AnyRef
+  type V = Map[K, Int] forSome { type K }
+  type W = A Either B#C
+  def f[A <% B](a: A): (A, Int) => B#C = {
+    var i: Int @unchecked = -a
+    i = 1
+    (a: @switch) match {
+      case p @ Some(q: Int) | None if q > 0 => a op[Int] b
+      case List(xs @ _*) => xs.length
+      case h :: t => s"$h"
+      case s"a$b" => ()
+      case (x, y) => this.x
+    }
+    while (i > 0) i -= 1
+    do i += 1 while (i < 0)
+    for (x <- xs; y = x; if y > 0) yield (x, y)
+    try throw new E() catch { case _: Throwable => } finally return
+    if (a) b else c
+    new { def x = 1 }
+    (x: Int) => (x toString)
+    xs.map { case 1 => 2 }
+    super[T].f(C.this.x)
+    g[Int](1: Int)
+  }
+  val v: x.type = x
+  val t: (Int, String) = null
+  val u: (=> Int) => Map[_ <: A, _] = null
+}
+trait T extends A
+object O
+`
+
+// children returns the child nodes of n by reflection, in the order of
+// the fields of n.
+func children(n ast.Node) []ast.Node {
+	nodeType := reflect.TypeOf((*ast.Node)(nil)).Elem()
+	var res []ast.Node
+	add := func(v reflect.Value) {
+		if (v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr) && !v.IsNil() {
+			res = append(res, v.Interface().(ast.Node))
+		}
+	}
+	v := reflect.ValueOf(n).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Type().Implements(nodeType):
+			add(f)
+		case f.Kind() == reflect.Slice && f.Type().Elem().Implements(nodeType):
+			for j := 0; j < f.Len(); j++ {
+				add(f.Index(j))
+			}
+		}
+	}
+	return res
+}
+
+func preorder(n ast.Node) []ast.Node {
+	res := []ast.Node{n}
+	for _, c := range children(n) {
+		res = append(res, preorder(c)...)
+	}
+	return res
+}
+
+func parseWalkFile(t *testing.T) *ast.File {
+	f, err := parser.ParseFile("walk.scala", walkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestWalkCoversFields(t *testing.T) {
+	f := parseWalkFile(t)
+	quote := &ast.Quote{Body: &ast.Splice{X: &ast.Ident{Name: "x"}}}
+	bad := &ast.Block{Stats: []ast.Stat{&ast.BadStat{}, &ast.BadExpr{}, quote}}
+	badPat := &ast.TypedPattern{X: &ast.Ident{Name: "x"}, Type: &ast.BadType{}}
+	for _, root := range []ast.Node{f, bad, badPat, &ast.BadPattern{}} {
+		var got []ast.Node
+		depth := 0
+		ast.Inspect(root, func(n ast.Node) bool {
+			if n == nil {
+				depth--
+				return false
+			}
+			depth++
+			got = append(got, n)
+			return true
+		})
+		if depth != 0 {
+			t.Errorf("%T: %d more nodes entered than left", root, depth)
+		}
+		want := preorder(root)
+		if len(got) != len(want) {
+			t.Errorf("%T: Inspect visited %d nodes, Expected = %d", root, len(got), len(want))
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%T: node %d is %T, Expected = %T", root, i, got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestWalkNodeTypes(t *testing.T) {
+	seen := map[string]bool{}
+	ast.Inspect(parseWalkFile(t), func(n ast.Node) bool {
+		if n != nil {
+			seen[strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")] = true
+		}
+		return true
+	})
+	// Every node type but the Bad* placeholders and the Scala 3 quotes,
+	// which TestWalkCoversFields builds by hand.
+	want := []string{
+		"Alternative", "AnnotatedType", "Annotated", "Annotation", "AppliedType", "Apply",
+		"ArgList", "Assign", "Bind", "Block", "ByNameType", "CaseClause", "ClassDef",
+		"CompoundType", "DefDef", "DoWhile", "ExistentialType", "ExtractorPattern", "File",
+		"For", "Function", "FunctionType", "Generator", "Guard", "Ident", "If", "Import",
+		"ImportSelector", "Importer", "InfixApply", "InfixPattern", "InfixType", "Init",
+		"InterpolatedPattern", "Interpolation", "Literal", "Match", "Modifier", "New",
+		"ObjectDef", "PackageClause", "Param", "ParamClause", "PartialFunction",
+		"PostfixApply", "PrefixApply", "Projection", "RepeatedType", "Return", "Select",
+		"SelfType", "SeqWildcard", "SingletonType", "Super", "Template", "This", "Throw",
+		"TraitDef", "Try", "Tuple", "TuplePattern", "TupleType", "TypeApply", "TypeDef",
+		"TypeParam", "Typed", "TypedPattern", "ValDef", "ValueEnum", "While", "WildcardType",
+	}
+	var missing []string
+	for _, name := range want {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("not visited: %s", strings.Join(missing, ", "))
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	x, err := parser.ParseExpr("f(g(1), h(2))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			names = append(names, n.Name)
+		case *ast.Apply:
+			// don't look into the arguments of g
+			if id, ok := n.Fun.(*ast.Ident); ok && id.Name == "g" {
+				return false
+			}
+		}
+		return true
+	})
+	if s := strings.Join(names, " "); s != "f h" {
+		t.Errorf("Inspect visited %s, Expected = f h", s)
+	}
+}
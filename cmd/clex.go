@@ -1,68 +1,164 @@
 package main
 
 import (
-	"errors"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	"github.com/sundargates/scalaparser/ast"
 	"github.com/sundargates/scalaparser/parser"
 )
 
+var encoding = flag.String("encoding", parser.AutoEncoding,
+	"encoding of input files without a byte-order mark: utf-8, utf-16, utf-16le, utf-16be, iso-8859-1 or windows-1252")
+
+var scala3 = flag.Bool("scala3", false, "lex input files with the Scala 3 syntax; not supported by -format=json")
+
+var outputFormat = flag.String("format", "text",
+	"output format: text, which reports lexical errors, or json, which prints the tokens and syntax tree of each file")
+
+func dialect() parser.Dialect {
+	if *scala3 {
+		return parser.Scala3
+	}
+	return parser.Scala2
+}
+
 func lexFile(filename string) error {
 	// fmt.Println("Processing ", filename)
 	bytes, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	input := string(bytes)
-	// fmt.Println(input)
+	src, err := parser.DecodeSource(bytes, *encoding)
+	if err != nil {
+		return err
+	}
+	// fmt.Println(src.Text)
 
-	lexer := parser.Lexer(input)
+	lexer := parser.DialectLexer(src.Text, dialect())
 	tokens := lexer.LexTillDone()
 	for _, token := range tokens {
 		// fmt.Println(token.String())
 		if token.Typ == parser.ERROR {
-			return errors.New(token.String())
+			return fmt.Errorf("%s (byte offset %d): %s", lexer.Position(token.Pos), src.RawOffset(token.Pos), token)
 		}
 	}
 	return nil
 }
 
+// A fileJSON is the output of the json format for a file, documented in
+// schema/clex.schema.json.
+type fileJSON struct {
+	File   string          `json:"file"`
+	Tokens []*parser.Token `json:"tokens"`
+	AST    *ast.File       `json:"ast"`
+	Errors []errorJSON     `json:"errors"`
+}
+
+type errorJSON struct {
+	Offset  int    `json:"offset"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// jsonFile prints the tokens, syntax tree and errors of a file as one
+// line of JSON. Offsets are those of the decoded text.
+func jsonFile(enc *json.Encoder, filename string) error {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	src, err := parser.DecodeSource(bytes, *encoding)
+	if err != nil {
+		return err
+	}
+	out := fileJSON{File: filename, Errors: []errorJSON{}}
+	out.Tokens = parser.Lexer(src.Text).LexTillDone()
+	f, err := parser.ParseFile(filename, src.Text)
+	out.AST = f
+	if list, ok := err.(parser.ErrorList); ok {
+		for _, e := range list {
+			out.Errors = append(out.Errors, errorJSON{e.Pos.Offset, e.Pos.Line, e.Pos.Column, e.Msg})
+		}
+	}
+	return enc.Encode(out)
+}
+
+// isScalaFile reports whether path is a Scala source file. Besides .scala
+// files this covers .sc scripts and worksheets.
+func isScalaFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".scala" || ext == ".sc"
+}
+
+// walkScalaFiles calls fn for every Scala source file in the file or
+// directory arg, skipping temporary and hidden files and directories.
+func walkScalaFiles(arg string, fn func(path string)) {
+	name, err := filepath.Abs(arg)
+	if err != nil {
+		fmt.Printf("%s: %s", arg, err)
+		return
+	}
+	filepath.Walk(name, func(path string, info os.FileInfo, err error) error {
+		if _, elem := filepath.Split(path); elem != "" {
+			// Skip various temporary or "hidden" files or directories.
+			if elem[0] == '.' || elem[0] == '#' || elem[0] == '~' || elem[len(elem)-1] == '~' {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if err != nil {
+			fmt.Printf("%s: %s", path, err)
+			return nil
+		}
+		// fmt.Println(info.Mode(), os.ModeT)
+		if info != nil && info.Mode()&os.ModeType == 0 && isScalaFile(path) {
+			fn(path)
+		}
+		return nil
+	})
+}
+
 func main() {
 	flag.Parse()
 	args := flag.Args()
 
+	if len(args) > 0 && args[0] == "fmt" {
+		os.Exit(fmtMain(args[1:]))
+	}
+	if len(args) > 0 && args[0] == "diff" {
+		os.Exit(diffMain(args[1:]))
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "clex: unknown format %q\n", *outputFormat)
+		os.Exit(2)
+	}
+	if *outputFormat == "json" && *scala3 {
+		// the syntax tree is always parsed as Scala 2, so the tokens of a
+		// record would not be those of its tree
+		fmt.Fprintln(os.Stderr, "clex: cannot use -scala3 with -format=json")
+		os.Exit(2)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
 	for _, arg := range args {
-		name, err := filepath.Abs(arg)
-		if err != nil {
-			fmt.Printf("%s: %s", arg, err)
-			continue
-		}
-		filepath.Walk(name, func(path string, info os.FileInfo, err error) error {
-			if _, elem := filepath.Split(path); elem != "" {
-				// Skip various temporary or "hidden" files or directories.
-				if elem[0] == '.' || elem[0] == '#' || elem[0] == '~' || elem[len(elem)-1] == '~' {
-					if info.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
-				}
+		walkScalaFiles(arg, func(path string) {
+			var err error
+			if *outputFormat == "json" {
+				err = jsonFile(enc, path)
+			} else {
+				err = lexFile(path)
 			}
 			if err != nil {
-				fmt.Printf("%s: %s", path, err)
-				return nil
+				fmt.Println("Failed processing ", path, "with error", err.Error())
 			}
-			// fmt.Println(info.Mode(), os.ModeT)
-			if info != nil && info.Mode()&os.ModeType == 0 && filepath.Ext(path) == ".scala" {
-				err := lexFile(path)
-				if err != nil {
-					fmt.Println("Failed processing ", path, "with error", err.Error())
-				}
-			}
-			return nil
 		})
 	}
 }
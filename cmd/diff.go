@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/ast/astdiff"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// diffMain runs the diff mode, which prints the structural differences
+// between two versions of a Scala source file, one change per line, as
+// in
+//
+//	new.scala:12:3: method `foo` moved from class A to object B
+//
+// Deletions are reported at their position in the old file and other
+// changes at theirs in the new one. Like diff, it returns the exit status
+// 0 if the files do not differ, 1 if they do and 2 if they cannot be
+// compared.
+func diffMain(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: clex [flags] diff old.scala new.scala\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+	old, err := parseForDiff(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	new, err := parseForDiff(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	changes := astdiff.Diff(old.file, new.file)
+	for _, c := range changes {
+		f, n := new, c.New
+		if c.Op == astdiff.Delete {
+			f, n = old, c.Old
+		}
+		fmt.Printf("%s:%s: %s\n", f.name, f.position(n.Pos()), c)
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// A parsedFile is a file to diff.
+type parsedFile struct {
+	name  string
+	file  *ast.File
+	lines interface {
+		Position(offset int) parser.Position
+	}
+}
+
+func parseForDiff(filename string) (*parsedFile, error) {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	src, err := parser.DecodeSource(bytes, *encoding)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(filename, src.Text)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{filename, f, parser.Lexer(src.Text)}, nil
+}
+
+// position returns the line and column of pos in f.
+func (f *parsedFile) position(pos ast.Pos) parser.Position {
+	return f.lines.Position(pos.Offset())
+}
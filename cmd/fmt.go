@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sundargates/scalaparser/format"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// fmtMain runs the fmt mode, which formats Scala source files the way
+// gofmt formats Go files: it prints the formatted source of each file,
+// or of the standard input if there are no files, unless one of -l, -d
+// and -w says otherwise. The formatting follows the .scalafmt.conf found
+// in the directory of each file or above it, or the one -config names.
+// It returns the exit status.
+func fmtMain(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	list := flags.Bool("l", false, "list files whose formatting differs from the formatter's")
+	doDiff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	write := flags.Bool("w", false, "write result to (source) file instead of stdout")
+	config := flags.String("config", "", "scalafmt configuration file; by default the nearest .scalafmt.conf")
+	maxColumn := flags.Int("maxcolumn", 0, "maximum width of a line, overriding the configuration")
+	indent := flags.Int("indent", 0, "number of spaces per indentation level, overriding the configuration")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: clex [flags] fmt [-l] [-d] [-w] [-config file] [path ...]\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	f := &formatter{
+		config:    *config,
+		maxColumn: *maxColumn,
+		indent:    *indent,
+		configs:   map[string]*format.Config{},
+		list:      *list,
+		diff:      *doDiff,
+		write:     *write,
+	}
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "cannot use -w with standard input")
+			return 2
+		}
+		if err := f.processFile("<standard input>", os.Stdin, os.Stdout); err != nil {
+			f.report(err)
+		}
+		return f.status
+	}
+	for _, arg := range flags.Args() {
+		walkScalaFiles(arg, func(path string) {
+			if err := f.processFile(path, nil, os.Stdout); err != nil {
+				f.report(err)
+			}
+		})
+	}
+	return f.status
+}
+
+type formatter struct {
+	config            string                    // -config, or "" to look for .scalafmt.conf
+	maxColumn, indent int                       // overrides of the configuration, if not 0
+	configs           map[string]*format.Config // by file name and by directory
+	list, diff, write bool
+	status            int
+}
+
+// configFor returns the configuration for the file filename. Warnings
+// about a configuration file are printed when it is first read.
+func (f *formatter) configFor(filename string) (*format.Config, error) {
+	if f.config != "" {
+		return f.load(f.config)
+	}
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	if cfg, ok := f.configs[dir]; ok {
+		return cfg, nil
+	}
+	cfg, err := f.load(findConfig(dir))
+	if err != nil {
+		return nil, err
+	}
+	f.configs[dir] = cfg
+	return cfg, nil
+}
+
+// load returns the configuration of the scalafmt configuration file
+// name, or the default one if name is "".
+func (f *formatter) load(name string) (*format.Config, error) {
+	if cfg, ok := f.configs[name]; ok {
+		return cfg, nil
+	}
+	cfg := &format.Config{}
+	if name != "" {
+		var warnings []string
+		var err error
+		if cfg, warnings, err = format.ScalafmtConfig(name); err != nil {
+			return nil, err
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
+		}
+	}
+	if f.maxColumn > 0 {
+		cfg.MaxColumn = f.maxColumn
+	}
+	if f.indent > 0 {
+		cfg.Indent = f.indent
+	}
+	f.configs[name] = cfg
+	return cfg, nil
+}
+
+// findConfig returns the .scalafmt.conf in dir or the nearest directory
+// above it, or "" if there is none.
+func findConfig(dir string) string {
+	for {
+		name := filepath.Join(dir, ".scalafmt.conf")
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func (f *formatter) report(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	f.status = 2
+}
+
+// processFile formats the file filename, read from in unless in is nil.
+func (f *formatter) processFile(filename string, in io.Reader, out io.Writer) error {
+	var raw []byte
+	var err error
+	if in == nil {
+		raw, err = ioutil.ReadFile(filename)
+	} else {
+		raw, err = ioutil.ReadAll(in)
+	}
+	if err != nil {
+		return err
+	}
+	src, err := parser.DecodeSource(raw, *encoding)
+	if err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	cfg, err := f.configFor(filename)
+	if err != nil {
+		return err
+	}
+	res, err := cfg.Source([]byte(src.Text))
+	if err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	// compare the decoded source, as res is UTF-8 whatever the encoding
+	// of the file
+	text := []byte(src.Text)
+	if src.BOM && src.Encoding == parser.UTF8 {
+		bom := []byte("\xEF\xBB\xBF")
+		text = append(bom, text...)
+		res = append(bom, res...)
+	}
+
+	if bytes.Equal(text, res) {
+		if !f.list && !f.diff && !f.write {
+			_, err = out.Write(res)
+		}
+		return err
+	}
+	if f.list {
+		fmt.Fprintln(out, filename)
+	}
+	if f.write {
+		if src.Encoding != parser.UTF8 {
+			return fmt.Errorf("%s: cannot write %s source as UTF-8", filename, src.Encoding)
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if f.diff {
+		data, err := diff(filename, text, res)
+		if err != nil {
+			return fmt.Errorf("computing diff: %s", err)
+		}
+		fmt.Fprintf(out, "diff -u %s.orig %s\n", filename, filename)
+		out.Write(data)
+	}
+	if !f.list && !f.write && !f.diff {
+		_, err = out.Write(res)
+	}
+	return err
+}
+
+// diff returns the unified diff of b1 and b2, the original and the
+// formatted source of filename, as computed by the diff command.
+func diff(filename string, b1, b2 []byte) ([]byte, error) {
+	f1, err := ioutil.TempFile("", "clex")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f1.Name())
+	defer f1.Close()
+
+	f2, err := ioutil.TempFile("", "clex")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f2.Name())
+	defer f2.Close()
+
+	f1.Write(b1)
+	f2.Write(b2)
+
+	data, err := exec.Command("diff", "-u", "-L", filename+".orig", "-L", filename, f1.Name(), f2.Name()).CombinedOutput()
+	if len(data) > 0 {
+		// diff exits with a non-zero status when the files don't match.
+		// Ignore that failure as long as we get output.
+		err = nil
+	}
+	return data, err
+}
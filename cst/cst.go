@@ -0,0 +1,238 @@
+// Package cst implements a lossless concrete syntax tree of Scala source,
+// for tools such as editors and codemods that must keep every character
+// of a file.
+//
+// Every token of the source, including whitespace and comments, is a
+// leaf of the tree, and the syntax nodes above the tokens follow the
+// nodes of the AST. The text of the tree is therefore the source, byte
+// for byte. As in Roslyn and rowan, the tree has two layers: immutable
+// green nodes, which know their text but not where it is, and red nodes,
+// made as the tree is visited, which add the parent and the absolute
+// offset. Green nodes are shared between the versions of a tree that
+// Edit makes, so that an edit only builds the nodes it changes.
+package cst
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// A Tree is the concrete syntax tree of a file.
+type Tree struct {
+	name  string
+	root  *GreenNode
+	err   error // syntax errors
+	cache *cache
+}
+
+// Parse parses the Scala 2 file src. The tree is returned even if src has
+// syntax errors; err is then the parser.ErrorList. Nodes of the tree have
+// the kind of the AST node they stand for, such as "DefDef", except for
+// the root, whose kind is "File".
+func Parse(name, src string) (t *Tree, err error) {
+	t = parse(name, src, newCache())
+	return t, t.err
+}
+
+func parse(name, src string, c *cache) *Tree {
+	f, spans, err := parser.ParseFileSpans(name, src)
+	b := newBuilder(src, spans, c)
+	root := c.node("File", b.elements(len(src), b.place(children(f), 0, len(src))))
+	return &Tree{name: name, root: root, err: err, cache: c}
+}
+
+// Root returns the root node of t.
+func (t *Tree) Root() *Node { return &Node{green: t.root} }
+
+// Text returns the source text of t.
+func (t *Tree) Text() string { return t.root.Text() }
+
+// Edit returns the tree of the text of t with src[start:end] replaced by
+// text. If the edit is inside the braces of a block or a template body,
+// only that block or body is parsed again, and the new tree shares the
+// green nodes of the rest of t; otherwise the whole text is. The tree is
+// returned even if the new text has syntax errors; err is then the
+// parser.ErrorList.
+func (t *Tree) Edit(start, end int, text string) (*Tree, error) {
+	src := t.Text()
+	if start < 0 || start > end || end > len(src) {
+		return nil, fmt.Errorf("cst: invalid edit %d..%d of text of length %d", start, end, len(src))
+	}
+	src = src[:start] + text + src[end:]
+	if t.err == nil {
+		if root := t.reparse(src, start, end, len(text)-(end-start)); root != nil {
+			return &Tree{name: t.name, root: root, cache: t.cache}, nil
+		}
+	}
+	nt := parse(t.name, src, t.cache)
+	return nt, nt.err
+}
+
+// reparse returns the root of the tree of src, the text of t after the
+// edit of [start, end) that changed its length by delta, made by parsing
+// the innermost block or template body around the edit again, or nil if
+// there is none or the edit changes more than its contents.
+func (t *Tree) reparse(src string, start, end, delta int) *GreenNode {
+	for n := t.Root().Covering(start, end); n != nil; n = n.parent {
+		lbrace, rbrace := n.braces()
+		if lbrace == nil || start < lbrace.End() || end > rbrace.Offset() {
+			continue
+		}
+		nodeEnd := n.End() + delta
+		var green *GreenNode
+		switch n.Kind() {
+		case "Block", "PartialFunction":
+			if lbrace.index != 0 {
+				continue
+			}
+			x, spans, err := parser.ParseBlockSpans(t.name, src, n.offset, nodeEnd)
+			if err != nil {
+				return nil
+			}
+			b := newBuilder(src, spans, t.cache)
+			green = t.cache.node(kind(x), b.elements(nodeEnd, b.place(children(x), n.offset, nodeEnd)))
+		case "Template":
+			tmpl, spans, err := parser.ParseTemplateBodySpans(t.name, src, lbrace.offset, nodeEnd)
+			if err != nil {
+				return nil
+			}
+			b := newBuilder(src, spans, t.cache)
+			body := b.elements(nodeEnd, b.place(children(tmpl), lbrace.offset, nodeEnd))
+			elems := append(n.green.children[:lbrace.index:lbrace.index], body...)
+			green = t.cache.node(n.Kind(), elems)
+		default:
+			continue
+		}
+		if green.width != nodeEnd-n.offset {
+			return nil
+		}
+		for ; n.parent != nil; n = n.parent {
+			green = n.parent.green.withChild(n.index, green)
+		}
+		return green
+	}
+	return nil
+}
+
+// braces returns the tokens { and } around the contents of n: its last
+// token, if it is a }, and the last { among the tokens of n before it.
+func (n *Node) braces() (lbrace, rbrace *Token) {
+	children := n.Children()
+	last := len(children) - 1
+	for last >= 0 {
+		if t, ok := children[last].(*Token); !ok || !t.IsTrivia() {
+			break
+		}
+		last--
+	}
+	if last < 0 {
+		return nil, nil
+	}
+	if t, ok := children[last].(*Token); !ok || t.Kind() != parser.R_CURLY {
+		return nil, nil
+	}
+	for i := last - 1; i >= 0; i-- {
+		if t, ok := children[i].(*Token); ok && t.Kind() == parser.L_CURLY {
+			return t, children[last].(*Token)
+		}
+	}
+	return nil, nil
+}
+
+// ----------------------------------------------------------------------------
+// Building
+
+// A builder builds green nodes from the spans of the source and the AST
+// nodes over them.
+type builder struct {
+	src   string
+	spans []parser.TokenSpan
+	next  int // index of the next span
+	cache *cache
+
+	// starts and ends hold the offsets where tokens start and end; AST
+	// nodes that do not start and end at tokens are left out.
+	starts, ends map[int]bool
+}
+
+func newBuilder(src string, spans []parser.TokenSpan, c *cache) *builder {
+	b := &builder{src: src, spans: spans, cache: c, starts: map[int]bool{}, ends: map[int]bool{}}
+	for _, s := range spans {
+		if !s.IsTrivia() {
+			b.starts[s.Pos.Offset()] = true
+			b.ends[s.End.Offset()] = true
+		}
+	}
+	return b
+}
+
+// elements returns the green elements up to offset end: the tokens and
+// the nodes for the AST nodes list, which place has arranged.
+func (b *builder) elements(end int, list []ast.Node) []GreenElement {
+	var elems []GreenElement
+	for _, n := range list {
+		elems = b.tokens(elems, n.Pos().Offset())
+		nodeEnd := n.End().Offset()
+		elems = append(elems, b.cache.node(kind(n), b.elements(nodeEnd, b.place(children(n), n.Pos().Offset(), nodeEnd))))
+	}
+	return b.tokens(elems, end)
+}
+
+// tokens appends the tokens before offset end to elems.
+func (b *builder) tokens(elems []GreenElement, end int) []GreenElement {
+	for ; b.next < len(b.spans) && b.spans[b.next].End.Offset() <= end; b.next++ {
+		s := b.spans[b.next]
+		elems = append(elems, b.cache.token(s.Typ, b.src[s.Pos.Offset():s.End.Offset()]))
+	}
+	return elems
+}
+
+// place returns the AST nodes of list that become nodes of the tree
+// within [start, end), in source order. Nodes that do not span whole
+// tokens, such as the arguments of an interpolated string, which are
+// inside a string token, or that overlap the nodes before them, are left
+// out, and their children take their place.
+func (b *builder) place(list []ast.Node, start, end int) []ast.Node {
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Pos() < list[j].Pos() })
+	var res []ast.Node
+	for _, n := range list {
+		if !n.Pos().IsValid() {
+			continue
+		}
+		pos, nodeEnd := n.Pos().Offset(), n.End().Offset()
+		if start <= pos && pos < nodeEnd && nodeEnd <= end && b.starts[pos] && b.ends[nodeEnd] {
+			res = append(res, n)
+			start = nodeEnd
+			continue
+		}
+		if kids := b.place(children(n), start, end); len(kids) > 0 {
+			res = append(res, kids...)
+			start = kids[len(kids)-1].End().Offset()
+		}
+	}
+	return res
+}
+
+// children returns the children of the AST node n.
+func children(n ast.Node) []ast.Node {
+	var list []ast.Node
+	ast.Inspect(n, func(c ast.Node) bool {
+		if c == n {
+			return true
+		}
+		if c != nil {
+			list = append(list, c)
+		}
+		return false
+	})
+	return list
+}
+
+// kind returns the kind of the tree nodes for the AST node n.
+func kind(n ast.Node) string {
+	return reflect.TypeOf(n).Elem().Name()
+}
@@ -0,0 +1,174 @@
+package cst
+
+import (
+	"strings"
+	"testing"
+)
+
+const testFile = `#!/usr/bin/env scala
+// header
+package a
+
+import b.{c => d}
+
+/** doc */
+class C[T](x: Int) extends B with D {
+  def f(y: Int) = {
+    val z = s"${y + 1}" // trailing
+    z * 2
+  }
+
+  def g = xs.map { case (a, b) => a }
+}
+
+object O {
+  val o = new { def h = 1 }
+}
+`
+
+func TestParse(t *testing.T) {
+	tree, err := Parse("test.scala", testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := tree.Text(); s != testFile {
+		t.Errorf("Text = %q, Expected = %q", s, testFile)
+	}
+	offset := 0
+	for _, tok := range tree.Root().Tokens() {
+		if tok.Offset() != offset {
+			t.Fatalf("token %s at %d, Expected = %d", tok, tok.Offset(), offset)
+		}
+		if s := testFile[tok.Offset():tok.End()]; s != tok.Text() {
+			t.Errorf("token %s holds %q", tok, s)
+		}
+		offset = tok.End()
+	}
+
+	tok := tree.Root().TokenAt(strings.Index(testFile, "y + 1"))
+	if tok == nil || tok.Text() != `s"${y + 1}"`[1:] {
+		t.Fatalf("TokenAt(y + 1) = %v, Expected the string token", tok)
+	}
+	var kinds []string
+	for n := tok.Parent(); n != nil; n = n.Parent() {
+		kinds = append(kinds, n.Kind())
+	}
+	expected := "Interpolation ValDef Block DefDef Template ClassDef PackageClause File"
+	if s := strings.Join(kinds, " "); s != expected {
+		t.Errorf("ancestors = %s, Expected = %s", s, expected)
+	}
+
+	start := strings.Index(testFile, "a }")
+	if n := tree.Root().Covering(start, start+1); n == nil || n.Kind() != "Ident" || n.Parent().Kind() != "CaseClause" {
+		t.Errorf("Covering(a) = %v, Expected the Ident in the case clause", n)
+	}
+
+	const small = "val x = 1 // one\n"
+	tree, _ = Parse("small.scala", small)
+	expectedDump := `File@0..17
+  ValDef@0..9
+    VAL@0..3 "val"
+    WHITESPACE@3..4 " "
+    Ident@4..5
+      IDENTIFIER@4..5 "x"
+    WHITESPACE@5..6 " "
+    OPERATOR@6..7 "="
+    WHITESPACE@7..8 " "
+    Literal@8..9
+      NUMBER@8..9 "1"
+  WHITESPACE@9..10 " "
+  COMMENT@10..16 "// one"
+  WHITESPACE@16..17 "\n"
+`
+	if s := tree.Root().Dump(); s != expectedDump {
+		t.Errorf("Dump = \n%s\nExpected = \n%s", s, expectedDump)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	src := "object O {\n  val = 1 ¤\n"
+	tree, err := Parse("bad.scala", src)
+	if err == nil {
+		t.Errorf("Parse(%q): Expected an error", src)
+	}
+	if tree == nil || tree.Text() != src {
+		t.Errorf("Parse(%q) does not keep the text", src)
+	}
+}
+
+// green returns the green node of the first node of kind whose text
+// starts with prefix.
+func green(n *Node, kind, prefix string) *GreenNode {
+	if n.Kind() == kind && strings.HasPrefix(n.Text(), prefix) {
+		return n.Green()
+	}
+	for _, c := range n.ChildNodes() {
+		if g := green(c, kind, prefix); g != nil {
+			return g
+		}
+	}
+	return nil
+}
+
+func TestEdit(t *testing.T) {
+	tree, err := Parse("test.scala", testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		old, new string
+		shared   bool // the definition of g is shared
+	}{
+		{"z * 2", "z * 3 + f(z)", true},
+		{"val z", "var z", true},
+		{"def h = 1", "def h = 2; val i = h", true},
+		{"case (a, b) => a", "case (a, b) => b", false},
+		{"extends B", "extends E", false},
+		{"z * 2\n  }", "z * 2\n  }}", false},
+		{"// trailing", "/* open", false},
+	}
+	for _, test := range tests {
+		start := strings.Index(testFile, test.old)
+		edited, err := tree.Edit(start, start+len(test.old), test.new)
+		src := strings.Replace(testFile, test.old, test.new, 1)
+		full, fullErr := Parse("test.scala", src)
+		if (err == nil) != (fullErr == nil) {
+			t.Errorf("Edit(%q => %q) error = %v, Expected = %v", test.old, test.new, err, fullErr)
+		}
+		if s := edited.Text(); s != src {
+			t.Errorf("Edit(%q => %q) text = %q, Expected = %q", test.old, test.new, s, src)
+		}
+		if s, expected := edited.Root().Dump(), full.Root().Dump(); s != expected {
+			t.Errorf("Edit(%q => %q) = \n%s\nExpected = \n%s", test.old, test.new, s, expected)
+		}
+		shared := green(tree.Root(), "DefDef", "def g") == green(edited.Root(), "DefDef", "def g")
+		if shared != test.shared {
+			t.Errorf("Edit(%q => %q) shares def g: %v, Expected = %v", test.old, test.new, shared, test.shared)
+		}
+	}
+
+	if _, err := tree.Edit(5, 4, ""); err == nil {
+		t.Errorf("Edit(5, 4) = nil error, Expected an invalid edit")
+	}
+}
+
+// TestEditEverywhere checks that deleting any byte, or inserting a line
+// break anywhere, gives the tree that parsing the new text gives.
+func TestEditEverywhere(t *testing.T) {
+	tree, err := Parse("test.scala", testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(testFile); i++ {
+		for _, edit := range []struct {
+			end  int
+			text string
+		}{{i + 1, ""}, {i, "\n"}} {
+			edited, _ := tree.Edit(i, edit.end, edit.text)
+			full, _ := Parse("test.scala", testFile[:i]+edit.text+testFile[edit.end:])
+			if s, expected := edited.Root().Dump(), full.Root().Dump(); s != expected {
+				t.Fatalf("Edit(%d, %d, %q) = \n%s\nExpected = \n%s", i, edit.end, edit.text, s, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cst
+
+import (
+	"strings"
+
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// A GreenElement is a *GreenNode or a *GreenToken.
+type GreenElement interface {
+	Width() int   // length of the text in bytes
+	Text() string // the source text
+	write(*strings.Builder)
+}
+
+// A GreenNode is an immutable syntax node. It knows its kind, its
+// children and the length of its text, but not where it is, so it can be
+// shared by several places of a tree and by several versions of a tree.
+type GreenNode struct {
+	kind     string
+	width    int
+	children []GreenElement
+}
+
+func newGreenNode(kind string, children []GreenElement) *GreenNode {
+	n := &GreenNode{kind: kind, children: children}
+	for _, c := range children {
+		n.width += c.Width()
+	}
+	return n
+}
+
+// Kind returns the kind of n, the name of the type of the AST node it
+// stands for, such as "ClassDef", or "File" for the root.
+func (n *GreenNode) Kind() string { return n.kind }
+
+func (n *GreenNode) Width() int { return n.width }
+
+func (n *GreenNode) Text() string {
+	var b strings.Builder
+	n.write(&b)
+	return b.String()
+}
+
+func (n *GreenNode) write(b *strings.Builder) {
+	for _, c := range n.children {
+		c.write(b)
+	}
+}
+
+// Children returns the children of n, which must not be modified.
+func (n *GreenNode) Children() []GreenElement { return n.children }
+
+// withChild returns a copy of n whose child i is c.
+func (n *GreenNode) withChild(i int, c GreenElement) *GreenNode {
+	children := make([]GreenElement, len(n.children))
+	copy(children, n.children)
+	children[i] = c
+	return newGreenNode(n.kind, children)
+}
+
+// A GreenToken is an immutable token or piece of trivia.
+type GreenToken struct {
+	kind parser.TokenType
+	text string
+}
+
+// Kind returns the type of t; trivia are WHITESPACE, COMMENT, SHEBANG or
+// ERROR for text that is not a token.
+func (t *GreenToken) Kind() parser.TokenType { return t.kind }
+
+func (t *GreenToken) Width() int               { return len(t.text) }
+func (t *GreenToken) Text() string             { return t.text }
+func (t *GreenToken) write(b *strings.Builder) { b.WriteString(t.text) }
+
+// IsTrivia reports whether t is whitespace, a comment, a script header or
+// text that is not a token, which the parser skips.
+func (t *GreenToken) IsTrivia() bool {
+	switch t.kind {
+	case parser.WHITESPACE, parser.COMMENT, parser.SHEBANG, parser.ERROR:
+		return true
+	}
+	return false
+}
+
+// A cache interns green tokens, and green nodes made of tokens only, so
+// that equal ones are shared within a tree and across its versions.
+type cache struct {
+	tokens map[tokenKey]*GreenToken
+	nodes  map[string]*GreenNode
+}
+
+type tokenKey struct {
+	kind parser.TokenType
+	text string
+}
+
+func newCache() *cache {
+	return &cache{tokens: map[tokenKey]*GreenToken{}, nodes: map[string]*GreenNode{}}
+}
+
+func (c *cache) token(kind parser.TokenType, text string) *GreenToken {
+	key := tokenKey{kind, text}
+	t := c.tokens[key]
+	if t == nil {
+		t = &GreenToken{kind: kind, text: text}
+		c.tokens[key] = t
+	}
+	return t
+}
+
+func (c *cache) node(kind string, children []GreenElement) *GreenNode {
+	var key strings.Builder
+	key.WriteString(kind)
+	for _, child := range children {
+		t, ok := child.(*GreenToken)
+		if !ok {
+			return newGreenNode(kind, children)
+		}
+		key.WriteByte(0)
+		key.WriteString(t.kind.String())
+		key.WriteByte(0)
+		key.WriteString(t.text)
+	}
+	n := c.nodes[key.String()]
+	if n == nil {
+		n = newGreenNode(kind, children)
+		c.nodes[key.String()] = n
+	}
+	return n
+}
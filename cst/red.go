@@ -0,0 +1,154 @@
+package cst
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// An Element is a *Node or a *Token.
+type Element interface {
+	Parent() *Node
+	Offset() int // byte offset of the start in the source
+	End() int    // byte offset of the end in the source
+	Text() string
+}
+
+// A Node is a syntax node of a tree: a green node at an offset in the
+// source, with a link to its parent. Nodes are made as they are visited,
+// so two Nodes for the same place are equal but not identical.
+type Node struct {
+	green  *GreenNode
+	parent *Node
+	index  int // in the children of parent
+	offset int
+}
+
+func (n *Node) Green() *GreenNode { return n.green }
+func (n *Node) Kind() string      { return n.green.kind }
+func (n *Node) Parent() *Node     { return n.parent }
+func (n *Node) Offset() int       { return n.offset }
+func (n *Node) End() int          { return n.offset + n.green.width }
+func (n *Node) Text() string      { return n.green.Text() }
+
+// Children returns the nodes and tokens under n, in source order.
+func (n *Node) Children() []Element {
+	list := make([]Element, len(n.green.children))
+	offset := n.offset
+	for i, c := range n.green.children {
+		list[i] = n.child(i, c, offset)
+		offset += c.Width()
+	}
+	return list
+}
+
+func (n *Node) child(i int, c GreenElement, offset int) Element {
+	if g, ok := c.(*GreenNode); ok {
+		return &Node{green: g, parent: n, index: i, offset: offset}
+	}
+	return &Token{green: c.(*GreenToken), parent: n, index: i, offset: offset}
+}
+
+// ChildNodes returns the nodes under n, in source order.
+func (n *Node) ChildNodes() []*Node {
+	var list []*Node
+	for _, c := range n.Children() {
+		if c, ok := c.(*Node); ok {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Tokens returns the tokens of n and of the nodes under it, including
+// trivia, in source order.
+func (n *Node) Tokens() []*Token {
+	var list []*Token
+	for _, c := range n.Children() {
+		switch c := c.(type) {
+		case *Node:
+			list = append(list, c.Tokens()...)
+		case *Token:
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// TokenAt returns the token of n that holds the byte at offset, or nil
+// if offset is not in n.
+func (n *Node) TokenAt(offset int) *Token {
+	if offset < n.offset || offset >= n.End() {
+		return nil
+	}
+	for _, c := range n.Children() {
+		if offset >= c.End() {
+			continue
+		}
+		if c, ok := c.(*Node); ok {
+			return c.TokenAt(offset)
+		}
+		return c.(*Token)
+	}
+	return nil
+}
+
+// Covering returns the innermost node under n, or n itself, whose text
+// includes the range [start, end) of the source, or nil if n does not.
+func (n *Node) Covering(start, end int) *Node {
+	if start < n.offset || end > n.End() {
+		return nil
+	}
+	for _, c := range n.ChildNodes() {
+		if c.offset <= start && end <= c.End() {
+			return c.Covering(start, end)
+		}
+	}
+	return n
+}
+
+// String returns the kind and range of n, as in ClassDef@10..42.
+func (n *Node) String() string { return fmt.Sprintf("%s@%d..%d", n.Kind(), n.offset, n.End()) }
+
+// Dump returns the tree under n with one node or token per line,
+// indented by depth.
+func (n *Node) Dump() string {
+	var b strings.Builder
+	n.dump(&b, 0)
+	return b.String()
+}
+
+func (n *Node) dump(b *strings.Builder, depth int) {
+	fmt.Fprintf(b, "%s%s\n", strings.Repeat("  ", depth), n)
+	for _, c := range n.Children() {
+		switch c := c.(type) {
+		case *Node:
+			c.dump(b, depth+1)
+		case *Token:
+			fmt.Fprintf(b, "%s%s\n", strings.Repeat("  ", depth+1), c)
+		}
+	}
+}
+
+// A Token is a token or a piece of trivia of a tree: a green token at an
+// offset in the source, with a link to its parent.
+type Token struct {
+	green  *GreenToken
+	parent *Node
+	index  int // in the children of parent
+	offset int
+}
+
+func (t *Token) Green() *GreenToken     { return t.green }
+func (t *Token) Kind() parser.TokenType { return t.green.kind }
+func (t *Token) Parent() *Node          { return t.parent }
+func (t *Token) Offset() int            { return t.offset }
+func (t *Token) End() int               { return t.offset + len(t.green.text) }
+func (t *Token) Text() string           { return t.green.text }
+func (t *Token) IsTrivia() bool         { return t.green.IsTrivia() }
+
+// String returns the kind, range and text of t, as in IDENTIFIER@4..7 "foo".
+func (t *Token) String() string {
+	return fmt.Sprintf("%s@%d..%d %q", t.Kind(), t.offset, t.End(), t.Text())
+}
@@ -0,0 +1,216 @@
+// Package desugar rewrites Scala syntactic sugar into the plain
+// expressions it stands for, as described by the Scala Language
+// Specification.
+//
+// The parts of the result taken from the source, such as the generator
+// right hand sides, the patterns and the body of a for comprehension,
+// keep their positions. The nodes made up by the rewrite are positioned
+// at the source they replace: a method name such as map at the "<-" of
+// its generator, withFilter at the "if" of its guard, and the functions
+// and argument lists so that they span the enumerators and body they
+// were made from.
+package desugar
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// For rewrites the for comprehension x into the chain of map, flatMap,
+// withFilter and foreach calls it stands for (SLS 6.19). The
+// enumerators of x must start with a generator. Nested for
+// comprehensions in the body or the generators are left as they are.
+func For(x *ast.For) (ast.Expr, error) {
+	if len(x.Enums) == 0 {
+		return nil, errors.New("for comprehension without enumerators")
+	}
+	if _, ok := x.Enums[0].(*ast.Generator); !ok {
+		return nil, errors.New("for comprehension must start with a generator")
+	}
+	d := &desugarer{}
+	enums := make([]ast.Enumerator, len(x.Enums))
+	for i, enum := range x.Enums {
+		if gen, ok := enum.(*ast.Generator); ok && !irrefutable(gen.Pat) {
+			// p <- e.withFilter { case p => true; case _ => false }
+			pos := gen.Pat.Pos()
+			check := &ast.PartialFunction{Lbrace: pos, Rbrace: gen.Pat.End() - 1, Cases: []*ast.CaseClause{
+				{Case: pos, Pat: copyPattern(gen.Pat), Arrow: gen.Arrow, Body: []ast.Stat{&ast.Literal{ValuePos: pos, Kind: ast.BooleanLit, Value: "true"}}},
+				{Case: pos, Pat: &ast.Ident{NamePos: pos, Name: "_"}, Arrow: gen.Arrow, Body: []ast.Stat{&ast.Literal{ValuePos: pos, Kind: ast.BooleanLit, Value: "false"}}},
+			}}
+			enum = &ast.Generator{Pat: gen.Pat, Arrow: gen.Arrow, Rhs: call(gen.Rhs, "withFilter", gen.Arrow, check)}
+		}
+		enums[i] = enum
+	}
+	return d.desugar(enums, x.Yield.IsValid(), x.Body), nil
+}
+
+type desugarer struct {
+	fresh int // counter for fresh names x$1, x$2, ...
+}
+
+func (d *desugarer) freshName(pos ast.Pos) *ast.Ident {
+	d.fresh++
+	return &ast.Ident{NamePos: pos, Name: fmt.Sprintf("x$%d", d.fresh)}
+}
+
+// desugar rewrites for (enums) [yield] body; enums starts with a
+// generator whose pattern needs no filtering.
+func (d *desugarer) desugar(enums []ast.Enumerator, yield bool, body ast.Expr) ast.Expr {
+	gen := enums[0].(*ast.Generator)
+	rest := enums[1:]
+	if len(rest) == 0 {
+		// for (p <- e) yield b  ==>  e.map { case p => b }
+		// for (p <- e) b        ==>  e.foreach { case p => b }
+		name := "foreach"
+		if yield {
+			name = "map"
+		}
+		return call(gen.Rhs, name, gen.Arrow, function(gen.Pat, gen.Arrow, body))
+	}
+	switch enum := rest[0].(type) {
+	case *ast.Guard:
+		// p <- e if g  ==>  p <- e.withFilter { case p => g }
+		filtered := &ast.Generator{Pat: gen.Pat, Arrow: gen.Arrow,
+			Rhs: call(gen.Rhs, "withFilter", enum.If, function(gen.Pat, gen.Arrow, enum.Cond))}
+		return d.desugar(append([]ast.Enumerator{filtered}, rest[1:]...), yield, body)
+	case *ast.ValueEnum:
+		n := 1
+		for n < len(rest) {
+			if _, ok := rest[n].(*ast.ValueEnum); !ok {
+				break
+			}
+			n++
+		}
+		tupled := d.valueEnums(gen, rest[:n])
+		return d.desugar(append([]ast.Enumerator{tupled}, rest[n:]...), yield, body)
+	}
+	// for (p <- e; p' <- e' ...) yield b  ==>  e.flatMap { case p => for (p' <- e' ...) yield b }
+	// for (p <- e; p' <- e' ...) b        ==>  e.foreach { case p => for (p' <- e' ...) b }
+	name := "foreach"
+	if yield {
+		name = "flatMap"
+	}
+	inner := d.desugar(rest, yield, body)
+	return call(gen.Rhs, name, gen.Arrow, function(gen.Pat, gen.Arrow, inner))
+}
+
+// valueEnums rewrites a generator followed by value definitions,
+//
+//	p <- e; p1 = e1; ... pn = en
+//
+// into the generator
+//
+//	(p, p1, ..., pn) <- for (x@p <- e) yield { val x1@p1 = e1; ... val xn@pn = en; (x, x1, ..., xn) }
+//
+// where the binders are left out for patterns that are plain variables.
+func (d *desugarer) valueEnums(gen *ast.Generator, vals []ast.Enumerator) *ast.Generator {
+	pats := []ast.Pattern{gen.Pat}
+	bound, ref := d.bind(gen.Pat)
+	refs := []ast.Expr{ref}
+	var stats []ast.Stat
+	for _, enum := range vals {
+		val := enum.(*ast.ValueEnum)
+		pats = append(pats, val.Pat)
+		pat, ref := d.bind(val.Pat)
+		refs = append(refs, ref)
+		stats = append(stats, &ast.ValDef{ValPos: val.Pos(), Keyword: "val", Pats: []ast.Pattern{pat}, Rhs: val.Rhs})
+	}
+	first, last := vals[0].Pos(), vals[len(vals)-1].End()
+	stats = append(stats, &ast.Tuple{Lparen: first, Elts: refs, Rparen: last - 1})
+	block := &ast.Block{Lbrace: first, Stats: stats, Rbrace: last - 1}
+	tuple := &ast.TuplePattern{Lparen: gen.Pat.Pos(), Elts: pats, Rparen: last - 1}
+	return &ast.Generator{Pat: tuple, Arrow: gen.Arrow, Rhs: d.desugar([]ast.Enumerator{&ast.Generator{Pat: bound, Arrow: gen.Arrow, Rhs: gen.Rhs}}, true, block)}
+}
+
+// bind returns pat bound to a name, and a reference to that name. A
+// variable pattern is its own name.
+func (d *desugarer) bind(pat ast.Pattern) (ast.Pattern, ast.Expr) {
+	if id, ok := pat.(*ast.Ident); ok && id.IsVariable() && id.Name != "_" {
+		return pat, &ast.Ident{NamePos: id.NamePos, Name: id.Name}
+	}
+	name := d.freshName(pat.Pos())
+	return &ast.Bind{Name: name, At: pat.Pos(), Pat: pat}, &ast.Ident{NamePos: name.NamePos, Name: name.Name}
+}
+
+// irrefutable reports whether pat matches any value without looking at
+// its type, so that a generator with this pattern needs no filter: a
+// variable, a wildcard, or a binder or tuple of such patterns. This is
+// the syntactic test scalac uses.
+func irrefutable(pat ast.Pattern) bool {
+	switch p := pat.(type) {
+	case *ast.Ident:
+		return p.IsVariable()
+	case *ast.Bind:
+		return irrefutable(p.Pat)
+	case *ast.TuplePattern:
+		for _, elt := range p.Elts {
+			if !irrefutable(elt) {
+				return false
+			}
+		}
+		return len(p.Elts) > 0
+	}
+	return false
+}
+
+// function returns the function { case pat => body }, written pat =>
+// body if pat is a variable or a wildcard. It uses a copy of pat, as a
+// pattern may be used by several functions.
+func function(pat ast.Pattern, arrow ast.Pos, body ast.Expr) ast.Expr {
+	pat = copyPattern(pat)
+	if id, ok := pat.(*ast.Ident); ok && id.IsVariable() {
+		return &ast.Function{Params: []*ast.Param{{Name: id}}, Arrow: arrow, Body: body}
+	}
+	c := &ast.CaseClause{Case: pat.Pos(), Pat: pat, Arrow: arrow, Body: []ast.Stat{body}}
+	return &ast.PartialFunction{Lbrace: pat.Pos(), Cases: []*ast.CaseClause{c}, Rbrace: body.End() - 1}
+}
+
+// call returns the call recv.name(arg), with name positioned at pos.
+func call(recv ast.Expr, name string, pos ast.Pos, arg ast.Expr) *ast.Apply {
+	sel := &ast.Select{X: recv, Sel: &ast.Ident{NamePos: pos, Name: name}}
+	return &ast.Apply{Fun: sel, Args: &ast.ArgList{Lparen: arg.Pos(), Args: []ast.Expr{arg}, Rparen: arg.End() - 1}}
+}
+
+// copyPattern returns a deep copy of pat, so that every place a pattern
+// of the for comprehension is used in the result gets nodes of its own.
+func copyPattern(pat ast.Pattern) ast.Pattern {
+	return deepCopy(reflect.ValueOf(&pat).Elem()).Interface().(ast.Pattern)
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			c.Field(i).Set(deepCopy(v.Field(i)))
+		}
+		return c
+	}
+	return v
+}
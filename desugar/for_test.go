@@ -0,0 +1,163 @@
+package desugar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// show prints the nodes a desugared for comprehension is made of in
+// Scala syntax.
+func show(n ast.Node) string {
+	switch x := n.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.Literal:
+		return x.Value
+	case *ast.Select:
+		return show(x.X) + "." + x.Sel.Name
+	case *ast.Apply:
+		return show(x.Fun) + "(" + showList(x.Args.Args) + ")"
+	case *ast.InfixApply:
+		return "(" + show(x.X) + " " + x.Op.Name + " " + show(x.Y) + ")"
+	case *ast.Function:
+		return x.Params[0].Name.Name + " => " + show(x.Body)
+	case *ast.PartialFunction:
+		var cases []string
+		for _, c := range x.Cases {
+			cases = append(cases, "case "+show(c.Pat)+" => "+show(c.Body[0]))
+		}
+		return "{ " + strings.Join(cases, "; ") + " }"
+	case *ast.Tuple:
+		return "(" + showList(x.Elts) + ")"
+	case *ast.Block:
+		var stats []string
+		for _, stat := range x.Stats {
+			stats = append(stats, show(stat))
+		}
+		return "{ " + strings.Join(stats, "; ") + " }"
+	case *ast.ValDef:
+		return "val " + show(x.Pats[0]) + " = " + show(x.Rhs)
+	case *ast.Bind:
+		return x.Name.Name + "@" + show(x.Pat)
+	case *ast.TuplePattern:
+		var elts []string
+		for _, elt := range x.Elts {
+			elts = append(elts, show(elt))
+		}
+		return "(" + strings.Join(elts, ", ") + ")"
+	case *ast.ExtractorPattern:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, show(arg))
+		}
+		return show(x.Fun) + "(" + strings.Join(args, ", ") + ")"
+	case *ast.TypedPattern:
+		return x.X.Name + ": " + show(x.Type)
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func showList(exprs []ast.Expr) string {
+	var res []string
+	for _, n := range exprs {
+		res = append(res, show(n))
+	}
+	return strings.Join(res, ", ")
+}
+
+var forTests = []struct {
+	input    string
+	expected string
+}{
+	{"for (x <- xs) yield x + 1", "xs.map(x => (x + 1))"},
+	{"for (x <- xs) println(x)", "xs.foreach(x => println(x))"},
+	{"for (x <- xs; y <- ys) yield (x, y)", "xs.flatMap(x => ys.map(y => (x, y)))"},
+	{"for (x <- xs; y <- ys) f(x, y)", "xs.foreach(x => ys.foreach(y => f(x, y)))"},
+	{"for (x <- xs if x > 0) yield x", "xs.withFilter(x => (x > 0)).map(x => x)"},
+	{"for { x <- xs\n if p(x)\n if q(x) } yield x", "xs.withFilter(x => p(x)).withFilter(x => q(x)).map(x => x)"},
+	{"for ((k, v) <- m) yield k", "m.map({ case (k, v) => k })"},
+	{"for ((k, _) <- m; if k > 0) yield k", "m.withFilter({ case (k, _) => (k > 0) }).map({ case (k, _) => k })"},
+	{"for (Some(x) <- xs) yield x",
+		"xs.withFilter({ case Some(x) => true; case _ => false }).map({ case Some(x) => x })"},
+	{"for (x: Int <- xs) yield x",
+		"xs.withFilter({ case x: Int => true; case _ => false }).map({ case x: Int => x })"},
+	{"for (x <- xs; y = x * 2) yield y",
+		"xs.map(x => { val y = (x * 2); (x, y) }).map({ case (x, y) => y })"},
+	{"for (x <- xs; y = f(x); z = g(y); w <- h(z)) yield w",
+		"xs.map(x => { val y = f(x); val z = g(y); (x, y, z) }).flatMap({ case (x, y, z) => h(z).map(w => w) })"},
+	{"for ((a, b) <- ps; (c, d) = f(a)) yield c",
+		"ps.map({ case x$1@(a, b) => { val x$2@(c, d) = f(a); (x$1, x$2) } }).map({ case ((a, b), (c, d)) => c })"},
+	{"for {\n  a <- fa\n  b <- fb(a)\n  c <- fc(b)\n} yield a + b + c",
+		"fa.flatMap(a => fb(a).flatMap(b => fc(b).map(c => ((a + b) + c))))"},
+	{"for (_ <- 1 to n) tick()", "(1 to n).foreach(_ => tick())"},
+}
+
+func TestFor(t *testing.T) {
+	for _, test := range forTests {
+		x, err := parser.ParseExpr(test.input)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q): %v", test.input, err)
+		}
+		f := x.(*ast.For)
+		res, err := For(f)
+		if err != nil {
+			t.Errorf("For(%q): %v", test.input, err)
+			continue
+		}
+		if s := show(res); s != test.expected {
+			t.Errorf("For(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+		if res.Pos() != f.Enums[0].(*ast.Generator).Rhs.Pos() || res.End() != f.End() {
+			t.Errorf("For(%q) spans %d-%d, Expected = %d-%d", test.input, res.Pos(), res.End(), f.Enums[0].(*ast.Generator).Rhs.Pos(), f.End())
+		}
+		seen := map[ast.Node]bool{}
+		ast.Inspect(res, func(n ast.Node) bool {
+			if n == nil {
+				return false
+			}
+			if seen[n] {
+				t.Errorf("For(%q): %s appears twice", test.input, show(n))
+			}
+			seen[n] = true
+			return true
+		})
+	}
+}
+
+func TestForPositions(t *testing.T) {
+	src := "for (x <- xs if x > 0) yield x"
+	x, err := parser.ParseExpr(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := For(x.(*ast.For))
+	if err != nil {
+		t.Fatal(err)
+	}
+	apply := res.(*ast.Apply)
+	mapSel := apply.Fun.(*ast.Select)
+	if mapSel.Sel.Pos().Offset() != strings.Index(src, "<-") {
+		t.Errorf("map at %d, Expected at <-", mapSel.Sel.Pos().Offset())
+	}
+	filter := mapSel.X.(*ast.Apply).Fun.(*ast.Select)
+	if filter.Sel.Pos().Offset() != strings.Index(src, "if") {
+		t.Errorf("withFilter at %d, Expected at if", filter.Sel.Pos().Offset())
+	}
+	if xs := filter.X.(*ast.Ident); xs.Pos().Offset() != strings.Index(src, "xs") {
+		t.Errorf("xs at %d, Expected = %d", xs.Pos().Offset(), strings.Index(src, "xs"))
+	}
+}
+
+func TestForErrors(t *testing.T) {
+	if _, err := For(&ast.For{}); err == nil {
+		t.Errorf("For without enumerators: no error")
+	}
+	guard := &ast.Guard{Cond: &ast.Ident{Name: "c"}}
+	if _, err := For(&ast.For{Enums: []ast.Enumerator{guard}, Body: &ast.Ident{Name: "b"}}); err == nil {
+		t.Errorf("For starting with a guard: no error")
+	}
+}
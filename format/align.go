@@ -0,0 +1,131 @@
+package format
+
+import (
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// An Align is how much Source aligns the tokens of consecutive lines.
+type Align int
+
+const (
+	AlignNone Align = iota
+	AlignSome       // the arrows of one-line case clauses
+	AlignMore       // also the = of vals and vars, <- of generators and trailing comments
+)
+
+// align pads the items of groups of consecutive lines with the same
+// indentation so that their anchors, such as the arrows of case clauses,
+// start in the same column. It needs the layout to be rendered.
+func (f *formatter) align() {
+	f.pad = make([]int, len(f.items))
+	if f.alignment == AlignNone {
+		return
+	}
+	f.alignAnchors(f.anchor)
+	if f.alignment >= AlignMore {
+		f.render()
+		f.alignAnchors(f.commentAnchor)
+	}
+}
+
+// anchor returns the kind of the line items[start:end] and the index of
+// the item that aligns with those of its neighbours of the same kind.
+func (f *formatter) anchor(start, end int) (kind string, at int) {
+	first := f.items[start]
+	depth := 0
+	for j := start + 1; j < end; j++ {
+		it := f.items[j]
+		switch {
+		case isOpening(it):
+			depth++
+		case isClosing(it):
+			depth--
+		case depth != 0 || it.typ != parser.OPORDELIM:
+		case first.typ == parser.CASE && (it.text == "=>" || it.text == "⇒"):
+			if f.isCaseClause(start) {
+				return "case", j
+			}
+			return "", -1
+		case f.alignment < AlignMore || first.typ == parser.CASE:
+		case (first.typ == parser.VAL || first.typ == parser.VAR) && it.text == "=":
+			return "val", j
+		case it.text == "<-" || it.text == "←":
+			return "gen", j
+		}
+	}
+	return "", -1
+}
+
+// commentAnchor anchors a line at its trailing line comment.
+func (f *formatter) commentAnchor(start, end int) (string, int) {
+	if last := f.items[end-1]; end-1 > start && last.isLineComment() {
+		return "comment", end - 1
+	}
+	return "", -1
+}
+
+// alignAnchors aligns the anchors of the lines that anchor finds.
+func (f *formatter) alignAnchors(anchor func(start, end int) (string, int)) {
+	var group []int // anchors of the current group
+	var kind string
+	flush := func() {
+		if len(group) > 1 {
+			f.alignGroup(group)
+		}
+		group = group[:0]
+	}
+	for start := 0; start < len(f.items); {
+		end := start + 1
+		for end < len(f.items) && f.items[end].breaks == 0 {
+			end++
+		}
+		k, at := anchor(start, end)
+		if k == "" || k != kind || f.items[start].breaks != 1 ||
+			len(group) > 0 && f.indent[start] != f.indent[f.lineStart(group[0])] {
+			flush()
+		}
+		if kind = k; k != "" {
+			group = append(group, at)
+		}
+		start = end
+	}
+	flush()
+}
+
+// alignGroup pads the anchors of a group to the column of the rightmost,
+// unless that takes a line past the maximum column.
+func (f *formatter) alignGroup(anchors []int) {
+	target := 0
+	for _, j := range anchors {
+		if c := f.column(j); c > target {
+			target = c
+		}
+	}
+	for _, j := range anchors {
+		last := j
+		for last+1 < len(f.items) && f.items[last+1].breaks == 0 && f.items[last+1].typ != parser.COMMENT {
+			last++
+		}
+		if f.end[last]+target-f.column(j) > f.maxColumn {
+			return
+		}
+	}
+	for _, j := range anchors {
+		f.pad[j] += target - f.column(j)
+	}
+}
+
+// column returns the column item j starts in.
+func (f *formatter) column(j int) int {
+	return f.end[j] - utf8.RuneCountInString(f.items[j].text)
+}
+
+// lineStart returns the index of the first item on the line of item j.
+func (f *formatter) lineStart(j int) int {
+	for j > 0 && f.items[j].breaks == 0 {
+		j--
+	}
+	return j
+}
@@ -0,0 +1,132 @@
+// Package format implements standard formatting of Scala source.
+//
+// The formatter works on the token stream of a file, so that comments
+// survive untouched, and consults the syntax tree only to find the
+// members of templates and packages. It normalizes the indentation of
+// every line, the spacing between the tokens of a line and the blank
+// lines between members, and breaks lines longer than the maximum width
+// at argument lists, blocks and definitions. It never joins lines, nor
+// breaks them where a newline could end a statement, so that the
+// formatted source parses to the same tree.
+package format
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// A Config controls the output of Source.
+type Config struct {
+	MaxColumn int     // maximum width of a line; 80 if 0
+	Indent    int     // number of spaces per indentation level; 2 if 0
+	Align     Align   // alignment of the tokens of consecutive lines
+	Rewrite   Rewrite // rewrites applied before formatting
+
+	// BreakBeforeElse puts an else that follows a closing brace on a
+	// line of its own.
+	BreakBeforeElse bool
+
+	// BreakBeforeLambdaParams puts the parameters of a lambda that opens
+	// a multi-line block on the line after the brace.
+	BreakBeforeLambdaParams bool
+}
+
+// Source formats src, a Scala 2 source file, using the default
+// configuration. It returns an error if src has syntax errors.
+func Source(src []byte) ([]byte, error) {
+	return (&Config{}).Source(src)
+}
+
+// Source formats src, a Scala 2 source file, for a given configuration
+// cfg. The lines keep the terminator style of src. It returns an error
+// if src has syntax errors.
+func (cfg *Config) Source(src []byte) ([]byte, error) {
+	if !utf8.Valid(src) {
+		return nil, errors.New("source is not valid UTF-8")
+	}
+	text := string(src)
+	file, err := parser.ParseFile("", text)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Rewrite != 0 {
+		var changed bool
+		if text, changed = rewrite(text, file, cfg.Rewrite); changed {
+			if file, err = parser.ParseFile("", text); err != nil {
+				return nil, fmt.Errorf("rewriting: %s", err)
+			}
+		}
+	}
+	items, err := scan(text)
+	if err != nil {
+		return nil, err
+	}
+	f := &formatter{
+		src:         text,
+		items:       items,
+		maxColumn:   cfg.MaxColumn,
+		indentWidth: cfg.Indent,
+		alignment:   cfg.Align,
+		newline:     parser.DetectLineEnding(text).Terminator(),
+
+		breakBeforeElse:         cfg.BreakBeforeElse,
+		breakBeforeLambdaParams: cfg.BreakBeforeLambdaParams,
+	}
+	if f.maxColumn <= 0 {
+		f.maxColumn = 80
+	}
+	if f.indentWidth <= 0 {
+		f.indentWidth = 2
+	}
+	f.classify()
+	out := f.format(file)
+	if err := sameTokens(text, out); err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// sameTokens returns an error unless src and out lex to the same tokens,
+// which is what guarantees that they parse to the same tree. Comments
+// and the number of blank lines in a row do not count.
+func sameTokens(src, out string) error {
+	a, b := significant(src), significant(out)
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return fmt.Errorf("formatting changed token %d from %s to %s", i, a[i], b[i])
+		}
+	}
+	if len(a) != len(b) {
+		return fmt.Errorf("formatting changed the number of tokens from %d to %d", len(a), len(b))
+	}
+	return nil
+}
+
+func significant(src string) []parser.Token {
+	var res []parser.Token
+	for _, t := range parser.Lexer(src).LexTillDone() {
+		switch t.Typ {
+		case parser.COMMENT:
+			continue
+		case parser.NEWLINES:
+			t.Typ = parser.NEWLINE
+		}
+		t.Pos = 0
+		res = append(res, *t)
+	}
+	return res
+}
+
+// isDefinition reports whether s is a member that blank lines separate
+// from its neighbours.
+func isDefinition(s ast.Stat) bool {
+	switch s.(type) {
+	case *ast.ValDef, *ast.DefDef, *ast.TypeDef, *ast.ClassDef, *ast.TraitDef, *ast.ObjectDef:
+		return true
+	}
+	return false
+}
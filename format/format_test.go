@@ -0,0 +1,253 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+var formatTests = []struct {
+	input, expected string
+}{
+	// indentation
+	{"object A {\nval x = 1\n      def f = 2\n}\n", "object A {\n  val x = 1\n  def f = 2\n}\n"},
+	{"def f =\n1\n", "def f =\n  1\n"},
+	{"def f(a: Int,\nb: Int) = 1\n", "def f(a: Int,\n  b: Int) = 1\n"},
+	{"val y = xs\n.map(f)\n.filter(g)\n", "val y = xs\n  .map(f)\n  .filter(g)\n"},
+	{"val l = m +\nn\n", "val l = m +\n  n\n"},
+	{"object A {\nval x = a &&\n    b ||\n c\nval y = 1\n}\n", "object A {\n  val x = a &&\n    b ||\n    c\n\n  val y = 1\n}\n"},
+	{"f(g(\nx))\n", "f(g(\n  x))\n"},
+	{"xs.map { x =>\nx + 1\n}\n", "xs.map { x =>\n  x + 1\n}\n"},
+	{"x match {\ncase 1 =>\nval y = 2\ny\ncase _ => 0\n}\n",
+		"x match {\n  case 1 =>\n    val y = 2\n    y\n  case _ => 0\n}\n"},
+	{"object A {\ncase class B(x: Int)\nval y = 1\n}\n", "object A {\n  case class B(x: Int)\n  val y = 1\n}\n"},
+	{"if (a)\nb\nelse if (c)\nd\nelse\ne\n", "if (a)\n  b\nelse if (c)\n  d\nelse\n  e\n"},
+	{"val x =\nif (a) b\nelse c\n", "val x =\n  if (a) b\n  else c\n"},
+	{"try\nf()\ncatch {\ncase _: E =>\n}\nfinally\ng()\n", "try\n  f()\ncatch {\n  case _: E =>\n}\nfinally\n  g()\n"},
+	{"for {\nx <- xs\n}\nyield x\n", "for {\n  x <- xs\n}\nyield x\n"},
+	{"do i += 1 while (i < 0)\nf()\n", "do i += 1 while (i < 0)\nf()\n"},
+	{"do {\ni += 1\n}\nwhile (i < 0)\n", "do {\n  i += 1\n}\nwhile (i < 0)\n"},
+	{"class A(x: Int)\nextends B {\ndef f = 1\n}\n", "class A(x: Int)\n  extends B {\n  def f = 1\n}\n"},
+	{"trait T { self: U =>\ndef f = 1\n}\n", "trait T { self: U =>\n  def f = 1\n}\n"},
+	{"object A {\n    // about f\n  def f = 1\n    // last\n}\n", "object A {\n  // about f\n  def f = 1\n  // last\n}\n"},
+	{"object A {\n    /** Doc\n      * more\n      */\n  def f = 1\n}\n", "object A {\n  /** Doc\n    * more\n    */\n  def f = 1\n}\n"},
+
+	// spacing
+	{"val x=a+b*c\n", "val x = a + b * c\n"},
+	{"f(a,b ,c)\n", "f(a, b, c)\n"},
+	{"def f[A<:B](x:A):Int=x.size\n", "def f[A <: B](x: A): Int = x.size\n"},
+	{"if(a)b else c\n", "if (a) b else c\n"},
+	{"while(i>0)i-=1\n", "while (i > 0) i -= 1\n"},
+	{"for(x<-xs)println(x)\n", "for (x <- xs) println(x)\n"},
+	{"val y = -x + !b + ~c\n", "val y = -x + !b + ~c\n"},
+	{"val y = - 1 + -1\n", "val y = - 1 + -1\n"},
+	{"a + -b\n", "a + -b\n"},
+	{"f(xs:_*)\n", "f(xs: _*)\n"},
+	{"def g(xs: Int*) = xs\n", "def g(xs: Int*) = xs\n"},
+	{"xs foreach println\n", "xs foreach println\n"},
+	{"xs map (_ * 2)\n", "xs map (_ * 2)\n"},
+	{"println (x)\n", "println(x)\n"},
+	{"x match{case h::t=>h}\n", "x match { case h :: t => h }\n"},
+	{"case class P(x:Int)extends Q{}\n", "case class P(x: Int) extends Q {}\n"},
+	{"import a.b.{ c=>d, _ }\n", "import a.b.{c => d, _}\n"},
+	{"private [this] val x = 1\n", "private[this] val x = 1\n"},
+	{"class A[+T, -U]\n", "class A[+T, -U]\n"},
+	{"def +(that: P): P = this\n", "def +(that: P): P = this\n"},
+	{"def unary_- : P = this\n", "def unary_- : P = this\n"},
+	{"val p@Some(q) = x\n", "val p @Some(q) = x\n"},
+	{"val p @ Some(q) = x\n", "val p @ Some(q) = x\n"},
+	{"@tailrec  def f(x:Int@unchecked) = 1\n", "@tailrec def f(x: Int @unchecked) = 1\n"},
+	{"val s = s\"a $b ${c+1}\"  +  f\"$x%d\"\n", "val s = s\"a $b ${c+1}\" + f\"$x%d\"\n"},
+	{"val t: A#B = null\n", "val t: A#B = null\n"},
+	{"val x = 1 ;val y = 2\n", "val x = 1; val y = 2\n"},
+	{"f(x)  // c  \n", "f(x) // c\n"},
+	{"f( /* c */ x)\n", "f(/* c */ x)\n"},
+
+	// blank lines
+	{"\n\nval x = 1\n\n\n\nval y = 2\n\n\n", "val x = 1\n\nval y = 2\n"},
+	{"object A {\n\n  val x = 1\n\n}\n", "object A {\n  val x = 1\n}\n"},
+	{"object A {\n  def f = {\n    1\n  }\n  def g = 2\n  val h = 3\n}\n",
+		"object A {\n  def f = {\n    1\n  }\n\n  def g = 2\n  val h = 3\n}\n"},
+	{"class A {\n}\n// B\nclass B\n", "class A {\n}\n\n// B\nclass B\n"},
+	{"object A {\n  val x = 1\n  f(x)\n  def g = {\n  }\n}\n", "object A {\n  val x = 1\n  f(x)\n  def g = {\n  }\n}\n"},
+
+	// line width
+	{"val result = someFunction(argumentNumberOne, argumentNumberTwo, argumentNumberThree)\n",
+		"val result = someFunction(\n  argumentNumberOne,\n  argumentNumberTwo,\n  argumentNumberThree\n)\n"},
+	{"def f(a: Int, b: Int): Int = aVeryLongExpressionName + anotherVeryLongExpressionName + more\n",
+		"def f(a: Int, b: Int): Int =\n  aVeryLongExpressionName + anotherVeryLongExpressionName + more\n"},
+	{"xs.foreach { x => println(\"a very long message that goes on and on\"); println(x + x) }\n",
+		"xs.foreach {\n  x => println(\"a very long message that goes on and on\"); println(x + x)\n}\n"},
+	{"val x = f(g(firstArgumentOfG, secondArgumentOfG), h(firstArgumentOfH, secondArgumentOfH))\n",
+		"val x = f(\n  g(firstArgumentOfG, secondArgumentOfG),\n  h(firstArgumentOfH, secondArgumentOfH)\n)\n"},
+	{"val x = 1 // a comment that is far too long to fit on this line of the source file\n",
+		"val x = 1 // a comment that is far too long to fit on this line of the source file\n"},
+
+	// line endings
+	{"object A {\r\nval x=1\r\n\r\n\r\ndef f = 2\r\n}\r\n", "object A {\r\n  val x = 1\r\n\r\n  def f = 2\r\n}\r\n"},
+	{"object A {\r\n    /** Doc\r\n      * more\r\n      */\r\n  def f = 1\r\n}\r\n",
+		"object A {\r\n  /** Doc\r\n    * more\r\n    */\r\n  def f = 1\r\n}\r\n"},
+}
+
+func TestSource(t *testing.T) {
+	for _, test := range formatTests {
+		out, err := Source([]byte(test.input))
+		if err != nil {
+			t.Errorf("Source(%q): %v", test.input, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("Source(%q) = %q, Expected = %q", test.input, out, test.expected)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg := &Config{MaxColumn: 20, Indent: 4}
+	out, err := cfg.Source([]byte("object A {\ndef f = g(alpha, beta, gamma)\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "object A {\n    def f = g(\n        alpha,\n        beta,\n        gamma\n    )\n}\n"
+	if string(out) != expected {
+		t.Errorf("Source = %q, Expected = %q", out, expected)
+	}
+}
+
+var configTests = []struct {
+	cfg             Config
+	input, expected string
+}{
+	{Config{Align: AlignSome}, "x match {\ncase 1 => a\ncase 100 => b\n}\nval x = 1\nval yy = 2\n",
+		"x match {\n  case 1   => a\n  case 100 => b\n}\nval x = 1\nval yy = 2\n"},
+	{Config{Align: AlignMore}, "val x = 1 // one\nval yy = 2 // two\n\nval z = 3\n",
+		"val x  = 1 // one\nval yy = 2 // two\n\nval z = 3\n"},
+	{Config{Align: AlignMore}, "for {\na <- as\nbbb <- bs\n} yield a\n", "for {\n  a   <- as\n  bbb <- bs\n} yield a\n"},
+	{Config{Align: AlignSome, MaxColumn: 20}, "x match {\ncase 1 => a\ncase 1000000 => bbbbb\n}\n",
+		"x match {\n  case 1 => a\n  case 1000000 => bbbbb\n}\n"},
+	{Config{Rewrite: SortModifiers}, "final implicit private case class A()\nlazy private val x = 1\n",
+		"implicit final private case class A()\nprivate lazy val x = 1\n"},
+	{Config{Rewrite: SortImports}, "import c.{y => z, +, X, a, _}\n", "import c.{+, a, y => z, X, _}\n"},
+	{Config{Rewrite: AsciiSortImports}, "import c.{y, +, X, a}\n", "import c.{+, X, a, y}\n"},
+	{Config{Rewrite: SortImports}, "import c.{y, /* keep */ a}\n", "import c.{y, /* keep */ a}\n"},
+	{Config{BreakBeforeElse: true}, "if (a) {\nb\n} else c\n", "if (a) {\n  b\n}\nelse c\n"},
+	{Config{BreakBeforeLambdaParams: true}, "xs.map { x =>\nx + 1\n}\nys.foreach { case (a, b) =>\nf(a)\n}\n",
+		"xs.map {\n  x =>\n    x + 1\n}\nys.foreach { case (a, b) =>\n  f(a)\n}\n"},
+}
+
+func TestConfigOptions(t *testing.T) {
+	for _, test := range configTests {
+		out, err := test.cfg.Source([]byte(test.input))
+		if err != nil {
+			t.Errorf("%+v: Source(%q): %v", test.cfg, test.input, err)
+			continue
+		}
+		if string(out) != test.expected {
+			t.Errorf("%+v: Source(%q) = %q, Expected = %q", test.cfg, test.input, out, test.expected)
+		}
+		if again, err := test.cfg.Source(out); err != nil || string(again) != string(out) {
+			t.Errorf("%+v: formatting %q again gives %q, %v", test.cfg, out, again, err)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	for _, src := range []string{"object A {", "val = 1", "val x = \xff"} {
+		if _, err := Source([]byte(src)); err == nil {
+			t.Errorf("Source(%q) succeeded, Expected an error", src)
+		}
+	}
+}
+
+var idempotenceFiles = []string{
+	`package a.b
+package c
+import x.y.{z => w, q => _, _}
+
+@deprecated("x") private[c] abstract class C[+T <: AnyRef : Ordering, F[_]] @inject() protected (val x: Int = 1, ys: String*)(implicit z: => Int)
+    extends { val early = 1 } with B(1)(2) with D { self: E with G { def g: Int } =>
+  type U >: Null <: AnyRef
+  type V = Map[K, Int] forSome { type K }
+  def f[A <% B](a: A): (A, Int) => B#C = {
+    var i: Int @unchecked = -a
+    (a: @switch) match {
+      case p @ Some(q: Int) | None if q > 0 => a op[Int] b
+      case List(xs @ _*) => xs.length
+      case s"a$b" => ()
+    }
+    do i += 1 while (i < 0)
+    try throw new E() catch { case _: Throwable => } finally return
+    new { def x = 1 }
+    super[T].f(C.this.x)
+    g[Int](1: Int)
+  }
+}
+`,
+	`object Main extends App {
+  def this(x: Int) = this()
+  val ` + "`yield`" + ` = 1
+  val ys = xs.foldLeft(0) { (acc, x) =>
+    val y = acc + x
+    y * 2 // double
+  }
+  /* a block
+     comment */
+  val stringly = """multi
+line"""
+  val k = -(-x) + !b + ~(a & b) + - 1
+  lazy val l = if (a) if (b) c else d else e
+  for {
+    (a, b) <- pairs
+    if a < b
+  } println(c)
+  val veryLongName = Map("alpha" -> 1, "beta" -> 2, "gamma" -> 3, "delta" -> 4, "epsilon" -> 5)
+  def longSignature(firstParameter: Int, secondParameter: String, thirdParameter: Boolean): Unit = ()
+}
+`,
+}
+
+func TestIdempotence(t *testing.T) {
+	configs := []*Config{
+		{},
+		{Align: AlignMore, Rewrite: SortModifiers | SortImports, BreakBeforeElse: true, BreakBeforeLambdaParams: true},
+	}
+	for _, cfg := range configs {
+		for i, src := range idempotenceFiles {
+			out1, err := cfg.Source([]byte(src))
+			if err != nil {
+				t.Errorf("%d: %v", i, err)
+				continue
+			}
+			out2, err := cfg.Source(out1)
+			if err != nil {
+				t.Errorf("%d: formatting the output: %v\n%s", i, err, out1)
+				continue
+			}
+			if string(out2) != string(out1) {
+				t.Errorf("%d: formatting is not idempotent:\n%s\nthen\n%s", i, out1, out2)
+			}
+			for n, line := range strings.Split(string(out1), "\n") {
+				if strings.HasSuffix(line, " ") {
+					t.Errorf("%d: line %d has trailing spaces: %q", i, n+1, line)
+				}
+			}
+		}
+	}
+}
+
+func TestLineEndings(t *testing.T) {
+	for i, src := range idempotenceFiles {
+		lf, err := Source([]byte(src))
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+			continue
+		}
+		crlf, err := Source([]byte(strings.Replace(src, "\n", "\r\n", -1)))
+		if err != nil {
+			t.Errorf("%d: CRLF: %v", i, err)
+			continue
+		}
+		if expected := strings.Replace(string(lf), "\n", "\r\n", -1); string(crlf) != expected {
+			t.Errorf("%d: CRLF source = %q, Expected = %q", i, crlf, expected)
+		}
+	}
+}
@@ -0,0 +1,661 @@
+package format
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+type formatter struct {
+	src         string
+	items       []*item
+	maxColumn   int
+	indentWidth int
+	alignment   Align
+	newline     string // line terminator of the source
+
+	breakBeforeElse         bool
+	breakBeforeLambdaParams bool
+
+	indent []int // indentation level of the line of each item
+	end    []int // column after each item
+	pad    []int // spaces added before each item to align it
+}
+
+// ----------------------------------------------------------------------------
+// Classification
+
+// classify matches brackets and tells apart the roles of identifiers:
+// whether an operator is used infix, prefix or postfix decides the
+// spacing around it.
+func (f *formatter) classify() {
+	var stack []int
+	dos := []int{0} // number of do loops awaiting their while, per bracket
+	doWhile := -1   // index of the while of the last do loop
+	prev := -1      // previous token, skipping comments
+	for i, it := range f.items {
+		it.match = -1
+		if it.typ == parser.COMMENT {
+			continue
+		}
+		switch {
+		case it.typ == parser.DO:
+			dos[len(dos)-1]++
+		case it.typ == parser.WHILE && dos[len(dos)-1] > 0:
+			dos[len(dos)-1]--
+			doWhile = i
+		case isOpening(it):
+			stack = append(stack, i)
+			dos = append(dos, 0)
+		case isClosing(it) && len(stack) > 0:
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			dos = dos[:len(dos)-1]
+			f.items[j].match, it.match = i, j
+			if j == 0 {
+				break
+			}
+			switch f.items[j-1].typ {
+			case parser.DOT:
+				f.items[j].selectors, it.selectors = it.typ == parser.R_CURLY, it.typ == parser.R_CURLY
+			case parser.IF:
+				it.header = it.typ == parser.R_PAREN
+			case parser.WHILE:
+				it.header = it.typ == parser.R_PAREN && j-1 != doWhile
+			case parser.FOR:
+				it.header = true
+			}
+		case it.typ == parser.IDENTIFIER:
+			afterOperand := prev >= 0 && !it.nl && f.items[prev].isOperandEnd()
+			if isOperator(it.text) {
+				it.prefix = !afterOperand
+				if afterOperand {
+					if next := f.next(i); next < 0 || !isClosing(f.items[next]) && !isPunct(f.items[next], ",") {
+						it.infix = true
+					} else {
+						it.postfix = true
+					}
+				}
+			} else {
+				it.infix = afterOperand
+			}
+		}
+		prev = i
+	}
+}
+
+// isOperandEnd reports whether an expression, pattern or type may end
+// with it, so that an identifier after it is an infix operator.
+func (it *item) isOperandEnd() bool {
+	switch it.typ {
+	case parser.IDENTIFIER:
+		return !it.infix && !it.prefix && !isOperator(it.text)
+	case parser.R_PAREN, parser.R_CURLY:
+		return !it.header
+	case parser.NUMBER, parser.STRING, parser.CHARACTER, parser.SYMBOL, parser.BOOLEAN,
+		parser.TRUE, parser.FALSE, parser.NULL, parser.THIS, parser.SUPER,
+		parser.R_BRACKET:
+		return true
+	}
+	return false
+}
+
+// next returns the index of the token after item i, skipping comments,
+// or -1.
+func (f *formatter) next(i int) int {
+	for i++; i < len(f.items); i++ {
+		if f.items[i].typ != parser.COMMENT {
+			return i
+		}
+	}
+	return -1
+}
+
+func isOpening(it *item) bool {
+	return it.typ == parser.L_PAREN || it.typ == parser.L_BRACKET || it.typ == parser.L_CURLY
+}
+
+func isClosing(it *item) bool {
+	return it.typ == parser.R_PAREN || it.typ == parser.R_BRACKET || it.typ == parser.R_CURLY
+}
+
+// isPunct reports whether it is the reserved operator or delimiter s.
+func isPunct(it *item, s string) bool {
+	return it.typ == parser.OPORDELIM && it.text == s
+}
+
+// isOperator reports whether the identifier name is symbolic.
+func isOperator(name string) bool {
+	c, _ := utf8.DecodeRuneInString(name)
+	if c < utf8.RuneSelf {
+		return strings.ContainsRune("!#%&*+-/:<=>?@\\^|~", c)
+	}
+	return unicode.In(c, unicode.Sm, unicode.So)
+}
+
+// ----------------------------------------------------------------------------
+// Blank lines
+
+// trimBlankLines drops the blank lines at the start of the file and at
+// the start and end of brackets.
+func (f *formatter) trimBlankLines() {
+	for i, it := range f.items {
+		switch {
+		case i == 0:
+			it.breaks = 0
+		case it.breaks > 1 && (isOpening(f.items[i-1]) || isClosing(it)):
+			it.breaks = 1
+		}
+	}
+}
+
+// separateMembers adds a blank line between two members of a file,
+// package or template if either of them spans several lines. A comment
+// directly above a member stays with it.
+func (f *formatter) separateMembers(file *ast.File) {
+	separate := func(stats []ast.Stat) {
+		for k := 1; k < len(stats); k++ {
+			a, b := stats[k-1], stats[k]
+			if !isDefinition(a) || !isDefinition(b) || !f.multiline(a) && !f.multiline(b) {
+				continue
+			}
+			i := f.index(b.Pos())
+			if i == len(f.items) || f.items[i].offset != b.Pos().Offset() {
+				continue
+			}
+			for i > 0 && f.items[i].breaks == 1 && f.items[i-1].typ == parser.COMMENT && f.items[i-1].breaks > 0 {
+				i--
+			}
+			if f.items[i].breaks == 1 {
+				f.items[i].breaks = 2
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.File:
+			separate(x.Stats)
+		case *ast.PackageClause:
+			separate(x.Stats)
+		case *ast.Template:
+			separate(x.Stats)
+		}
+		return true
+	})
+}
+
+// index returns the index of the first item at or after p.
+func (f *formatter) index(p ast.Pos) int {
+	return sort.Search(len(f.items), func(i int) bool { return f.items[i].offset >= p.Offset() })
+}
+
+// multiline reports whether n spans several lines as laid out.
+func (f *formatter) multiline(n ast.Node) bool {
+	for i := f.index(n.Pos()) + 1; i < f.index(n.End()); i++ {
+		if f.items[i].breaks > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Indentation
+
+// A region is the part of the source between a pair of brackets, or the
+// whole file.
+type region struct {
+	indent int  // indentation of the line the region opens on
+	stmt   int  // indentation of the statement the region opens in
+	curly  bool // whether the region is a block or template body
+	first  bool // whether the region has not had a line break yet
+	inCase bool // whether the region has had case clauses
+
+	// indentation of the lines of if, try, for and do keywords that may
+	// still be continued by else, catch or finally, yield and while
+	ifs, trys, fors, dos []int
+}
+
+// indentLines computes the indentation of every line. The lines of a
+// region are indented one level more than the line it opens on, the
+// statements of a case clause one level more than the case, and a line
+// that continues a statement one level more than the statement.
+func (f *formatter) indentLines() {
+	f.indent = make([]int, len(f.items))
+	regions := []*region{{indent: -1}}
+	cur, stmt := 0, 0 // indentation of the current line and statement
+	line := 0         // first item of the current line
+	prev := -1        // previous token, skipping comments
+	for i, it := range f.items {
+		r := regions[len(regions)-1]
+		if i > 0 && it.breaks > 0 {
+			cur = f.lineIndent(i, prev, r, &stmt)
+			r.first = false
+			line = i
+		}
+		f.indent[i] = cur
+		if it.typ == parser.COMMENT {
+			continue
+		}
+		prev = i
+		switch {
+		case isOpening(it):
+			nr := &region{indent: cur, stmt: stmt, curly: it.typ == parser.L_CURLY, first: true}
+			if t := f.items[line].typ; nr.curly && (t == parser.EXTENDS || t == parser.WITH) {
+				// the body of a template whose parents are on a line of
+				// their own belongs to the definition
+				nr.indent = stmt
+			}
+			regions = append(regions, nr)
+			stmt = cur
+		case isClosing(it) && len(regions) > 1:
+			regions = regions[:len(regions)-1]
+			stmt = r.stmt
+		}
+		switch it.typ {
+		case parser.IF:
+			r.ifs = append(r.ifs, cur)
+		case parser.TRY:
+			r.trys = append(r.trys, cur)
+		case parser.FOR:
+			r.fors = append(r.fors, cur)
+		case parser.DO:
+			r.dos = append(r.dos, cur)
+		case parser.ELSE:
+			r.ifs = pop(r.ifs)
+		case parser.FINALLY:
+			r.trys = pop(r.trys)
+		case parser.YIELD:
+			r.fors = pop(r.fors)
+		case parser.WHILE:
+			r.dos = pop(r.dos)
+		}
+	}
+}
+
+// lineIndent returns the indentation of the line starting with item i
+// in region r, where prev is the last token before it, and updates the
+// indentation of the current statement.
+func (f *formatter) lineIndent(i, prev int, r *region, stmt *int) int {
+	t := f.items[i]
+	elem := t.nl // whether t starts a statement or element
+	if t.typ == parser.COMMENT {
+		// a comment is indented like the token it precedes, except that
+		// one before a closing bracket belongs inside
+		if next := f.next(i); next >= 0 && !isClosing(f.items[next]) {
+			i, t, elem = next, f.items[next], f.items[next].nl
+		} else {
+			elem = true
+		}
+	} else if isClosing(t) {
+		return r.indent
+	}
+	if prev >= 0 && f.items[prev].infix && isOperator(f.items[prev].text) {
+		// a line after a trailing infix operator continues its operation
+		elem = false
+	}
+	caseClause := r.curly && t.typ == parser.CASE && f.isCaseClause(i)
+	var pending []int
+	switch t.typ {
+	case parser.ELSE:
+		pending = r.ifs
+	case parser.CATCH, parser.FINALLY:
+		pending = r.trys
+	case parser.YIELD:
+		pending = r.fors
+	case parser.WHILE:
+		pending = r.dos
+	}
+	if len(pending) > 0 {
+		*stmt = pending[len(pending)-1]
+		return *stmt
+	}
+	if prev >= 0 && f.items[prev].isHeader() {
+		if !r.first {
+			*stmt = f.indent[prev] + 1
+			return *stmt
+		}
+		// as in xs.map { x =>
+		elem = true
+	}
+	if prev < 0 || elem || caseClause || isOpening(f.items[prev]) || isPunct(f.items[prev], ",") || f.items[prev].typ == parser.SEMICOLON {
+		content := r.indent + 1
+		if caseClause {
+			r.inCase = true
+		} else if r.inCase {
+			content++
+		}
+		*stmt = content
+		return content
+	}
+	return *stmt + 1
+}
+
+// isCaseClause reports whether the case keyword items[i] starts a case
+// clause rather than a case class or object.
+func (f *formatter) isCaseClause(i int) bool {
+	next := f.next(i)
+	return next < 0 || f.items[next].typ != parser.CLASS && f.items[next].typ != parser.OBJECT
+}
+
+// isHeader reports whether a line ending with it is continued by a body
+// that is indented one level more, such as the body of a definition, a
+// case clause or an if.
+func (it *item) isHeader() bool {
+	switch it.typ {
+	case parser.OPORDELIM:
+		switch it.text {
+		case "=", "=>", "⇒", "<-", "←":
+			return true
+		}
+	case parser.ELSE, parser.YIELD, parser.DO, parser.TRY, parser.FINALLY:
+		return true
+	case parser.R_PAREN, parser.R_CURLY:
+		return it.header
+	}
+	return false
+}
+
+func pop(stack []int) []int {
+	if len(stack) == 0 {
+		return stack
+	}
+	return stack[:len(stack)-1]
+}
+
+// ----------------------------------------------------------------------------
+// Spacing
+
+// space reports whether a space separates the items a and b on a line.
+// A space in the source is only dropped if the two do not then lex as
+// something else.
+func (f *formatter) space(a, b *item) bool {
+	sp := spaceBetween(a, b)
+	if !sp && b.space && !separate(a.text, b.text) {
+		sp = true
+	}
+	return sp
+}
+
+func spaceBetween(a, b *item) bool {
+	switch {
+	case b.typ == parser.STRING && a.typ == parser.IDENTIFIER && !b.space:
+		return false // interpolated string
+	case a.prefix:
+		if a.text == "-" || a.text == "+" || a.text == "!" || a.text == "~" {
+			// -1 is a literal but - 1 is not
+			return b.typ == parser.NUMBER && b.space
+		}
+		return b.space
+	case b.postfix:
+		return b.space
+	case b.typ == parser.COMMENT || a.typ == parser.COMMENT:
+		return !isOpening(a) && !isClosing(b) && !isPunct(b, ",") && b.typ != parser.SEMICOLON
+	case isPunct(b, ",") || b.typ == parser.SEMICOLON || b.typ == parser.R_PAREN || b.typ == parser.R_BRACKET:
+		return false
+	case b.typ == parser.R_CURLY:
+		return a.typ != parser.L_CURLY && !b.selectors
+	case a.typ == parser.L_PAREN || a.typ == parser.L_BRACKET:
+		return false
+	case a.typ == parser.L_CURLY:
+		return !a.selectors
+	case isPunct(a, ",") || a.typ == parser.SEMICOLON:
+		return true
+	case b.typ == parser.DOT:
+		return a.typ == parser.NUMBER && b.space
+	case a.typ == parser.DOT || isPunct(a, "#") || isPunct(b, "#") || isPunct(b, ":"):
+		return false
+	case isPunct(a, "@"):
+		return b.space // an annotation, or a binder if spaced
+	case b.typ == parser.L_PAREN:
+		switch a.typ {
+		case parser.IDENTIFIER:
+			return a.infix
+		case parser.R_PAREN:
+			return b.space // class C @ann() (x: Int)
+		case parser.R_BRACKET, parser.R_CURLY, parser.THIS, parser.SUPER,
+			parser.STRING, parser.NUMBER, parser.CHARACTER, parser.SYMBOL:
+			return false
+		}
+	case b.typ == parser.L_BRACKET:
+		switch a.typ {
+		case parser.IDENTIFIER, parser.R_PAREN, parser.R_BRACKET, parser.THIS, parser.SUPER,
+			parser.PRIVATE, parser.PROTECTED:
+			return false
+		}
+	}
+	return true
+}
+
+// separate reports whether x and y, written one after the other, lex as
+// the tokens they lex as apart.
+func separate(x, y string) bool {
+	tokens := func(s string) []parser.Token {
+		var res []parser.Token
+		for _, t := range parser.Lexer(s).LexTillDone() {
+			res = append(res, parser.Token{Typ: t.Typ, Val: t.Val})
+		}
+		return res
+	}
+	apart := append(tokens(x), tokens(y)...)
+	together := tokens(x + y)
+	if len(apart) != len(together) {
+		return false
+	}
+	for i := range apart {
+		if apart[i] != together[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ----------------------------------------------------------------------------
+// Output
+
+// format lays out the items and returns the formatted source.
+func (f *formatter) format(file *ast.File) string {
+	f.trimBlankLines()
+	f.breakNewlines()
+	for n := 0; n <= len(f.items); n++ {
+		f.indentLines()
+		f.render()
+		if !f.breakLines() {
+			break
+		}
+	}
+	f.separateMembers(file)
+	f.render()
+	f.align()
+	return f.render()
+}
+
+// render returns the formatted source as laid out so far, and records
+// the column after each item.
+func (f *formatter) render() string {
+	var buf strings.Builder
+	f.end = make([]int, len(f.items))
+	col := 0
+	for i, it := range f.items {
+		switch {
+		case i == 0:
+		case it.breaks > 0:
+			buf.WriteString(strings.Repeat(f.newline, it.breaks))
+			col = f.indent[i] * f.indentWidth
+			if col < 0 {
+				col = 0
+			}
+			buf.WriteString(strings.Repeat(" ", col))
+		case f.space(f.items[i-1], it):
+			buf.WriteByte(' ')
+			col++
+		}
+		if it.breaks == 0 && f.pad != nil && f.pad[i] > 0 {
+			buf.WriteString(strings.Repeat(" ", f.pad[i]))
+			col += f.pad[i]
+		}
+		text := it.text
+		if it.typ == parser.COMMENT {
+			text = reindent(text, col-it.col, f.newline)
+		}
+		buf.WriteString(text)
+		if n := strings.LastIndexAny(text, "\r\n"); n >= 0 {
+			col = utf8.RuneCountInString(text[n+1:])
+		} else {
+			col += utf8.RuneCountInString(text)
+		}
+		f.end[i] = col
+	}
+	if len(f.items) > 0 {
+		buf.WriteString(f.newline)
+	}
+	return buf.String()
+}
+
+// reindent shifts the lines after the first of the block comment text by
+// delta columns, as far as their leading spaces allow, and ends them with
+// newline.
+func reindent(text string, delta int, newline string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for k, line := range lines {
+		line = strings.TrimRight(line, " \t\r\f")
+		if k > 0 && line != "" {
+			if delta > 0 {
+				line = strings.Repeat(" ", delta) + line
+			} else {
+				n := len(line) - len(strings.TrimLeft(line, " "))
+				if n > -delta {
+					n = -delta
+				}
+				line = line[n:]
+			}
+		}
+		lines[k] = line
+	}
+	return strings.Join(lines, newline)
+}
+
+// breakNewlines starts the new lines the configuration asks for.
+func (f *formatter) breakNewlines() {
+	for i, it := range f.items {
+		switch {
+		case f.breakBeforeElse && it.typ == parser.ELSE && i > 0 && f.items[i-1].typ == parser.R_CURLY:
+			f.breakBefore(i)
+		case f.breakBeforeLambdaParams && it.typ == parser.L_CURLY && it.match > i+1:
+			if f.items[i+1].typ != parser.CASE && f.opensLambda(i) {
+				f.breakBefore(i + 1)
+			}
+		}
+	}
+}
+
+// opensLambda reports whether the brace items[i] spans several lines
+// and its first line ends with the arrow of a lambda.
+func (f *formatter) opensLambda(i int) bool {
+	depth := 0
+	for j := i + 1; j < f.items[i].match; j++ {
+		it := f.items[j]
+		switch {
+		case it.breaks > 0:
+			last := f.items[j-1]
+			return depth == 0 && last.typ == parser.OPORDELIM && (last.text == "=>" || last.text == "⇒")
+		case isOpening(it):
+			depth++
+		case isClosing(it):
+			depth--
+		}
+	}
+	return false
+}
+
+// breakLines breaks the lines that are wider than the maximum column,
+// and reports whether it broke any.
+func (f *formatter) breakLines() bool {
+	broke := false
+	for start := 0; start < len(f.items); {
+		end := start + 1
+		for end < len(f.items) && f.items[end].breaks == 0 {
+			end++
+		}
+		for i := start; i < end; i++ {
+			if f.end[i] > f.maxColumn && f.items[i].typ != parser.COMMENT {
+				if f.breakLine(start, end) {
+					broke = true
+				}
+				break
+			}
+		}
+		start = end
+	}
+	return broke
+}
+
+// breakLine breaks the line of items[start:end]. It prefers to break
+// the first bracketed group that runs past the maximum column: after
+// the opening bracket, after each comma in it and before the closing
+// bracket. Otherwise it breaks after the first = of the line that is
+// not in brackets. Newlines are never inferred in these places.
+func (f *formatter) breakLine(start, end int) bool {
+	for j := start; j < end; j++ {
+		it := f.items[j]
+		m := it.match
+		if !isOpening(it) || m < j || m == j+1 {
+			continue
+		}
+		if m < end && f.end[m] <= f.maxColumn || m >= end && it.typ == parser.L_CURLY {
+			continue
+		}
+		broke := f.breakBefore(j + 1)
+		if it.typ != parser.L_CURLY {
+			depth := 0
+			for k := j + 1; k < m && k < end; k++ {
+				switch {
+				case isOpening(f.items[k]):
+					depth++
+				case isClosing(f.items[k]):
+					depth--
+				case depth == 0 && isPunct(f.items[k], ",") && k+1 < end:
+					if f.breakBefore(k + 1) {
+						broke = true
+					}
+				}
+			}
+		}
+		if m < end && f.breakBefore(m) {
+			broke = true
+		}
+		if broke {
+			return true
+		}
+	}
+	depth := 0
+	for j := start; j+1 < end; j++ {
+		switch it := f.items[j]; {
+		case isOpening(it):
+			depth++
+		case isClosing(it):
+			depth--
+		case depth == 0 && isPunct(it, "="):
+			return f.breakBefore(j + 1)
+		}
+	}
+	return false
+}
+
+// breakBefore starts a new line at item i unless it is a comment, which
+// stays on the line it follows, and reports whether it did.
+func (f *formatter) breakBefore(i int) bool {
+	it := f.items[i]
+	if it.breaks > 0 || it.typ == parser.COMMENT {
+		return false
+	}
+	it.breaks = 1
+	return true
+}
@@ -0,0 +1,179 @@
+package format
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// A Rewrite is a set of rewrites of the syntax tree that Source applies
+// before formatting.
+type Rewrite uint
+
+const (
+	// SortModifiers orders the modifiers of a definition as implicit,
+	// final, sealed, abstract, override, private or protected, lazy.
+	SortModifiers Rewrite = 1 << iota
+	// SortImports orders the selectors of an import in braces with
+	// symbols first, then lower case names, then upper case ones.
+	SortImports
+	// AsciiSortImports orders the selectors of an import in braces by
+	// their bytes.
+	AsciiSortImports
+)
+
+// modifierRank is the order of modifiers that SortModifiers sorts to.
+var modifierRank = map[string]int{
+	"implicit":  0,
+	"final":     1,
+	"sealed":    2,
+	"abstract":  3,
+	"override":  4,
+	"private":   5,
+	"protected": 5,
+	"lazy":      6,
+}
+
+// An edit replaces the bytes src[start:end] by text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// rewrite applies the rewrites in rw to src, whose tree is file, and
+// reports whether it changed anything.
+func rewrite(src string, file *ast.File, rw Rewrite) (string, bool) {
+	var mods []*ast.Modifier
+	var importers []*ast.Importer
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Modifier:
+			mods = append(mods, n)
+		case *ast.Importer:
+			importers = append(importers, n)
+		}
+		return true
+	})
+	var edits []edit
+	if rw&SortModifiers != 0 {
+		sort.Slice(mods, func(i, j int) bool { return mods[i].Pos() < mods[j].Pos() })
+		for i := 0; i < len(mods); {
+			j := i + 1
+			for j < len(mods) && strings.TrimSpace(text(src, mods[j-1].End(), mods[j].Pos())) == "" {
+				j++
+			}
+			edits = append(edits, sortModifiers(src, mods[i:j])...)
+			i = j
+		}
+	}
+	if rw&(SortImports|AsciiSortImports) != 0 {
+		for _, imp := range importers {
+			edits = append(edits, sortSelectors(src, imp, rw&AsciiSortImports != 0)...)
+		}
+	}
+	if len(edits) == 0 {
+		return src, false
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = src[:e.start] + e.text + src[e.end:]
+	}
+	return src, true
+}
+
+// text returns the source between the positions from and to.
+func text(src string, from, to ast.Pos) string {
+	return src[int(from)-1 : int(to)-1]
+}
+
+// sortModifiers returns the edits that sort the adjacent modifiers mods.
+// A trailing case stays last, and modifiers it does not know are left
+// in place along with the others.
+func sortModifiers(src string, mods []*ast.Modifier) []edit {
+	n := len(mods)
+	if n > 0 && mods[n-1].Name == "case" {
+		n--
+	}
+	if n < 2 {
+		return nil
+	}
+	for _, m := range mods[:n] {
+		if _, ok := modifierRank[m.Name]; !ok {
+			return nil
+		}
+	}
+	nodes := make([]ast.Node, n)
+	sorted := make([]ast.Node, n)
+	for i, m := range mods[:n] {
+		nodes[i], sorted[i] = m, m
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return modifierRank[sorted[i].(*ast.Modifier).Name] < modifierRank[sorted[j].(*ast.Modifier).Name]
+	})
+	return reorder(src, nodes, sorted)
+}
+
+// sortSelectors returns the edits that sort the selectors of imp. The
+// wildcard stays last, and selectors with comments or givens are left
+// alone.
+func sortSelectors(src string, imp *ast.Importer, ascii bool) []edit {
+	sels := imp.Selectors
+	if !imp.Lbrace.IsValid() || len(sels) < 2 {
+		return nil
+	}
+	if inner := text(src, imp.Lbrace, imp.Rbrace); strings.Contains(inner, "//") || strings.Contains(inner, "/*") {
+		return nil
+	}
+	for _, s := range sels {
+		if s.Given.IsValid() || s.Name == nil {
+			return nil
+		}
+	}
+	n := len(sels)
+	if sels[n-1].IsWildcard() {
+		n--
+	}
+	nodes := make([]ast.Node, n)
+	sorted := make([]ast.Node, n)
+	for i, s := range sels[:n] {
+		nodes[i], sorted[i] = s, s
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].(*ast.ImportSelector).Name.Name, sorted[j].(*ast.ImportSelector).Name.Name
+		if !ascii {
+			if ca, cb := selectorClass(a), selectorClass(b); ca != cb {
+				return ca < cb
+			}
+		}
+		return a < b
+	})
+	return reorder(src, nodes, sorted)
+}
+
+// selectorClass orders names that start with a symbol before lower case
+// names, and these before upper case names.
+func selectorClass(name string) int {
+	r, _ := utf8.DecodeRuneInString(name)
+	switch {
+	case unicode.IsUpper(r):
+		return 2
+	case unicode.IsLetter(r):
+		return 1
+	}
+	return 0
+}
+
+// reorder returns the edits that put the text of the nodes sorted in the
+// places of the nodes, keeping what separates them.
+func reorder(src string, nodes, sorted []ast.Node) []edit {
+	var edits []edit
+	for i, n := range nodes {
+		if n != sorted[i] {
+			edits = append(edits, edit{int(n.Pos()) - 1, int(n.End()) - 1, text(src, sorted[i].Pos(), sorted[i].End())})
+		}
+	}
+	return edits
+}
@@ -0,0 +1,157 @@
+package format
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sundargates/scalaparser/hocon"
+)
+
+// ScalafmtConfig reads the scalafmt configuration file filename, usually
+// .scalafmt.conf, and returns the equivalent Config. Settings that
+// Source does not support, or only supports in part, yield warnings
+// rather than errors, and leave the formatting as it would be without
+// them. Includes may refer to files anywhere in the repository that
+// holds filename, which is the nearest directory above it with a .git.
+func ScalafmtConfig(filename string) (*Config, []string, error) {
+	v, err := hocon.ParseFile(filename, repoRoot(filename))
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &scalafmt{cfg: &Config{MaxColumn: 80, Indent: 2, Align: AlignSome}}
+	if err := s.object(v, ""); err != nil {
+		return nil, nil, err
+	}
+	return s.cfg, s.warnings, nil
+}
+
+// repoRoot returns the root of the repository holding filename, or the
+// directory of filename if it is not in a repository.
+func repoRoot(filename string) string {
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return filepath.Dir(filename)
+	}
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+type scalafmt struct {
+	cfg      *Config
+	warnings []string
+}
+
+func (s *scalafmt) warn(pos hocon.Position, format string, args ...interface{}) {
+	s.warnings = append(s.warnings, pos.String()+": "+fmt.Sprintf(format, args...))
+}
+
+// object maps the fields of the object v, whose path is prefix.
+func (s *scalafmt) object(v *hocon.Value, prefix string) error {
+	for _, f := range v.Fields {
+		key := prefix + f.Key
+		if f.Value.Kind == hocon.Object {
+			if err := s.object(f.Value, key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.setting(key, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setting maps the setting key, whose field is f.
+func (s *scalafmt) setting(key string, f *hocon.Field) error {
+	v := f.Value
+	var err error
+	switch key {
+	case "version":
+	case "maxColumn":
+		s.cfg.MaxColumn, err = v.Int()
+	case "indent.main":
+		s.cfg.Indent, err = v.Int()
+	case "align", "align.preset":
+		var preset string
+		if preset, err = v.Str(); err != nil {
+			break
+		}
+		switch strings.ToLower(preset) {
+		case "none", "false":
+			s.cfg.Align = AlignNone
+		case "some", "true":
+			s.cfg.Align = AlignSome
+		case "more":
+			s.cfg.Align = AlignMore
+		case "most":
+			s.cfg.Align = AlignMore
+			s.warn(f.Pos, "%s = most is treated as more", key)
+		default:
+			s.warn(f.Pos, "unsupported %s %s", key, preset)
+		}
+	case "rewrite.rules":
+		var rules []string
+		if rules, err = v.Strings(); err != nil {
+			break
+		}
+		for _, rule := range rules {
+			switch rule {
+			case "SortModifiers":
+				s.cfg.Rewrite |= SortModifiers
+			case "SortImports":
+				s.cfg.Rewrite |= SortImports
+			case "AsciiSortImports":
+				s.cfg.Rewrite |= AsciiSortImports
+			default:
+				s.warn(f.Pos, "unsupported rewrite rule %s", rule)
+			}
+		}
+	case "newlines.source":
+		var source string
+		if source, err = v.Str(); err == nil && source != "keep" {
+			s.warn(f.Pos, "newlines.source = %s is not supported; line breaks are kept", source)
+		}
+	case "newlines.alwaysBeforeElseAfterCurlyIf":
+		s.cfg.BreakBeforeElse, err = v.Bool()
+	case "newlines.beforeCurlyLambdaParams":
+		var when string
+		if when, err = v.Str(); err != nil {
+			break
+		}
+		switch when {
+		case "always", "true":
+			s.cfg.BreakBeforeLambdaParams = true
+		case "never", "false":
+			s.cfg.BreakBeforeLambdaParams = false
+		default:
+			s.warn(f.Pos, "newlines.beforeCurlyLambdaParams = %s is not supported", when)
+		}
+	case "runner.dialect":
+		var dialect string
+		if dialect, err = v.Str(); err == nil && !strings.HasPrefix(strings.ToLower(dialect), "scala2") {
+			s.warn(f.Pos, "runner.dialect = %s is not supported; formatting as Scala 2", dialect)
+		}
+	case "preset":
+		var preset string
+		if preset, err = v.Str(); err == nil && preset != "default" {
+			s.warn(f.Pos, "unsupported preset %s", preset)
+		}
+	default:
+		s.warn(f.Pos, "unsupported scalafmt setting %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package format
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScalafmtConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scalafmt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		".git/HEAD": "",
+		"shared.conf": `
+maxColumn = 100
+rewrite.rules = [SortImports]
+`,
+		"project/.scalafmt.conf": `
+version = "3.7.3"
+include "../shared.conf"
+indent.main = 4
+align.preset = more
+rewrite.rules += SortModifiers
+rewrite.rules += RedundantBraces
+newlines {
+  source = keep
+  alwaysBeforeElseAfterCurlyIf = true
+  beforeCurlyLambdaParams = always
+}
+docstrings.style = Asterisk
+runner.dialect = scala213
+`,
+		"bad.conf": "maxColumn = wide",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, warnings, err := ScalafmtConfig(filepath.Join(dir, "project/.scalafmt.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Config{
+		MaxColumn:               100,
+		Indent:                  4,
+		Align:                   AlignMore,
+		Rewrite:                 SortImports | SortModifiers,
+		BreakBeforeElse:         true,
+		BreakBeforeLambdaParams: true,
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("ScalafmtConfig = %+v, Expected = %+v", cfg, expected)
+	}
+	expectedWarnings := []string{
+		"unsupported rewrite rule RedundantBraces",
+		"unsupported scalafmt setting docstrings.style",
+	}
+	if len(warnings) != len(expectedWarnings) {
+		t.Fatalf("warnings = %q, Expected = %q", warnings, expectedWarnings)
+	}
+	for i, w := range warnings {
+		if !strings.HasSuffix(w, expectedWarnings[i]) || !strings.Contains(w, ".scalafmt.conf:") {
+			t.Errorf("warning %d = %q, Expected = %q", i, w, expectedWarnings[i])
+		}
+	}
+
+	if _, _, err := ScalafmtConfig(filepath.Join(dir, "bad.conf")); err == nil || !strings.Contains(err.Error(), "maxColumn") {
+		t.Errorf("ScalafmtConfig(bad.conf) = %v, Expected an error about maxColumn", err)
+	}
+}
@@ -0,0 +1,143 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// An item is a token or a comment of the source being formatted.
+type item struct {
+	typ    parser.TokenType // COMMENT for comments of either kind
+	text   string           // source text, including quotes
+	offset int              // byte offset of text in the source
+	col    int              // byte column of text in the source
+	breaks int              // line breaks before the item: 0, 1 or 2 for a blank line
+	space  bool             // whether whitespace precedes the item in the source
+	nl     bool             // whether the lexer inferred a newline before the item
+
+	// set by classify
+	match     int  // index of the matching bracket, or -1
+	infix     bool // identifier used as an infix operator
+	prefix    bool // operator identifier that does not follow an operand
+	postfix   bool // operator identifier followed by a closing bracket or comma
+	header    bool // closing bracket of an if, while or for header
+	selectors bool // brace around import selectors
+}
+
+// scan splits src into items. The lexer drops the quotes of literals and
+// the comments that are not on a line of their own, so the text of every
+// item is taken from src and comments are found in the gaps between
+// tokens.
+func scan(src string) ([]*item, error) {
+	var items []*item
+	lexer := parser.Lexer(src)
+	pos, nl := 0, false
+	for _, t := range lexer.LexTillDone() {
+		switch t.Typ {
+		case parser.ERROR:
+			return nil, fmt.Errorf("%s: %s", lexer.Position(t.Pos), t.Val)
+		case parser.NEWLINE, parser.NEWLINES:
+			nl = true
+			continue
+		case parser.COMMENT:
+			continue
+		}
+		start, end := span(src, t)
+		it := &item{typ: t.Typ, text: src[start:end], offset: start, nl: nl}
+		items = gap(items, src, pos, start, it)
+		pos, nl = end, false
+	}
+	return gap(items, src, pos, len(src), nil), nil
+}
+
+// span returns the extent of t in src.
+func span(src string, t *parser.Token) (start, end int) {
+	switch t.Typ {
+	case parser.STRING:
+		if t.Pos >= 3 && src[t.Pos-3:t.Pos] == `"""` {
+			end = t.Pos + len(t.Val)
+			for end < len(src) && src[end] == '"' {
+				end++
+			}
+			return t.Pos - 3, end
+		}
+		return t.Pos - 1, t.Pos + len(t.Val) + 1
+	case parser.CHARACTER:
+		return t.Pos - 1, t.Pos + len(t.Val) + 1
+	}
+	return t.Pos, t.Pos + len(t.Val)
+}
+
+// gap appends the comments found in src[start:end], the whitespace
+// between two tokens, and then next, if it is not nil.
+func gap(items []*item, src string, start, end int, next *item) []*item {
+	breaks, space := 0, false
+	add := func(it *item, i int) {
+		it.offset, it.breaks, it.space = i, breaks, space
+		if it.breaks > 2 {
+			it.breaks = 2
+		}
+		it.col = i - (strings.LastIndexAny(src[:i], "\r\n") + 1)
+		items = append(items, it)
+		breaks, space = 0, false
+	}
+	for i := start; i < end; {
+		switch rest := src[i:end]; {
+		case rest[0] == '\r' && strings.HasPrefix(rest, "\r\n"):
+			i++
+			fallthrough
+		case rest[0] == '\n' || rest[0] == '\r':
+			breaks++
+			space = true
+			i++
+		case strings.HasPrefix(rest, "//"):
+			n := strings.IndexAny(rest, "\r\n")
+			if n < 0 {
+				n = len(rest)
+			}
+			add(&item{typ: parser.COMMENT, text: strings.TrimRight(rest[:n], " \t\f")}, i)
+			i += n
+		case strings.HasPrefix(rest, "/*"):
+			n := blockCommentLen(rest)
+			add(&item{typ: parser.COMMENT, text: rest[:n]}, i)
+			i += n
+		default:
+			_, n := utf8.DecodeRuneInString(rest)
+			space = true
+			i += n
+		}
+	}
+	if next != nil {
+		add(next, next.offset)
+	}
+	return items
+}
+
+// blockCommentLen returns the length of the block comment s starts with.
+// Block comments nest.
+func blockCommentLen(s string) int {
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch s[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// isLineComment reports whether it is a comment that runs to the end of
+// the line.
+func (it *item) isLineComment() bool {
+	return it.typ == parser.COMMENT && strings.HasPrefix(it.text, "//")
+}
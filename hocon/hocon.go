@@ -0,0 +1,218 @@
+// Package hocon implements a parser for HOCON, the Human-Optimized
+// Config Object Notation of Typesafe Config that scalafmt and sbt use
+// for their configuration files.
+//
+// The parser covers objects and arrays, with or without commas, path
+// keys such as a.b.c, the separators :, = and +=, quoted, triple-quoted
+// and unquoted strings, value concatenation, the merging of duplicate
+// objects, substitutions ${path} and ${?path}, which fall back to the
+// environment, and file includes. Includes may not reach outside of the
+// root directory given to ParseFile; url() and classpath() includes are
+// not supported. Durations and sizes are read with Value.Duration and
+// Value.Size.
+package hocon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Kind is the type of a Value.
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+
+	unresolved // a concatenation with substitutions, while parsing
+	subst      // a substitution, while parsing
+)
+
+var kindNames = [...]string{
+	Null:       "null",
+	Bool:       "boolean",
+	Number:     "number",
+	String:     "string",
+	Array:      "array",
+	Object:     "object",
+	unresolved: "unresolved value",
+	subst:      "substitution",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Position is a position in a configuration file.
+type Position struct {
+	Filename string
+	Line     int // starting at 1
+	Column   int // byte column, starting at 1
+}
+
+func (p Position) String() string {
+	s := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.Filename != "" {
+		s = p.Filename + ":" + s
+	}
+	return s
+}
+
+// An Error is a syntax error, or an error resolving a substitution or
+// an include.
+type Error struct {
+	Pos Position
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// A Value is a configuration value.
+type Value struct {
+	Kind   Kind
+	Pos    Position
+	Text   string   // text of a Bool, Number or String; "null" for Null
+	Elems  []*Value // elements of an Array
+	Fields []*Field // fields of an Object, in order of definition
+
+	// parts of an unresolved concatenation and the whitespace before
+	// each; the path of a substitution is in Text
+	parts    []*Value
+	spaces   []string
+	optional bool // ${?path}
+}
+
+// A Field is a field of an object.
+type Field struct {
+	Key   string
+	Value *Value
+	Pos   Position // position of the key that last set the field
+}
+
+// Field returns the field key of the object v, or nil.
+func (v *Value) Field(key string) *Field {
+	if v == nil || v.Kind != Object {
+		return nil
+	}
+	for _, f := range v.Fields {
+		if f.Key == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// Get returns the value at the dotted path in the object v, such as
+// "indent.main", or nil if there is none.
+func (v *Value) Get(path string) *Value {
+	return v.lookup(strings.Split(path, "."))
+}
+
+func (v *Value) lookup(keys []string) *Value {
+	for _, k := range keys {
+		f := v.Field(k)
+		if f == nil {
+			return nil
+		}
+		v = f.Value
+	}
+	return v
+}
+
+func (v *Value) String() string {
+	switch v.Kind {
+	case Array:
+		elems := make([]string, len(v.Elems))
+		for i, e := range v.Elems {
+			elems[i] = e.String()
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case Object:
+		fields := make([]string, len(v.Fields))
+		for i, f := range v.Fields {
+			fields[i] = strconv.Quote(f.Key) + ": " + f.Value.String()
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case String:
+		return strconv.Quote(v.Text)
+	}
+	return v.Text
+}
+
+func (v *Value) errorf(format string, args ...interface{}) error {
+	return &Error{Pos: v.Pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Bool returns the value of a boolean, which may also be written as the
+// strings yes, no, on and off.
+func (v *Value) Bool() (bool, error) {
+	if v.Kind == Bool || v.Kind == String {
+		switch v.Text {
+		case "true", "yes", "on":
+			return true, nil
+		case "false", "no", "off":
+			return false, nil
+		}
+	}
+	return false, v.errorf("%s is not a boolean", v)
+}
+
+// Int returns the value of an integer number.
+func (v *Value) Int() (int, error) {
+	if v.Kind == Number || v.Kind == String {
+		if n, err := strconv.Atoi(v.Text); err == nil {
+			return n, nil
+		}
+	}
+	return 0, v.errorf("%s is not an integer", v)
+}
+
+// Float returns the value of a number.
+func (v *Value) Float() (float64, error) {
+	if v.Kind == Number || v.Kind == String {
+		if f, err := strconv.ParseFloat(v.Text, 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, v.errorf("%s is not a number", v)
+}
+
+// Str returns the text of a string, number or boolean.
+func (v *Value) Str() (string, error) {
+	switch v.Kind {
+	case String, Number, Bool:
+		return v.Text, nil
+	}
+	return "", v.errorf("%s is not a string", v)
+}
+
+// Strings returns the texts of the elements of an array of strings. A
+// single string is read as an array of one.
+func (v *Value) Strings() ([]string, error) {
+	if v.Kind != Array {
+		s, err := v.Str()
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+	var res []string
+	for _, e := range v.Elems {
+		s, err := e.Str()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
@@ -0,0 +1,183 @@
+package hocon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var parseTests = []struct {
+	input, expected string
+}{
+	{`a = 1`, `{"a": 1}`},
+	{`{"a": true, "b": null}`, `{"a": true, "b": null}`},
+	{"a: x\nb = \"y z\"", `{"a": "x", "b": "y z"}`},
+	{"a {\n  b = 1\n}\na.c = 2", `{"a": {"b": 1, "c": 2}}`},
+	{`a.b.c = 1, "a.b" = 2`, `{"a": {"b": {"c": 1}}, "a.b": 2}`},
+	{"a = [1, 2,\n3\n4]", `{"a": [1, 2, 3, 4]}`},
+	{"a = 1\na = 2", `{"a": 2}`},
+	{"a = {b = 1}\na = {c = 2}", `{"a": {"b": 1, "c": 2}}`},
+	{"a = {b = 1}\na = 3", `{"a": 3}`},
+	{`a = foo bar  "baz"`, `{"a": "foo bar  baz"}`},
+	{`a = [1] [2]`, `{"a": [1, 2]}`},
+	{`a = {b: 1} {c: 2}`, `{"a": {"b": 1, "c": 2}}`},
+	{"a = \"\"\"x \"y\"\n z\"\"\"", `{"a": "x \"y\"\n z"}`},
+	{"# comment\na = 1 // comment\n// b = 2", `{"a": 1}`},
+	{"a = 1\nb = ${a}", `{"a": 1, "b": 1}`},
+	{"b = ${a.c}\na { c = x }", `{"b": "x", "a": {"c": "x"}}`},
+	{"a = x\nb = ${a} y", `{"a": "x", "b": "x y"}`},
+	{"a = ${?nope}\nb = 1", `{"b": 1}`},
+	{"a = [1]\na = ${a} [2]", `{"a": [1, 2]}`},
+	{"a += 1\na += 2", `{"a": [1, 2]}`},
+	{"a = {x = 1}\nb = ${a} {y = 2}", `{"a": {"x": 1}, "b": {"x": 1, "y": 2}}`},
+	{"a = s\na = ${a}t", `{"a": "st"}`},
+	{"x = [${?nope}, 1]", `{"x": [1]}`},
+	{"a { b = 1, c = ${a.b} }", `{"a": {"b": 1, "c": 1}}`},
+	{"HOCON_TEST_VAR = ${HOCON_TEST_VAR}", `{"HOCON_TEST_VAR": "env"}`},
+}
+
+func TestParse(t *testing.T) {
+	os.Setenv("HOCON_TEST_VAR", "env")
+	defer os.Unsetenv("HOCON_TEST_VAR")
+	for _, test := range parseTests {
+		v, err := Parse("test.conf", []byte(test.input))
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.input, err)
+			continue
+		}
+		if s := v.String(); s != test.expected {
+			t.Errorf("Parse(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+	}
+}
+
+var errorTests = []struct {
+	input, expected string
+}{
+	{`a = `, `test.conf:1:5: expected value, found end of file`},
+	{"a {\nb = 1", `test.conf:2:6: unexpected end of file, expected }`},
+	{`a = "x`, `test.conf:1:5: unterminated quoted string`},
+	{`a = ${b}`, `test.conf:1:5: undefined substitution ${b}`},
+	{"a = ${b}\nb = ${a}", `test.conf:1:1: substitution cycle through a`},
+	{`a = ${a}`, `test.conf:1:5: substitution ${a} refers to itself but has no previous value`},
+	{`a = [1] x`, `test.conf:1:9: cannot concatenate array and string`},
+	{`a = 1, , b = 2`, `test.conf:1:8: expected key, found ,`},
+	{`[1]`, `test.conf:1:1: the root of a configuration must be an object`},
+	{`include url("http://example.com")`, `test.conf:1:9: url() includes are not supported`},
+	{`include "../x.conf"`, `include "../x.conf" is outside of`},
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range errorTests {
+		_, err := Parse("test.conf", []byte(test.input))
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, Expected = %s", test.input, test.expected)
+		} else if !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Parse(%q) = %v, Expected = %s", test.input, err, test.expected)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hocon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"main.conf":         "a = 1\ninclude \"sub/base.conf\"\nb = ${c}\ninclude required(file(\"missing.conf\"))",
+		"sub/base.conf":     "c = 2\ninclude \"optional.conf\"\ninclude \"../loop.conf\"",
+		"loop.conf":         "d = 3\ninclude \"sub/base.conf\"",
+		"good.conf":         "a = 1\ninclude \"sub/plain.conf\"",
+		"sub/plain.conf":    "x { y = 2 }",
+		"sub/escape.conf":   "include \"../../outside.conf\"",
+		"sub/required.conf": "include required(\"nope.conf\")",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v, err := ParseFile(filepath.Join(dir, "good.conf"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.String(); s != `{"a": 1, "x": {"y": 2}}` {
+		t.Errorf("good.conf = %s", s)
+	}
+	for name, expected := range map[string]string{
+		"main.conf":         "include cycle through sub/base.conf",
+		"sub/escape.conf":   "is outside of",
+		"sub/required.conf": "no such file",
+	} {
+		_, err := ParseFile(filepath.Join(dir, name), dir)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: got %v, Expected = %s", name, err, expected)
+		}
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	v, err := Parse("", []byte(`
+n = 42
+f = 1.5
+yes = on
+s = [a, "b"]
+d1 = 10s, d2 = 500 millis, d3 = 2 hours, d4 = 250, d5 = 1.5m
+z1 = 512K, z2 = 10 MiB, z3 = 1 kB, z4 = 100, z5 = 2 megabytes
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := v.Get("n").Int(); n != 42 || err != nil {
+		t.Errorf("n = %d, %v", n, err)
+	}
+	if f, err := v.Get("f").Float(); f != 1.5 || err != nil {
+		t.Errorf("f = %v, %v", f, err)
+	}
+	if b, err := v.Get("yes").Bool(); !b || err != nil {
+		t.Errorf("yes = %v, %v", b, err)
+	}
+	if s, err := v.Get("s").Strings(); strings.Join(s, ",") != "a,b" || err != nil {
+		t.Errorf("s = %q, %v", s, err)
+	}
+	durations := map[string]time.Duration{
+		"d1": 10 * time.Second,
+		"d2": 500 * time.Millisecond,
+		"d3": 2 * time.Hour,
+		"d4": 250 * time.Millisecond,
+		"d5": 90 * time.Second,
+	}
+	for key, expected := range durations {
+		if d, err := v.Get(key).Duration(); d != expected || err != nil {
+			t.Errorf("%s = %v, %v, Expected = %v", key, d, err, expected)
+		}
+	}
+	sizes := map[string]int64{
+		"z1": 512 << 10,
+		"z2": 10 << 20,
+		"z3": 1000,
+		"z4": 100,
+		"z5": 2000000,
+	}
+	for key, expected := range sizes {
+		if z, err := v.Get(key).Size(); z != expected || err != nil {
+			t.Errorf("%s = %v, %v, Expected = %v", key, z, err, expected)
+		}
+	}
+	if _, err := v.Get("s").Int(); err == nil {
+		t.Errorf("s.Int() succeeded, Expected an error")
+	}
+	if _, err := v.Get("n").Duration(); err != nil {
+		t.Errorf("n.Duration(): %v", err)
+	}
+	if v.Get("missing") != nil || v.Get("n.x") != nil {
+		t.Errorf("Get of a missing path is not nil")
+	}
+}
@@ -0,0 +1,757 @@
+package hocon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Parse parses the configuration src, read from filename. Includes are
+// resolved relative to filename and may not leave its directory.
+func Parse(filename string, src []byte) (*Value, error) {
+	return parse(filename, src, filepath.Dir(filename))
+}
+
+// ParseFile reads and parses the configuration file filename. Includes
+// are resolved relative to the including file and may not leave the
+// directory root.
+func ParseFile(filename, root string) (*Value, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parse(filename, src, root)
+}
+
+func parse(filename string, src []byte, root string) (*Value, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDoc(filename, src, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &resolver{root: doc, state: map[*Field]int{}, walking: map[*Value]bool{}}
+	if err := r.walk(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type parser struct {
+	toks     []token
+	i        int
+	filename string
+	doc      *Value   // root object of the file
+	root     string   // absolute directory includes must stay in
+	stack    []string // absolute names of the files being included
+}
+
+// parseDoc parses the file filename into an object whose substitutions
+// are not yet resolved.
+func parseDoc(filename string, src []byte, root string, stack []string) (*Value, error) {
+	toks, err := scan(filename, string(src))
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{
+		toks:     toks,
+		filename: filename,
+		doc:      &Value{Kind: Object, Pos: Position{Filename: filename, Line: 1, Column: 1}},
+		root:     root,
+		stack:    append(stack, abs),
+	}
+	p.skipNewlines()
+	if t := p.tok(); t.kind == tLBrace {
+		p.next()
+		if err := p.parseFields(p.doc, []string{}, tRBrace); err != nil {
+			return nil, err
+		}
+		p.next()
+		p.skipNewlines()
+	} else if t.kind == tLBrack {
+		return nil, p.errorf(t.pos, "the root of a configuration must be an object")
+	} else if err := p.parseFields(p.doc, []string{}, tEOF); err != nil {
+		return nil, err
+	}
+	if t := p.tok(); t.kind != tEOF {
+		return nil, p.errorf(t.pos, "unexpected %s", t)
+	}
+	return p.doc, nil
+}
+
+func (p *parser) tok() token {
+	return p.toks[p.i]
+}
+
+func (p *parser) next() {
+	if p.i < len(p.toks)-1 {
+		p.i++
+	}
+}
+
+func (p *parser) skipNewlines() {
+	for p.tok().kind == tNewline {
+		p.next()
+	}
+}
+
+func (p *parser) errorf(pos Position, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tEOF:
+		return "end of file"
+	case tNewline:
+		return "newline"
+	case tQuoted:
+		return strconv.Quote(t.text)
+	case tUnquoted:
+		return t.text
+	case tSubst:
+		if t.optional {
+			return "${?" + t.text + "}"
+		}
+		return "${" + t.text + "}"
+	}
+	return [...]string{tComma: ",", tSep: "separator", tAppend: "+=", tLBrace: "{", tRBrace: "}", tLBrack: "[", tRBrack: "]"}[t.kind]
+}
+
+// parseFields parses the fields of obj up to the token end, which it
+// leaves for the caller. The path of obj is nil if obj cannot be the
+// target of a substitution, as in an array.
+func (p *parser) parseFields(obj *Value, path []string, end tokenKind) error {
+	for {
+		p.skipNewlines()
+		t := p.tok()
+		if t.kind == end {
+			return nil
+		}
+		if t.kind == tEOF {
+			return p.errorf(t.pos, "unexpected end of file, expected }")
+		}
+		if err := p.parseField(obj, path); err != nil {
+			return err
+		}
+		switch t := p.tok(); t.kind {
+		case tComma:
+			p.next()
+		case tNewline, end:
+		case tEOF:
+			return p.errorf(t.pos, "unexpected end of file, expected }")
+		default:
+			return p.errorf(t.pos, "expected , or newline after field, found %s", t)
+		}
+	}
+}
+
+func (p *parser) parseField(obj *Value, path []string) error {
+	start := p.tok()
+	if start.kind == tUnquoted && start.text == "include" {
+		if t := p.toks[p.i+1]; t.kind == tQuoted || t.kind == tUnquoted && strings.HasSuffix(t.text, "(") {
+			return p.parseInclude(obj)
+		}
+	}
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	var full []string
+	if path != nil {
+		full = append(append([]string{}, path...), keys...)
+	}
+	add := false
+	switch t := p.tok(); t.kind {
+	case tAppend:
+		add = true
+		fallthrough
+	case tSep:
+		p.next()
+		p.skipNewlines()
+	case tLBrace:
+	default:
+		return p.errorf(t.pos, "expected : or = after key, found %s", t)
+	}
+	v, err := p.parseValue(full)
+	if err != nil {
+		return err
+	}
+	return p.setField(obj, keys, full, start.pos, v, add)
+}
+
+// parseKey parses a path expression such as a.b."c.d", whose unquoted
+// parts split at dots.
+func (p *parser) parseKey() ([]string, error) {
+	start := p.tok()
+	var keys []string
+	var key strings.Builder
+	quoted := false // whether key holds a quoted part
+	for first := true; ; first = false {
+		t := p.tok()
+		if t.kind != tQuoted && t.kind != tUnquoted {
+			if first {
+				return nil, p.errorf(t.pos, "expected key, found %s", t)
+			}
+			break
+		}
+		if !first {
+			key.WriteString(t.space)
+		}
+		if t.kind == tQuoted {
+			key.WriteString(t.text)
+			quoted = true
+		} else {
+			for i, part := range strings.Split(t.text, ".") {
+				if i > 0 {
+					if key.Len() == 0 && !quoted {
+						return nil, p.errorf(start.pos, "invalid key")
+					}
+					keys = append(keys, key.String())
+					key.Reset()
+					quoted = false
+				}
+				key.WriteString(part)
+			}
+		}
+		p.next()
+	}
+	if key.Len() == 0 && !quoted {
+		return nil, p.errorf(start.pos, "invalid key")
+	}
+	return append(keys, key.String()), nil
+}
+
+// parseValue parses a value, which concatenates the values that follow
+// each other on a line.
+func (p *parser) parseValue(path []string) (*Value, error) {
+	pos := p.tok().pos
+	var parts []*Value
+	var spaces []string
+	pending := false    // whether parts holds substitutions
+	single := tUnquoted // kind of the first token
+loop:
+	for {
+		t := p.tok()
+		var v *Value
+		switch t.kind {
+		case tQuoted, tUnquoted:
+			v = &Value{Kind: String, Pos: t.pos, Text: t.text}
+			p.next()
+		case tSubst:
+			v = &Value{Kind: subst, Pos: t.pos, Text: t.text, optional: t.optional}
+			pending = true
+			p.next()
+		case tLBrace:
+			p.next()
+			v = &Value{Kind: Object, Pos: t.pos}
+			if err := p.parseFields(v, path, tRBrace); err != nil {
+				return nil, err
+			}
+			p.next()
+		case tLBrack:
+			var err error
+			if v, err = p.parseArray(); err != nil {
+				return nil, err
+			}
+		default:
+			break loop
+		}
+		if len(parts) == 0 {
+			single = t.kind
+		}
+		parts = append(parts, v)
+		spaces = append(spaces, t.space)
+	}
+	switch {
+	case len(parts) == 0:
+		t := p.tok()
+		return nil, p.errorf(t.pos, "expected value, found %s", t)
+	case len(parts) == 1:
+		if single == tUnquoted {
+			literal(parts[0])
+		}
+		return parts[0], nil
+	case pending:
+		return &Value{Kind: unresolved, Pos: pos, parts: parts, spaces: spaces}, nil
+	}
+	return concat(pos, parts, spaces)
+}
+
+var numberRE = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// literal gives the unquoted text v the kind of the literal it spells.
+func literal(v *Value) {
+	switch {
+	case v.Text == "true" || v.Text == "false":
+		v.Kind = Bool
+	case v.Text == "null":
+		v.Kind = Null
+	case numberRE.MatchString(v.Text):
+		v.Kind = Number
+	}
+}
+
+func (p *parser) parseArray() (*Value, error) {
+	arr := &Value{Kind: Array, Pos: p.tok().pos}
+	p.next()
+	for {
+		p.skipNewlines()
+		t := p.tok()
+		if t.kind == tRBrack {
+			p.next()
+			return arr, nil
+		}
+		if t.kind == tEOF {
+			return nil, p.errorf(arr.Pos, "unterminated array")
+		}
+		e, err := p.parseValue(nil)
+		if err != nil {
+			return nil, err
+		}
+		arr.Elems = append(arr.Elems, e)
+		sep := false
+		if p.tok().kind == tNewline {
+			p.skipNewlines()
+			sep = true
+		}
+		if p.tok().kind == tComma {
+			p.next()
+			sep = true
+		}
+		if t := p.tok(); !sep && t.kind != tRBrack {
+			return nil, p.errorf(t.pos, "expected , or ] in array, found %s", t)
+		}
+	}
+}
+
+// parseInclude parses include "name", include file("name") or either
+// wrapped in required(...), and merges the included file into obj.
+func (p *parser) parseInclude(obj *Value) error {
+	p.next()
+	t := p.tok()
+	required, closers := false, ""
+	if t.kind == tUnquoted {
+		text := t.text
+		if strings.HasPrefix(text, "required(") {
+			required, closers = true, ")"
+			text = text[len("required("):]
+		}
+		switch text {
+		case "":
+		case "file(":
+			closers += ")"
+		case "url(", "classpath(":
+			return p.errorf(t.pos, "%s) includes are not supported", text)
+		default:
+			return p.errorf(t.pos, "invalid include %s", t.text)
+		}
+		p.next()
+		t = p.tok()
+	}
+	if t.kind != tQuoted {
+		return p.errorf(t.pos, "expected quoted file name after include, found %s", t)
+	}
+	name := t.text
+	p.next()
+	if closers != "" {
+		if t := p.tok(); t.kind != tUnquoted || t.text != closers {
+			return p.errorf(t.pos, "expected %s after include file name, found %s", closers, t)
+		}
+		p.next()
+	}
+	return p.include(obj, t.pos, name, required)
+}
+
+func (p *parser) include(obj *Value, pos Position, name string, required bool) error {
+	filename := name
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(filepath.Dir(p.filename), filename)
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return p.errorf(pos, "%s", err)
+	}
+	if rel, err := filepath.Rel(p.root, abs); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p.errorf(pos, "include %q is outside of %s", name, p.root)
+	}
+	for _, s := range p.stack {
+		if s == abs {
+			return p.errorf(pos, "include cycle through %s", name)
+		}
+	}
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return p.errorf(pos, "%s", err)
+	}
+	doc, err := parseDoc(filename, src, p.root, p.stack)
+	if err != nil {
+		return err
+	}
+	mergeInto(obj, doc)
+	return nil
+}
+
+// setField sets the field at the path keys in obj, whose full path is
+// full, to v, or appends v to it if add is set.
+func (p *parser) setField(obj *Value, keys, full []string, pos Position, v *Value, add bool) error {
+	for _, k := range keys[:len(keys)-1] {
+		f := obj.Field(k)
+		if f == nil {
+			f = &Field{Key: k, Value: &Value{Kind: Object, Pos: pos}, Pos: pos}
+			obj.Fields = append(obj.Fields, f)
+		}
+		obj = objectIn(f, pos)
+	}
+	if full != nil {
+		var err error
+		if v, err = p.selfRefs(v, full); err != nil {
+			return err
+		}
+	}
+	k := keys[len(keys)-1]
+	f := obj.Field(k)
+	if add {
+		if v == nil {
+			return nil
+		}
+		var prev *Value
+		if f != nil {
+			prev = f.Value
+		}
+		switch {
+		case prev == nil:
+			v = &Value{Kind: Array, Pos: v.Pos, Elems: []*Value{v}}
+		case prev.Kind == Array:
+			v = &Value{Kind: Array, Pos: prev.Pos, Elems: append(append([]*Value{}, prev.Elems...), v)}
+		case prev.Kind == subst || prev.Kind == unresolved:
+			v = &Value{Kind: unresolved, Pos: prev.Pos, parts: []*Value{prev, {Kind: Array, Pos: v.Pos, Elems: []*Value{v}}}, spaces: []string{"", ""}}
+		default:
+			return p.errorf(pos, "cannot append to %s %s", prev.Kind, k)
+		}
+	}
+	switch {
+	case v == nil:
+	case f == nil:
+		obj.Fields = append(obj.Fields, &Field{Key: k, Value: v, Pos: pos})
+	case v.Kind == Object && f.Value.Kind == Object:
+		mergeInto(f.Value, v)
+		f.Pos = pos
+	case v.Kind == Object && (f.Value.Kind == subst || f.Value.Kind == unresolved):
+		f.Value = &Value{Kind: unresolved, Pos: f.Value.Pos, parts: []*Value{f.Value, v}, spaces: []string{"", ""}}
+		f.Pos = pos
+	default:
+		f.Value = v
+		f.Pos = pos
+	}
+	return nil
+}
+
+// objectIn returns the object to set the fields of f's value in. An
+// unresolved value becomes the concatenation of itself and the object.
+func objectIn(f *Field, pos Position) *Value {
+	switch v := f.Value; v.Kind {
+	case Object:
+		return v
+	case unresolved:
+		if last := v.parts[len(v.parts)-1]; last.Kind == Object {
+			return last
+		}
+		fallthrough
+	case subst:
+		obj := &Value{Kind: Object, Pos: pos}
+		f.Value = &Value{Kind: unresolved, Pos: v.Pos, parts: []*Value{v, obj}, spaces: []string{"", ""}}
+		return obj
+	}
+	obj := &Value{Kind: Object, Pos: pos}
+	f.Value = obj
+	return obj
+}
+
+// selfRefs replaces the substitutions in v that refer to the field at
+// full, or to one of the objects containing it, by the values they have
+// before v is set.
+func (p *parser) selfRefs(v *Value, full []string) (*Value, error) {
+	switch v.Kind {
+	case subst:
+		keys := splitPath(v.Text)
+		if !hasPrefix(full, keys) {
+			return v, nil
+		}
+		if prev := p.doc.lookup(keys); prev != nil {
+			return copyValue(prev), nil
+		}
+		if s, ok := os.LookupEnv(v.Text); ok {
+			return &Value{Kind: String, Pos: v.Pos, Text: s}, nil
+		}
+		if v.optional {
+			return nil, nil
+		}
+		return nil, p.errorf(v.Pos, "substitution ${%s} refers to itself but has no previous value", v.Text)
+	case unresolved, Array:
+		elems := v.parts
+		if v.Kind == Array {
+			elems = v.Elems
+		}
+		for i, e := range elems {
+			if e == nil {
+				continue
+			}
+			r, err := p.selfRefs(e, full)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = r
+		}
+		if v.Kind == Array {
+			v.Elems = dropNil(v.Elems)
+		}
+	}
+	return v, nil
+}
+
+func splitPath(path string) []string {
+	keys := strings.Split(path, ".")
+	for i, k := range keys {
+		keys[i] = strings.Trim(k, `"`)
+	}
+	return keys
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, k := range prefix {
+		if path[i] != k {
+			return false
+		}
+	}
+	return true
+}
+
+func dropNil(vs []*Value) []*Value {
+	res := vs[:0]
+	for _, v := range vs {
+		if v != nil {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func copyValue(v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	c.Elems, c.Fields, c.parts = nil, nil, nil
+	for _, e := range v.Elems {
+		c.Elems = append(c.Elems, copyValue(e))
+	}
+	for _, f := range v.Fields {
+		c.Fields = append(c.Fields, &Field{Key: f.Key, Value: copyValue(f.Value), Pos: f.Pos})
+	}
+	for _, part := range v.parts {
+		c.parts = append(c.parts, copyValue(part))
+	}
+	c.spaces = append([]string(nil), v.spaces...)
+	return &c
+}
+
+// mergeInto merges the fields of the object src into the object dst.
+// Objects merge recursively; any other value replaces the earlier one.
+func mergeInto(dst, src *Value) {
+	for _, sf := range src.Fields {
+		df := dst.Field(sf.Key)
+		switch {
+		case df == nil:
+			dst.Fields = append(dst.Fields, &Field{Key: sf.Key, Value: sf.Value, Pos: sf.Pos})
+		case df.Value.Kind == Object && sf.Value.Kind == Object:
+			mergeInto(df.Value, sf.Value)
+			df.Pos = sf.Pos
+		default:
+			df.Value, df.Pos = sf.Value, sf.Pos
+		}
+	}
+}
+
+// concat concatenates the values parts, separated by spaces. Missing
+// optional substitutions, which are nil, are left out.
+func concat(pos Position, parts []*Value, spaces []string) (*Value, error) {
+	var vs []*Value
+	var sp []string
+	for i, v := range parts {
+		if v != nil {
+			vs = append(vs, v)
+			sp = append(sp, spaces[i])
+		}
+	}
+	switch len(vs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return vs[0], nil
+	}
+	kind := vs[0].Kind
+	for _, v := range vs[1:] {
+		if (kind == Object || kind == Array || v.Kind == Object || v.Kind == Array) && v.Kind != kind {
+			return nil, &Error{Pos: v.Pos, Msg: fmt.Sprintf("cannot concatenate %s and %s", kind, v.Kind)}
+		}
+	}
+	res := &Value{Kind: kind, Pos: pos}
+	switch kind {
+	case Object:
+		for _, v := range vs {
+			mergeInto(res, copyValue(v))
+		}
+	case Array:
+		for _, v := range vs {
+			res.Elems = append(res.Elems, v.Elems...)
+		}
+	default:
+		res.Kind = String
+		for i, v := range vs {
+			if i > 0 {
+				res.Text += sp[i]
+			}
+			res.Text += v.Text
+		}
+	}
+	return res, nil
+}
+
+// A resolver resolves the substitutions of a configuration.
+type resolver struct {
+	root    *Value
+	state   map[*Field]int  // 1 while resolving the field, 2 when done
+	walking map[*Value]bool // objects and arrays being walked
+}
+
+// walk resolves the substitutions in the object or array v and in the
+// values it contains, and removes the missing optional ones.
+func (r *resolver) walk(v *Value) error {
+	if r.walking[v] {
+		return &Error{Pos: v.Pos, Msg: "substitution cycle: value contains itself"}
+	}
+	r.walking[v] = true
+	defer delete(r.walking, v)
+	switch v.Kind {
+	case Object:
+		for _, f := range v.Fields {
+			if err := r.resolveField(f); err != nil {
+				return err
+			}
+			if f.Value != nil {
+				if err := r.walk(f.Value); err != nil {
+					return err
+				}
+			}
+		}
+		fields := v.Fields[:0]
+		for _, f := range v.Fields {
+			if f.Value != nil {
+				fields = append(fields, f)
+			}
+		}
+		v.Fields = fields
+	case Array:
+		for i, e := range v.Elems {
+			e, err := r.resolve(e)
+			if err != nil {
+				return err
+			}
+			if e != nil {
+				if err := r.walk(e); err != nil {
+					return err
+				}
+			}
+			v.Elems[i] = e
+		}
+		v.Elems = dropNil(v.Elems)
+	}
+	return nil
+}
+
+func (r *resolver) resolveField(f *Field) error {
+	switch r.state[f] {
+	case 1:
+		return &Error{Pos: f.Pos, Msg: fmt.Sprintf("substitution cycle through %s", f.Key)}
+	case 2:
+		return nil
+	}
+	r.state[f] = 1
+	v, err := r.resolve(f.Value)
+	if err != nil {
+		return err
+	}
+	f.Value = v
+	r.state[f] = 2
+	return nil
+}
+
+// resolve returns v with its own substitutions resolved, but not those
+// of the values it contains, or nil for a missing optional substitution.
+func (r *resolver) resolve(v *Value) (*Value, error) {
+	switch v.Kind {
+	case subst:
+		t, err := r.lookup(splitPath(v.Text))
+		if err != nil || t != nil {
+			return t, err
+		}
+		if s, ok := os.LookupEnv(v.Text); ok {
+			return &Value{Kind: String, Pos: v.Pos, Text: s}, nil
+		}
+		if v.optional {
+			return nil, nil
+		}
+		return nil, &Error{Pos: v.Pos, Msg: fmt.Sprintf("undefined substitution ${%s}", v.Text)}
+	case unresolved:
+		parts := make([]*Value, len(v.parts))
+		for i, part := range v.parts {
+			if part == nil {
+				continue
+			}
+			var err error
+			if parts[i], err = r.resolve(part); err != nil {
+				return nil, err
+			}
+		}
+		return concat(v.Pos, parts, v.spaces)
+	}
+	return v, nil
+}
+
+// lookup returns the value at the path keys, resolving the values on
+// the way.
+func (r *resolver) lookup(keys []string) (*Value, error) {
+	v := r.root
+	for _, k := range keys {
+		f := v.Field(k)
+		if f == nil || f.Value == nil {
+			return nil, nil
+		}
+		if f.Value.Kind == subst || f.Value.Kind == unresolved {
+			if err := r.resolveField(f); err != nil {
+				return nil, err
+			}
+		}
+		if v = f.Value; v == nil {
+			return nil, nil
+		}
+	}
+	return v, nil
+}
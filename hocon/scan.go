@@ -0,0 +1,229 @@
+package hocon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tNewline
+	tComma
+	tSep    // : or =
+	tAppend // +=
+	tLBrace
+	tRBrace
+	tLBrack
+	tRBrack
+	tQuoted   // quoted string; text is unescaped
+	tUnquoted // unquoted text
+	tSubst    // substitution ${path} or ${?path}; text is the path
+)
+
+type token struct {
+	kind     tokenKind
+	text     string
+	optional bool   // ${?path}
+	space    string // whitespace before the token on its line
+	pos      Position
+}
+
+// forbidden holds the characters that may not appear in unquoted text.
+const forbidden = "$\"{}[]:=,+#`^?!@*&\\"
+
+type scanner struct {
+	src      string
+	filename string
+	offset   int
+	line     int
+	lineOff  int // offset of the start of the line
+}
+
+// scan splits src into tokens.
+func scan(filename, src string) ([]token, error) {
+	s := &scanner{src: src, filename: filename, line: 1}
+	if strings.HasPrefix(src, "\ufeff") {
+		s.offset = len("\ufeff")
+	}
+	var toks []token
+	for {
+		space := s.skipSpace()
+		t := token{pos: s.pos(), space: space}
+		if s.offset >= len(s.src) {
+			t.kind = tEOF
+			return append(toks, t), nil
+		}
+		rest := s.src[s.offset:]
+		switch c := rest[0]; {
+		case c == '\n':
+			t.kind = tNewline
+			s.offset++
+			s.line++
+			s.lineOff = s.offset
+		case c == '#' || strings.HasPrefix(rest, "//"):
+			for s.offset < len(s.src) && s.src[s.offset] != '\n' {
+				s.offset++
+			}
+			continue
+		case c == ',':
+			t.kind = tComma
+			s.offset++
+		case c == ':' || c == '=':
+			t.kind = tSep
+			s.offset++
+		case strings.HasPrefix(rest, "+="):
+			t.kind = tAppend
+			s.offset += 2
+		case c == '{':
+			t.kind = tLBrace
+			s.offset++
+		case c == '}':
+			t.kind = tRBrace
+			s.offset++
+		case c == '[':
+			t.kind = tLBrack
+			s.offset++
+		case c == ']':
+			t.kind = tRBrack
+			s.offset++
+		case strings.HasPrefix(rest, `"""`):
+			t.kind = tQuoted
+			end := strings.Index(rest[3:], `"""`)
+			if end < 0 {
+				return nil, s.errorf(t.pos, "unterminated triple-quoted string")
+			}
+			end += 3
+			// quotes before the closing """ belong to the string
+			for end+3 < len(rest) && rest[end+3] == '"' {
+				end++
+			}
+			t.text = rest[3:end]
+			s.advance(end + 3)
+		case c == '"':
+			t.kind = tQuoted
+			text, n, err := unquote(rest)
+			if err != nil {
+				return nil, s.errorf(t.pos, "%s", err)
+			}
+			t.text = text
+			s.offset += n
+		case strings.HasPrefix(rest, "${"):
+			t.kind = tSubst
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, s.errorf(t.pos, "unterminated substitution")
+			}
+			path := rest[2:end]
+			if strings.HasPrefix(path, "?") {
+				t.optional, path = true, path[1:]
+			}
+			t.text = strings.TrimSpace(path)
+			s.offset += end + 1
+		default:
+			n := 0
+			for n < len(rest) {
+				r, w := utf8.DecodeRuneInString(rest[n:])
+				if isSpace(r) || r == '\n' || strings.ContainsRune(forbidden, r) || strings.HasPrefix(rest[n:], "//") {
+					break
+				}
+				n += w
+			}
+			if n == 0 {
+				return nil, s.errorf(t.pos, "unexpected character %q", c)
+			}
+			t.kind = tUnquoted
+			t.text = rest[:n]
+			s.offset += n
+		}
+		toks = append(toks, t)
+	}
+}
+
+func (s *scanner) pos() Position {
+	return Position{Filename: s.filename, Line: s.line, Column: s.offset - s.lineOff + 1}
+}
+
+// advance moves past n bytes, which may span lines.
+func (s *scanner) advance(n int) {
+	text := s.src[s.offset : s.offset+n]
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		s.line += strings.Count(text, "\n")
+		s.lineOff = s.offset + i + 1
+	}
+	s.offset += n
+}
+
+func (s *scanner) skipSpace() string {
+	start := s.offset
+	for s.offset < len(s.src) {
+		r, w := utf8.DecodeRuneInString(s.src[s.offset:])
+		if !isSpace(r) {
+			break
+		}
+		s.offset += w
+	}
+	return s.src[start:s.offset]
+}
+
+func (s *scanner) errorf(pos Position, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isSpace reports whether r is whitespace other than a newline.
+func isSpace(r rune) bool {
+	return r != '\n' && (unicode.IsSpace(r) || r == '\ufeff')
+}
+
+// unquote returns the value of the JSON string s starts with and its
+// length in s.
+func unquote(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\n':
+			return "", 0, fmt.Errorf("newline in quoted string")
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated quoted string")
+			}
+			switch e := s[i+1]; e {
+			case '"', '\\', '/':
+				b.WriteByte(e)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+6 > len(s) {
+					return "", 0, fmt.Errorf("invalid escape \\u")
+				}
+				r, err := strconv.ParseUint(s[i+2:i+6], 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid escape \\u%s", s[i+2:i+6])
+				}
+				b.WriteRune(rune(r))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", e)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
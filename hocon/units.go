@@ -0,0 +1,97 @@
+package hocon
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nano": time.Nanosecond, "nanos": time.Nanosecond,
+	"nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "micro": time.Microsecond, "micros": time.Microsecond,
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "milli": time.Millisecond, "millis": time.Millisecond,
+	"millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+}
+
+// Duration returns the value of a duration such as 10s, 500 millis or
+// 2 hours. A number without a unit counts milliseconds.
+func (v *Value) Duration() (time.Duration, error) {
+	n, unit, ok := v.quantity()
+	if !ok {
+		return 0, v.errorf("%s is not a duration", v)
+	}
+	scale := time.Millisecond
+	if unit != "" {
+		if scale, ok = durationUnits[unit]; !ok {
+			return 0, v.errorf("unknown duration unit %q", unit)
+		}
+	}
+	return time.Duration(math.Round(n * float64(scale))), nil
+}
+
+// sizeUnits maps the units of sizes to their powers of 1000 or 1024.
+var sizeUnits = map[string]struct {
+	base  float64
+	power int
+}{
+	"B": {1, 0}, "b": {1, 0}, "byte": {1, 0}, "bytes": {1, 0},
+	"kB": {1000, 1}, "kilobyte": {1000, 1}, "kilobytes": {1000, 1},
+	"MB": {1000, 2}, "megabyte": {1000, 2}, "megabytes": {1000, 2},
+	"GB": {1000, 3}, "gigabyte": {1000, 3}, "gigabytes": {1000, 3},
+	"TB": {1000, 4}, "terabyte": {1000, 4}, "terabytes": {1000, 4},
+	"PB": {1000, 5}, "petabyte": {1000, 5}, "petabytes": {1000, 5},
+	"EB": {1000, 6}, "exabyte": {1000, 6}, "exabytes": {1000, 6},
+	"K": {1024, 1}, "k": {1024, 1}, "Ki": {1024, 1}, "KiB": {1024, 1}, "kibibyte": {1024, 1}, "kibibytes": {1024, 1},
+	"M": {1024, 2}, "m": {1024, 2}, "Mi": {1024, 2}, "MiB": {1024, 2}, "mebibyte": {1024, 2}, "mebibytes": {1024, 2},
+	"G": {1024, 3}, "g": {1024, 3}, "Gi": {1024, 3}, "GiB": {1024, 3}, "gibibyte": {1024, 3}, "gibibytes": {1024, 3},
+	"T": {1024, 4}, "t": {1024, 4}, "Ti": {1024, 4}, "TiB": {1024, 4}, "tebibyte": {1024, 4}, "tebibytes": {1024, 4},
+	"P": {1024, 5}, "p": {1024, 5}, "Pi": {1024, 5}, "PiB": {1024, 5}, "pebibyte": {1024, 5}, "pebibytes": {1024, 5},
+	"E": {1024, 6}, "e": {1024, 6}, "Ei": {1024, 6}, "EiB": {1024, 6}, "exbibyte": {1024, 6}, "exbibytes": {1024, 6},
+}
+
+// Size returns the number of bytes of a size such as 512K, 10 MiB or
+// 1 kB. A number without a unit counts bytes.
+func (v *Value) Size() (int64, error) {
+	n, unit, ok := v.quantity()
+	if !ok {
+		return 0, v.errorf("%s is not a size", v)
+	}
+	if unit == "" {
+		unit = "B"
+	}
+	u, ok := sizeUnits[unit]
+	if !ok {
+		return 0, v.errorf("unknown size unit %q", unit)
+	}
+	return int64(math.Round(n * math.Pow(u.base, float64(u.power)))), nil
+}
+
+// quantity splits a number followed by an optional unit.
+func (v *Value) quantity() (n float64, unit string, ok bool) {
+	if v.Kind != Number && v.Kind != String {
+		return 0, "", false
+	}
+	text := strings.TrimSpace(v.Text)
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return !('0' <= r && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	})
+	if i < 0 {
+		i = len(text)
+	}
+	// an exponent marker is part of a unit that follows the digits
+	for i > 0 && (text[i-1] == 'e' || text[i-1] == 'E') {
+		i--
+	}
+	n, err := strconv.ParseFloat(text[:i], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return n, strings.TrimSpace(text[i:]), true
+}
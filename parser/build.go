@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// Helpers that build AST nodes out of tokens and partially built trees.
+
+// newIdent returns the identifier of an IDENTIFIER token; backquotes are
+// stripped and recorded.
+func newIdent(pos ast.Pos, val string) *ast.Ident {
+	if len(val) >= 2 && strings.HasPrefix(val, backtick) && strings.HasSuffix(val, backtick) {
+		return &ast.Ident{NamePos: pos, Name: val[1 : len(val)-1], Backquoted: true}
+	}
+	return &ast.Ident{NamePos: pos, Name: val}
+}
+
+// literalKind classifies the source text of a literal token.
+func literalKind(typ TokenType, raw string) ast.LitKind {
+	switch typ {
+	case STRING:
+		return ast.StringLit
+	case CHARACTER:
+		return ast.CharLit
+	case SYMBOL:
+		return ast.SymbolLit
+	case BOOLEAN, TRUE, FALSE:
+		return ast.BooleanLit
+	case NULL:
+		return ast.NullLit
+	}
+	lower := strings.ToLower(strings.TrimPrefix(raw, "-"))
+	switch {
+	case strings.HasSuffix(lower, "l"):
+		return ast.LongLit
+	case strings.HasPrefix(lower, "0x"):
+		return ast.IntLit
+	case strings.HasSuffix(lower, "f"):
+		return ast.FloatLit
+	case strings.ContainsAny(lower, ".e") || strings.HasSuffix(lower, "d"):
+		return ast.DoubleLit
+	}
+	return ast.IntLit
+}
+
+// infixChain is a flat sequence of operands separated by operators, as
+// the grammar sees it before precedence is applied: operands[i] ops[i]
+// operands[i+1] ...
+type infixChain struct {
+	operands []ast.Node
+	ops      []*ast.Ident
+	targs    map[*ast.Ident][]ast.Type // explicit type arguments, as in a op[T] b
+
+	// mixed, if not nil, is called by build with each operator that has
+	// the precedence but not the associativity of the operator whose
+	// operand it shares once tighter operators are reduced, as + in
+	// a +: b + c (SLS 6.12.3).
+	mixed func(op *ast.Ident)
+}
+
+func newChain(operand ast.Node) *infixChain {
+	return &infixChain{operands: []ast.Node{operand}, targs: map[*ast.Ident][]ast.Type{}}
+}
+
+func (c *infixChain) add(op *ast.Ident, operand ast.Node) *infixChain {
+	c.ops = append(c.ops, op)
+	c.operands = append(c.operands, operand)
+	return c
+}
+
+// build applies SLS 6.12.3 precedence and associativity to the chain
+// and combines operands with mk.
+func (c *infixChain) build(mk func(x ast.Node, op *ast.Ident, y ast.Node) ast.Node) ast.Node {
+	var operands []ast.Node
+	var ops []*ast.Ident
+	reduce := func() {
+		n := len(operands)
+		op := ops[len(ops)-1]
+		operands = append(operands[:n-2], mk(operands[n-2], op, operands[n-1]))
+		ops = ops[:len(ops)-1]
+	}
+	operands = append(operands, c.operands[0])
+	for i, op := range c.ops {
+		prec := OperatorPrecedence(op.Name)
+		for len(ops) > 0 {
+			top := OperatorPrecedence(ops[len(ops)-1].Name)
+			if top == prec && c.mixed != nil && IsRightAssociative(ops[len(ops)-1].Name) != IsRightAssociative(op.Name) {
+				c.mixed(op)
+			}
+			if top > prec || top == prec && !IsRightAssociative(op.Name) {
+				reduce()
+				continue
+			}
+			break
+		}
+		ops = append(ops, op)
+		operands = append(operands, c.operands[i+1])
+	}
+	for len(ops) > 0 {
+		reduce()
+	}
+	return operands[0]
+}
+
+func (c *infixChain) expr() ast.Expr {
+	return c.build(func(x ast.Node, op *ast.Ident, y ast.Node) ast.Node {
+		return &ast.InfixApply{X: x.(ast.Expr), Op: op, Targs: c.targs[op], Y: y.(ast.Expr)}
+	}).(ast.Expr)
+}
+
+func (c *infixChain) typ() ast.Type {
+	return c.build(func(x ast.Node, op *ast.Ident, y ast.Node) ast.Node {
+		return &ast.InfixType{X: x.(ast.Type), Op: op, Y: y.(ast.Type)}
+	}).(ast.Type)
+}
+
+func (c *infixChain) pattern() ast.Pattern {
+	return c.build(func(x ast.Node, op *ast.Ident, y ast.Node) ast.Node {
+		return &ast.InfixPattern{X: x.(ast.Pattern), Op: op, Y: y.(ast.Pattern)}
+	}).(ast.Pattern)
+}
+
+// exprToParams converts the left hand side of "=>" in an expression
+// into the parameters of an anonymous function. It reports false if lhs
+// cannot be a parameter list.
+func exprToParams(lhs ast.Expr) (params []*ast.Param, lparen, rparen ast.Pos, ok bool) {
+	var elts []ast.Expr
+	switch x := lhs.(type) {
+	case *ast.Tuple:
+		elts, lparen, rparen = x.Elts, x.Lparen, x.Rparen
+	default:
+		elts = []ast.Expr{lhs}
+	}
+	for _, elt := range elts {
+		param := exprToParam(elt)
+		if param == nil {
+			return nil, ast.NoPos, ast.NoPos, false
+		}
+		params = append(params, param)
+	}
+	return params, lparen, rparen, true
+}
+
+func exprToParam(x ast.Expr) *ast.Param {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return &ast.Param{Name: x}
+	case *ast.Typed:
+		if id, ok := x.X.(*ast.Ident); ok {
+			return &ast.Param{Name: id, Type: x.Type}
+		}
+	}
+	return nil
+}
+
+// typeToParams returns the parameter types of a function type whose
+// left hand side is lhs.
+func typeToParams(lhs ast.Type) (params []ast.Type, lparen, rparen ast.Pos) {
+	if t, ok := lhs.(*ast.TupleType); ok {
+		return t.Elts, t.Lparen, t.Rparen
+	}
+	return []ast.Type{lhs}, ast.NoPos, ast.NoPos
+}
+
+// nestPackages moves the statements following a chained package clause,
+// which the grammar produces flat, into that clause.
+func nestPackages(stats []ast.Stat) []ast.Stat {
+	for i, stat := range stats {
+		if pkg, ok := stat.(*ast.PackageClause); ok && !pkg.Lbrace.IsValid() {
+			pkg.Stats = nestPackages(stats[i+1:])
+			return stats[:i+1]
+		}
+	}
+	return stats
+}
+
+// setModifiers attaches the annotations and modifiers preceding a
+// definition to it.
+func setModifiers(stat ast.Stat, annots []*ast.Annotation, mods []*ast.Modifier) bool {
+	switch x := stat.(type) {
+	case *ast.ValDef:
+		x.Annotations, x.Modifiers = annots, mods
+	case *ast.DefDef:
+		x.Annotations, x.Modifiers = annots, mods
+	case *ast.TypeDef:
+		x.Annotations, x.Modifiers = annots, mods
+	case *ast.ClassDef:
+		x.Annotations, x.Modifiers = annots, append(mods, x.Modifiers...)
+	case *ast.TraitDef:
+		x.Annotations, x.Modifiers = annots, mods
+	case *ast.ObjectDef:
+		x.Annotations, x.Modifiers = annots, append(mods, x.Modifiers...)
+	default:
+		return false
+	}
+	return true
+}
+
+// splitImportPath splits the last name off a path such as a.b.c into the
+// importer a.b with selector c. A path that does not end in a name, such
+// as C.this, has neither.
+func splitImportPath(path ast.Expr) (ast.Expr, *ast.Ident) {
+	switch x := path.(type) {
+	case *ast.Select:
+		return x.X, x.Sel
+	case *ast.Ident:
+		return nil, x
+	}
+	return nil, nil
+}
+
+// interpolationPart is an argument of an interpolated string: either a
+// simple name $id or the source of a block ${...}.
+type interpolationPart struct {
+	offset int    // offset of the name or of the block's contents
+	name   string // set for $id
+	block  string // set for ${...}
+}
+
+// splitInterpolation splits the body of an interpolated string, starting
+// at offset, into literal parts and arguments.
+func splitInterpolation(body string, offset int) (parts []string, args []interpolationPart) {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '$' || i+1 == len(body) {
+			b.WriteByte(body[i])
+			continue
+		}
+		switch next := body[i+1]; {
+		case next == '$':
+			b.WriteString("$$")
+			i++
+		case next == '{':
+			depth, j := 1, i+2
+			for ; j < len(body) && depth > 0; j++ {
+				switch body[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+			parts = append(parts, b.String())
+			b.Reset()
+			args = append(args, interpolationPart{offset: offset + i + 2, block: body[i+2 : j-1]})
+			i = j - 1
+		case isLetter(rune(next)):
+			j := i + 1
+			for j < len(body) && (isLetterOrDigit(rune(body[j])) && body[j] != '$') {
+				j++
+			}
+			parts = append(parts, b.String())
+			b.Reset()
+			args = append(args, interpolationPart{offset: offset + i + 1, name: body[i+1 : j]})
+			i = j - 1
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	return append(parts, b.String()), args
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// comments returns the comments of src in groups. The lexer does not
+// keep the text of comments, so they are found in the gaps between the
+// tokens items, which hold nothing but whitespace, comments and the
+// script header.
+func comments(src string, items []*item) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	pos := 0
+	for _, it := range items {
+		if it.tok != nil && (it.is(NEWLINE) || it.is(NEWLINES)) {
+			continue
+		}
+		if start := it.pos.Offset(); start > pos {
+			groups = gapComments(groups, src, pos, start)
+		}
+		if end := it.end.Offset(); end > pos {
+			pos = end
+		}
+	}
+	return gapComments(groups, src, pos, len(src))
+}
+
+// gapComments appends the comment groups of src[start:end] to groups.
+// The comments on the line of the token before the gap form a group of
+// their own, which can trail that token.
+func gapComments(groups []*ast.CommentGroup, src string, start, end int) []*ast.CommentGroup {
+	var group *ast.CommentGroup
+	newlines := 0         // since the last comment
+	sameLine := start > 0 // on the line of the previous token
+	for i := start; i < end; {
+		rest := src[i:end]
+		var n int
+		switch {
+		case rest[0] == '\n':
+			if sameLine {
+				group, sameLine = nil, false
+			}
+			newlines++
+			i++
+			continue
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\f':
+			i++
+			continue
+		case strings.HasPrefix(rest, "//"):
+			n = strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			n = len(strings.TrimRight(rest[:n], "\r"))
+		case strings.HasPrefix(rest, "/*"):
+			n = blockCommentLen(rest)
+		default:
+			// a script header
+			n = strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			i += n
+			group = nil
+			continue
+		}
+		c := &ast.Comment{Slash: ast.PosOf(i), Text: rest[:n]}
+		if group == nil || newlines > 1 {
+			group = &ast.CommentGroup{}
+			groups = append(groups, group)
+		}
+		group.List = append(group.List, c)
+		newlines = 0
+		i += n
+	}
+	return groups
+}
+
+// blockCommentLen returns the length of the block comment s starts with.
+// Block comments nest.
+func blockCommentLen(s string) int {
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch s[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
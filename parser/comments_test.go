@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+const commentsFile = `#!/usr/bin/env scala
+// header
+/* more
+   header */
+
+/** doc */
+object O { // after brace
+  val s = "// not a comment" /* a /* nested */ comment */
+  f(x) // one
+  // two
+
+  /**/ g
+}
+// end`
+
+func TestComments(t *testing.T) {
+	expected := [][]string{
+		{"// header", "/* more\n   header */"},
+		{"/** doc */"},
+		{"// after brace"},
+		{"/* a /* nested */ comment */"},
+		{"// one"},
+		{"// two"},
+		{"/**/"},
+		{"// end"},
+	}
+	f, err := ParseFile("test.scala", commentsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lalr, err := ParseFileLALR("test.scala", commentsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []*ast.File{f, lalr} {
+		var groups [][]string
+		for _, g := range file.Comments {
+			var list []string
+			for _, c := range g.List {
+				if s := commentsFile[c.Pos().Offset():c.End().Offset()]; s != c.Text {
+					t.Errorf("comment %q at %d-%d holds %q", c.Text, c.Pos(), c.End(), s)
+				}
+				list = append(list, c.Text)
+			}
+			groups = append(groups, list)
+		}
+		if !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Comments = %q, Expected = %q", groups, expected)
+		}
+	}
+}
@@ -0,0 +1,1890 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// The recursive-descent parser. It follows the productions of the Scala 2
+// syntax summary (SLS chapter 13) and resolves the context-sensitive
+// spots, such as optional newlines, anonymous functions and self types,
+// by looking at the surrounding tokens. On a syntax error it records the
+// error, puts a Bad node in the tree and skips to the end of the
+// statement, so that a file with several mistakes still yields a mostly
+// complete tree and every error.
+
+// ParseFile parses the Scala 2 compilation unit or script src. The file
+// is returned even if src has syntax errors; err is then an ErrorList.
+func ParseFile(name, src string) (f *ast.File, err error) {
+	p := newParser(name, src, 0)
+	f = p.parseFile()
+	return f, p.errors.Err()
+}
+
+// ParseExpr parses the Scala 2 expression src, such as a line of an sbt
+// build or a ScalaTest assertion. Infix operations are grouped into
+// *ast.InfixApply nodes by operator precedence and associativity (SLS
+// 6.12.3). If src has syntax errors, a partial expression is returned
+// with an ErrorList.
+func ParseExpr(src string) (ast.Expr, error) {
+	p := newParser("", src, 0)
+	p.skipSeps()
+	x := p.parseExpr(inBlock)
+	p.expectEnd("expression")
+	return x, p.errors.Err()
+}
+
+// ParseType parses the Scala 2 type src, such as Map[String, Int] => Unit.
+// If src has syntax errors or does not end after the type, a partial type
+// is returned with an ErrorList.
+func ParseType(src string) (ast.Type, error) {
+	p := newParser("", src, 0)
+	p.skipSeps()
+	t := p.parseType()
+	p.expectEnd("type")
+	return t, p.errors.Err()
+}
+
+// ParsePattern parses the Scala 2 pattern src, such as the pattern of a
+// case clause. If src has syntax errors or does not end after the
+// pattern, a partial pattern is returned with an ErrorList.
+func ParsePattern(src string) (ast.Pattern, error) {
+	p := newParser("", src, 0)
+	p.skipSeps()
+	pat := p.parsePattern()
+	p.expectEnd("pattern")
+	return pat, p.errors.Err()
+}
+
+// ParseStats parses the Scala 2 statements src, separated by semicolons
+// or newlines as in a block or script. The statements are returned even
+// if src has syntax errors; err is then an ErrorList.
+func ParseStats(src string) ([]ast.Stat, error) {
+	p := newParser("", src, 0)
+	stats := p.parseStatSeq(topStats)
+	return stats, p.errors.Err()
+}
+
+// An ErrorList is a list of syntax errors, sorted by position.
+type ErrorList []*Error
+
+func (e ErrorList) Error() string {
+	switch len(e) {
+	case 0:
+		return "no errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0], len(e)-1)
+}
+
+// Err returns e as an error, or nil if e is empty.
+func (e ErrorList) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	sort.SliceStable(e, func(i, j int) bool { return e[i].Pos.Offset < e[j].Pos.Offset })
+	return e
+}
+
+type parser struct {
+	name   string
+	src    string
+	lines  *lexer // for positions
+	items  []*item
+	index  int
+	tok    *item // current token, items[index]
+	errors ErrorList
+
+	// parens records the parentheses around expressions written as
+	// (x), which the tree does not otherwise keep.
+	parens map[ast.Expr][2]ast.Pos
+
+	// inAlternative is set while parsing a pattern where | separates
+	// alternatives, so that it does not end the type of x: T there.
+	inAlternative bool
+}
+
+// newParser prepares a parser for the text of src starting at offset.
+func newParser(name, src string, offset int) *parser {
+	p := &parser{name: name, src: src, lines: Lexer(src), parens: map[ast.Expr][2]ast.Pos{}}
+	p.items = scan(src, offset, func(offset int, msg string) {
+		p.errors = append(p.errors, &Error{Filename: name, Pos: p.lines.Position(offset), Msg: msg})
+	})
+	eof := ast.PosOf(len(src))
+	p.items = append(p.items, &item{tok: &Token{Typ: EOF, Pos: len(src)}, pos: eof, end: eof})
+	p.tok = p.items[0]
+	return p
+}
+
+// ----------------------------------------------------------------------------
+// Tokens
+
+func (it *item) is(typ TokenType) bool { return it.tok.Typ == typ }
+
+// isOp reports whether it is the reserved operator or delimiter val.
+func (it *item) isOp(val string) bool { return it.tok.Typ == OPORDELIM && it.tok.Val == val }
+
+// isIdent reports whether it is the identifier val, such as "_" or "|".
+func (it *item) isIdent(val string) bool { return it.tok.Typ == IDENTIFIER && it.tok.Val == val }
+
+func (it *item) isArrow() bool { return it.isOp("=>") || it.isOp("⇒") }
+
+func (it *item) isLeftArrow() bool { return it.isOp("<-") || it.isOp("←") }
+
+func (it *item) isSep() bool {
+	return it.is(SEMICOLON) || it.is(NEWLINE) || it.is(NEWLINES)
+}
+
+func (it *item) isLiteral() bool {
+	switch it.tok.Typ {
+	case NUMBER, STRING, CHARACTER, SYMBOL, BOOLEAN, TRUE, FALSE, NULL:
+		return true
+	}
+	return false
+}
+
+func (it *item) isModifier() bool {
+	switch it.tok.Typ {
+	case ABSTRACT, FINAL, SEALED, IMPLICIT, LAZY, OVERRIDE, PRIVATE, PROTECTED:
+		return true
+	}
+	return false
+}
+
+// canStartSimpleExpr reports whether it can start an operand of an
+// infix expression.
+func (it *item) canStartSimpleExpr() bool {
+	switch it.tok.Typ {
+	case IDENTIFIER, L_PAREN, L_CURLY, NEW, THIS, SUPER:
+		return true
+	}
+	return it.isLiteral()
+}
+
+func (it *item) canStartExpr() bool {
+	switch it.tok.Typ {
+	case IF, WHILE, DO, TRY, FOR, THROW, RETURN:
+		return true
+	}
+	return it.canStartSimpleExpr()
+}
+
+func (it *item) canStartType() bool {
+	switch it.tok.Typ {
+	case IDENTIFIER, L_PAREN, THIS:
+		return true
+	}
+	return false
+}
+
+func (p *parser) next() {
+	if p.index < len(p.items)-1 {
+		p.index++
+	}
+	p.tok = p.items[p.index]
+}
+
+func (p *parser) peek(n int) *item {
+	if i := p.index + n; i < len(p.items) {
+		return p.items[i]
+	}
+	return p.items[len(p.items)-1]
+}
+
+// reset backtracks to the token at index, dropping the errors found
+// since.
+func (p *parser) reset(index, errors int) {
+	p.index = index
+	p.tok = p.items[index]
+	p.errors = p.errors[:errors]
+}
+
+// adjacent reports whether the token after the current one follows it
+// without white space.
+func (p *parser) adjacent() bool {
+	return p.peek(1).pos == p.tok.end
+}
+
+// isInterpolation reports whether the current token is the identifier
+// of an interpolated string id"...".
+func (p *parser) isInterpolation() bool {
+	return p.tok.is(IDENTIFIER) && p.peek(1).is(STRING) && p.adjacent()
+}
+
+// isCaseDef reports whether the current token starts case class or case
+// object.
+func (p *parser) isCaseDef() bool {
+	return p.tok.is(CASE) && (p.peek(1).is(CLASS) || p.peek(1).is(OBJECT))
+}
+
+func (p *parser) isDefStart() bool {
+	switch p.tok.tok.Typ {
+	case VAL, VAR, DEF, TYPE, CLASS, TRAIT, OBJECT:
+		return true
+	case CASE:
+		return p.isCaseDef()
+	case IMPLICIT:
+		// implicit x => ... is an anonymous function
+		return !(p.peek(1).is(IDENTIFIER) && p.peek(2).isArrow())
+	}
+	return p.tok.isOp("@") || p.tok.isModifier()
+}
+
+// newLineOpt skips a single newline.
+func (p *parser) newLineOpt() {
+	if p.tok.is(NEWLINE) {
+		p.next()
+	}
+}
+
+// newLinesOpt skips a newline or a blank line.
+func (p *parser) newLinesOpt() {
+	if p.tok.is(NEWLINE) || p.tok.is(NEWLINES) {
+		p.next()
+	}
+}
+
+// newLineOptWhenFollowedBy skips a single newline if the token after it
+// is typ, as in a block argument or template body on the next line.
+func (p *parser) newLineOptWhenFollowedBy(typ TokenType) {
+	if p.tok.is(NEWLINE) && p.peek(1).is(typ) {
+		p.next()
+	}
+}
+
+func (p *parser) skipSeps() {
+	for p.tok.isSep() {
+		p.next()
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Errors
+
+func (p *parser) error(pos ast.Pos, msg string) {
+	epos := p.lines.Position(pos.Offset())
+	// one error per line; the others are likely spurious
+	if n := len(p.errors); n > 0 && p.errors[n-1].Pos.Line == epos.Line {
+		return
+	}
+	p.errors = append(p.errors, &Error{Filename: p.name, Pos: epos, Msg: msg})
+}
+
+func (p *parser) errorf(pos ast.Pos, format string, a ...interface{}) {
+	p.error(pos, fmt.Sprintf(format, a...))
+}
+
+func (p *parser) errorExpected(what string) {
+	p.errorf(p.tok.pos, "expected %s, found %s", what, p.tok.describe())
+}
+
+var tokenTexts = map[TokenType]string{
+	L_PAREN: "(", R_PAREN: ")", L_BRACKET: "[", R_BRACKET: "]",
+	L_CURLY: "{", R_CURLY: "}", DOT: ".", SEMICOLON: ";",
+}
+
+func tokenText(typ TokenType) string {
+	if text, ok := tokenTexts[typ]; ok {
+		return text
+	}
+	for text, t := range keywordsToTokenType {
+		if t == typ {
+			return text
+		}
+	}
+	return typ.String()
+}
+
+// expect consumes a token of type typ and returns its position. If the
+// current token is of another type, an error is recorded and nothing is
+// consumed.
+func (p *parser) expect(typ TokenType) ast.Pos {
+	pos := p.tok.pos
+	if !p.tok.is(typ) {
+		p.errorExpected("'" + tokenText(typ) + "'")
+		return pos
+	}
+	p.next()
+	return pos
+}
+
+// expectOp is like expect for a reserved operator or delimiter.
+func (p *parser) expectOp(val string) ast.Pos {
+	pos := p.tok.pos
+	ok := p.tok.isOp(val)
+	switch val {
+	case "=>":
+		ok = p.tok.isArrow()
+	case "<-":
+		ok = p.tok.isLeftArrow()
+	}
+	if !ok {
+		p.errorExpected("'" + val + "'")
+		return pos
+	}
+	p.next()
+	return pos
+}
+
+// expectEnd records an error unless only newlines and semicolons are
+// left after a snippet of the given kind.
+func (p *parser) expectEnd(what string) {
+	p.skipSeps()
+	if !p.tok.is(EOF) {
+		p.errorExpected("end of " + what)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Statements
+
+// A statContext tells which tokens end a statement sequence.
+type statContext int
+
+const (
+	topStats      statContext = iota // the file: EOF
+	templateStats                    // a template body, packaging or refinement: "}"
+	blockStats                       // a block: "}"
+	caseStats                        // a case clause: "}" or the next case
+)
+
+func (p *parser) atStatSeqEnd(ctx statContext) bool {
+	switch {
+	case p.tok.is(EOF):
+		return true
+	case p.tok.is(R_CURLY):
+		return ctx != topStats
+	case p.tok.is(CASE):
+		return ctx == caseStats && !p.isCaseDef()
+	}
+	return false
+}
+
+func (p *parser) parseFile() *ast.File {
+	stats := p.parseStatSeq(topStats)
+	return &ast.File{
+		Name:      p.name,
+		FileStart: ast.PosOf(0),
+		Stats:     stats,
+		FileEnd:   ast.PosOf(len(p.src)),
+		Comments:  comments(p.src, p.items),
+	}
+}
+
+// parseStatSeq parses statements separated by semicolons or newlines. A
+// statement that does not end where it should is followed by a BadStat
+// covering the tokens skipped to get to the next statement.
+func (p *parser) parseStatSeq(ctx statContext) []ast.Stat {
+	var stats []ast.Stat
+	for {
+		p.skipSeps()
+		if p.atStatSeqEnd(ctx) {
+			return stats
+		}
+		stat := p.parseStat(ctx)
+		if stat != nil {
+			stats = append(stats, stat)
+		}
+		if pkg, ok := stat.(*ast.PackageClause); ok && !pkg.Lbrace.IsValid() {
+			// a chained package clause holds the rest of the file
+			pkg.Stats = p.parseStatSeq(ctx)
+			return stats
+		}
+		if p.tok.isSep() || p.atStatSeqEnd(ctx) {
+			continue
+		}
+		if stat != nil {
+			p.errorExpected("end of statement")
+		}
+		stats = append(stats, p.syncStat(ctx))
+	}
+}
+
+// syncStat skips to the end of the current statement and returns a
+// BadStat for the skipped tokens. Nested brackets are skipped as a whole.
+func (p *parser) syncStat(ctx statContext) *ast.BadStat {
+	bad := &ast.BadStat{From: p.tok.pos, To: p.tok.pos}
+	depth := 0
+	for !p.tok.is(EOF) {
+		if depth == 0 && (p.tok.isSep() || p.atStatSeqEnd(ctx)) {
+			break
+		}
+		switch p.tok.tok.Typ {
+		case L_PAREN, L_BRACKET, L_CURLY:
+			depth++
+		case R_PAREN, R_BRACKET, R_CURLY:
+			if depth > 0 {
+				depth--
+			}
+		}
+		bad.To = p.tok.end
+		p.next()
+	}
+	return bad
+}
+
+// parseStat returns nil, after recording an error, if no statement can
+// start at the current token.
+func (p *parser) parseStat(ctx statContext) ast.Stat {
+	switch {
+	case p.tok.is(IMPORT):
+		return p.parseImport()
+	case p.tok.is(PACKAGE):
+		return p.parsePackaging()
+	case p.isDefStart():
+		return p.parseDefinition()
+	case p.tok.canStartExpr() || p.tok.is(IMPLICIT):
+		loc := inBlock
+		if ctx == templateStats {
+			loc = inTemplate
+		}
+		return p.parseExpr(loc)
+	}
+	p.errorf(p.tok.pos, "illegal start of statement: %s", p.tok.describe())
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Packages and imports
+
+func (p *parser) parsePackaging() ast.Stat {
+	pos := p.expect(PACKAGE)
+	if p.tok.is(OBJECT) {
+		obj := &ast.ObjectDef{Package: pos, Object: p.tok.pos}
+		p.next()
+		obj.Name = p.parseIdent()
+		obj.Template = p.parseClassTemplateOpt()
+		return obj
+	}
+	pkg := &ast.PackageClause{Package: pos, Name: p.parseQualId()}
+	p.newLineOptWhenFollowedBy(L_CURLY)
+	if p.tok.is(L_CURLY) {
+		pkg.Lbrace = p.tok.pos
+		p.next()
+		pkg.Stats = p.parseStatSeq(templateStats)
+		pkg.Rbrace = p.expect(R_CURLY)
+	}
+	return pkg
+}
+
+func (p *parser) parseQualId() ast.Expr {
+	var x ast.Expr = p.parseIdent()
+	for p.tok.is(DOT) {
+		p.next()
+		x = &ast.Select{X: x, Sel: p.parseIdent()}
+	}
+	return x
+}
+
+func (p *parser) parseImport() *ast.Import {
+	imp := &ast.Import{Import: p.expect(IMPORT)}
+	for {
+		imp.Importers = append(imp.Importers, p.parseImporter())
+		if !p.tok.isOp(",") {
+			return imp
+		}
+		p.next()
+	}
+}
+
+// parseImporter parses an import expression. Its path is a stable
+// identifier, which may go through C.this and C.super.
+func (p *parser) parseImporter() *ast.Importer {
+	var path ast.Expr
+	switch {
+	case p.tok.is(THIS):
+		path = &ast.This{ThisPos: p.tok.pos}
+		p.next()
+	case p.tok.is(SUPER):
+		path = p.parseSuper(nil)
+	default:
+		path = p.parseIdent()
+	}
+	for p.tok.is(DOT) {
+		p.next()
+		qual, isIdent := path.(*ast.Ident)
+		switch {
+		case p.tok.isIdent("_") || p.tok.isIdent("*") || p.isGivenSelector():
+			return &ast.Importer{Path: path, Selectors: []*ast.ImportSelector{p.parseImportSelector()}}
+		case p.tok.is(L_CURLY):
+			return p.parseImportSelectors(path)
+		case isIdent && p.tok.is(THIS):
+			path = &ast.This{Qual: qual, ThisPos: p.tok.pos}
+			p.next()
+			continue
+		case isIdent && p.tok.is(SUPER):
+			path = p.parseSuper(qual)
+			continue
+		}
+		path = &ast.Select{X: path, Sel: p.parseIdent()}
+	}
+	prefix, name := splitImportPath(path)
+	switch {
+	case name == nil:
+		// C.this or C.super without a selector
+		p.errorExpected("'.'")
+		return &ast.Importer{Path: path, Selectors: []*ast.ImportSelector{{Name: &ast.Ident{NamePos: path.End()}}}}
+	case prefix == nil:
+		p.errorf(name.Pos(), "import of a single name %s", name.Name)
+		prefix = name
+	}
+	sel := &ast.ImportSelector{Name: name}
+	if p.tok.isIdent("as") {
+		// Scala 3 import a.b as c
+		p.parseImportRename(sel)
+	}
+	return &ast.Importer{Path: prefix, Selectors: []*ast.ImportSelector{sel}}
+}
+
+// isGivenSelector reports whether the current token is the soft keyword
+// given of a Scala 3 given selector, rather than a name.
+func (p *parser) isGivenSelector() bool {
+	next := p.peek(1)
+	return p.tok.isIdent("given") && !next.is(DOT) && !next.isArrow() && !next.isIdent("as")
+}
+
+// parseImportSelectors parses the selectors {a, b => c, _} of an
+// importer of path. Wildcards must come last.
+func (p *parser) parseImportSelectors(path ast.Expr) *ast.Importer {
+	imp := &ast.Importer{Path: path, Lbrace: p.expect(L_CURLY)}
+	var wildcard *ast.ImportSelector
+	for !p.tok.is(R_CURLY) && !p.tok.is(EOF) {
+		sel := p.parseImportSelector()
+		switch {
+		case sel.IsWildcard() || sel.Given.IsValid():
+			wildcard = sel
+		case wildcard != nil:
+			p.error(wildcard.Pos(), "wildcard import must be in last position")
+		}
+		imp.Selectors = append(imp.Selectors, sel)
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	imp.Rbrace = p.expect(R_CURLY)
+	return imp
+}
+
+func (p *parser) parseImportSelector() *ast.ImportSelector {
+	if p.isGivenSelector() {
+		sel := &ast.ImportSelector{Given: p.tok.pos}
+		p.next()
+		if p.tok.canStartType() {
+			sel.Type = p.parseInfixType()
+		}
+		return sel
+	}
+	sel := &ast.ImportSelector{Name: p.parseIdent()}
+	if p.tok.isArrow() || p.tok.isIdent("as") {
+		p.parseImportRename(sel)
+	}
+	return sel
+}
+
+// parseImportRename parses the "=> name" or "as name" renaming sel.
+func (p *parser) parseImportRename(sel *ast.ImportSelector) {
+	sel.Arrow = p.tok.pos
+	p.next()
+	sel.Rename = p.parseIdent()
+}
+
+// ----------------------------------------------------------------------------
+// Definitions
+
+func (p *parser) parseIdent() *ast.Ident {
+	if !p.tok.is(IDENTIFIER) {
+		p.errorExpected("identifier")
+		return &ast.Ident{NamePos: p.tok.pos, Name: "_"}
+	}
+	id := newIdent(p.tok.pos, p.tok.raw)
+	p.next()
+	return id
+}
+
+// parseModifiers parses the annotations and modifiers in front of a
+// definition. A newline may follow each of them.
+func (p *parser) parseModifiers() (annots []*ast.Annotation, mods []*ast.Modifier) {
+	for {
+		switch {
+		case p.tok.isOp("@"):
+			annots = append(annots, p.parseAnnotation())
+		case p.tok.isModifier():
+			mods = append(mods, p.parseModifier())
+		default:
+			return annots, mods
+		}
+		p.newLineOpt()
+	}
+}
+
+func (p *parser) parseAnnotations() []*ast.Annotation {
+	var annots []*ast.Annotation
+	for p.tok.isOp("@") {
+		annots = append(annots, p.parseAnnotation())
+	}
+	return annots
+}
+
+func (p *parser) parseAnnotation() *ast.Annotation {
+	annot := &ast.Annotation{At: p.expectOp("@"), Init: &ast.Init{Type: p.parseSimpleType()}}
+	for p.tok.is(L_PAREN) {
+		annot.Init.Args = append(annot.Init.Args, p.parseArgs())
+	}
+	return annot
+}
+
+// parseCtorAnnotation parses an annotation of a primary constructor,
+// class C @Inject() (x: Int). It takes at most one argument list, so
+// that the parameters are not mistaken for a second one.
+func (p *parser) parseCtorAnnotation() *ast.Annotation {
+	annot := &ast.Annotation{At: p.expectOp("@"), Init: &ast.Init{Type: p.parseSimpleType()}}
+	if p.tok.is(L_PAREN) {
+		annot.Init.Args = []*ast.ArgList{p.parseArgs()}
+	}
+	return annot
+}
+
+func (p *parser) parseModifier() *ast.Modifier {
+	mod := &ast.Modifier{ModPos: p.tok.pos, Name: p.tok.raw}
+	access := p.tok.is(PRIVATE) || p.tok.is(PROTECTED)
+	p.next()
+	if access && p.tok.is(L_BRACKET) {
+		p.next()
+		if p.tok.is(THIS) {
+			mod.Qual = &ast.This{ThisPos: p.tok.pos}
+			p.next()
+		} else {
+			mod.Qual = p.parseIdent()
+		}
+		mod.Rbrack = p.expect(R_BRACKET)
+	}
+	return mod
+}
+
+func (p *parser) parseDefinition() ast.Stat {
+	pos := p.tok.pos
+	annots, mods := p.parseModifiers()
+	var def ast.Stat
+	switch p.tok.tok.Typ {
+	case VAL, VAR:
+		def = p.parseValDef()
+	case DEF:
+		def = p.parseDefDef()
+	case TYPE:
+		def = p.parseTypeDef()
+	case CLASS, TRAIT, OBJECT, CASE:
+		def = p.parseTmplDef()
+	default:
+		p.errorExpected("definition")
+		return &ast.BadStat{From: pos, To: p.tok.pos}
+	}
+	setModifiers(def, annots, mods)
+	return def
+}
+
+func (p *parser) parseValDef() *ast.ValDef {
+	def := &ast.ValDef{ValPos: p.tok.pos, Keyword: p.tok.raw}
+	p.next()
+	for {
+		def.Pats = append(def.Pats, p.parsePattern2())
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	if p.tok.isOp(":") {
+		p.next()
+		def.Type = p.parseType()
+	}
+	if p.tok.isOp("=") {
+		p.next()
+		def.Rhs = p.parseExpr(inLocal)
+	}
+	return def
+}
+
+func (p *parser) parseDefDef() *ast.DefDef {
+	def := &ast.DefDef{Def: p.expect(DEF)}
+	if p.tok.is(THIS) {
+		def.Name = &ast.Ident{NamePos: p.tok.pos, Name: "this"}
+		p.next()
+	} else {
+		def.Name = p.parseIdent()
+	}
+	def.EndPos = def.Name.End()
+	var rbrack ast.Pos
+	if def.TypeParams, rbrack = p.parseTypeParamsOpt(); rbrack.IsValid() {
+		def.EndPos = rbrack + 1
+	}
+	if def.Params = p.parseParamClauses(false); len(def.Params) > 0 {
+		def.EndPos = def.Params[len(def.Params)-1].End()
+	}
+	if p.tok.isOp(":") {
+		p.next()
+		def.ResultType = p.parseType()
+		def.EndPos = def.ResultType.End()
+	}
+	switch {
+	case p.tok.isOp("="):
+		p.next()
+		def.Rhs = p.parseExpr(inLocal)
+	case def.ResultType == nil && (p.tok.is(L_CURLY) || p.tok.is(NEWLINE) && p.peek(1).is(L_CURLY)):
+		// procedure syntax
+		p.newLineOpt()
+		def.Rhs = p.parseBlockExpr()
+		def.Procedure = true
+	}
+	if def.Rhs != nil {
+		def.EndPos = def.Rhs.End()
+	}
+	return def
+}
+
+func (p *parser) parseTypeDef() *ast.TypeDef {
+	def := &ast.TypeDef{TypePos: p.expect(TYPE), Name: p.parseIdent()}
+	def.EndPos = def.Name.End()
+	var rbrack ast.Pos
+	if def.TypeParams, rbrack = p.parseTypeParamsOpt(); rbrack.IsValid() {
+		def.EndPos = rbrack + 1
+	}
+	if p.tok.isOp("=") {
+		p.next()
+		def.Rhs = p.parseType()
+		def.EndPos = def.Rhs.End()
+		return def
+	}
+	def.Lo, def.Hi = p.parseTypeBounds()
+	for _, t := range []ast.Type{def.Lo, def.Hi} {
+		if t != nil {
+			def.EndPos = t.End()
+		}
+	}
+	return def
+}
+
+func (p *parser) parseTypeBounds() (lo, hi ast.Type) {
+	if p.tok.isOp(">:") {
+		p.next()
+		lo = p.parseType()
+	}
+	if p.tok.isOp("<:") {
+		p.next()
+		hi = p.parseType()
+	}
+	return lo, hi
+}
+
+// parseTypeParamsOpt parses [A, B] and returns the position of "]", or
+// NoPos if there are no type parameters.
+func (p *parser) parseTypeParamsOpt() ([]*ast.TypeParam, ast.Pos) {
+	if !p.tok.is(L_BRACKET) {
+		return nil, ast.NoPos
+	}
+	p.next()
+	var tparams []*ast.TypeParam
+	for !p.tok.is(R_BRACKET) && !p.tok.is(EOF) {
+		tparams = append(tparams, p.parseTypeParam())
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return tparams, p.expect(R_BRACKET)
+}
+
+func (p *parser) parseTypeParam() *ast.TypeParam {
+	tp := &ast.TypeParam{Annotations: p.parseAnnotations()}
+	if p.tok.isIdent("+") || p.tok.isIdent("-") {
+		tp.Variance, tp.VariancePos = p.tok.raw, p.tok.pos
+		p.next()
+	}
+	tp.Name = p.parseIdent()
+	tp.EndPos = tp.Name.End()
+	var rbrack ast.Pos
+	if tp.TypeParams, rbrack = p.parseTypeParamsOpt(); rbrack.IsValid() {
+		tp.EndPos = rbrack + 1
+	}
+	tp.Lo, tp.Hi = p.parseTypeBounds()
+	bounds := []ast.Type{tp.Lo, tp.Hi}
+	for {
+		switch {
+		case p.tok.isOp("<%"):
+			p.next()
+			tp.ViewBounds = append(tp.ViewBounds, p.parseType())
+			bounds = append(bounds, tp.ViewBounds[len(tp.ViewBounds)-1])
+			continue
+		case p.tok.isOp(":"):
+			p.next()
+			tp.ContextBounds = append(tp.ContextBounds, p.parseType())
+			bounds = append(bounds, tp.ContextBounds[len(tp.ContextBounds)-1])
+			continue
+		}
+		break
+	}
+	for _, t := range bounds {
+		if t != nil && t.End() > tp.EndPos {
+			tp.EndPos = t.End()
+		}
+	}
+	return tp
+}
+
+// parseParamClauses parses the parameter clauses of a method or, if
+// class is set, of a class constructor. Only an implicit clause may
+// start on a new line.
+func (p *parser) parseParamClauses(class bool) []*ast.ParamClause {
+	var clauses []*ast.ParamClause
+	for {
+		if p.tok.is(NEWLINE) && p.peek(1).is(L_PAREN) && p.peek(2).is(IMPLICIT) {
+			p.next()
+		}
+		if !p.tok.is(L_PAREN) {
+			return clauses
+		}
+		clause := &ast.ParamClause{Lparen: p.tok.pos}
+		p.next()
+		if p.tok.is(IMPLICIT) {
+			clause.Implicit = p.tok.pos
+			p.next()
+		}
+		for !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+			clause.Params = append(clause.Params, p.parseParam(class))
+			if !p.tok.isOp(",") {
+				break
+			}
+			p.next()
+		}
+		clause.Rparen = p.expect(R_PAREN)
+		clauses = append(clauses, clause)
+		if clause.Implicit.IsValid() {
+			return clauses
+		}
+	}
+}
+
+func (p *parser) parseParam(class bool) *ast.Param {
+	param := &ast.Param{}
+	if class {
+		param.Annotations, param.Modifiers = p.parseModifiers()
+		if p.tok.is(VAL) || p.tok.is(VAR) {
+			param.ValPos, param.Keyword = p.tok.pos, p.tok.raw
+			p.next()
+		}
+	} else {
+		param.Annotations = p.parseAnnotations()
+	}
+	param.Name = p.parseIdent()
+	p.expectOp(":")
+	param.Type = p.parseParamType()
+	if p.tok.isOp("=") {
+		p.next()
+		param.Default = p.parseExpr(inLocal)
+	}
+	return param
+}
+
+func (p *parser) parseParamType() ast.Type {
+	if p.tok.isArrow() {
+		arrow := p.tok.pos
+		p.next()
+		return &ast.ByNameType{Arrow: arrow, Type: p.parseType()}
+	}
+	t := p.parseType()
+	if p.tok.isIdent("*") {
+		t = &ast.RepeatedType{Type: t, Star: p.tok.pos}
+		p.next()
+	}
+	return t
+}
+
+// ----------------------------------------------------------------------------
+// Templates
+
+func (p *parser) parseTmplDef() ast.Stat {
+	var mods []*ast.Modifier
+	if p.tok.is(CASE) {
+		mods = []*ast.Modifier{{ModPos: p.tok.pos, Name: "case"}}
+		p.next()
+	}
+	switch {
+	case p.tok.is(CLASS):
+		class := &ast.ClassDef{Modifiers: mods, Class: p.tok.pos}
+		p.next()
+		class.Name = p.parseIdent()
+		class.TypeParams, _ = p.parseTypeParamsOpt()
+		for p.tok.isOp("@") {
+			class.CtorAnnotations = append(class.CtorAnnotations, p.parseCtorAnnotation())
+		}
+		if p.tok.is(PRIVATE) || p.tok.is(PROTECTED) {
+			class.CtorModifiers = []*ast.Modifier{p.parseModifier()}
+		}
+		class.Params = p.parseParamClauses(true)
+		class.Template = p.parseClassTemplateOpt()
+		return class
+	case p.tok.is(TRAIT):
+		trait := &ast.TraitDef{Trait: p.tok.pos}
+		p.next()
+		trait.Name = p.parseIdent()
+		trait.TypeParams, _ = p.parseTypeParamsOpt()
+		trait.Template = p.parseClassTemplateOpt()
+		return trait
+	case p.tok.is(OBJECT):
+		obj := &ast.ObjectDef{Modifiers: mods, Object: p.tok.pos}
+		p.next()
+		obj.Name = p.parseIdent()
+		obj.Template = p.parseClassTemplateOpt()
+		return obj
+	}
+	pos := p.tok.pos
+	p.errorExpected("class or object")
+	return &ast.BadStat{From: pos, To: pos}
+}
+
+// parseClassTemplateOpt parses what follows a class, trait or object
+// header: an optional extends clause and body.
+func (p *parser) parseClassTemplateOpt() *ast.Template {
+	if p.tok.is(EXTENDS) {
+		extends := p.tok.pos
+		p.next()
+		tmpl := p.parseTemplate()
+		tmpl.Extends = extends
+		return tmpl
+	}
+	tmpl := &ast.Template{}
+	p.newLineOptWhenFollowedBy(L_CURLY)
+	if p.tok.is(L_CURLY) {
+		p.parseTemplateBody(tmpl)
+	}
+	return tmpl
+}
+
+// parseTemplate parses the parents and body after extends or new,
+// including early definitions { ... } with.
+func (p *parser) parseTemplate() *ast.Template {
+	tmpl := &ast.Template{}
+	if p.tok.is(L_CURLY) {
+		p.parseTemplateBody(tmpl)
+		if !p.tok.is(WITH) {
+			return tmpl
+		}
+		p.next()
+		early := tmpl.Stats
+		tmpl = &ast.Template{EarlyDefs: early}
+	}
+	tmpl.Parents = p.parseParents()
+	p.newLineOptWhenFollowedBy(L_CURLY)
+	if p.tok.is(L_CURLY) {
+		p.parseTemplateBody(tmpl)
+	}
+	return tmpl
+}
+
+func (p *parser) parseParents() []*ast.Init {
+	first := &ast.Init{Type: p.parseAnnotType()}
+	for p.tok.is(L_PAREN) {
+		first.Args = append(first.Args, p.parseArgs())
+	}
+	parents := []*ast.Init{first}
+	for p.tok.is(WITH) {
+		p.next()
+		parents = append(parents, &ast.Init{Type: p.parseAnnotType()})
+	}
+	return parents
+}
+
+// parseTemplateBody parses { [self =>] stats } into tmpl.
+func (p *parser) parseTemplateBody(tmpl *ast.Template) {
+	tmpl.Lbrace = p.expect(L_CURLY)
+	p.skipSeps()
+	if p.tok.is(IDENTIFIER) || p.tok.is(THIS) {
+		index, errors := p.index, len(p.errors)
+		x := p.parseExpr(inTemplate)
+		if p.tok.isArrow() {
+			tmpl.Self = p.selfType(x)
+		} else {
+			// an ordinary statement, parsed again below
+			p.reset(index, errors)
+		}
+	}
+	tmpl.Stats = p.parseStatSeq(templateStats)
+	tmpl.Rbrace = p.expect(R_CURLY)
+}
+
+// selfType converts x, parsed as an expression in front of "=>", into a
+// self type.
+func (p *parser) selfType(x ast.Expr) *ast.SelfType {
+	self := &ast.SelfType{Arrow: p.tok.pos}
+	p.next()
+	if typed, ok := x.(*ast.Typed); ok {
+		x, self.Type = typed.X, typed.Type
+	}
+	switch x := x.(type) {
+	case *ast.Ident:
+		self.Name = x
+	case *ast.This:
+		if x.Qual == nil {
+			self.Name = &ast.Ident{NamePos: x.ThisPos, Name: "this"}
+		}
+	}
+	if self.Name == nil {
+		p.error(x.Pos(), "illegal self type")
+		self.Name = &ast.Ident{NamePos: x.Pos(), Name: "_"}
+	}
+	return self
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+// An exprLoc tells where an expression appears, which decides how far an
+// anonymous function extends.
+type exprLoc int
+
+const (
+	inLocal    exprLoc = iota // any other place
+	inBlock                   // a statement of a block: x => ... extends to the end of the block
+	inTemplate                // a template statement: x => ... is a self type
+)
+
+func (p *parser) parseExpr(loc exprLoc) ast.Expr {
+	switch p.tok.tok.Typ {
+	case IF:
+		return p.parseIf()
+	case WHILE:
+		return p.parseWhile()
+	case DO:
+		return p.parseDoWhile()
+	case TRY:
+		return p.parseTry()
+	case FOR:
+		return p.parseFor()
+	case THROW:
+		throw := &ast.Throw{Throw: p.tok.pos}
+		p.next()
+		throw.X = p.parseExpr(inLocal)
+		return throw
+	case RETURN:
+		ret := &ast.Return{Return: p.tok.pos}
+		p.next()
+		if p.tok.canStartExpr() {
+			ret.X = p.parseExpr(inLocal)
+		}
+		return ret
+	case IMPLICIT:
+		f := &ast.Function{Implicit: p.tok.pos}
+		p.next()
+		f.Params = []*ast.Param{{Name: p.parseIdent()}}
+		f.Arrow = p.expectOp("=>")
+		f.Body = p.parseLambdaBody(loc)
+		return f
+	}
+	x := p.parsePostfixExpr()
+	switch {
+	case p.tok.isOp("="):
+		assign := &ast.Assign{X: x, TokPos: p.tok.pos}
+		p.next()
+		assign.Rhs = p.parseExpr(inLocal)
+		return assign
+	case p.tok.isOp(":"):
+		x = p.parseAscription(x)
+		if _, ok := x.(*ast.Typed); !ok || loc != inBlock || !p.tok.isArrow() {
+			return x
+		}
+	case p.tok.is(MATCH):
+		for p.tok.is(MATCH) {
+			x = p.parseMatch(x)
+		}
+		return x
+	}
+	if p.tok.isArrow() && loc != inTemplate {
+		return p.parseLambda(x, loc)
+	}
+	return x
+}
+
+// parseLambda parses the body of the anonymous function whose parameters
+// were parsed as the expression lhs.
+func (p *parser) parseLambda(lhs ast.Expr, loc exprLoc) ast.Expr {
+	params, lparen, rparen, ok := exprToParams(lhs)
+	if parens, isParen := p.parens[lhs]; isParen && ok {
+		lparen, rparen = parens[0], parens[1]
+	}
+	if !ok {
+		p.error(lhs.Pos(), "illegal start of anonymous function parameters")
+	}
+	f := &ast.Function{Lparen: lparen, Params: params, Rparen: rparen, Arrow: p.tok.pos}
+	p.next()
+	f.Body = p.parseLambdaBody(loc)
+	return f
+}
+
+// parseLambdaBody parses the body of an anonymous function, which in a
+// block extends to the end of the block (SLS 6.23).
+func (p *parser) parseLambdaBody(loc exprLoc) ast.Expr {
+	if loc != inBlock {
+		return p.parseExpr(inLocal)
+	}
+	stats := p.parseStatSeq(blockStats)
+	if len(stats) == 1 {
+		if x, ok := stats[0].(ast.Expr); ok {
+			return x
+		}
+	}
+	return &ast.Block{Stats: stats}
+}
+
+func (p *parser) parseAscription(x ast.Expr) ast.Expr {
+	colon := p.expectOp(":")
+	switch {
+	case p.tok.isIdent("_") && p.peek(1).isIdent("*") && p.adjacent():
+		star := p.peek(1).pos
+		wildcard := p.parseIdent()
+		p.next()
+		return &ast.Typed{X: x, Colon: colon, Type: &ast.RepeatedType{Type: wildcard, Star: star}}
+	case p.tok.isOp("@"):
+		return &ast.Annotated{X: x, Colon: colon, Annotations: p.parseAnnotations()}
+	}
+	return &ast.Typed{X: x, Colon: colon, Type: p.parseInfixType()}
+}
+
+func (p *parser) parseMatch(x ast.Expr) *ast.Match {
+	m := &ast.Match{X: x, Match: p.expect(MATCH), Lbrace: p.expect(L_CURLY)}
+	m.Cases = p.parseCaseClauses()
+	m.Rbrace = p.expect(R_CURLY)
+	return m
+}
+
+func (p *parser) parseIf() *ast.If {
+	x := &ast.If{If: p.expect(IF)}
+	p.expect(L_PAREN)
+	x.Cond = p.parseExpr(inLocal)
+	p.expect(R_PAREN)
+	p.newLinesOpt()
+	x.Then = p.parseExpr(inLocal)
+	if p.tok.is(SEMICOLON) && p.peek(1).is(ELSE) {
+		p.next()
+	}
+	if p.tok.is(ELSE) {
+		p.next()
+		x.Else = p.parseExpr(inLocal)
+	}
+	return x
+}
+
+func (p *parser) parseWhile() *ast.While {
+	x := &ast.While{While: p.expect(WHILE)}
+	p.expect(L_PAREN)
+	x.Cond = p.parseExpr(inLocal)
+	p.expect(R_PAREN)
+	p.newLinesOpt()
+	x.Body = p.parseExpr(inLocal)
+	return x
+}
+
+func (p *parser) parseDoWhile() *ast.DoWhile {
+	x := &ast.DoWhile{Do: p.expect(DO)}
+	x.Body = p.parseExpr(inLocal)
+	if p.tok.isSep() && p.peek(1).is(WHILE) {
+		p.next()
+	}
+	p.expect(WHILE)
+	p.expect(L_PAREN)
+	x.Cond = p.parseExpr(inLocal)
+	x.EndPos = p.expect(R_PAREN) + 1
+	return x
+}
+
+func (p *parser) parseTry() *ast.Try {
+	x := &ast.Try{Try: p.expect(TRY)}
+	x.Body = p.parseExpr(inLocal)
+	if p.tok.is(SEMICOLON) && (p.peek(1).is(CATCH) || p.peek(1).is(FINALLY)) {
+		p.next()
+	}
+	if p.tok.is(CATCH) {
+		x.CatchPos = p.tok.pos
+		p.next()
+		handler := p.parseExpr(inLocal)
+		if pf, ok := handler.(*ast.PartialFunction); ok {
+			x.Cases, x.EndPos = pf.Cases, pf.End()
+		} else {
+			x.Catch = handler
+		}
+	}
+	if p.tok.is(SEMICOLON) && p.peek(1).is(FINALLY) {
+		p.next()
+	}
+	if p.tok.is(FINALLY) {
+		x.FinallyPos = p.tok.pos
+		p.next()
+		x.Finally = p.parseExpr(inLocal)
+	}
+	return x
+}
+
+func (p *parser) parseFor() *ast.For {
+	x := &ast.For{For: p.expect(FOR)}
+	switch {
+	case p.tok.is(L_PAREN):
+		p.next()
+		x.Enums = p.parseEnumerators()
+		p.expect(R_PAREN)
+	case p.tok.is(L_CURLY):
+		p.next()
+		x.Enums = p.parseEnumerators()
+		p.expect(R_CURLY)
+	default:
+		p.errorExpected("'(' or '{'")
+	}
+	if len(x.Enums) > 0 {
+		if _, ok := x.Enums[0].(*ast.Generator); !ok {
+			p.error(x.Enums[0].Pos(), "for comprehension must start with a generator")
+		}
+	}
+	p.newLinesOpt()
+	if p.tok.is(YIELD) {
+		x.Yield = p.tok.pos
+		p.next()
+	}
+	x.Body = p.parseExpr(inLocal)
+	return x
+}
+
+func (p *parser) parseEnumerators() []ast.Enumerator {
+	var enums []ast.Enumerator
+	for {
+		p.skipSeps()
+		if p.tok.is(IF) {
+			guard := &ast.Guard{If: p.tok.pos}
+			p.next()
+			guard.Cond = p.parsePostfixExpr()
+			enums = append(enums, guard)
+		} else {
+			pat := p.parsePattern1()
+			switch {
+			case p.tok.isOp("="):
+				enum := &ast.ValueEnum{Pat: pat, TokPos: p.tok.pos}
+				p.next()
+				enum.Rhs = p.parseExpr(inLocal)
+				enums = append(enums, enum)
+			default:
+				enum := &ast.Generator{Pat: pat, Arrow: p.expectOp("<-")}
+				enum.Rhs = p.parseExpr(inLocal)
+				enums = append(enums, enum)
+			}
+		}
+		if !p.tok.isSep() && !p.tok.is(IF) {
+			return enums
+		}
+		p.skipSeps()
+		if p.tok.is(R_PAREN) || p.tok.is(R_CURLY) || p.tok.is(EOF) {
+			return enums
+		}
+	}
+}
+
+func (p *parser) parseCaseClauses() []*ast.CaseClause {
+	var cases []*ast.CaseClause
+	for p.tok.is(CASE) && !p.isCaseDef() {
+		c := &ast.CaseClause{Case: p.tok.pos}
+		p.next()
+		c.Pat = p.parsePattern()
+		if p.tok.is(IF) {
+			p.next()
+			c.Guard = p.parsePostfixExpr()
+		}
+		c.Arrow = p.expectOp("=>")
+		c.Body = p.parseStatSeq(caseStats)
+		cases = append(cases, c)
+	}
+	if cases == nil {
+		p.errorExpected("'case'")
+	}
+	return cases
+}
+
+// parsePostfixExpr parses a chain of prefix expressions separated by
+// infix operators, optionally ended by a postfix operator.
+func (p *parser) parsePostfixExpr() ast.Expr {
+	chain := newChain(p.parsePrefixExpr())
+	chain.mixed = func(op *ast.Ident) {
+		p.error(op.Pos(), "left- and right-associative operators with same precedence may not be mixed")
+	}
+	for p.tok.is(IDENTIFIER) {
+		op := p.parseIdent()
+		var targs []ast.Type
+		if p.tok.is(L_BRACKET) {
+			p.next()
+			targs = p.parseTypes()
+			p.expect(R_BRACKET)
+		}
+		if p.tok.is(NEWLINE) && p.peek(1).canStartSimpleExpr() {
+			p.next()
+		}
+		if targs == nil && !p.tok.canStartSimpleExpr() {
+			return &ast.PostfixApply{X: chain.expr(), Op: op}
+		}
+		chain.add(op, p.parsePrefixExpr())
+		if targs != nil {
+			chain.targs[op] = targs
+		}
+	}
+	return chain.expr()
+}
+
+func (p *parser) parsePrefixExpr() ast.Expr {
+	if !p.tok.is(IDENTIFIER) || !p.peek(1).canStartSimpleExpr() {
+		return p.parseSimpleExpr()
+	}
+	switch p.tok.tok.Val {
+	case "-":
+		if p.peek(1).is(NUMBER) && p.adjacent() {
+			minus := p.tok
+			p.next()
+			lit := p.parseLiteral()
+			lit.ValuePos, lit.Value = minus.pos, "-"+lit.Value
+			return p.parseSimpleExprRest(lit, true)
+		}
+		fallthrough
+	case "+", "!", "~":
+		op := p.parseIdent()
+		return &ast.PrefixApply{Op: op, X: p.parseSimpleExpr()}
+	}
+	return p.parseSimpleExpr()
+}
+
+func (p *parser) parseLiteral() *ast.Literal {
+	lit := &ast.Literal{ValuePos: p.tok.pos, Kind: literalKind(p.tok.tok.Typ, p.tok.raw), Value: p.tok.raw}
+	p.next()
+	return lit
+}
+
+func (p *parser) parseSimpleExpr() ast.Expr {
+	var x ast.Expr
+	canApply := true
+	switch {
+	case p.tok.isLiteral():
+		x = p.parseLiteral()
+	case p.isInterpolation():
+		x = p.parseInterpolation()
+	case p.tok.is(IDENTIFIER):
+		x = p.parseIdent()
+	case p.tok.is(THIS):
+		x = &ast.This{ThisPos: p.tok.pos}
+		p.next()
+	case p.tok.is(SUPER):
+		x = p.parseSuper(nil)
+	case p.tok.is(L_PAREN):
+		x = p.parseParenExpr()
+	case p.tok.is(L_CURLY):
+		x = p.parseBlockExpr()
+	case p.tok.is(NEW):
+		x = &ast.New{New: p.tok.pos}
+		p.next()
+		x.(*ast.New).Template = p.parseTemplate()
+		canApply = false
+	default:
+		p.errorExpected("expression")
+		return &ast.BadExpr{From: p.tok.pos, To: p.tok.pos}
+	}
+	return p.parseSimpleExprRest(x, canApply)
+}
+
+// parseSimpleExprRest parses the selections, type applications and
+// argument lists following x.
+func (p *parser) parseSimpleExprRest(x ast.Expr, canApply bool) ast.Expr {
+	for {
+		if canApply {
+			p.newLineOptWhenFollowedBy(L_CURLY)
+		}
+		switch {
+		case p.tok.is(DOT):
+			p.next()
+			switch {
+			case p.tok.is(THIS):
+				x = &ast.This{Qual: p.qualifier(x), ThisPos: p.tok.pos}
+				p.next()
+			case p.tok.is(SUPER):
+				x = p.parseSuper(p.qualifier(x))
+			default:
+				x = &ast.Select{X: x, Sel: p.parseIdent()}
+			}
+		case p.tok.is(L_BRACKET):
+			app := &ast.TypeApply{Fun: x, Lbrack: p.tok.pos}
+			p.next()
+			app.Targs = p.parseTypes()
+			app.Rbrack = p.expect(R_BRACKET)
+			x = app
+		case canApply && (p.tok.is(L_PAREN) || p.tok.is(L_CURLY)):
+			x = &ast.Apply{Fun: x, Args: p.parseArgumentExprs()}
+		case p.tok.isIdent("_"):
+			// method value f _
+			return &ast.PostfixApply{X: x, Op: p.parseIdent()}
+		default:
+			return x
+		}
+		canApply = true
+	}
+}
+
+// qualifier returns the qualifier C of C.this or C.super, which must be
+// a simple name.
+func (p *parser) qualifier(x ast.Expr) *ast.Ident {
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		p.error(x.Pos(), "qualifier of this or super must be a simple name")
+		return &ast.Ident{NamePos: x.Pos(), Name: "_"}
+	}
+	return id
+}
+
+// parseSuper parses super[Mix].sel, qualified by qual if it is not nil.
+func (p *parser) parseSuper(qual *ast.Ident) ast.Expr {
+	super := &ast.Super{Qual: qual, SuperPos: p.expect(SUPER)}
+	if p.tok.is(L_BRACKET) {
+		p.next()
+		super.Mix = p.parseIdent()
+		super.Rbrack = p.expect(R_BRACKET)
+	}
+	p.expect(DOT)
+	return &ast.Select{X: super, Sel: p.parseIdent()}
+}
+
+func (p *parser) parseParenExpr() ast.Expr {
+	lparen := p.expect(L_PAREN)
+	var elts []ast.Expr
+	for !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+		elts = append(elts, p.parseExpr(inLocal))
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	rparen := p.expect(R_PAREN)
+	if len(elts) == 1 {
+		if _, ok := elts[0].(*ast.Assign); !ok {
+			p.parens[elts[0]] = [2]ast.Pos{lparen, rparen}
+			return elts[0]
+		}
+	}
+	return &ast.Tuple{Lparen: lparen, Elts: elts, Rparen: rparen}
+}
+
+// parseArgumentExprs parses (args) or a block argument.
+func (p *parser) parseArgumentExprs() *ast.ArgList {
+	if p.tok.is(L_CURLY) {
+		block := p.parseBlockExpr()
+		return &ast.ArgList{Lparen: block.Pos(), Args: []ast.Expr{block}, Rparen: block.End() - 1}
+	}
+	return p.parseArgs()
+}
+
+func (p *parser) parseArgs() *ast.ArgList {
+	args := &ast.ArgList{Lparen: p.expect(L_PAREN)}
+	for !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+		args.Args = append(args.Args, p.parseExpr(inLocal))
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	args.Rparen = p.expect(R_PAREN)
+	return args
+}
+
+// parseBlockExpr parses a block or a partial function { case ... }.
+func (p *parser) parseBlockExpr() ast.Expr {
+	lbrace := p.expect(L_CURLY)
+	if p.tok.is(CASE) && !p.isCaseDef() {
+		cases := p.parseCaseClauses()
+		return &ast.PartialFunction{Lbrace: lbrace, Cases: cases, Rbrace: p.expect(R_CURLY)}
+	}
+	stats := p.parseStatSeq(blockStats)
+	return &ast.Block{Lbrace: lbrace, Stats: stats, Rbrace: p.expect(R_CURLY)}
+}
+
+func (p *parser) parseInterpolation() *ast.Interpolation {
+	id := p.parseIdent()
+	str := p.tok
+	p.next()
+	body, offset := stringBody(str.raw, str.end)
+	parts, args := splitInterpolation(body, offset)
+	x := &ast.Interpolation{Id: id, Parts: parts, EndPos: str.end}
+	for _, arg := range args {
+		if arg.name != "" {
+			x.Args = append(x.Args, newIdent(ast.PosOf(arg.offset), arg.name))
+			continue
+		}
+		x.Args = append(x.Args, p.parseEmbeddedBlock(arg.offset, len(arg.block)))
+	}
+	return x
+}
+
+// parseEmbeddedBlock parses the block of length n at offset, such as the
+// contents of ${...} in an interpolated string.
+func (p *parser) parseEmbeddedBlock(offset, n int) ast.Expr {
+	sub := newParser(p.name, p.src[:offset+n], offset)
+	stats := sub.parseStatSeq(blockStats)
+	if !sub.tok.is(EOF) {
+		sub.errorExpected("end of block")
+	}
+	p.errors = append(p.errors, sub.errors...)
+	if len(stats) == 1 {
+		if x, ok := stats[0].(ast.Expr); ok {
+			return x
+		}
+	}
+	return &ast.Block{Lbrace: ast.PosOf(offset - 1), Stats: stats, Rbrace: ast.PosOf(offset + n)}
+}
+
+// ----------------------------------------------------------------------------
+// Patterns
+
+func (p *parser) parsePattern() ast.Pattern {
+	defer func(inAlternative bool) { p.inAlternative = inAlternative }(p.inAlternative)
+	p.inAlternative = true
+	pat := p.parsePattern1()
+	if !p.tok.isIdent("|") {
+		return pat
+	}
+	alt := &ast.Alternative{Alts: []ast.Pattern{pat}}
+	for p.tok.isIdent("|") {
+		p.next()
+		alt.Alts = append(alt.Alts, p.parsePattern1())
+	}
+	return alt
+}
+
+func (p *parser) parsePatterns() []ast.Pattern {
+	var pats []ast.Pattern
+	for !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+		pats = append(pats, p.parsePattern())
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return pats
+}
+
+// parsePattern1 parses a typed pattern x: T or a Pattern2.
+func (p *parser) parsePattern1() ast.Pattern {
+	if p.tok.is(IDENTIFIER) && p.peek(1).isOp(":") {
+		x := p.parseVarIdent()
+		colon := p.tok.pos
+		p.next()
+		return &ast.TypedPattern{X: x, Colon: colon, Type: p.parseInfixType()}
+	}
+	return p.parsePattern2()
+}
+
+// parsePattern2 parses a binder x @ p or a Pattern3.
+func (p *parser) parsePattern2() ast.Pattern {
+	if p.tok.is(IDENTIFIER) && p.peek(1).isOp("@") {
+		bind := &ast.Bind{Name: p.parseVarIdent(), At: p.tok.pos}
+		p.next()
+		bind.Pat = p.parsePattern3()
+		return bind
+	}
+	return p.parsePattern3()
+}
+
+// parseVarIdent parses the variable of a typed pattern or a binder,
+// which must not be a stable identifier.
+func (p *parser) parseVarIdent() *ast.Ident {
+	id := p.parseIdent()
+	if !id.IsVariable() {
+		p.errorf(id.Pos(), "pattern variable %s must start with a lower case letter", id.Name)
+	}
+	return id
+}
+
+// parsePattern3 parses simple patterns separated by infix operators.
+func (p *parser) parsePattern3() ast.Pattern {
+	chain := newChain(p.parseSimplePattern())
+	for p.tok.is(IDENTIFIER) && !p.tok.isIdent("|") {
+		op := p.parseIdent()
+		chain.add(op, p.parseSimplePattern())
+	}
+	return chain.pattern()
+}
+
+func (p *parser) parseSimplePattern() ast.Pattern {
+	switch {
+	case p.tok.isIdent("_") && p.peek(1).isIdent("*") && p.adjacent():
+		pat := &ast.SeqWildcard{Underscore: p.tok.pos}
+		p.next()
+		p.next()
+		return pat
+	case p.tok.isIdent("-") && p.peek(1).is(NUMBER) && p.adjacent():
+		minus := p.tok
+		p.next()
+		lit := p.parseLiteral()
+		lit.ValuePos, lit.Value = minus.pos, "-"+lit.Value
+		return lit
+	case p.tok.isLiteral():
+		return p.parseLiteral()
+	case p.isInterpolation():
+		return p.parseInterpolatedPattern()
+	case p.tok.is(IDENTIFIER) || p.tok.is(THIS):
+		ref := p.parseStableId()
+		var targs []ast.Type
+		if p.tok.is(L_BRACKET) {
+			p.next()
+			targs = p.parseTypes()
+			p.expect(R_BRACKET)
+		}
+		if !p.tok.is(L_PAREN) {
+			if targs != nil {
+				p.errorExpected("'('")
+			}
+			return ref.(ast.Pattern)
+		}
+		pat := &ast.ExtractorPattern{Fun: ref, Targs: targs, Lparen: p.tok.pos}
+		p.next()
+		pat.Args = p.parsePatterns()
+		pat.Rparen = p.expect(R_PAREN)
+		return pat
+	case p.tok.is(L_PAREN):
+		lparen := p.tok.pos
+		p.next()
+		pats := p.parsePatterns()
+		rparen := p.expect(R_PAREN)
+		if len(pats) == 1 {
+			return pats[0]
+		}
+		return &ast.TuplePattern{Lparen: lparen, Elts: pats, Rparen: rparen}
+	}
+	pos := p.tok.pos
+	p.errorExpected("pattern")
+	return &ast.BadPattern{From: pos, To: pos}
+}
+
+// parseStableId parses a path x.y.z or this.x.
+func (p *parser) parseStableId() ast.Expr {
+	var x ast.Expr
+	if p.tok.is(THIS) {
+		x = &ast.This{ThisPos: p.tok.pos}
+		p.next()
+		p.expect(DOT)
+		x = &ast.Select{X: x, Sel: p.parseIdent()}
+	} else {
+		x = p.parseIdent()
+	}
+	for p.tok.is(DOT) && p.peek(1).is(IDENTIFIER) {
+		p.next()
+		x = &ast.Select{X: x, Sel: p.parseIdent()}
+	}
+	return x
+}
+
+func (p *parser) parseInterpolatedPattern() *ast.InterpolatedPattern {
+	id := p.parseIdent()
+	str := p.tok
+	p.next()
+	body, offset := stringBody(str.raw, str.end)
+	parts, args := splitInterpolation(body, offset)
+	pat := &ast.InterpolatedPattern{Id: id, Parts: parts, EndPos: str.end}
+	for _, arg := range args {
+		if arg.name == "" {
+			p.error(ast.PosOf(arg.offset), "only simple names are supported in interpolated patterns")
+			pat.Args = append(pat.Args, &ast.BadPattern{From: ast.PosOf(arg.offset), To: ast.PosOf(arg.offset + len(arg.block))})
+			continue
+		}
+		pat.Args = append(pat.Args, newIdent(ast.PosOf(arg.offset), arg.name))
+	}
+	return pat
+}
+
+// ----------------------------------------------------------------------------
+// Types
+
+func (p *parser) parseType() ast.Type {
+	t := p.parseInfixType()
+	switch {
+	case p.tok.isArrow():
+		params, lparen, rparen := typeToParams(t)
+		f := &ast.FunctionType{Lparen: lparen, Params: params, Rparen: rparen, Arrow: p.tok.pos}
+		p.next()
+		f.Result = p.parseType()
+		return f
+	case p.tok.is(FORSOME):
+		ex := &ast.ExistentialType{Type: t, ForSome: p.tok.pos}
+		p.next()
+		ex.Lbrace = p.expect(L_CURLY)
+		ex.Decls = p.parseStatSeq(templateStats)
+		ex.Rbrace = p.expect(R_CURLY)
+		return ex
+	}
+	return t
+}
+
+func (p *parser) parseTypes() []ast.Type {
+	var types []ast.Type
+	for !p.tok.is(R_BRACKET) && !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+		types = append(types, p.parseType())
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return types
+}
+
+// isTypeOp reports whether the current token is an infix type operator.
+// An identifier that cannot be followed by a type, such as * in T*, is
+// not, nor is | in the type of a typed pattern that may be followed by
+// alternatives, where it separates them, as in case _: A | _: B.
+func (p *parser) isTypeOp(inAlternative bool) bool {
+	return p.tok.is(IDENTIFIER) && !(inAlternative && p.tok.isIdent("|")) && p.peek(1).canStartType()
+}
+
+func (p *parser) parseInfixType() ast.Type {
+	// the types inside this one, as in x: Either[A | B, C], are not
+	// followed by alternatives
+	inAlternative := p.inAlternative
+	p.inAlternative = false
+	defer func() { p.inAlternative = inAlternative }()
+	chain := newChain(p.parseCompoundType())
+	for p.isTypeOp(inAlternative) {
+		op := p.parseIdent()
+		chain.add(op, p.parseCompoundType())
+	}
+	return chain.typ()
+}
+
+func (p *parser) parseCompoundType() ast.Type {
+	var types []ast.Type
+	if !p.tok.is(L_CURLY) {
+		types = append(types, p.parseAnnotType())
+		for p.tok.is(WITH) {
+			p.next()
+			types = append(types, p.parseAnnotType())
+		}
+		p.newLineOptWhenFollowedBy(L_CURLY)
+		if !p.tok.is(L_CURLY) {
+			if len(types) == 1 {
+				return types[0]
+			}
+			return &ast.CompoundType{Types: types}
+		}
+	}
+	t := &ast.CompoundType{Types: types, Lbrace: p.expect(L_CURLY)}
+	t.Decls = p.parseStatSeq(templateStats)
+	t.Rbrace = p.expect(R_CURLY)
+	return t
+}
+
+func (p *parser) parseAnnotType() ast.Type {
+	t := p.parseSimpleType()
+	if p.tok.isOp("@") {
+		return &ast.AnnotatedType{Type: t, Annotations: p.parseAnnotations()}
+	}
+	return t
+}
+
+func (p *parser) parseSimpleType() ast.Type {
+	var t ast.Type
+	switch {
+	case p.tok.is(L_PAREN):
+		t = p.parseParenType()
+	case p.tok.isIdent("_"):
+		w := &ast.WildcardType{Underscore: p.tok.pos}
+		p.next()
+		w.Lo, w.Hi = p.parseTypeBounds()
+		t = w
+	case p.tok.is(IDENTIFIER) || p.tok.is(THIS):
+		t = p.parseTypePath()
+	default:
+		pos := p.tok.pos
+		p.errorExpected("type")
+		return &ast.BadType{From: pos, To: pos}
+	}
+	for {
+		switch {
+		case p.tok.is(L_BRACKET):
+			app := &ast.AppliedType{Type: t, Lbrack: p.tok.pos}
+			p.next()
+			app.Args = p.parseTypes()
+			app.Rbrack = p.expect(R_BRACKET)
+			t = app
+		case p.tok.isOp("#"):
+			p.next()
+			t = &ast.Projection{X: t, Sel: p.parseIdent()}
+		default:
+			return t
+		}
+	}
+}
+
+// parseParenType parses a tuple type, a type in parentheses or the
+// parameter types of a function type. Followed by "=>", the parameters
+// are returned as a *ast.TupleType even if there is only one, so that
+// ((A, B)) => C keeps its single tuple parameter; they may then be by-name
+// types.
+func (p *parser) parseParenType() ast.Type {
+	lparen := p.expect(L_PAREN)
+	var types []ast.Type
+	for !p.tok.is(R_PAREN) && !p.tok.is(EOF) {
+		if p.tok.isArrow() {
+			types = append(types, p.parseParamType())
+		} else {
+			types = append(types, p.parseType())
+		}
+		if !p.tok.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	rparen := p.expect(R_PAREN)
+	if p.tok.isArrow() {
+		return &ast.TupleType{Lparen: lparen, Elts: types, Rparen: rparen}
+	}
+	for _, t := range types {
+		if _, ok := t.(*ast.ByNameType); ok {
+			p.error(t.Pos(), "by-name type outside of function parameters")
+		}
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return &ast.TupleType{Lparen: lparen, Elts: types, Rparen: rparen}
+}
+
+// parseTypePath parses a stable identifier used as a type, or a
+// singleton type p.type.
+func (p *parser) parseTypePath() ast.Type {
+	var x ast.Expr
+	if p.tok.is(THIS) {
+		x = &ast.This{ThisPos: p.tok.pos}
+		p.next()
+		if !p.tok.is(DOT) || !p.peek(1).is(TYPE) {
+			p.expect(DOT)
+			x = &ast.Select{X: x, Sel: p.parseIdent()}
+		}
+	} else {
+		x = p.parseIdent()
+	}
+	for p.tok.is(DOT) {
+		p.next()
+		switch {
+		case p.tok.is(TYPE):
+			t := &ast.SingletonType{Ref: x, TypePos: p.tok.pos}
+			p.next()
+			return t
+		case p.tok.is(THIS):
+			x = &ast.This{Qual: p.qualifier(x), ThisPos: p.tok.pos}
+			p.next()
+		default:
+			x = &ast.Select{X: x, Sel: p.parseIdent()}
+		}
+	}
+	if t, ok := x.(ast.Type); ok {
+		return t
+	}
+	p.error(x.Pos(), "expected type")
+	return &ast.BadType{From: x.Pos(), To: x.End()}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UsingDirective is a scala-cli "//> using key values" directive.
+type UsingDirective struct {
+	Key    string   // dotted key, e.g. "dep" or "test.dep"
+	Values []string // values with quotes removed; empty for boolean keys
+}
+
+// isUsingDirective reports whether the line s is a using directive:
+// "//> using" followed by whitespace and a key. Other lines starting with
+// "//>", such as "//> usingscala" or a bare "//> using", are comments.
+func isUsingDirective(s string) bool {
+	if !strings.HasPrefix(s, usingdirective) {
+		return false
+	}
+	s = s[len(usingdirective):]
+	if end := strings.IndexAny(s, newline); end >= 0 {
+		s = s[:end]
+	}
+	rest := strings.TrimLeftFunc(s, isDirectiveSpace)
+	return len(rest) < len(s) && rest != ""
+}
+
+// isDirectiveSpace reports whether c separates the words of a using
+// directive, which ends at the end of its line.
+func isDirectiveSpace(c rune) bool {
+	return unicode.IsSpace(c) && c != '\n' && c != '\r'
+}
+
+// ParseUsingDirective splits the text of a USING_DIRECTIVE token into its
+// key and values. Values are separated by whitespace or commas and may be
+// double quoted, in which case Scala string escapes apply.
+func ParseUsingDirective(val string) (UsingDirective, error) {
+	var res UsingDirective
+	if !isUsingDirective(val) {
+		return res, fmt.Errorf("not a using directive: %q", val)
+	}
+	rest := strings.TrimLeftFunc(val[len(usingdirective):], isDirectiveSpace)
+	end := strings.IndexFunc(rest, isDirectiveSpace)
+	if end == -1 {
+		end = len(rest)
+	}
+	res.Key = rest[:end]
+	rest = rest[end:]
+	for {
+		rest = strings.TrimLeftFunc(rest, func(c rune) bool { return isDirectiveSpace(c) || c == ',' })
+		if rest == "" {
+			return res, nil
+		}
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return res, fmt.Errorf("unterminated string in using directive: %q", val)
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return res, err
+			}
+			res.Values = append(res.Values, value)
+			rest = rest[len(quoted):]
+			continue
+		}
+		end := strings.IndexFunc(rest, func(c rune) bool { return isDirectiveSpace(c) || c == ',' })
+		if end == -1 {
+			end = len(rest)
+		}
+		res.Values = append(res.Values, rest[:end])
+		rest = rest[end:]
+	}
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText encodes t by name, as String returns it.
+func (t TokenType) MarshalText() ([]byte, error) {
+	if t < NIL || t > lastTokenType {
+		return nil, fmt.Errorf("parser: invalid TokenType %d", int(t))
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *TokenType) UnmarshalText(text []byte) error {
+	for typ := NIL; typ <= lastTokenType; typ++ {
+		if typ.String() == string(text) {
+			*t = typ
+			return nil
+		}
+	}
+	return fmt.Errorf("parser: unknown TokenType %q", text)
+}
+
+// A jsonToken is the JSON encoding of a Token, documented in
+// schema/tokens.schema.json at the root of the module.
+type jsonToken struct {
+	Type  TokenType `json:"type"`
+	Value string    `json:"value"`
+	Span  jsonSpan  `json:"span"`
+}
+
+// A jsonSpan holds the byte offsets of the start and the end of a text.
+type jsonSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// MarshalJSON encodes t as an object with its type, its value and the
+// span of the value, as in
+//
+//	{"type": "IDENTIFIER", "value": "foo", "span": {"start": 4, "end": 7}}
+//
+// The value of an ERROR token is the error message and its span that of
+// the offending text.
+func (t Token) MarshalJSON() ([]byte, error) {
+	// Scala is full of < and >, which json.Marshal would escape.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(jsonToken{t.Typ, t.Val, jsonSpan{t.Pos, t.Pos + t.width()}}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var tok jsonToken
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return err
+	}
+	*t = Token{Typ: tok.Type, Val: tok.Value, Pos: tok.Span.Start}
+	if t.Typ == ERROR {
+		t.Width = tok.Span.End - tok.Span.Start
+	}
+	if tok.Span.End-tok.Span.Start != t.width() || t.Width < 0 {
+		return fmt.Errorf("parser: token %q does not fit its span %d..%d", tok.Value, tok.Span.Start, tok.Span.End)
+	}
+	return nil
+}
+
+// width returns the length of the span of t.
+func (t Token) width() int {
+	if t.Typ == ERROR {
+		return t.Width
+	}
+	return len(t.Val)
+}
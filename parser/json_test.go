@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite schema/tokens.schema.json")
+
+// TestTokenTypeValues checks that new token types do not renumber the
+// existing ones.
+func TestTokenTypeValues(t *testing.T) {
+	for typ, expected := range map[TokenType]int{NEWLINE: 11, OPORDELIM: 13, R_CURLY: 21, ABSTRACT: 22, YIELD: 60} {
+		if int(typ) != expected {
+			t.Errorf("%s = %d, Expected = %d", typ, int(typ), expected)
+		}
+	}
+}
+
+func TestTokenTypeText(t *testing.T) {
+	for typ := NIL; typ <= lastTokenType; typ++ {
+		text, err := typ.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded TokenType
+		if err := decoded.UnmarshalText(text); err != nil || decoded != typ {
+			t.Errorf("UnmarshalText(%s) = %d, %v, Expected = %d", text, int(decoded), err, int(typ))
+		}
+	}
+	if _, err := TokenType(-1).MarshalText(); err == nil {
+		t.Errorf("MarshalText(-1): Expected an error")
+	}
+}
+
+func TestTokenJSON(t *testing.T) {
+	const src = "def f[A <: B](x: A) = \"<\" + x"
+	tokens := Lexer(src).LexTillDone()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(tokens[:3]); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"type":"DEF","value":"def","span":{"start":0,"end":3}},` +
+		`{"type":"IDENTIFIER","value":"f","span":{"start":4,"end":5}},` +
+		`{"type":"[","value":"[","span":{"start":5,"end":6}}]` + "\n"
+	if s := buf.String(); s != expected {
+		t.Errorf("Encode = %s, Expected = %s", s, expected)
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []*Token
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, tokens) {
+		t.Errorf("Unmarshal(Marshal(%v)) = %v", tokens, decoded)
+	}
+
+	for _, tok := range Lexer("x = 1e+e").LexTillDone() {
+		if tok.Typ != ERROR {
+			continue
+		}
+		data, err := json.Marshal(tok)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := `{"type":"ERROR","value":"bad number syntax: \"1e+e\"","span":{"start":4,"end":8}}`; string(data) != expected {
+			t.Errorf("Marshal of an error = %s, Expected = %s", data, expected)
+		}
+	}
+
+	var tok Token
+	if err := json.Unmarshal([]byte(`{"type":"IDENTIFIER","value":"x","span":{"start":1,"end":3}}`), &tok); err == nil {
+		t.Errorf("Unmarshal of a token that does not fit its span: Expected an error")
+	}
+}
+
+// tokensSchema returns the JSON schema of the JSON encoding of tokens.
+func tokensSchema() map[string]interface{} {
+	var types []string
+	for typ := NIL; typ <= lastTokenType; typ++ {
+		types = append(types, typ.String())
+	}
+	pos := map[string]interface{}{"type": "integer", "minimum": 0}
+	return map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         "https://github.com/sundargates/scalaparser/schema/tokens.schema.json",
+		"title":       "Scala token",
+		"description": "A token of a Scala source file, as encoded by the MarshalJSON method of parser.Token.",
+		"type":        "object",
+		"required":    []string{"type", "value", "span"},
+		"properties": map[string]interface{}{
+			"type":  map[string]interface{}{"description": "the name of the TokenType", "enum": types},
+			"value": map[string]interface{}{"description": "the text of the token, without the quotes of a literal; the error message of an ERROR token", "type": "string"},
+			"span": map[string]interface{}{
+				"description":          "the byte offsets of the start and the end of the value, which is exclusive; of the offending text for an ERROR token",
+				"type":                 "object",
+				"required":             []string{"start", "end"},
+				"properties":           map[string]interface{}{"start": pos, "end": pos},
+				"additionalProperties": false,
+			},
+		},
+		"additionalProperties": false,
+	}
+}
+
+func TestTokensSchema(t *testing.T) {
+	data, err := json.MarshalIndent(tokensSchema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+	filename := filepath.Join("..", "schema", "tokens.schema.json")
+	if *update {
+		if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old, data) {
+		t.Errorf("%s is out of date; run go test ./parser -run TestTokensSchema -update", filename)
+	}
+}
@@ -14,6 +14,14 @@ type TokenType int
 
 type stateFn func(*lexer) *Token
 
+// Dialect selects the version of the Scala syntax being lexed.
+type Dialect int
+
+const (
+	Scala2 Dialect = iota
+	Scala3
+)
+
 type lexer struct {
 	input       string // string being scanned
 	start       int    // string being scanned
@@ -22,11 +30,19 @@ type lexer struct {
 	lastToken   *Token
 	lastStateFn stateFn
 	regionStack []TokenType
+	dialect     Dialect
+	lineStarts  []int // computed on demand by Position
 }
 
+// A Token is a token of the input. Val is its text, without the quotes
+// of a literal, except for an ERROR token: Val is then the error message
+// and Width the length of the offending text at Pos. Width is 0 for the
+// other tokens, whose text is Val.
 type Token struct {
-	Typ TokenType
-	Val string
+	Typ   TokenType
+	Val   string
+	Pos   int // byte offset of Val within the input
+	Width int // length of the text of an ERROR token, or 0
 }
 
 // for debugging purposes
@@ -35,7 +51,12 @@ func (t Token) String() string {
 }
 
 func Lexer(src string) *lexer {
-	return &lexer{input: src, lastStateFn: lexStart}
+	return DialectLexer(src, Scala2)
+}
+
+// DialectLexer returns a lexer for src written in the given dialect.
+func DialectLexer(src string, dialect Dialect) *lexer {
+	return &lexer{input: src, lastStateFn: lexStart, dialect: dialect}
 }
 
 func (l *lexer) LexTillDone() []*Token {
@@ -63,30 +84,61 @@ func isKeyword(val string) bool {
 	return false
 }
 
+// IsIdentifier reports whether name can be written as a plain
+// identifier, without backquotes: it lexes as a single identifier, so
+// that it is neither a keyword nor a reserved operator.
+func IsIdentifier(name string) bool {
+	if !utf8.ValidString(name) {
+		return false
+	}
+	tokens := Lexer(name).LexTillDone()
+	return len(tokens) == 1 && tokens[0].Typ == IDENTIFIER && tokens[0].Val == name
+}
+
 func isDigit(c rune) bool {
 	return strings.IndexRune(num, c) != -1
 }
 
-func isOpOrDelim(val string) bool {
-	for _, od := range opDelims {
-		if strings.HasPrefix(val, od) {
+// isReservedOp reports whether an operator run is one of the reserved
+// operators of the language rather than an identifier.
+func isReservedOp(val string) bool {
+	for _, op := range reservedOps {
+		if val == op {
 			return true
 		}
 	}
 	return false
 }
 
-func isOperatorCharacter(c rune) bool {
+// IsOperatorChar reports whether c is an opchar, which together with the
+// opchars next to it forms a symbolic identifier: printable ASCII
+// symbols and the Unicode math (Sm) and other (So) symbols.
+func IsOperatorChar(c rune) bool {
 	if c == eof {
 		return false
 	}
-	if !unicode.IsPrint(c) {
-		return false
+	if c < utf8.RuneSelf {
+		return strings.IndexRune(opChars, c) != -1
 	}
-	if strings.IndexRune(whitespace+letter+num+paren+delim+newline, c) != -1 {
-		return false
+	return unicode.In(c, unicode.Sm, unicode.So)
+}
+
+// isLetter reports whether c may start an alphanumeric identifier.
+func isLetter(c rune) bool {
+	if c < utf8.RuneSelf {
+		return strings.IndexRune(letter, c) != -1
 	}
-	return true
+	return unicode.In(c, unicode.L, unicode.Nl)
+}
+
+func isLetterOrDigit(c rune) bool {
+	return isLetter(c) || isDigit(c)
+}
+
+// isWhitespace reports whether c separates tokens without ending a line:
+// space, tab, form feed or any other Unicode space separator (Zs).
+func isWhitespace(c rune) bool {
+	return strings.IndexRune(whitespaceSansNewline, c) != -1 || unicode.Is(unicode.Zs, c)
 }
 
 // reads & returns the next rune, steps width forward
@@ -130,6 +182,24 @@ func (l *lexer) acceptRun(valid string) {
 	l.backup()
 }
 
+// accepts all runes for which valid returns true
+func (l *lexer) acceptRunFunc(valid func(rune) bool) {
+	for c := l.next(); c != eof && valid(c); c = l.next() {
+	}
+	l.backup()
+}
+
+// acceptNewline consumes a single line terminator, which is either "\r\n",
+// "\n" or a lone "\r".
+func (l *lexer) acceptNewline() bool {
+	if strings.HasPrefix(l.input[l.pos:], crlf) {
+		l.width = len(crlf)
+		l.pos += l.width
+		return true
+	}
+	return l.accept(newline)
+}
+
 func (l *lexer) acceptAllBut(invalid string) bool {
 	for c := l.next(); c != eof && strings.IndexRune(invalid, c) == -1; {
 		return true
@@ -149,15 +219,6 @@ func isCharacterLiteral(val string) bool {
 	return re.MatchString(val)
 }
 
-func (l *lexer) lastAccepted() rune {
-	// check if we did consume some character
-	if l.width > 0 {
-		l.backup()
-		return l.next()
-	}
-	return eof
-}
-
 func (l *lexer) peek() rune {
 	return l.peekNth(0)
 }
@@ -211,12 +272,12 @@ func (l *lexer) lookAhead(i int) (*Token, error) {
 		}
 	}
 	// invert the lexer changes
-	switch curr_token.Typ {
-	case L_PAREN, L_CURLY, L_BRACKET:
+	switch {
+	case opensRegion(curr_token):
 		// this would have pushed the paren into the stack
 		// time to pop
 		l.regionStack = l.regionStack[:len(l.regionStack)-1]
-	case R_PAREN, R_CURLY, R_BRACKET:
+	case curr_token.Typ == R_PAREN || curr_token.Typ == R_CURLY || curr_token.Typ == R_BRACKET:
 		// this would have popped something from the stack
 		// let's push it back
 		invertedParen, e := invertParen(curr_token.Typ)
@@ -242,16 +303,23 @@ func (l *lexer) emit(t TokenType, fn stateFn) *Token {
 	l.start = l.pos
 	l.width = 0
 	// create the resultant token
-	l.lastToken = &Token{Typ: t, Val: v}
+	token := &Token{Typ: t, Val: v, Pos: l.pos - len(v)}
+	// comments are whitespace as far as newline inference is concerned
+	if t != COMMENT {
+		l.lastToken = token
+	}
 	l.lastStateFn = fn
-	return l.lastToken
+	return token
 }
 
-// func (l *lexer) emitErrorf(format string, a ...interface{}) {
-// 	l.Tokens <- Token{Typ: Error, Val: fmt.Sprintf(format, a)}
-// }
+func (l *lexer) emitErrorf(format string, a ...interface{}) *Token {
+	return l.emitError(fmt.Sprintf(format, a...))
+}
 
-func (l *lexer) emitError(a ...interface{}) *Token {
+// emitError emits an ERROR token with the message msg for the text
+// from the start of the current token.
+func (l *lexer) emitError(msg string) *Token {
+	pos := l.start
 	// ignore up to whatever has been parsed
 	if l.pos > l.start {
 		l.ignore()
@@ -259,7 +327,7 @@ func (l *lexer) emitError(a ...interface{}) *Token {
 		l.skip()
 	}
 	l.lastStateFn = lexStart
-	return &Token{Typ: ERROR, Val: fmt.Sprint(a)}
+	return &Token{Typ: ERROR, Val: msg, Pos: pos, Width: l.start - pos}
 }
 
 // func (l *lexer) emitEof() {
@@ -303,6 +371,17 @@ func invertParen(l TokenType) (TokenType, error) {
 	}
 }
 
+// opensRegion reports whether lexing t pushed onto the region stack.
+func opensRegion(t *Token) bool {
+	switch t.Typ {
+	case L_PAREN, L_CURLY, L_BRACKET, TYPE_QUOTE, SPLICE:
+		return true
+	case QUOTE:
+		return strings.HasSuffix(t.Val, "{")
+	}
+	return false
+}
+
 func isMatchingParen(l TokenType, r TokenType) bool {
 	switch {
 	case l == L_PAREN && r == R_PAREN:
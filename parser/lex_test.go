@@ -36,12 +36,16 @@ var lexTests = []struct {
 	    spans three
 	    lines."""""""""""""sundaram`, []TokenType{STRING, IDENTIFIER}},
 	{"truee true", []TokenType{IDENTIFIER, BOOLEAN}},
+	{"T forSome { type T }", []TokenType{IDENTIFIER, FORSOME, L_CURLY, TYPE, IDENTIFIER, R_CURLY}},
+	{"forsome", []TokenType{IDENTIFIER}},
 	{"'a''\\n'", []TokenType{CHARACTER, CHARACTER}},
 	{"1e30f", []TokenType{NUMBER}},
 	{"3.14159f", []TokenType{NUMBER}},
 	{"1.0e-100", []TokenType{NUMBER}},
 	{".1", []TokenType{NUMBER}},
 	{"_;", []TokenType{IDENTIFIER, SEMICOLON}},
+	{"_: Int", []TokenType{IDENTIFIER, OPORDELIM, IDENTIFIER}},
+	{"x_+", []TokenType{IDENTIFIER}},
 	{";", []TokenType{SEMICOLON}},
 	{"22.`yield`", []TokenType{NUMBER, DOT, IDENTIFIER}},
 	{`
@@ -87,6 +91,34 @@ def join[%s](%s): Future[(%s)] = join(Seq(%s)) map { _ => (%s) }""".format(
   '
   */Identifier`, []TokenType{IDENTIFIER}},
 	{"'defined", []TokenType{SYMBOL}},
+	{"a\nb", []TokenType{IDENTIFIER, NEWLINE, IDENTIFIER}},
+	{"a\r\nb", []TokenType{IDENTIFIER, NEWLINE, IDENTIFIER}},
+	{"a\rb", []TokenType{IDENTIFIER, NEWLINE, IDENTIFIER}},
+	{"a\r\n\r\nb", []TokenType{IDENTIFIER, NEWLINES, IDENTIFIER}},
+	{"a\r\n \t\r\nb", []TokenType{IDENTIFIER, NEWLINES, IDENTIFIER}},
+	{"a\f\u00a0\u2003b", []TokenType{IDENTIFIER, IDENTIFIER}},
+	{"a // comment\r\nb", []TokenType{IDENTIFIER, COMMENT, NEWLINE, IDENTIFIER}},
+	{"val a =\r\n  1", []TokenType{VAL, IDENTIFIER, OPORDELIM, NUMBER}},
+	{"f(a\r\nb)", []TokenType{IDENTIFIER, L_PAREN, IDENTIFIER, IDENTIFIER, R_PAREN}},
+	{"{a\r\nb}", []TokenType{L_CURLY, IDENTIFIER, NEWLINE, IDENTIFIER, R_CURLY}},
+	{"a\nelse", []TokenType{IDENTIFIER, ELSE}},
+	{"a\ncase x", []TokenType{IDENTIFIER, CASE, IDENTIFIER}},
+	{"a\ncase class", []TokenType{IDENTIFIER, NEWLINE, CASE, CLASS}},
+	{"#!/usr/bin/env scala\nprintln(1)", []TokenType{SHEBANG, IDENTIFIER, L_PAREN, NUMBER, R_PAREN}},
+	{" #!x", []TokenType{IDENTIFIER, IDENTIFIER}},
+	{"#!/usr/bin/env -S scala-cli shebang\n//> using scala 3.3\n// note\n//> using dep \"a::b:1\"\nval x", []TokenType{SHEBANG, USING_DIRECTIVE, COMMENT, USING_DIRECTIVE, VAL, IDENTIFIER}},
+	{"val x\n//> using scala 3", []TokenType{VAL, IDENTIFIER, COMMENT}},
+	{"//> usingscala 3\nval x", []TokenType{COMMENT, VAL, IDENTIFIER}},
+	{"//> using\tscala 3\n//> using\fjvm 17\nval x", []TokenType{USING_DIRECTIVE, USING_DIRECTIVE, VAL, IDENTIFIER}},
+	{"//> using\n//> using \r\nval x", []TokenType{COMMENT, COMMENT, VAL, IDENTIFIER}},
+	{"x += 1", []TokenType{IDENTIFIER, IDENTIFIER, NUMBER}},
+	{"h :: t", []TokenType{IDENTIFIER, IDENTIFIER, IDENTIFIER}},
+	{"a==b", []TokenType{IDENTIFIER, IDENTIFIER, IDENTIFIER}},
+	{"(x: Int) => x", []TokenType{L_PAREN, IDENTIFIER, OPORDELIM, IDENTIFIER, R_PAREN, OPORDELIM, IDENTIFIER}},
+	{"a ⇒ b ∘ c", []TokenType{IDENTIFIER, OPORDELIM, IDENTIFIER, IDENTIFIER, IDENTIFIER}},
+	{"T <: U, V", []TokenType{IDENTIFIER, OPORDELIM, IDENTIFIER, OPORDELIM, IDENTIFIER}},
+	{"a +// plus\nb", []TokenType{IDENTIFIER, IDENTIFIER, COMMENT, NEWLINE, IDENTIFIER}},
+	{"xs: _*", []TokenType{IDENTIFIER, OPORDELIM, IDENTIFIER, IDENTIFIER}},
 }
 
 func getTokenTypes(tokens []*Token) []TokenType {
@@ -110,3 +142,136 @@ func TestLex(t *testing.T) {
 		}
 	}
 }
+
+func TestIsIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"x", true},
+		{"x_+", true},
+		{"::", true},
+		{"αρετη", true},
+		{"_", true},
+		{"yield", false},
+		{"true", false},
+		{"=>", false},
+		{"#", false},
+		{"x+", false},
+		{"a b", false},
+		{"1x", false},
+		{"", false},
+		{"\xff", false},
+	}
+	for _, test := range tests {
+		if res := IsIdentifier(test.name); res != test.expected {
+			t.Errorf("IsIdentifier(%q) = %v, Expected = %v", test.name, res, test.expected)
+		}
+	}
+}
+
+func TestKeywordTokenTypes(t *testing.T) {
+	for _, k := range keywords {
+		if typ, ok := keywordsToTokenType[k]; !ok || typ == NIL {
+			t.Errorf("keyword %s has no token type", k)
+		}
+	}
+}
+
+func TestLexPositions(t *testing.T) {
+	src := "val a =\r\n  1\rb\n\"c\""
+	expected := []Position{{0, 1, 1}, {4, 1, 5}, {6, 1, 7}, {11, 2, 3}, {13, 3, 1}, {16, 4, 2}}
+	lexer := Lexer(src)
+	var tokens []*Token
+	for _, token := range lexer.LexTillDone() {
+		if token.Typ != NEWLINE {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Lex(%q) = %s, Expected %d tokens", src, tokens, len(expected))
+	}
+	for i, token := range tokens {
+		if p := lexer.Position(token.Pos); p != expected[i] {
+			t.Errorf("Position(%s) = %+v, Expected = %+v", token, p, expected[i])
+		}
+	}
+}
+
+var lineEndingTests = []struct {
+	input    string
+	expected LineEnding
+}{
+	{"a", NoLineEnding},
+	{"a\nb\n", LF},
+	{"a\r\nb\r\n", CRLF},
+	{"a\rb\r", CR},
+	{"a\r\nb\n", MixedLineEndings},
+}
+
+func TestLineEnding(t *testing.T) {
+	for _, test := range lineEndingTests {
+		if e := Lexer(test.input).LineEnding(); e != test.expected {
+			t.Errorf("LineEnding(%q) = %s, Expected = %s", test.input, e, test.expected)
+		}
+	}
+}
+
+var usingDirectiveTests = []struct {
+	input    string
+	expected UsingDirective
+}{
+	{"//> using scala 3.3.1", UsingDirective{"scala", []string{"3.3.1"}}},
+	{"//> using dep \"com.lihaoyi::os-lib:0.9.1\" com.lihaoyi::upickle:3.1.0", UsingDirective{"dep", []string{"com.lihaoyi::os-lib:0.9.1", "com.lihaoyi::upickle:3.1.0"}}},
+	{"//> using options -Xfatal-warnings, -deprecation", UsingDirective{"options", []string{"-Xfatal-warnings", "-deprecation"}}},
+	{"//> using test.dep \"a \\\"b\\\"\"", UsingDirective{"test.dep", []string{"a \"b\""}}},
+	{"//> using toolkit", UsingDirective{"toolkit", nil}},
+	{"//> using\fjvm\f17", UsingDirective{"jvm", []string{"17"}}},
+}
+
+func TestParseUsingDirective(t *testing.T) {
+	for _, test := range usingDirectiveTests {
+		res, err := ParseUsingDirective(test.input)
+		if err != nil {
+			t.Errorf("ParseUsingDirective(%s) failed: %s", test.input, err)
+			continue
+		}
+		if fmt.Sprintf("%q", res) != fmt.Sprintf("%q", test.expected) {
+			t.Errorf("ParseUsingDirective(%s) = %q, Expected = %q", test.input, res, test.expected)
+		}
+	}
+	for _, input := range []string{"//> usingscala", "//> using", "//> using ", "//> using dep \"open"} {
+		if _, err := ParseUsingDirective(input); err == nil {
+			t.Errorf("ParseUsingDirective(%s) succeeded, Expected an error", input)
+		}
+	}
+}
+
+var dialectLexTests = []struct {
+	dialect  Dialect
+	input    string
+	expected []TokenType
+}{
+	{Scala2, "'sym", []TokenType{SYMBOL}},
+	{Scala3, "'sym", []TokenType{QUOTE, IDENTIFIER}},
+	{Scala3, "'a'", []TokenType{CHARACTER}},
+	{Scala3, "'{'", []TokenType{CHARACTER}},
+	{Scala3, "'{ x + 1 }", []TokenType{QUOTE, IDENTIFIER, IDENTIFIER, NUMBER, R_CURLY}},
+	{Scala3, "'[List[T]]", []TokenType{TYPE_QUOTE, IDENTIFIER, L_BRACKET, IDENTIFIER, R_BRACKET, R_BRACKET}},
+	{Scala3, "'{ ${ f('x) } }", []TokenType{QUOTE, SPLICE, IDENTIFIER, L_PAREN, QUOTE, IDENTIFIER, R_PAREN, R_CURLY, R_CURLY}},
+	{Scala3, "'{\n a\n b\n}", []TokenType{QUOTE, IDENTIFIER, NEWLINE, IDENTIFIER, R_CURLY}},
+	{Scala3, "'[T\n]", []TokenType{TYPE_QUOTE, IDENTIFIER, R_BRACKET}},
+	{Scala3, "x\n'{ y }", []TokenType{IDENTIFIER, NEWLINE, QUOTE, IDENTIFIER, R_CURLY}},
+	{Scala3, "'{ x ]", []TokenType{QUOTE, IDENTIFIER, ERROR}},
+	{Scala2, "${x}", []TokenType{IDENTIFIER, L_CURLY, IDENTIFIER, R_CURLY}},
+	{Scala3, "s\"${x}\"", []TokenType{IDENTIFIER, STRING}},
+}
+
+func TestDialectLex(t *testing.T) {
+	for _, test := range dialectLexTests {
+		tokens := DialectLexer(test.input, test.dialect).LexTillDone()
+		if fmt.Sprintf("%v", getTokenTypes(tokens)) != fmt.Sprintf("%v", test.expected) {
+			t.Errorf("Lex(%s) = %s, Expected = %s", test.input, tokens, test.expected)
+		}
+	}
+}
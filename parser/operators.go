@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Operator precedences of SLS 6.12.3, from loosest to tightest binding.
+// Assignment operators bind looser than any other operator (SLS 6.12.4).
+const (
+	AssignmentPrecedence     = iota
+	LetterPrecedence         // all letters
+	PipePrecedence           // |
+	CaretPrecedence          // ^
+	AmpersandPrecedence      // &
+	EqualityPrecedence       // = !
+	RelationalPrecedence     // < >
+	ColonPrecedence          // :
+	AdditivePrecedence       // + -
+	MultiplicativePrecedence // * / %
+	SpecialPrecedence        // all other special characters
+)
+
+// OperatorName strips the backquotes from an identifier so that `op`
+// is classified like op.
+func OperatorName(val string) string {
+	return strings.Trim(val, backtick)
+}
+
+// OperatorPrecedence returns the precedence of op when it is used as an
+// infix operator. Higher values bind tighter.
+func OperatorPrecedence(op string) int {
+	op = OperatorName(op)
+	if IsAssignmentOperator(op) {
+		return AssignmentPrecedence
+	}
+	c, _ := utf8.DecodeRuneInString(op)
+	switch c {
+	case '|':
+		return PipePrecedence
+	case '^':
+		return CaretPrecedence
+	case '&':
+		return AmpersandPrecedence
+	case '=', '!':
+		return EqualityPrecedence
+	case '<', '>':
+		return RelationalPrecedence
+	case ':':
+		return ColonPrecedence
+	case '+', '-':
+		return AdditivePrecedence
+	case '*', '/', '%':
+		return MultiplicativePrecedence
+	}
+	if IsOperatorChar(c) {
+		return SpecialPrecedence
+	}
+	return LetterPrecedence
+}
+
+// IsRightAssociative reports whether op associates to the right, which
+// is the case for operators ending in a colon.
+func IsRightAssociative(op string) bool {
+	return strings.HasSuffix(OperatorName(op), ":")
+}
+
+// IsAssignmentOperator reports whether op is an assignment operator such
+// as += : it ends in '=', is not one of <=, >= and != and does not start
+// with '='.
+func IsAssignmentOperator(op string) bool {
+	op = OperatorName(op)
+	switch op {
+	case "<=", ">=", "!=":
+		return false
+	}
+	if !strings.HasSuffix(op, "=") || strings.HasPrefix(op, "=") {
+		return false
+	}
+	// a symbolic name throughout, so that e.g. `x_=` is not an assignment
+	for _, c := range op {
+		if !IsOperatorChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Precedence returns the infix precedence of an IDENTIFIER token, or -1
+// for any other token.
+func (t *Token) Precedence() int {
+	if t.Typ != IDENTIFIER {
+		return -1
+	}
+	return OperatorPrecedence(t.Val)
+}
+
+// IsRightAssociative reports whether t is a right associative operator.
+func (t *Token) IsRightAssociative() bool {
+	return t.Typ == IDENTIFIER && IsRightAssociative(t.Val)
+}
+
+// IsAssignmentOperator reports whether t is an assignment operator.
+func (t *Token) IsAssignmentOperator() bool {
+	return t.Typ == IDENTIFIER && IsAssignmentOperator(t.Val)
+}
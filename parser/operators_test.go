@@ -0,0 +1,66 @@
+package parser
+
+import "testing"
+
+var operatorTests = []struct {
+	op         string
+	precedence int
+	right      bool
+	assignment bool
+}{
+	{"max", LetterPrecedence, false, false},
+	{"`max`", LetterPrecedence, false, false},
+	{"|", PipePrecedence, false, false},
+	{"||", PipePrecedence, false, false},
+	{"^", CaretPrecedence, false, false},
+	{"&&", AmpersandPrecedence, false, false},
+	{"==", EqualityPrecedence, false, false},
+	{"!=", EqualityPrecedence, false, false},
+	{"<=", RelationalPrecedence, false, false},
+	{">>", RelationalPrecedence, false, false},
+	{"::", ColonPrecedence, true, false},
+	{"+:", AdditivePrecedence, true, false},
+	{":+", ColonPrecedence, false, false},
+	{"-", AdditivePrecedence, false, false},
+	{"*", MultiplicativePrecedence, false, false},
+	{"%", MultiplicativePrecedence, false, false},
+	{"/:", MultiplicativePrecedence, true, false},
+	{"?", SpecialPrecedence, false, false},
+	{"#::", SpecialPrecedence, true, false},
+	{"∘", SpecialPrecedence, false, false},
+	{"+=", AssignmentPrecedence, false, true},
+	{"::=", AssignmentPrecedence, false, true},
+	{"===", EqualityPrecedence, false, false},
+	{"x_=", LetterPrecedence, false, false},
+}
+
+func TestOperatorClassification(t *testing.T) {
+	for _, test := range operatorTests {
+		if p := OperatorPrecedence(test.op); p != test.precedence {
+			t.Errorf("OperatorPrecedence(%s) = %d, Expected = %d", test.op, p, test.precedence)
+		}
+		if r := IsRightAssociative(test.op); r != test.right {
+			t.Errorf("IsRightAssociative(%s) = %t, Expected = %t", test.op, r, test.right)
+		}
+		if a := IsAssignmentOperator(test.op); a != test.assignment {
+			t.Errorf("IsAssignmentOperator(%s) = %t, Expected = %t", test.op, a, test.assignment)
+		}
+	}
+}
+
+func TestTokenOperatorClassification(t *testing.T) {
+	tokens := Lexer("xs +:= y :: zs").LexTillDone()
+	if len(tokens) != 5 {
+		t.Fatalf("Lex = %s, Expected 5 tokens", tokens)
+	}
+	if !tokens[1].IsAssignmentOperator() || tokens[1].Precedence() != AssignmentPrecedence {
+		t.Errorf("%s is not classified as an assignment operator", tokens[1])
+	}
+	if !tokens[3].IsRightAssociative() || tokens[3].Precedence() != ColonPrecedence {
+		t.Errorf("%s is not classified as a right associative colon operator", tokens[3])
+	}
+	eq := Lexer("=").LexTillDone()[0]
+	if eq.Precedence() != -1 || eq.IsAssignmentOperator() {
+		t.Errorf("%s is classified as an identifier", eq)
+	}
+}
@@ -0,0 +1,525 @@
+package parser
+
+//go:generate goyacc -o parser.go -v "" parser.go.y
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// An Error is a syntax error at a position of a named file.
+type Error struct {
+	Filename string
+	Pos      Position
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s:%s: %s", e.Filename, e.Pos, e.Msg)
+}
+
+// ParseFileLALR parses the Scala 2 compilation unit or script src with
+// the LALR parser generated from parser.go.y. Parsing stops at the first
+// syntax error, which is returned as an *Error.
+func ParseFileLALR(name, src string) (*ast.File, error) {
+	l, err := newYYLex(name, src, 0, tSTART_FILE)
+	if err != nil {
+		return nil, err
+	}
+	yyNewParser().Parse(l)
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.file.Name = name
+	l.file.FileStart = ast.PosOf(0)
+	l.file.FileEnd = ast.PosOf(len(src))
+	l.file.Comments = comments(src, l.items)
+	return l.file, nil
+}
+
+// item is a token as the generated parser sees it: the lexer's token with
+// its yacc token code and its extent in the source.
+type item struct {
+	code int
+	tok  *Token
+	pos  ast.Pos // position of the first character, including quotes
+	end  ast.Pos
+	raw  string // source text of the token
+}
+
+// yyLex adapts the lexer to the yyLexer interface of the generated
+// parser. Tokens are lexed upfront so that the few places where the
+// grammar needs a second token of lookahead can be resolved here.
+type yyLex struct {
+	name   string
+	src    string // the whole file
+	lexer  *lexer // lexer of the whole file, for positions
+	items  []*item
+	next   int
+	last   *item
+	file   *ast.File
+	expr   ast.Expr
+	err    error
+	offset int // offset of the lexed text within src
+
+	// parens records the parentheses around expressions written as
+	// (x), which the tree does not otherwise keep.
+	parens map[ast.Expr][2]ast.Pos
+}
+
+// newYYLex lexes the text of src starting at offset and prepares it for
+// the parser; start selects the grammar's entry point.
+func newYYLex(name, src string, offset int, start int) (*yyLex, error) {
+	l := &yyLex{name: name, src: src, lexer: Lexer(src), offset: offset, parens: map[ast.Expr][2]ast.Pos{}}
+	items := scan(src, offset, func(offset int, msg string) {
+		if l.err == nil {
+			l.err = l.errorAt(offset, msg)
+		}
+	})
+	if l.err != nil {
+		return nil, l.err
+	}
+	first := &item{code: start, pos: ast.PosOf(offset), end: ast.PosOf(offset)}
+	l.items = mergeItems(append([]*item{first}, items...))
+	return l, nil
+}
+
+// scan lexes the text of src starting at offset into the items both
+// parsers read. Comments and the script header are dropped; lexical
+// errors are passed to report and dropped as well.
+func scan(src string, offset int, report func(offset int, msg string)) []*item {
+	var items []*item
+	for _, tok := range Lexer(src[offset:]).LexTillDone() {
+		tok := &Token{Typ: tok.Typ, Val: tok.Val, Pos: tok.Pos + offset}
+		switch tok.Typ {
+		case ERROR:
+			report(tok.Pos, tok.Val)
+			continue
+		case COMMENT, SHEBANG, USING_DIRECTIVE:
+			continue
+		}
+		items = append(items, newItem(src, tok))
+	}
+	return items
+}
+
+func newItem(src string, tok *Token) *item {
+	it := &item{tok: tok, pos: ast.PosOf(tok.Pos), end: ast.PosOf(tok.Pos + len(tok.Val))}
+	switch tok.Typ {
+	case STRING:
+		delim := quote
+		if tok.Pos >= 3 && src[tok.Pos-3:tok.Pos] == multilinequote && strings.HasPrefix(src[tok.Pos+len(tok.Val):], multilinequote) {
+			delim = multilinequote
+		}
+		it.pos -= ast.Pos(len(delim))
+		it.end += ast.Pos(len(delim))
+	case CHARACTER:
+		it.pos--
+		it.end++
+	}
+	it.raw = src[it.pos.Offset():it.end.Offset()]
+	it.code = yyCode(tok)
+	return it
+}
+
+// yyCode maps a token to the token code of the grammar.
+func yyCode(tok *Token) int {
+	switch tok.Typ {
+	case IDENTIFIER:
+		switch tok.Val {
+		case "_":
+			return tUSCORE
+		case "*":
+			return tSTAR
+		case "|":
+			return tPIPE
+		case "+":
+			return tPLUS
+		case "-":
+			return tMINUS
+		case "!":
+			return tBANG
+		case "~":
+			return tTILDE
+		}
+		return tIDENT
+	case OPORDELIM:
+		switch tok.Val {
+		case ",":
+			return tCOMMA
+		case ":":
+			return tCOLON
+		case "=":
+			return tEQ
+		case "=>", "⇒":
+			return tARROW
+		case "<-", "←":
+			return tLARROW
+		case "<:":
+			return tSUBTYPE
+		case ">:":
+			return tSUPERTYPE
+		case "<%":
+			return tVIEWBOUND
+		case "#":
+			return tHASH
+		case "@":
+			return tAT
+		}
+	case NUMBER:
+		return tNUMBER
+	case STRING:
+		return tSTRING
+	case CHARACTER:
+		return tCHAR
+	case SYMBOL:
+		return tSYMBOL
+	case BOOLEAN, TRUE, FALSE:
+		return tBOOLEAN
+	case NULL:
+		return tNULL
+	case NEWLINE:
+		return tNL
+	case NEWLINES:
+		return tNLS
+	case SEMICOLON:
+		return tSEMI
+	case DOT:
+		return tDOT
+	case L_PAREN:
+		return tLPAREN
+	case R_PAREN:
+		return tRPAREN
+	case L_BRACKET:
+		return tLBRACK
+	case R_BRACKET:
+		return tRBRACK
+	case L_CURLY:
+		return tLBRACE
+	case R_CURLY:
+		return tRBRACE
+	}
+	if code, ok := keywordCodes[tok.Typ]; ok {
+		return code
+	}
+	return yyErrCode
+}
+
+var keywordCodes = map[TokenType]int{
+	ABSTRACT: tABSTRACT, CASE: tCASE, CATCH: tCATCH, CLASS: tCLASS, DEF: tDEF,
+	DO: tDO, ELSE: tELSE, EXTENDS: tEXTENDS, FINAL: tFINAL, FINALLY: tFINALLY,
+	FOR: tFOR, FORSOME: tFORSOME, IF: tIF, IMPLICIT: tIMPLICIT, IMPORT: tIMPORT,
+	LAZY: tLAZY, MATCH: tMATCH, NEW: tNEW, OBJECT: tOBJECT, OVERRIDE: tOVERRIDE,
+	PACKAGE: tPACKAGE, PRIVATE: tPRIVATE, PROTECTED: tPROTECTED, RETURN: tRETURN,
+	SEALED: tSEALED, SUPER: tSUPER, THIS: tTHIS, THROW: tTHROW, TRAIT: tTRAIT,
+	TRY: tTRY, TYPE: tTYPE, VAL: tVAL, VAR: tVAR, WHILE: tWHILE, WITH: tWITH,
+	YIELD: tYIELD,
+}
+
+// mergeItems resolves the spots of the syntax that need more than one
+// token of lookahead:
+//   - case class and case object become single tokens, so that a block
+//     starting with them is not taken for a partial function,
+//   - _* becomes a single token,
+//   - an identifier directly followed by a string is an interpolation,
+//   - a single newline before "{" continues the current expression,
+//   - a newline after a symbolic operator continues the expression,
+//   - a semicolon before else, catch or finally is dropped.
+func mergeItems(items []*item) []*item {
+	var res []*item
+	for i := 0; i < len(items); i++ {
+		it := items[i]
+		var next *item
+		if i+1 < len(items) {
+			next = items[i+1]
+		}
+		switch {
+		case it.code == tCASE && next != nil && (next.code == tCLASS || next.code == tOBJECT):
+			code := tCASECLASS
+			if next.code == tOBJECT {
+				code = tCASEOBJECT
+			}
+			res = append(res, &item{code: code, tok: it.tok, pos: it.pos, end: next.end, raw: it.raw})
+			i++
+			continue
+		case it.code == tUSCORE && next != nil && next.code == tSTAR && next.pos == it.end:
+			res = append(res, &item{code: tUSCORE_STAR, tok: it.tok, pos: it.pos, end: next.end, raw: "_*"})
+			i++
+			continue
+		case it.code == tIDENT && next != nil && next.code == tSTRING && next.pos == it.end:
+			res = append(res, &item{code: tINTERP, tok: next.tok, pos: it.pos, end: next.end, raw: it.raw + next.raw})
+			i++
+			continue
+		case it.code == tNL && next != nil && next.code == tLBRACE:
+			continue
+		case it.code == tNL && len(res) > 0 && isSymbolicOp(res[len(res)-1]):
+			continue
+		case it.code == tSEMI && next != nil && (next.code == tELSE || next.code == tCATCH || next.code == tFINALLY):
+			continue
+		}
+		res = append(res, it)
+	}
+	return res
+}
+
+func isSymbolicOp(it *item) bool {
+	switch it.code {
+	case tIDENT, tSTAR, tPIPE, tPLUS, tMINUS, tBANG, tTILDE:
+	default:
+		return false
+	}
+	for _, c := range it.raw {
+		if !IsOperatorChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Lex implements yyLexer.
+func (l *yyLex) Lex(lval *yySymType) int {
+	if l.next >= len(l.items) {
+		end := ast.PosOf(len(l.src))
+		l.last = &item{pos: end, end: end}
+		lval.item = l.last
+		return 0
+	}
+	l.last = l.items[l.next]
+	l.next++
+	lval.item = l.last
+	return l.last.code
+}
+
+// Error implements yyLexer; only the first error is kept.
+func (l *yyLex) Error(msg string) {
+	if l.err != nil {
+		return
+	}
+	if l.last == nil || msg != "syntax error" {
+		l.err = l.errorAt(len(l.src), strings.TrimPrefix(msg, "syntax error: "))
+		return
+	}
+	l.err = l.errorAt(l.last.pos.Offset(), "unexpected "+l.last.describe())
+}
+
+// describe names an item in error messages.
+func (it *item) describe() string {
+	switch {
+	case it.tok == nil || it.tok.Typ == EOF:
+		return "end of file"
+	case it.tok.Typ == NEWLINE || it.tok.Typ == NEWLINES:
+		return "newline"
+	case it.tok.Typ == STRING:
+		return "string literal"
+	}
+	return "'" + it.raw + "'"
+}
+
+func (l *yyLex) errorAt(offset int, msg string) *Error {
+	return &Error{Filename: l.name, Pos: l.lexer.Position(offset), Msg: msg}
+}
+
+// errorf records a semantic error found while building the tree.
+func (l *yyLex) errorf(pos ast.Pos, format string, a ...interface{}) {
+	if l.err == nil {
+		l.err = l.errorAt(pos.Offset(), fmt.Sprintf(format, a...))
+	}
+}
+
+// Node constructors used by the grammar's actions.
+
+func (l *yyLex) ident(it *item) *ast.Ident {
+	return newIdent(it.pos, it.raw)
+}
+
+func (l *yyLex) literal(it *item) *ast.Literal {
+	return &ast.Literal{ValuePos: it.pos, Kind: literalKind(it.tok.Typ, it.raw), Value: it.raw}
+}
+
+func (l *yyLex) negative(minus, number *item) *ast.Literal {
+	raw := "-" + number.raw
+	return &ast.Literal{ValuePos: minus.pos, Kind: literalKind(NUMBER, raw), Value: raw}
+}
+
+// interpolation splits an interpolated string item; arguments in braces
+// are parsed as expressions.
+func (l *yyLex) interpolation(it *item) *ast.Interpolation {
+	id, body, offset := l.splitInterpolated(it)
+	parts, args := splitInterpolation(body, offset)
+	res := &ast.Interpolation{Id: id, Parts: parts, EndPos: it.end}
+	for _, arg := range args {
+		if arg.block == "" && arg.name != "" {
+			res.Args = append(res.Args, newIdent(ast.PosOf(arg.offset), arg.name))
+			continue
+		}
+		res.Args = append(res.Args, l.parseEmbeddedExpr(arg.offset, len(arg.block)))
+	}
+	return res
+}
+
+func (l *yyLex) interpolatedPattern(it *item) *ast.InterpolatedPattern {
+	id, body, offset := l.splitInterpolated(it)
+	parts, args := splitInterpolation(body, offset)
+	res := &ast.InterpolatedPattern{Id: id, Parts: parts, EndPos: it.end}
+	for _, arg := range args {
+		if arg.name == "" {
+			l.errorf(ast.PosOf(arg.offset), "only simple names are supported in interpolated patterns")
+			res.Args = append(res.Args, &ast.BadPattern{From: ast.PosOf(arg.offset), To: ast.PosOf(arg.offset + len(arg.block))})
+			continue
+		}
+		res.Args = append(res.Args, newIdent(ast.PosOf(arg.offset), arg.name))
+	}
+	return res
+}
+
+// splitInterpolated returns the interpolator of an interpolated string
+// item, the body between the quotes and the offset of the body.
+func (l *yyLex) splitInterpolated(it *item) (*ast.Ident, string, int) {
+	str := it.raw[strings.Index(it.raw, quote):]
+	id := newIdent(it.pos, it.raw[:len(it.raw)-len(str)])
+	body, offset := stringBody(str, it.end)
+	return id, body, offset
+}
+
+// stringBody returns the text between the quotes of the string literal
+// raw ending at end, and the offset of that text.
+func stringBody(raw string, end ast.Pos) (string, int) {
+	delim := quote
+	if strings.HasPrefix(raw, multilinequote) && len(raw) >= 6 {
+		delim = multilinequote
+	}
+	body := raw[len(delim) : len(raw)-len(delim)]
+	return body, end.Offset() - len(delim) - len(body)
+}
+
+// parseEmbeddedExpr parses the expression of length n at offset, such as
+// the contents of ${...} in an interpolated string.
+func (l *yyLex) parseEmbeddedExpr(offset, n int) ast.Expr {
+	bad := &ast.BadExpr{From: ast.PosOf(offset), To: ast.PosOf(offset + n)}
+	sub, err := newYYLex(l.name, l.src[:offset+n], offset, tSTART_EXPR)
+	if err != nil {
+		if l.err == nil {
+			l.err = err
+		}
+		return bad
+	}
+	yyNewParser().Parse(sub)
+	if sub.err != nil {
+		if l.err == nil {
+			l.err = sub.err
+		}
+		return bad
+	}
+	return sub.expr
+}
+
+// defPrefix holds the annotations and modifiers preceding a definition.
+type defPrefix struct {
+	annots []*ast.Annotation
+	mods   []*ast.Modifier
+}
+
+// caseModifier returns the case modifier of a merged case class or case
+// object item.
+func (l *yyLex) caseModifier(it *item) []*ast.Modifier {
+	return []*ast.Modifier{{ModPos: it.pos, Name: "case"}}
+}
+
+func (l *yyLex) typeParam(annots []*ast.Annotation, variance *item, name *ast.Ident, tparams []*ast.TypeParam, b tparamBounds) *ast.TypeParam {
+	tp := &ast.TypeParam{
+		Annotations:   annots,
+		Name:          name,
+		TypeParams:    tparams,
+		Lo:            b.lo,
+		Hi:            b.hi,
+		ViewBounds:    b.views,
+		ContextBounds: b.contexts,
+		EndPos:        name.End(),
+	}
+	if variance != nil {
+		tp.Variance, tp.VariancePos = variance.raw, variance.pos
+	}
+	if len(tparams) > 0 {
+		tp.EndPos = l.closing(tparams[len(tparams)-1].End(), "]")
+	}
+	for _, t := range append([]ast.Type{b.lo, b.hi}, append(b.views, b.contexts...)...) {
+		if t != nil && t.End() > tp.EndPos {
+			tp.EndPos = t.End()
+		}
+	}
+	return tp
+}
+
+// closing returns the position after the first occurrence of delim at or
+// after pos, skipping white space.
+func (l *yyLex) closing(pos ast.Pos, delim string) ast.Pos {
+	if i := strings.Index(l.src[pos.Offset():], delim); i >= 0 {
+		return pos + ast.Pos(i+len(delim))
+	}
+	return pos
+}
+
+func (l *yyLex) tryExpr(try *item, body ast.Expr, catch *item, handler ast.Expr, finally *item, finalizer ast.Expr) *ast.Try {
+	res := &ast.Try{Try: try.pos, Body: body, CatchPos: catch.pos}
+	if pf, ok := handler.(*ast.PartialFunction); ok {
+		res.Cases, res.EndPos = pf.Cases, pf.End()
+	} else {
+		res.Catch = handler
+	}
+	if finally != nil {
+		res.FinallyPos, res.Finally = finally.pos, finalizer
+	}
+	return res
+}
+
+func (l *yyLex) forExpr(forItem *item, enums []ast.Enumerator, yield *item, body ast.Expr) *ast.For {
+	res := &ast.For{For: forItem.pos, Enums: enums, Body: body}
+	if yield != nil {
+		res.Yield = yield.pos
+	}
+	return res
+}
+
+// qualifier returns the qualifier C of C.this or C.super, which must be
+// a simple name.
+func (l *yyLex) qualifier(x ast.Expr) *ast.Ident {
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		l.errorf(x.Pos(), "qualifier of this or super must be a simple name")
+		return &ast.Ident{NamePos: x.Pos(), Name: "_"}
+	}
+	return id
+}
+
+func classParam(prefix *defPrefix, valvar *item, name *ast.Ident, typ ast.Type, def ast.Expr) *ast.Param {
+	p := &ast.Param{Name: name, Type: typ, Default: def}
+	if prefix != nil {
+		p.Annotations, p.Modifiers = prefix.annots, prefix.mods
+	}
+	if valvar != nil {
+		p.ValPos, p.Keyword = valvar.pos, valvar.raw
+	}
+	return p
+}
+
+// blockStats makes an anonymous function at the start of a block extend
+// to the end of the block, as in { x => a; b } (SLS 6.23).
+func (l *yyLex) blockStats(stats []ast.Stat) []ast.Stat {
+	if len(stats) < 2 {
+		return stats
+	}
+	f, ok := stats[0].(*ast.Function)
+	if !ok {
+		return stats
+	}
+	if _, isParen := l.parens[f]; isParen {
+		return stats
+	}
+	f.Body = &ast.Block{Stats: append([]ast.Stat{f.Body}, stats[1:]...)}
+	return stats[:1]
+}
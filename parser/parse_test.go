@@ -0,0 +1,1018 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// render prints the structure of the expression, pattern or type
+// nodes the tests below look at, fully parenthesized.
+func render(n ast.Node) string {
+	switch x := n.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.Literal:
+		return x.Value
+	case *ast.Select:
+		return render(x.X) + "." + x.Sel.Name
+	case *ast.This:
+		return "this"
+	case *ast.InfixApply:
+		op := x.Op.Name
+		if x.Targs != nil {
+			op += "[" + renderList(x.Targs) + "]"
+		}
+		return "(" + render(x.X) + " " + op + " " + render(x.Y) + ")"
+	case *ast.PrefixApply:
+		return "(" + x.Op.Name + render(x.X) + ")"
+	case *ast.PostfixApply:
+		return "(" + render(x.X) + " " + x.Op.Name + ")"
+	case *ast.Assign:
+		return "(" + render(x.X) + " = " + render(x.Rhs) + ")"
+	case *ast.Apply:
+		return render(x.Fun) + render(x.Args)
+	case *ast.ArgList:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, render(arg))
+		}
+		return "(" + strings.Join(args, ", ") + ")"
+	case *ast.TypeApply:
+		return render(x.Fun) + "[" + renderList(x.Targs) + "]"
+	case *ast.Typed:
+		return "(" + render(x.X) + ": " + render(x.Type) + ")"
+	case *ast.Tuple:
+		var elts []string
+		for _, elt := range x.Elts {
+			elts = append(elts, render(elt))
+		}
+		return "(" + strings.Join(elts, ", ") + ")"
+	case *ast.Block:
+		var stats []string
+		for _, stat := range x.Stats {
+			stats = append(stats, render(stat))
+		}
+		return "{" + strings.Join(stats, "; ") + "}"
+	case *ast.Function:
+		var params []string
+		for _, p := range x.Params {
+			params = append(params, render(p.Name))
+		}
+		return "((" + strings.Join(params, ", ") + ") => " + render(x.Body) + ")"
+	case *ast.If:
+		s := "if " + render(x.Cond) + " " + render(x.Then)
+		if x.Else != nil {
+			s += " else " + render(x.Else)
+		}
+		return s
+	case *ast.Interpolation:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, render(arg))
+		}
+		return fmt.Sprintf("%s%q%s", x.Id.Name, x.Parts, args)
+	case *ast.InfixPattern:
+		return "(" + render(x.X) + " " + x.Op.Name + " " + render(x.Y) + ")"
+	case *ast.Bind:
+		return "(" + x.Name.Name + " @ " + render(x.Pat) + ")"
+	case *ast.TypedPattern:
+		return "(" + x.X.Name + ": " + render(x.Type) + ")"
+	case *ast.Alternative:
+		var alts []string
+		for _, alt := range x.Alts {
+			alts = append(alts, render(alt))
+		}
+		return "(" + strings.Join(alts, " | ") + ")"
+	case *ast.ExtractorPattern:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, render(arg))
+		}
+		fun := render(x.Fun)
+		if x.Targs != nil {
+			fun += "[" + renderList(x.Targs) + "]"
+		}
+		return fun + "(" + strings.Join(args, ", ") + ")"
+	case *ast.TuplePattern:
+		var elts []string
+		for _, elt := range x.Elts {
+			elts = append(elts, render(elt))
+		}
+		return "(" + strings.Join(elts, ", ") + ")"
+	case *ast.InterpolatedPattern:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, render(arg))
+		}
+		return fmt.Sprintf("%s%q%s", x.Id.Name, x.Parts, args)
+	case *ast.SeqWildcard:
+		return "_*"
+	case *ast.InfixType:
+		return "(" + render(x.X) + " " + x.Op.Name + " " + render(x.Y) + ")"
+	case *ast.AppliedType:
+		return render(x.Type) + "[" + renderList(x.Args) + "]"
+	case *ast.FunctionType:
+		return "((" + renderList(x.Params) + ") => " + render(x.Result) + ")"
+	case *ast.TupleType:
+		return "(" + renderList(x.Elts) + ")"
+	case *ast.WildcardType:
+		s := "_"
+		if x.Lo != nil {
+			s += " >: " + render(x.Lo)
+		}
+		if x.Hi != nil {
+			s += " <: " + render(x.Hi)
+		}
+		return s
+	case *ast.Projection:
+		return render(x.X) + "#" + x.Sel.Name
+	case *ast.SingletonType:
+		return render(x.Ref) + ".type"
+	case *ast.ByNameType:
+		return "=> " + render(x.Type)
+	case *ast.CompoundType:
+		s := renderList(x.Types)
+		if x.Lbrace.IsValid() {
+			s = strings.TrimSpace(s + fmt.Sprintf(" {%d decls}", len(x.Decls)))
+		}
+		return "(" + strings.ReplaceAll(s, ", ", " with ") + ")"
+	case *ast.ExistentialType:
+		return "(" + render(x.Type) + fmt.Sprintf(" forSome {%d decls})", len(x.Decls))
+	case *ast.AnnotatedType:
+		s := render(x.Type)
+		for _, a := range x.Annotations {
+			s += " @" + render(a.Init.Type)
+		}
+		return "(" + s + ")"
+	case *ast.RepeatedType:
+		return render(x.Type) + "*"
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func renderList(types []ast.Type) string {
+	var res []string
+	for _, t := range types {
+		res = append(res, render(t))
+	}
+	return strings.Join(res, ", ")
+}
+
+func parseStats(t *testing.T, src string) []ast.Stat {
+	t.Helper()
+	f, err := ParseFile("test.scala", src)
+	if err != nil {
+		t.Fatalf("ParseFile(%q): %v", src, err)
+	}
+	return f.Stats
+}
+
+var exprTests = []struct {
+	input    string
+	expected string
+}{
+	{"a + b * c", "(a + (b * c))"},
+	{"a * b + c", "((a * b) + c)"},
+	{"a :: b :: Nil", "(a :: (b :: Nil))"},
+	{"a || b && c", "(a || (b && c))"},
+	{"a max b + 1", "(a max (b + 1))"},
+	{"x += y + 1", "(x += (y + 1))"},
+	{"x = y + 1", "(x = (y + 1))"},
+	{"f(a = b)", "f((a = b))"},
+	{"f((a = b))", "f(((a = b)))"},
+	{"!a == -b", "((!a) == (-b))"},
+	{"-1 + x", "(-1 + x)"},
+	{"xs map f", "(xs map f)"},
+	{"xs.toList sorted", "(xs.toList sorted)"},
+	{"f(a)(b)[T]", "f(a)(b)[T]"},
+	{"f { x }", "f({x})"},
+	{"(x: Int) => x + 1", "((x) => (x + 1))"},
+	{"{ x =>\n  f(x)\n  g(x)\n}", "{((x) => {f(x); g(x)})}"},
+	{"{ x: Int => x }", "{((x) => x)}"},
+	{"(a, b)", "(a, b)"},
+	{"xs: _*", "(xs: _*)"},
+	{"if (a) b else if (c) d\nelse e", "if a b else if c d else e"},
+	{`s"x=$x, y=${y + 1}"`, `s["x=" ", y=" ""][x (y + 1)]`},
+	{"A.this.x", "this.x"},
+}
+
+// dslTests are taken from sbt builds, ScalaTest suites and code using
+// cats syntax.
+var dslTests = []struct {
+	input    string
+	expected string
+}{
+	{`libraryDependencies += "org.typelevel" %% "cats-core" % "2.9.0" % Test`,
+		`(libraryDependencies += ((("org.typelevel" %% "cats-core") % "2.9.0") % Test))`},
+	{`scalacOptions ++= Seq("-deprecation", "-feature")`, `(scalacOptions ++= Seq("-deprecation", "-feature"))`},
+	{`name := "scalaparser"`, `(name := "scalaparser")`},
+	{"result should be (3)", "(result should be(3))"},
+	{"xs should contain only (1, 2)", "((xs should contain) only (1, 2))"},
+	{"x shouldBe >= (0)", "(x shouldBe >=(0))"},
+	{"an [IllegalStateException] should be thrownBy { f() }", "((an[IllegalStateException] should be) thrownBy {f()})"},
+	{"fa |+| fb |+| fc", "((fa |+| fb) |+| fc)"},
+	{"f >>> g <<< h", "((f >>> g) <<< h)"},
+	{"a :: b :: Nil", "(a :: (b :: Nil))"},
+	{"0 +: xs :+ 1", "((0 +: xs) :+ 1)"},
+	{"a +: b ++: c", "(a +: (b ++: c))"},
+	{"a + b * c == d && !e || f", "((((a + (b * c)) == d) && (!e)) || f)"},
+	{"x max y min z", "((x max y) min z)"},
+	{"-x * -1", "((-x) * -1)"},
+	{"~mask & bits ^ flags", "(((~mask) & bits) ^ flags)"},
+	{"xs.length - 1", "(xs.length - 1)"},
+	{"x += y * 2", "(x += (y * 2))"},
+	{"a(i) = b max c", "(a(i) = (b max c))"},
+	{"xs map[Int] f", "(xs map[Int] f)"},
+	{"(x: Int) + 1", "((x: Int) + 1)"},
+	{"xs sorted", "(xs sorted)"},
+	{"1 to 10 by 2", "((1 to 10) by 2)"},
+	{"a ==\n  b", "(a == b)"},
+	{"x - 1 :: y +: zs", "((x - 1) :: (y +: zs))"},
+	{"a + b :: c +: d", "((a + b) :: (c +: d))"},
+}
+
+func TestParseExpr(t *testing.T) {
+	for _, tests := range [][]struct{ input, expected string }{exprTests, dslTests} {
+		for _, test := range tests {
+			x, err := ParseExpr(test.input)
+			if err != nil {
+				t.Errorf("ParseExpr(%q): %v", test.input, err)
+				continue
+			}
+			if s := render(x); s != test.expected {
+				t.Errorf("ParseExpr(%q) = %s, Expected = %s", test.input, s, test.expected)
+			}
+		}
+	}
+}
+
+var exprErrorTests = []struct {
+	input    string
+	expected string
+}{
+	{"a +: b + c", "1:8: left- and right-associative operators with same precedence may not be mixed"},
+	{"a :: b +: c + d", "1:13: left- and right-associative operators with same precedence may not be mixed"},
+	{"(a + b", "1:7: expected ')', found end of file"},
+	{"for (y = 1; x <- xs) yield x", "1:6: for comprehension must start with a generator"},
+	{"f(x) 1", "1:6: expected end of expression, found '1'"},
+}
+
+func TestParseExprErrors(t *testing.T) {
+	for _, test := range exprErrorTests {
+		_, err := ParseExpr(test.input)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseExpr(%q) error = %v, Expected = %s", test.input, err, test.expected)
+		}
+	}
+}
+
+var patternTests = []struct {
+	input    string
+	expected string
+}{
+	{"h :: t", "(h :: t)"},
+	{"a :: b :: rest", "(a :: (b :: rest))"},
+	{"x @ Some(_)", "(x @ Some(_))"},
+	{"_: Int | _: Long", "((_: Int) | (_: Long))"},
+	{"List(a, rest @ _*)", "List(a, (rest @ _*))"},
+	{"x: Map[K, _]", "(x: Map[K, _])"},
+	{"-1 | 0 | 'c' | \"s\" | true | null", "(-1 | 0 | 'c' | \"s\" | true | null)"},
+	{"scala.None", "scala.None"},
+	{"this.Empty", "this.Empty"},
+	{"`x`", "x"},
+	{"(a, (b, _), c)", "(a, (b, _), c)"},
+	{"()", "()"},
+	{"Some((k, v))", "Some((k, v))"},
+	{"Seq(1, _*)", "Seq(1, _*)"},
+	{"Cons[Int](h, t)", "Cons[Int](h, t)"},
+	{"p @ (_: Int | _: Long)", "(p @ ((_: Int) | (_: Long)))"},
+	{"Some(1 | 2)", "Some((1 | 2))"},
+	{"init :+ last", "(init :+ last)"},
+	{"h +: t :+ l", "((h +: t) :+ l)"},
+	{"a ~ b ~ c", "((a ~ b) ~ c)"},
+	{`r"$year-$month"`, `r["" "-" ""][year month]`},
+	{"xs @ List(_, _)", "(xs @ List(_, _))"},
+	{"x: A | B", "((x: A) | B)"},
+	{"x: Either[A | B, C]", "(x: Either[(A | B), C])"},
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, test := range patternTests {
+		pat, err := ParsePattern(test.input)
+		if err != nil {
+			t.Errorf("ParsePattern(%q): %v", test.input, err)
+			continue
+		}
+		if s := render(pat); s != test.expected {
+			t.Errorf("ParsePattern(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+	}
+}
+
+func TestPatternVariables(t *testing.T) {
+	for _, test := range []struct {
+		input    string
+		variable bool
+	}{
+		{"x", true},
+		{"_", true},
+		{"_x", true},
+		{"None", false},
+		{"`x`", false},
+		{"Nil", false},
+	} {
+		src := "x match { case " + test.input + " => }"
+		m := parseStats(t, src)[0].(*ast.Match)
+		id, ok := m.Cases[0].Pat.(*ast.Ident)
+		if !ok {
+			t.Fatalf("pattern %s = %T, Expected *ast.Ident", test.input, m.Cases[0].Pat)
+		}
+		if id.IsVariable() != test.variable {
+			t.Errorf("%s.IsVariable() = %v, Expected = %v", test.input, id.IsVariable(), test.variable)
+		}
+	}
+}
+
+var typeTests = []struct {
+	input    string
+	expected string
+}{
+	{"Int => String", "((Int) => String)"},
+	{"(Int, Int) => Int", "((Int, Int) => Int)"},
+	{"A => B => C", "((A) => ((B) => C))"},
+	{"Either[String, Int]", "Either[String, Int]"},
+	{"A Either B", "(A Either B)"},
+	{"(A, B)", "(A, B)"},
+	{"() => Unit", "(() => Unit)"},
+	{"(=> Int) => Int", "((=> Int) => Int)"},
+	{"((A, B)) => C", "(((A, B)) => C)"},
+	{"(A) => B", "((A) => B)"},
+	{"Outer#Inner", "Outer#Inner"},
+	{"Outer#Inner[A]#T", "Outer#Inner[A]#T"},
+	{"x.type", "x.type"},
+	{"this.type", "this.type"},
+	{"a.b.type", "a.b.type"},
+	{"scala.collection.Seq[Int]", "scala.collection.Seq[Int]"},
+	{"A with B with C", "(A with B with C)"},
+	{"A with B { def f: Int; val x: String }", "(A with B {2 decls})"},
+	{"{ def close(): Unit }", "({1 decls})"},
+	{"T forSome { type T <: AnyRef }", "(T forSome {1 decls})"},
+	{"Map[_ <: K, _ >: V]", "Map[_ <: K, _ >: V]"},
+	{"Int @unchecked", "(Int @unchecked)"},
+	{"A @foo @bar", "(A @foo @bar)"},
+	{"A :: B :: HNil", "(A :: (B :: HNil))"},
+	{"Int Refined Positive", "(Int Refined Positive)"},
+	{"({ type L[X] = Either[E, X] })#L", "({1 decls})#L"},
+	{"A with B => C", "(((A with B)) => C)"},
+	{"A | B", "(A | B)"},
+	{"Either[A | B, C] | D", "(Either[(A | B), C] | D)"},
+}
+
+func TestParseType(t *testing.T) {
+	for _, test := range typeTests {
+		typ, err := ParseType(test.input)
+		if err != nil {
+			t.Errorf("ParseType(%q): %v", test.input, err)
+			continue
+		}
+		if s := render(typ); s != test.expected {
+			t.Errorf("ParseType(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+	}
+}
+
+// TestUnionTypes checks that | is an infix type operator outside of the
+// types of typed patterns.
+func TestUnionTypes(t *testing.T) {
+	stats := parseStats(t, "val x: A | B = y\ndef f(a: A | B): C | D = a\ntype T = A | B\nfor (x: A | B <- xs) yield x")
+	var types []string
+	for _, stat := range stats {
+		switch stat := stat.(type) {
+		case *ast.ValDef:
+			types = append(types, render(stat.Type))
+		case *ast.DefDef:
+			types = append(types, render(stat.Params[0].Params[0].Type), render(stat.ResultType))
+		case *ast.TypeDef:
+			types = append(types, render(stat.Rhs))
+		case *ast.For:
+			types = append(types, render(stat.Enums[0].(*ast.Generator).Pat))
+		}
+	}
+	expected := "[(A | B) (A | B) (C | D) (A | B) (x: (A | B))]"
+	if s := fmt.Sprint(types); s != expected {
+		t.Errorf("types = %s, Expected = %s", s, expected)
+	}
+}
+
+func TestParseStats(t *testing.T) {
+	stats, err := ParseStats("import a._\nval x = 1; def f = x\n\nprintln(f)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, stat := range stats {
+		kinds = append(kinds, fmt.Sprintf("%T", stat))
+	}
+	expected := "[*ast.Import *ast.ValDef *ast.DefDef *ast.Apply]"
+	if s := fmt.Sprint(kinds); s != expected {
+		t.Errorf("ParseStats = %s, Expected = %s", s, expected)
+	}
+}
+
+var snippetErrorTests = []struct {
+	parse    func(src string) (ast.Node, error)
+	input    string
+	expected string
+}{
+	{func(src string) (ast.Node, error) { return ParseType(src) }, "Int String", "1:5: expected end of type, found 'String'"},
+	{func(src string) (ast.Node, error) { return ParseType(src) }, "Map[K, V", "1:9: expected ']', found end of file"},
+	{func(src string) (ast.Node, error) { return ParsePattern(src) }, "Some(x) => y", "1:9: expected end of pattern, found '=>'"},
+	{func(src string) (ast.Node, error) { return ParsePattern(src) }, "x: Int = 1", "1:8: expected end of pattern, found '='"},
+	{func(src string) (ast.Node, error) { return ParseExpr(src) }, "a + b )", "1:7: closing paren found without a matching opening bracket: \")\""},
+	{func(src string) (ast.Node, error) { return ParseExpr(src) }, "a; b", "1:4: expected end of expression, found 'b'"},
+	{func(src string) (ast.Node, error) {
+		stats, err := ParseStats(src)
+		return &ast.Block{Stats: stats}, err
+	}, "val x = 1\ncase y => 2", "2:1: expected end of statement, found 'case'"},
+}
+
+func TestParseSnippetErrors(t *testing.T) {
+	for _, test := range snippetErrorTests {
+		n, err := test.parse(test.input)
+		if n == nil {
+			t.Errorf("parse(%q) returned no node", test.input)
+		}
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("parse(%q) error = %v, Expected = %s", test.input, err, test.expected)
+		}
+	}
+}
+
+const sampleFile = `package a.b
+package c
+
+import scala.collection.mutable.{Map => MMap, _}
+import java.util._, x.y
+
+/** A doc comment. */
+@deprecated("x", "1") sealed abstract class Foo[+A <: AnyRef : Ordering, B](val x: Int, var y: String = "s")(implicit ev: A =:= B) extends Bar[A](x) with Baz {
+  self: Qux =>
+  private[b] def f[T](a: T*)(b: => Int): List[T] = a.toList ::: Nil
+  lazy val (p, q) = (1, 2)
+  type T <: Seq[_ <: Int]
+  override def toString: String = s"foo $x ${y + 1} done"
+  def h(): Unit
+}
+
+case class P(x: Int)
+case object Q extends R
+object O {
+  def main(args: Array[String]): Unit = {
+    for (x <- xs if x > 1; y = x * 2) println(y)
+    val r = for {
+      a <- xs
+      b <- xs
+    } yield a + b
+    try f() catch { case e: Exception => () } finally g()
+    new Foo[Int](1) { def z = 2 }
+    do i -= 1 while (i > 0)
+    return
+  }
+}
+trait Tr[F[_]] { this: O.type => }
+`
+
+func TestParseFile(t *testing.T) {
+	f, err := ParseFile("sample.scala", sampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileEnd.Offset() != len(sampleFile) {
+		t.Errorf("FileEnd = %d, Expected = %d", f.FileEnd.Offset(), len(sampleFile))
+	}
+	if len(f.Stats) != 1 {
+		t.Fatalf("len(Stats) = %d, Expected = 1", len(f.Stats))
+	}
+	ab := f.Stats[0].(*ast.PackageClause)
+	if render(ab.Name) != "a.b" || len(ab.Stats) != 1 {
+		t.Fatalf("package %s has %d statements, Expected = a.b with 1", render(ab.Name), len(ab.Stats))
+	}
+	c := ab.Stats[0].(*ast.PackageClause)
+	var kinds []string
+	for _, stat := range c.Stats {
+		kinds = append(kinds, fmt.Sprintf("%T", stat))
+	}
+	expected := "[*ast.Import *ast.Import *ast.ClassDef *ast.ClassDef *ast.ObjectDef *ast.ObjectDef *ast.TraitDef]"
+	if s := fmt.Sprint(kinds); s != expected {
+		t.Errorf("Stats = %s, Expected = %s", s, expected)
+	}
+	foo := c.Stats[2].(*ast.ClassDef)
+	if len(foo.Annotations) != 1 || len(foo.Modifiers) != 2 || len(foo.Params) != 2 || foo.Params[1].Implicit == ast.NoPos {
+		t.Errorf("class Foo = %+v", foo)
+	}
+	if foo.Template.Self == nil || foo.Template.Self.Name.Name != "self" || len(foo.Template.Stats) != 5 {
+		t.Errorf("template of Foo = %+v", foo.Template)
+	}
+	if p := c.Stats[3].(*ast.ClassDef); p.Modifiers[0].Name != "case" || p.Pos().Offset() != strings.Index(sampleFile, "case class") {
+		t.Errorf("case class P = %+v", p)
+	}
+}
+
+func TestParseTypeParams(t *testing.T) {
+	src := "class C[+A, -B >: L <: H, V <% Ordered[V], D: Ordering: ClassTag, F[_], @specialized G]"
+	class := parseStats(t, src)[0].(*ast.ClassDef)
+	var tparams []string
+	for _, tp := range class.TypeParams {
+		s := tp.Variance + tp.Name.Name
+		if tp.TypeParams != nil {
+			s += fmt.Sprintf("[%d]", len(tp.TypeParams))
+		}
+		if tp.Lo != nil {
+			s += " >: " + render(tp.Lo)
+		}
+		if tp.Hi != nil {
+			s += " <: " + render(tp.Hi)
+		}
+		for _, v := range tp.ViewBounds {
+			s += " <% " + render(v)
+		}
+		for _, c := range tp.ContextBounds {
+			s += ": " + render(c)
+		}
+		if tp.Annotations != nil {
+			s = "@" + render(tp.Annotations[0].Init.Type) + " " + s
+		}
+		tparams = append(tparams, s)
+	}
+	expected := "+A, -B >: L <: H, V <% Ordered[V], D: Ordering: ClassTag, F[1], @specialized G"
+	if s := strings.Join(tparams, ", "); s != expected {
+		t.Errorf("type parameters = %s, Expected = %s", s, expected)
+	}
+	if end := class.TypeParams[3].End().Offset(); end != strings.Index(src, ", F") {
+		t.Errorf("D ends at %d, Expected = %d", end, strings.Index(src, ", F"))
+	}
+}
+
+// importedNames describes what imp brings into scope: for each selector
+// the member it imports and the name it is available under.
+func importedNames(imp *ast.Import) string {
+	var res []string
+	for _, importer := range imp.Importers {
+		path := ast.QualifiedName(importer.Path)
+		for _, sel := range importer.Selectors {
+			switch {
+			case sel.Given.IsValid() && sel.Type != nil:
+				res = append(res, path+".given "+render(sel.Type))
+			case sel.Given.IsValid():
+				res = append(res, path+".given")
+			case sel.IsWildcard():
+				res = append(res, path+"._")
+			case sel.IsHidden():
+				res = append(res, "-"+path+"."+sel.Name.Name)
+			default:
+				res = append(res, path+"."+sel.Name.Name+" as "+sel.Alias())
+			}
+		}
+	}
+	return strings.Join(res, ", ")
+}
+
+var importTests = []struct {
+	input    string
+	expected string
+}{
+	{"import a.b.c", "a.b.c as c"},
+	{"import a.b._", "a.b._"},
+	{"import a.b.{c, d => e, f => _, _}", "a.b.c as c, a.b.d as e, -a.b.f, a.b._"},
+	{"import a.b.{c => `type`}", "a.b.c as type"},
+	{"import java.util.{List => JList}, scala.collection.mutable", "java.util.List as JList, scala.collection.mutable as mutable"},
+	{"import a.b.*", "a.b._"},
+	{"import a.b.{c as d, e as _, *}", "a.b.c as d, -a.b.e, a.b._"},
+	{"import a.b as c", "a.b as c"},
+	{"import a.b.given", "a.b.given"},
+	{"import a.b.{given, *}", "a.b.given, a.b._"},
+	{"import a.b.{given Ordering[Int], given ExecutionContext}", "a.b.given Ordering[Int], a.b.given ExecutionContext"},
+	{"import a.b.{`*`, `_`}", "a.b.* as *, a.b._ as _"},
+	{"import collection.mutable", "collection.mutable as mutable"},
+	{"import _root_.scala.util.Try", "_root_.scala.util.Try as Try"},
+	{"import this.x", "this.x as x"},
+	{"import ctx.implicits._", "ctx.implicits._"},
+}
+
+func TestParseImport(t *testing.T) {
+	for _, test := range importTests {
+		stats := parseStats(t, test.input)
+		imp, ok := stats[0].(*ast.Import)
+		if len(stats) != 1 || !ok {
+			t.Errorf("ParseFile(%q) = %d statements, Expected an import", test.input, len(stats))
+			continue
+		}
+		if s := importedNames(imp); s != test.expected {
+			t.Errorf("ParseFile(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+		if end := imp.End().Offset(); end != len(test.input) {
+			t.Errorf("ParseFile(%q) ends at %d, Expected = %d", test.input, end, len(test.input))
+		}
+	}
+}
+
+// importPathTests are imports from paths through this and super.
+var importPathTests = []struct {
+	input    string
+	expected string
+}{
+	{"import A.this.b", "A.this.b as b"},
+	{"import A.this.b._", "A.this.b._"},
+	{"import A.super.b", "A.super.b as b"},
+	{"import A.super[T].b.{c, d}", "A.super[T].b.c as c, A.super[T].b.d as d"},
+	{"import super.b._", "super.b._"},
+}
+
+func TestParseImportPath(t *testing.T) {
+	for _, test := range importPathTests {
+		for _, parse := range []func(string, string) (*ast.File, error){ParseFile, ParseFileLALR} {
+			f, err := parse("test.scala", test.input)
+			if err != nil {
+				t.Errorf("parsing %q: %v", test.input, err)
+				continue
+			}
+			if s := importedNames(f.Stats[0].(*ast.Import)); s != test.expected {
+				t.Errorf("parsing %q = %s, Expected = %s", test.input, s, test.expected)
+			}
+		}
+	}
+	for _, src := range []string{"import A.this", "import A.super.b.this"} {
+		if _, err := ParseFile("test.scala", src); err == nil {
+			t.Errorf("ParseFile(%q): Expected an error", src)
+		}
+		if _, err := ParseFileLALR("test.scala", src); err == nil {
+			t.Errorf("ParseFileLALR(%q): Expected an error", src)
+		}
+	}
+}
+
+// outline summarizes a class, trait or object definition.
+func outline(stat ast.Stat) string {
+	var kind string
+	var name *ast.Ident
+	var annots []*ast.Annotation
+	var mods []*ast.Modifier
+	var tmpl *ast.Template
+	var params []*ast.ParamClause
+	var b strings.Builder
+	switch x := stat.(type) {
+	case *ast.ClassDef:
+		kind, name, annots, mods, tmpl, params = "class", x.Name, x.Annotations, x.Modifiers, x.Template, x.Params
+	case *ast.TraitDef:
+		kind, name, annots, mods, tmpl = "trait", x.Name, x.Annotations, x.Modifiers, x.Template
+	case *ast.ObjectDef:
+		kind, name, annots, mods, tmpl = "object", x.Name, x.Annotations, x.Modifiers, x.Template
+	default:
+		return fmt.Sprintf("%T", stat)
+	}
+	for _, a := range annots {
+		b.WriteString("@" + render(a.Init.Type) + " ")
+	}
+	for _, m := range mods {
+		b.WriteString(m.Name + " ")
+	}
+	b.WriteString(kind + " " + name.Name)
+	if class, ok := stat.(*ast.ClassDef); ok {
+		for _, a := range class.CtorAnnotations {
+			fmt.Fprintf(&b, " @%s%d", render(a.Init.Type), len(a.Init.Args))
+		}
+		for _, m := range class.CtorModifiers {
+			b.WriteString(" " + m.Name)
+		}
+	}
+	for _, clause := range params {
+		var ps []string
+		for _, param := range clause.Params {
+			s := param.Name.Name + ": " + render(param.Type)
+			if param.Keyword != "" {
+				s = param.Keyword + " " + s
+			}
+			for i := len(param.Modifiers) - 1; i >= 0; i-- {
+				s = param.Modifiers[i].Name + " " + s
+			}
+			ps = append(ps, s)
+		}
+		if clause.Implicit.IsValid() {
+			ps[0] = "implicit " + ps[0]
+		}
+		b.WriteString("(" + strings.Join(ps, ", ") + ")")
+	}
+	if tmpl.EarlyDefs != nil {
+		fmt.Fprintf(&b, " extends {%d} with", len(tmpl.EarlyDefs))
+	} else if tmpl.Extends.IsValid() {
+		b.WriteString(" extends")
+	}
+	for i, parent := range tmpl.Parents {
+		if i > 0 {
+			b.WriteString(" with")
+		}
+		b.WriteString(" " + render(parent.Type))
+		for _, args := range parent.Args {
+			b.WriteString(render(args))
+		}
+	}
+	if tmpl.Lbrace.IsValid() {
+		b.WriteString(" {")
+		if tmpl.Self != nil {
+			b.WriteString(" " + tmpl.Self.Name.Name)
+			if tmpl.Self.Type != nil {
+				b.WriteString(": " + render(tmpl.Self.Type))
+			}
+			b.WriteString(" =>")
+		}
+		fmt.Fprintf(&b, " %d }", len(tmpl.Stats))
+	}
+	return b.String()
+}
+
+var templateTests = []struct {
+	input    string
+	expected string
+}{
+	{"class A", "class A"},
+	{"class A()", "class A()"},
+	{"final case class Point(x: Int, y: Int = 0)", "final case class Point(x: Int, y: Int)"},
+	{"class C(val x: Int, var y: String, private val z: Long, override protected val w: T)",
+		"class C(val x: Int, var y: String, private val z: Long, override protected val w: T)"},
+	{"class C[A](a: A)(implicit ord: Ordering[A], val ct: ClassTag[A])", "class C(a: A)(implicit ord: Ordering[A], val ct: ClassTag[A])"},
+	{"class C(a: A)\n  (implicit ev: E)", "class C(a: A)(implicit ev: E)"},
+	{"class Service @Inject() (db: Db)", "class Service @Inject1(db: Db)"},
+	{"class Service @Inject()(db: Db)", "class Service @Inject1(db: Db)"},
+	{"class Singleton private ()", "class Singleton private()"},
+	{"class Cache @volatile private[cache] (size: Int)", "class Cache @volatile0 private(size: Int)"},
+	{"@SerialVersionUID(1L) sealed abstract class Expr extends Product with Serializable",
+		"@SerialVersionUID sealed abstract class Expr extends Product with Serializable"},
+	{"class Sub(x: Int) extends Base(x, \"sub\")(ec) with Logging with Ordered[Sub] {\n  def compare(that: Sub) = 0\n}",
+		`class Sub(x: Int) extends Base(x, "sub")(ec) with Logging with Ordered[Sub] { 1 }`},
+	{"class A extends { val x = 1; type T = Int } with B with C { def f = x }", "class A extends {2} with B with C { 1 }"},
+	{"class A extends {\n  val x = 1\n}", "class A extends { 1 }"},
+	{"trait Service { self: Logging with Config =>\n  def run(): Unit\n}", "trait Service { self: (Logging with Config) => 1 }"},
+	{"trait T { this: A => }", "trait T { this: A => 0 }"},
+	{"trait T { _: A => def f = 1 }", "trait T { _: A => 1 }"},
+	{"class Outer { outer =>\n  class Inner\n}", "class Outer { outer => 1 }"},
+	{"trait Monad[F[_]] extends Functor[F]", "trait Monad extends Functor[F]"},
+	{"case object Empty extends Stack[Nothing]", "case object Empty extends Stack[Nothing]"},
+	{"object Main extends App {\n  println(args.length)\n  val x = 1\n}", "object Main extends App { 2 }"},
+	{"object Main extends App\n{\n  run()\n}", "object Main extends App { 1 }"},
+	{"package object util extends Helpers { type Id = String }", "object util extends Helpers { 1 }"},
+	{"implicit class RichInt(private val n: Int) extends AnyVal", "implicit class RichInt(private val n: Int) extends AnyVal"},
+	{"class A {\n  def this(x: Int) = this()\n  def this(s: String) { this(s.length) }\n}", "class A { 2 }"},
+}
+
+func TestParseTemplates(t *testing.T) {
+	for _, test := range templateTests {
+		stats := parseStats(t, test.input)
+		if len(stats) != 1 {
+			t.Errorf("ParseFile(%q) = %d statements, Expected = 1", test.input, len(stats))
+			continue
+		}
+		if s := outline(stats[0]); s != test.expected {
+			t.Errorf("ParseFile(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+		if end := stats[0].End().Offset(); end != len(test.input) {
+			t.Errorf("ParseFile(%q) ends at %d, Expected = %d", test.input, end, len(test.input))
+		}
+	}
+}
+
+func renderAnnotations(annots []*ast.Annotation) string {
+	var res []string
+	for _, a := range annots {
+		s := "@" + a.Name()
+		if a.Name() == "" {
+			s = "@" + render(a.Init.Type)
+		}
+		for _, args := range a.Init.Args {
+			s += render(args)
+		}
+		res = append(res, s)
+	}
+	return strings.Join(res, " ")
+}
+
+var annotationTests = []struct {
+	input    string
+	annots   func(stat ast.Stat) []*ast.Annotation
+	expected string
+}{
+	{`@deprecated(message = "use g", since = "1.0") def f = 1`,
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.DefDef).Annotations },
+		`@deprecated((message = "use g"), (since = "1.0"))`},
+	{`@throws[java.io.IOException]("io") def read(): Int`,
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.DefDef).Annotations },
+		`@throws("io")`},
+	{"@SerialVersionUID(1L)\n@deprecated\nclass A",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.ClassDef).Annotations },
+		"@SerialVersionUID(1L) @deprecated"},
+	{"@Ann(1)(b = 2) @scala.annotation.tailrec() class B",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.ClassDef).Annotations },
+		"@Ann(1)((b = 2)) @scala.annotation.tailrec()"},
+	{"class C(@transient val x: Int, @(transient @field) y: Int)",
+		func(stat ast.Stat) []*ast.Annotation {
+			params := stat.(*ast.ClassDef).Params[0].Params
+			return append(params[0].Annotations, params[1].Annotations...)
+		},
+		"@transient @(transient @field)"},
+	{"class Service @Inject() (db: Db)",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.ClassDef).CtorAnnotations },
+		"@Inject()"},
+	{"def f(@unused x: Int)(@deprecatedName('y) z: Int) = x",
+		func(stat ast.Stat) []*ast.Annotation {
+			params := stat.(*ast.DefDef).Params
+			return append(params[0].Params[0].Annotations, params[1].Params[0].Annotations...)
+		},
+		"@unused @deprecatedName('y)"},
+	{"class S[@specialized(Int, Long) T]",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.ClassDef).TypeParams[0].Annotations },
+		"@specialized(Int, Long)"},
+	{"val x: Int @unchecked = 1",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.ValDef).Type.(*ast.AnnotatedType).Annotations },
+		"@unchecked"},
+	{"x match { case _: List[String] @unchecked => }",
+		func(stat ast.Stat) []*ast.Annotation {
+			pat := stat.(*ast.Match).Cases[0].Pat.(*ast.TypedPattern)
+			return pat.Type.(*ast.AnnotatedType).Annotations
+		},
+		"@unchecked"},
+	{"(x: @switch @nowarn) match { case 1 => }",
+		func(stat ast.Stat) []*ast.Annotation { return stat.(*ast.Match).X.(*ast.Annotated).Annotations },
+		"@switch @nowarn"},
+	{"def loop(n: Int): Int = {\n  @tailrec\n  def go(i: Int): Int = go(i)\n  go(n)\n}",
+		func(stat ast.Stat) []*ast.Annotation {
+			return stat.(*ast.DefDef).Rhs.(*ast.Block).Stats[0].(*ast.DefDef).Annotations
+		},
+		"@tailrec"},
+	{"class A { @volatile private[this] var v = 0 }",
+		func(stat ast.Stat) []*ast.Annotation {
+			return stat.(*ast.ClassDef).Template.Stats[0].(*ast.ValDef).Annotations
+		},
+		"@volatile"},
+}
+
+func TestParseAnnotations(t *testing.T) {
+	for _, test := range annotationTests {
+		stats := parseStats(t, test.input)
+		if s := renderAnnotations(test.annots(stats[0])); s != test.expected {
+			t.Errorf("annotations of %q = %s, Expected = %s", test.input, s, test.expected)
+		}
+	}
+}
+
+const packagesFile = `package com.example
+package app
+
+import scala.util.Try
+
+class Main
+package object util {
+  type Id = String
+}
+package model {
+  case class User(id: Int)
+  package db.sql {
+    trait Repo
+  }
+}
+object Main
+package other { object X }
+def helper = 1
+`
+
+func TestTopLevelDefs(t *testing.T) {
+	f, err := ParseFile("packages.scala", packagesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, def := range f.TopLevelDefs() {
+		names = append(names, def.Package+" "+def.FullName())
+	}
+	expected := []string{
+		"com.example.app com.example.app.Main",
+		"com.example.app com.example.app.util.package",
+		"com.example.app.model com.example.app.model.User",
+		"com.example.app.model.db.sql com.example.app.model.db.sql.Repo",
+		"com.example.app com.example.app.Main",
+		"com.example.app.other com.example.app.other.X",
+		"com.example.app com.example.app.helper",
+	}
+	if s := strings.Join(names, "\n"); s != strings.Join(expected, "\n") {
+		t.Errorf("TopLevelDefs =\n%s\nExpected =\n%s", s, strings.Join(expected, "\n"))
+	}
+
+	f, err = ParseFile("script.sc", "val (a, b) = (1, 2)\nclass C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs := f.TopLevelDefs()
+	if len(defs) != 2 || defs[0].FullName() != "" || defs[1].Package != "" || defs[1].FullName() != "C" {
+		t.Errorf("TopLevelDefs of a script = %+v", defs)
+	}
+
+	def := ast.TopLevelDef{Package: "p", Def: &ast.ValDef{Keyword: "val"}}
+	if name := def.FullName(); name != "" {
+		t.Errorf("FullName of a val without patterns = %q, Expected = \"\"", name)
+	}
+}
+
+var errorTests = []struct {
+	input    string
+	expected string
+}{
+	{"val x = ", "test.scala:1:9: expected expression, found end of file"},
+	{"class A {\n  def f = (1, 2)\n  def g =\n}", "test.scala:4:1: expected expression, found '}'"},
+	{"val x = 1\n)", "test.scala:2:1: closing paren found without a matching opening bracket: \")\""},
+	{"a match { case x => 1 else }", "test.scala:1:23: expected end of statement, found 'else'"},
+	{"import a", "test.scala:1:8: import of a single name a"},
+	{"x match { case Some(X: Int) => }", "test.scala:1:21: pattern variable X must start with a lower case letter"},
+	{"import a.{_, b}", "test.scala:1:11: wildcard import must be in last position"},
+	{"type T = (=> Int, String)", "test.scala:1:11: by-name type outside of function parameters"},
+	{"x match { case Nil @ _ => }", "test.scala:1:16: pattern variable Nil must start with a lower case letter"},
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range errorTests {
+		_, err := ParseFile("test.scala", test.input)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseFile(%q) error = %v, Expected = %s", test.input, err, test.expected)
+		}
+	}
+}
+
+const brokenFile = `object A {
+  val x = 1 + * 2
+  def f(a: Int) = a * 2
+  val y = if x then 1 else 2
+  class B extends C { val z = => }
+  def g = f(3)
+}
+`
+
+func TestParseRecovery(t *testing.T) {
+	f, err := ParseFile("broken.scala", brokenFile)
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("ParseFile error = %v, Expected an ErrorList", err)
+	}
+	expected := []string{
+		"broken.scala:2:17: expected end of statement, found '2'",
+		"broken.scala:4:14: expected '(', found 'x'",
+		"broken.scala:5:31: expected expression, found '=>'",
+	}
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	if strings.Join(msgs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("errors =\n%s\nExpected =\n%s", strings.Join(msgs, "\n"), strings.Join(expected, "\n"))
+	}
+	if len(f.Stats) != 1 {
+		t.Fatalf("Stats = %d, Expected = 1", len(f.Stats))
+	}
+	var kinds []string
+	for _, stat := range f.Stats[0].(*ast.ObjectDef).Template.Stats {
+		kinds = append(kinds, fmt.Sprintf("%T", stat))
+	}
+	expectedKinds := "[*ast.ValDef *ast.BadStat *ast.DefDef *ast.ValDef *ast.ClassDef *ast.DefDef]"
+	if s := fmt.Sprint(kinds); s != expectedKinds {
+		t.Errorf("Stats of A = %s, Expected = %s", s, expectedKinds)
+	}
+}
+
+var lalrErrorTests = []struct {
+	input    string
+	expected string
+}{
+	{"val x = ", "test.scala:1:9: unexpected end of file"},
+	{"class A {\n  def f = (1, 2)\n  def g =\n}", "test.scala:4:1: unexpected '}'"},
+	{"val x = 1\n)", "test.scala:2:1: closing paren found without a matching opening bracket: \")\""},
+	{"a match { case x => 1 else }", "test.scala:1:23: unexpected 'else'"},
+	{"import a", "test.scala:1:8: import of a single name a"},
+}
+
+func TestParseFileLALR(t *testing.T) {
+	for _, test := range exprTests {
+		f, err := ParseFileLALR("test.scala", test.input)
+		if err != nil {
+			t.Errorf("ParseFileLALR(%q): %v", test.input, err)
+			continue
+		}
+		if s := render(f.Stats[0]); s != test.expected {
+			t.Errorf("ParseFileLALR(%q) = %s, Expected = %s", test.input, s, test.expected)
+		}
+	}
+	for _, test := range lalrErrorTests {
+		_, err := ParseFileLALR("test.scala", test.input)
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("ParseFileLALR(%q) error = %v, Expected = %s", test.input, err, test.expected)
+		}
+	}
+}
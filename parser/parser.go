@@ -0,0 +1,2620 @@
+// Code generated by goyacc -o parser.go -v  parser.go.y. DO NOT EDIT.
+
+//line parser.go.y:2
+package parser
+
+import __yyfmt__ "fmt"
+
+//line parser.go.y:2
+
+import "github.com/sundargates/scalaparser/ast"
+
+// The grammar follows the syntax summary of the Scala 2.13 language
+// specification. Where the summary is not LALR(1) the grammar accepts a
+// superset and the actions check the rest:
+//   - infix expressions, types and patterns are parsed as flat chains and
+//     precedence is applied afterwards, see infixChain,
+//   - the parameters of anonymous functions are parsed as expressions and
+//     converted when "=>" is seen,
+//   - chained package clauses are nested by nestPackages.
+// yyLex resolves the spots that need a second token of lookahead.
+
+type bounds struct {
+	lo, hi ast.Type
+}
+
+type tparamBounds struct {
+	bounds
+	views, contexts []ast.Type
+}
+
+func lex(yylex yyLexer) *yyLex {
+	return yylex.(*yyLex)
+}
+
+//line parser.go.y:30
+type yySymType struct {
+	yys        int
+	item       *item
+	expr       ast.Expr
+	exprs      []ast.Expr
+	stat       ast.Stat
+	stats      []ast.Stat
+	typ        ast.Type
+	types      []ast.Type
+	pat        ast.Pattern
+	pats       []ast.Pattern
+	ident      *ast.Ident
+	args       *ast.ArgList
+	argss      []*ast.ArgList
+	caseClause *ast.CaseClause
+	cases      []*ast.CaseClause
+	enum       ast.Enumerator
+	enums      []ast.Enumerator
+	mod        *ast.Modifier
+	mods       []*ast.Modifier
+	annot      *ast.Annotation
+	annots     []*ast.Annotation
+	param      *ast.Param
+	params     []*ast.Param
+	clause     *ast.ParamClause
+	clauses    []*ast.ParamClause
+	tparam     *ast.TypeParam
+	tparams    []*ast.TypeParam
+	tmpl       *ast.Template
+	init       *ast.Init
+	inits      []*ast.Init
+	importer   *ast.Importer
+	importers  []*ast.Importer
+	selector   *ast.ImportSelector
+	selectors  []*ast.ImportSelector
+	chain      *infixChain
+	self       *ast.SelfType
+	bounds     bounds
+	tbounds    tparamBounds
+	prefix     *defPrefix
+}
+
+const tSTART_FILE = 57346
+const tSTART_EXPR = 57347
+const tIDENT = 57348
+const tUSCORE = 57349
+const tUSCORE_STAR = 57350
+const tSTAR = 57351
+const tPIPE = 57352
+const tPLUS = 57353
+const tMINUS = 57354
+const tBANG = 57355
+const tTILDE = 57356
+const tNUMBER = 57357
+const tSTRING = 57358
+const tCHAR = 57359
+const tSYMBOL = 57360
+const tBOOLEAN = 57361
+const tNULL = 57362
+const tINTERP = 57363
+const tNL = 57364
+const tNLS = 57365
+const tSEMI = 57366
+const tDOT = 57367
+const tCOMMA = 57368
+const tCOLON = 57369
+const tEQ = 57370
+const tARROW = 57371
+const tLARROW = 57372
+const tSUBTYPE = 57373
+const tSUPERTYPE = 57374
+const tVIEWBOUND = 57375
+const tHASH = 57376
+const tAT = 57377
+const tLPAREN = 57378
+const tRPAREN = 57379
+const tLBRACK = 57380
+const tRBRACK = 57381
+const tLBRACE = 57382
+const tRBRACE = 57383
+const tABSTRACT = 57384
+const tCASE = 57385
+const tCASECLASS = 57386
+const tCASEOBJECT = 57387
+const tCATCH = 57388
+const tCLASS = 57389
+const tDEF = 57390
+const tDO = 57391
+const tELSE = 57392
+const tEXTENDS = 57393
+const tFINAL = 57394
+const tFINALLY = 57395
+const tFOR = 57396
+const tFORSOME = 57397
+const tIF = 57398
+const tIMPLICIT = 57399
+const tIMPORT = 57400
+const tLAZY = 57401
+const tMATCH = 57402
+const tNEW = 57403
+const tOBJECT = 57404
+const tOVERRIDE = 57405
+const tPACKAGE = 57406
+const tPRIVATE = 57407
+const tPROTECTED = 57408
+const tRETURN = 57409
+const tSEALED = 57410
+const tSUPER = 57411
+const tTHIS = 57412
+const tTHROW = 57413
+const tTRAIT = 57414
+const tTRY = 57415
+const tTYPE = 57416
+const tVAL = 57417
+const tVAR = 57418
+const tWHILE = 57419
+const tWITH = 57420
+const tYIELD = 57421
+const pLOW = 57422
+
+var yyToknames = [...]string{
+	"$end",
+	"error",
+	"$unk",
+	"tSTART_FILE",
+	"tSTART_EXPR",
+	"tIDENT",
+	"tUSCORE",
+	"tUSCORE_STAR",
+	"tSTAR",
+	"tPIPE",
+	"tPLUS",
+	"tMINUS",
+	"tBANG",
+	"tTILDE",
+	"tNUMBER",
+	"tSTRING",
+	"tCHAR",
+	"tSYMBOL",
+	"tBOOLEAN",
+	"tNULL",
+	"tINTERP",
+	"tNL",
+	"tNLS",
+	"tSEMI",
+	"tDOT",
+	"tCOMMA",
+	"tCOLON",
+	"tEQ",
+	"tARROW",
+	"tLARROW",
+	"tSUBTYPE",
+	"tSUPERTYPE",
+	"tVIEWBOUND",
+	"tHASH",
+	"tAT",
+	"tLPAREN",
+	"tRPAREN",
+	"tLBRACK",
+	"tRBRACK",
+	"tLBRACE",
+	"tRBRACE",
+	"tABSTRACT",
+	"tCASE",
+	"tCASECLASS",
+	"tCASEOBJECT",
+	"tCATCH",
+	"tCLASS",
+	"tDEF",
+	"tDO",
+	"tELSE",
+	"tEXTENDS",
+	"tFINAL",
+	"tFINALLY",
+	"tFOR",
+	"tFORSOME",
+	"tIF",
+	"tIMPLICIT",
+	"tIMPORT",
+	"tLAZY",
+	"tMATCH",
+	"tNEW",
+	"tOBJECT",
+	"tOVERRIDE",
+	"tPACKAGE",
+	"tPRIVATE",
+	"tPROTECTED",
+	"tRETURN",
+	"tSEALED",
+	"tSUPER",
+	"tTHIS",
+	"tTHROW",
+	"tTRAIT",
+	"tTRY",
+	"tTYPE",
+	"tVAL",
+	"tVAR",
+	"tWHILE",
+	"tWITH",
+	"tYIELD",
+	"pLOW",
+}
+
+var yyStatenames = [...]string{}
+
+const yyEofCode = 1
+const yyErrCode = 2
+const yyInitialStackSize = 16
+
+//line parser.go.y:1467
+
+//line yacctab:1
+var yyExca = [...]int16{
+	-1, 1,
+	1, -1,
+	-2, 0,
+	-1, 260,
+	27, 153,
+	29, 153,
+	-2, 193,
+	-1, 261,
+	27, 154,
+	29, 154,
+	-2, 213,
+	-1, 262,
+	27, 155,
+	29, 155,
+	-2, 224,
+}
+
+const yyPrivate = 57344
+
+const yyLast = 1341
+
+var yyAct = [...]int16{
+	11, 10, 177, 67, 21, 506, 505, 500, 449, 380,
+	403, 311, 463, 477, 217, 80, 180, 91, 99, 228,
+	227, 91, 91, 414, 252, 20, 202, 240, 91, 91,
+	91, 91, 91, 91, 258, 218, 79, 4, 406, 312,
+	134, 137, 178, 140, 142, 256, 165, 151, 226, 53,
+	152, 201, 200, 31, 301, 210, 105, 208, 323, 97,
+	168, 169, 164, 106, 74, 221, 239, 464, 89, 156,
+	191, 255, 116, 118, 172, 44, 478, 76, 77, 75,
+	121, 122, 123, 124, 125, 181, 182, 5, 153, 418,
+	163, 205, 91, 70, 45, 46, 71, 72, 92, 93,
+	94, 95, 135, 175, 161, 12, 73, 103, 103, 179,
+	155, 485, 155, 373, 203, 204, 108, 280, 328, 402,
+	110, 219, 166, 61, 62, 63, 64, 65, 66, 111,
+	489, 404, 342, 233, 234, 76, 77, 75, 173, 404,
+	220, 131, 130, 186, 113, 238, 428, 91, 91, 91,
+	253, 374, 237, 70, 352, 281, 71, 72, 12, 277,
+	264, 332, 174, 169, 242, 241, 241, 362, 377, 348,
+	166, 229, 337, 160, 91, 91, 211, 12, 114, 214,
+	215, 216, 335, 179, 334, 268, 207, 166, 254, 91,
+	411, 99, 91, 91, 91, 251, 213, 408, 244, 246,
+	248, 285, 91, 278, 183, 128, 243, 230, 336, 437,
+	91, 269, 313, 322, 296, 474, 101, 100, 270, 283,
+	466, 385, 263, 266, 436, 273, 274, 91, 91, 91,
+	304, 326, 370, 131, 130, 179, 156, 356, 344, 345,
+	284, 235, 185, 289, 292, 293, 282, 286, 355, 367,
+	300, 297, 338, 339, 354, 153, 343, 184, 12, 298,
+	317, 307, 179, 229, 309, 353, 209, 327, 324, 324,
+	360, 329, 156, 369, 325, 144, 368, 371, 330, 275,
+	307, 158, 131, 130, 295, 127, 397, 12, 320, 143,
+	278, 490, 91, 131, 130, 386, 341, 128, 376, 396,
+	173, 112, 112, 363, 347, 502, 361, 454, 347, 392,
+	162, 155, 229, 417, 55, 375, 316, 401, 131, 130,
+	372, 520, 219, 129, 319, 390, 391, 155, 55, 269,
+	70, 389, 519, 71, 72, 92, 93, 94, 95, 278,
+	173, 482, 405, 381, 47, 351, 128, 302, 129, 162,
+	422, 423, 481, 400, 388, 409, 410, 128, 387, 394,
+	12, 91, 91, 138, 407, 416, 340, 139, 334, 133,
+	419, 236, 85, 84, 241, 420, 424, 83, 85, 84,
+	223, 359, 128, 12, 222, 429, 427, 91, 91, 132,
+	30, 434, 395, 147, 148, 149, 150, 254, 331, 112,
+	313, 319, 430, 305, 86, 55, 451, 91, 433, 444,
+	86, 194, 425, 426, 56, 459, 443, 460, 461, 462,
+	194, 452, 455, 179, 112, 112, 497, 383, 413, 179,
+	109, 109, 496, 457, 458, 468, 91, 465, 439, 440,
+	384, 91, 91, 476, 438, 91, 456, 471, 76, 77,
+	75, 421, 112, 412, 357, 91, 445, 446, 450, 350,
+	479, 349, 145, 346, 491, 486, 492, 358, 272, 212,
+	12, 431, 131, 130, 176, 146, 12, 278, 112, 366,
+	112, 112, 348, 112, 334, 451, 91, 469, 507, 302,
+	510, 499, 381, 472, 498, 333, 475, 91, 272, 91,
+	303, 30, 129, 494, 501, 480, 484, 189, 179, 271,
+	91, 518, 80, 522, 12, 534, 523, 513, 514, 198,
+	190, 157, 507, 517, 501, 528, 91, 525, 109, 170,
+	197, 531, 533, 79, 529, 535, 128, 450, 532, 76,
+	77, 75, 171, 81, 277, 223, 30, 501, 511, 222,
+	512, 501, 126, 109, 109, 524, 30, 515, 487, 232,
+	231, 521, 365, 32, 364, 26, 29, 467, 25, 17,
+	442, 12, 308, 33, 294, 314, 315, 527, 82, 530,
+	35, 109, 526, 28, 36, 277, 45, 46, 112, 34,
+	112, 192, 193, 27, 503, 18, 15, 16, 70, 30,
+	483, 71, 72, 92, 93, 94, 95, 109, 276, 109,
+	109, 306, 109, 70, 58, 187, 71, 72, 49, 48,
+	50, 51, 61, 62, 63, 64, 65, 66, 59, 495,
+	493, 441, 70, 112, 432, 71, 72, 92, 93, 94,
+	95, 321, 30, 60, 232, 231, 225, 55, 224, 32,
+	166, 26, 29, 206, 25, 17, 39, 188, 415, 33,
+	90, 41, 247, 37, 23, 14, 35, 196, 52, 28,
+	36, 13, 45, 46, 43, 34, 69, 68, 42, 27,
+	40, 18, 15, 16, 38, 120, 70, 58, 88, 71,
+	72, 49, 48, 50, 51, 61, 62, 63, 64, 65,
+	66, 59, 304, 516, 76, 77, 75, 76, 77, 75,
+	9, 76, 77, 75, 102, 30, 60, 109, 447, 109,
+	55, 78, 32, 470, 26, 29, 435, 25, 17, 39,
+	378, 115, 33, 277, 41, 195, 37, 23, 14, 35,
+	119, 52, 28, 36, 13, 45, 46, 43, 34, 69,
+	68, 42, 27, 40, 18, 15, 16, 38, 398, 399,
+	260, 261, 109, 71, 72, 49, 48, 50, 51, 61,
+	62, 63, 64, 65, 66, 59, 2, 3, 76, 77,
+	75, 76, 77, 75, 1, 257, 379, 96, 154, 30,
+	60, 310, 453, 318, 55, 504, 32, 267, 26, 29,
+	448, 25, 17, 39, 279, 393, 33, 159, 41, 259,
+	37, 23, 14, 35, 24, 52, 28, 36, 13, 45,
+	46, 43, 34, 69, 262, 42, 27, 40, 18, 15,
+	16, 38, 70, 58, 87, 71, 72, 49, 48, 50,
+	51, 61, 62, 63, 64, 65, 66, 59, 76, 77,
+	75, 98, 488, 141, 57, 54, 22, 8, 203, 204,
+	108, 7, 60, 19, 110, 6, 55, 61, 62, 63,
+	64, 65, 66, 111, 0, 39, 0, 0, 0, 0,
+	41, 0, 37, 136, 0, 0, 0, 52, 113, 299,
+	0, 0, 0, 43, 0, 69, 68, 42, 0, 40,
+	0, 70, 58, 38, 71, 72, 49, 48, 50, 51,
+	61, 62, 63, 64, 65, 66, 59, 30, 0, 0,
+	0, 0, 114, 0, 32, 0, 0, 0, 0, 0,
+	0, 60, 265, 0, 33, 55, 0, 0, 0, 82,
+	0, 35, 0, 0, 39, 36, 0, 45, 46, 41,
+	34, 37, 136, 0, 0, 0, 52, 508, 509, 0,
+	0, 0, 43, 0, 69, 68, 42, 0, 40, 0,
+	70, 58, 38, 71, 72, 49, 48, 50, 51, 61,
+	62, 63, 64, 65, 66, 59, 70, 0, 0, 71,
+	72, 92, 93, 94, 95, 0, 203, 204, 108, 0,
+	60, 167, 110, 0, 55, 61, 62, 63, 64, 65,
+	66, 111, 0, 39, 0, 30, 0, 0, 41, 0,
+	37, 136, 0, 0, 0, 52, 113, 199, 0, 0,
+	0, 43, 0, 69, 68, 42, 0, 40, 0, 70,
+	58, 38, 71, 72, 49, 48, 50, 51, 61, 62,
+	63, 64, 65, 66, 59, 0, 0, 0, 0, 0,
+	114, 0, 0, 0, 0, 203, 204, 108, 0, 60,
+	0, 110, 0, 55, 61, 62, 63, 64, 65, 66,
+	111, 0, 39, 0, 0, 0, 0, 41, 0, 37,
+	136, 0, 0, 0, 52, 113, 0, 0, 0, 0,
+	43, 0, 69, 68, 42, 0, 40, 0, 70, 58,
+	38, 71, 72, 49, 48, 50, 51, 61, 62, 63,
+	64, 65, 66, 59, 0, 0, 0, 0, 0, 114,
+	0, 0, 81, 0, 0, 0, 0, 0, 60, 0,
+	0, 0, 55, 70, 473, 30, 71, 72, 92, 93,
+	94, 95, 32, 70, 382, 0, 71, 72, 92, 93,
+	94, 95, 33, 52, 0, 0, 0, 82, 0, 35,
+	0, 69, 68, 36, 0, 45, 46, 0, 34, 70,
+	58, 0, 71, 72, 0, 508, 509, 0, 61, 62,
+	63, 64, 65, 66, 59, 70, 287, 0, 71, 72,
+	92, 93, 94, 95, 0, 131, 107, 108, 0, 60,
+	0, 110, 0, 55, 61, 62, 63, 64, 65, 66,
+	111, 0, 0, 0, 0, 0, 0, 0, 0, 288,
+	0, 0, 0, 0, 52, 113, 0, 0, 104, 107,
+	108, 0, 69, 68, 110, 0, 0, 61, 62, 63,
+	64, 65, 66, 111, 0, 0, 0, 70, 291, 290,
+	71, 72, 92, 93, 94, 95, 0, 70, 113, 114,
+	71, 72, 92, 93, 94, 95, 70, 0, 0, 71,
+	72, 92, 93, 94, 95, 70, 0, 0, 71, 72,
+	92, 93, 94, 95, 0, 0, 0, 0, 0, 0,
+	0, 0, 114, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	250, 249, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 245, 0, 0, 0, 0, 0, 0, 0, 0,
+	117,
+}
+
+var yyPact = [...]int16{
+	772, -1000, 680, -1000, 759, -1000, -1000, -1000, -1000, -1000,
+	521, -1000, 350, 626, 147, 1232, 1232, 1270, 1279, -1000,
+	-1000, -1000, -1000, 734, 1279, 1279, 1279, 1279, 1279, 1279,
+	312, -1000, -1000, -1000, -1000, -1000, -1000, 353, 333, 1033,
+	1033, 327, 1033, 1033, -1000, 251, 237, 437, 1173, 1173,
+	1173, 1173, 287, -1000, 274, 607, -1000, -1000, -1000, -1000,
+	964, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 504,
+	-1000, -1000, -1000, 826, 680, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, 466, 1033, 1033, 164, 217, 1279, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 589, -1000, 632, -1000,
+	-1000, 482, 565, -1000, 385, 729, -1000, -1000, -1000, -1000,
+	652, -1000, 494, 990, 628, 565, 228, -1000, 228, 440,
+	1102, 228, 228, 228, 70, 70, 346, 623, 621, 227,
+	528, -1000, 1033, 1033, -1000, 344, 734, 99, 108, 108,
+	-1000, -1000, -1000, 1261, 592, 1251, 227, 437, 437, 437,
+	437, -1000, 72, -7, -1000, 754, -1000, 511, -1000, -1000,
+	-1000, -1000, 895, 756, 144, -1000, 1059, -1000, 472, -1000,
+	1279, 1279, -1000, -1000, -1000, 579, -1000, 355, -1000, -1000,
+	77, -1000, -1000, 79, 680, 1279, 70, 147, 1189, 1279,
+	1279, 546, 1232, 227, 1199, 1199, -1000, 852, 1279, -1000,
+	463, 692, -1000, 376, 584, -1000, 1279, 544, -1000, 564,
+	288, 613, 1033, -1000, 29, 29, 70, -1000, -1000, 287,
+	-1000, 313, 227, 1279, 324, 87, 458, -1000, 153, 135,
+	-1000, 227, 227, 329, 259, 55, 466, 1033, 1033, 426,
+	-1000, 431, 1102, 113, 226, 215, 209, 198, -1000, -1000,
+	429, 342, -1000, 312, -1000, 312, 126, 680, 759, 535,
+	-1000, -1000, -1000, 313, 355, -1000, 442, -1000, -1000, -1000,
+	220, -1000, 1033, -1000, 193, 759, 1033, 312, -1000, 73,
+	680, 312, 127, 689, -1000, -1000, -1000, -1000, 1147, -1000,
+	-1000, 402, -1000, 182, 1033, -1000, -1000, 729, -1000, -1000,
+	321, -1000, 1059, -1000, 1059, 312, 312, -1000, 1033, 365,
+	260, -1000, 752, 564, -1000, -1000, 1033, -1000, -1000, 82,
+	-1000, 227, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 158,
+	-1000, -1000, -1000, -1000, 227, 227, 150, 424, 397, -1000,
+	636, 636, 277, 727, -1000, 36, 636, 108, 1102, 1033,
+	1033, -1000, 636, -1000, -1000, -1000, -1000, 1279, 1279, -1000,
+	-1000, 72, -1000, 105, -1000, 312, -1000, 680, 1102, -1000,
+	609, -1000, -1000, 680, 312, 685, -1000, -1000, -1000, 183,
+	-1000, 415, -1000, 1279, 1279, 606, -1000, -1000, 692, -1000,
+	727, 727, -1000, 542, -1000, 227, -1000, 564, 228, 228,
+	712, -1000, -1000, 980, -1000, -1000, 271, 271, -1000, -1000,
+	-1000, 680, 227, 227, 1033, -1000, 1033, 1033, 1033, -12,
+	-1000, -1000, -1000, -1000, -12, -1000, 181, -1000, -1000, 538,
+	759, 406, 1279, 682, -1000, -1000, -1000, 1147, 1137, -1000,
+	176, 1279, 1033, -1000, -1000, 528, 528, 228, 315, -1000,
+	573, 980, -1000, -1000, 74, -1000, 517, -1000, -1000, 80,
+	-1000, 254, -1000, 1033, -1000, 1033, 605, -1000, 680, -1000,
+	-1000, -1000, -1000, -1000, 604, -1000, -1000, 399, -1000, 399,
+	528, -1000, 980, 276, 567, -1000, 882, -1000, -1000, 1033,
+	-1000, -1000, -1000, 1279, 759, 1279, 227, 227, 399, -1000,
+	529, 694, 227, 276, 295, -1000, 1279, 1110, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 1033, -1000, -1000, 527, -1000,
+	882, 555, 1279, -1000, 1033, -1000, 276, 552, -1000, 503,
+	276, 1033, 487, -1000, 1033, -1000,
+}
+
+var yyPgo = [...]int16{
+	0, 34, 45, 87, 865, 710, 863, 861, 857, 0,
+	856, 102, 75, 344, 855, 414, 854, 285, 49, 853,
+	852, 851, 834, 60, 814, 19, 56, 54, 3, 660,
+	809, 118, 807, 65, 62, 46, 66, 27, 20, 7,
+	42, 16, 521, 70, 805, 48, 804, 51, 26, 91,
+	63, 52, 714, 4, 53, 58, 25, 2, 1, 8,
+	6, 800, 795, 793, 792, 55, 38, 11, 791, 57,
+	14, 47, 35, 24, 788, 50, 59, 787, 9, 786,
+	785, 76, 13, 39, 12, 5, 10, 784, 106, 64,
+	23,
+}
+
+var yyR1 = [...]int8{
+	0, 87, 87, 89, 89, 89, 88, 88, 90, 90,
+	1, 1, 1, 1, 3, 3, 7, 7, 7, 22,
+	22, 4, 4, 4, 4, 4, 58, 58, 58, 58,
+	58, 53, 53, 53, 53, 53, 53, 53, 54, 54,
+	54, 54, 54, 54, 55, 55, 56, 57, 57, 8,
+	77, 77, 76, 76, 76, 21, 21, 21, 21, 21,
+	21, 21, 21, 79, 79, 78, 78, 78, 78, 5,
+	5, 5, 5, 5, 5, 5, 5, 5, 5, 5,
+	5, 43, 43, 44, 44, 52, 52, 65, 65, 63,
+	63, 86, 86, 61, 61, 59, 59, 59, 59, 39,
+	39, 39, 69, 69, 68, 68, 67, 67, 67, 83,
+	83, 83, 81, 81, 81, 81, 82, 82, 82, 6,
+	6, 6, 6, 6, 66, 66, 64, 64, 62, 62,
+	60, 60, 60, 60, 85, 85, 85, 70, 70, 70,
+	71, 71, 71, 75, 75, 74, 73, 73, 72, 72,
+	2, 80, 80, 30, 30, 30, 9, 9, 9, 19,
+	19, 10, 10, 10, 10, 10, 10, 10, 10, 10,
+	10, 10, 10, 10, 10, 10, 10, 10, 20, 20,
+	84, 84, 36, 36, 36, 36, 37, 37, 37, 11,
+	11, 24, 24, 28, 28, 28, 29, 29, 29, 29,
+	29, 27, 12, 12, 12, 12, 12, 13, 13, 13,
+	13, 14, 14, 14, 14, 14, 14, 14, 14, 14,
+	14, 14, 14, 16, 16, 16, 16, 15, 15, 15,
+	15, 15, 15, 23, 23, 32, 32, 31, 31, 33,
+	33, 18, 18, 34, 34, 35, 35, 47, 47, 51,
+	51, 48, 48, 48, 49, 49, 26, 26, 50, 50,
+	50, 50, 50, 50, 50, 50, 50, 50, 17, 17,
+	17, 38, 38, 38, 38, 25, 25, 40, 40, 40,
+	40, 46, 46, 41, 41, 42, 42, 42, 42, 42,
+	42, 42, 45, 45,
+}
+
+var yyR2 = [...]int8{
+	0, 2, 4, 1, 1, 1, 0, 2, 0, 1,
+	0, 1, 2, 3, 1, 1, 2, 5, 4, 1,
+	3, 1, 1, 2, 1, 5, 1, 1, 2, 2,
+	2, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	4, 4, 4, 4, 0, 1, 3, 1, 2, 2,
+	1, 3, 1, 3, 5, 1, 1, 3, 6, 3,
+	3, 5, 8, 1, 3, 1, 1, 3, 3, 3,
+	5, 3, 5, 5, 7, 5, 5, 4, 4, 5,
+	1, 0, 2, 0, 2, 1, 3, 0, 2, 2,
+	4, 0, 1, 1, 3, 3, 5, 4, 6, 1,
+	2, 2, 0, 3, 1, 3, 4, 4, 5, 0,
+	1, 1, 0, 2, 2, 4, 1, 3, 3, 6,
+	6, 4, 3, 3, 0, 2, 2, 4, 1, 3,
+	4, 6, 5, 7, 0, 1, 1, 0, 1, 2,
+	2, 1, 4, 1, 3, 2, 0, 1, 3, 4,
+	1, 2, 4, 1, 1, 1, 1, 3, 4, 0,
+	1, 7, 6, 7, 2, 4, 4, 6, 7, 7,
+	2, 2, 3, 1, 3, 3, 3, 5, 0, 2,
+	0, 1, 1, 3, 2, 3, 3, 3, 2, 1,
+	2, 1, 3, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 1, 2, 2, 2, 2, 2, 1, 1,
+	2, 1, 1, 1, 1, 2, 3, 3, 3, 5,
+	8, 4, 2, 1, 1, 3, 6, 1, 1, 1,
+	1, 1, 1, 1, 3, 1, 1, 2, 3, 0,
+	2, 3, 3, 1, 2, 4, 6, 1, 3, 1,
+	3, 3, 3, 1, 3, 1, 1, 3, 1, 1,
+	1, 2, 1, 1, 3, 4, 2, 3, 1, 3,
+	3, 3, 4, 5, 1, 1, 3, 1, 2, 4,
+	5, 2, 3, 1, 2, 1, 3, 3, 4, 3,
+	3, 2, 1, 3,
+}
+
+var yyChk = [...]int16{
+	-1000, -87, 4, 5, -1, -3, -4, -7, -8, -5,
+	-58, -9, -11, 64, 58, 75, 76, 48, 74, -6,
+	-56, -53, -10, 57, -24, 47, 44, 72, 62, 45,
+	35, -54, 42, 52, 68, 59, 63, 56, 77, 49,
+	73, 54, 71, 67, -12, 65, 66, -13, 12, 11,
+	13, 14, 61, -18, -14, 40, -15, -16, 7, 21,
+	36, 15, 16, 17, 18, 19, 20, -28, 70, 69,
+	6, 9, 10, -88, -89, 24, 22, 23, -5, -56,
+	-53, 22, 57, 27, 29, 28, 60, -22, 62, -27,
+	-29, -28, 11, 12, 13, 14, -77, -76, -21, -28,
+	70, 69, -52, -49, 6, -26, -50, 7, 8, -15,
+	12, 21, -17, 36, 70, -52, -27, 70, -27, 6,
+	-29, -27, -27, -27, -27, -27, -42, -17, 70, 36,
+	7, 6, 36, 36, -9, -11, 57, -9, 36, 40,
+	-9, -19, -9, 38, 38, 25, 38, -13, -13, -13,
+	-13, -71, -75, -72, -74, 40, -41, -42, 7, -32,
+	-31, -18, 36, -1, -34, -35, 43, 37, -23, -9,
+	25, 38, -9, -89, -3, -25, 8, -57, -40, -56,
+	-41, -9, -9, 40, 40, 25, -27, 26, 25, 25,
+	38, -43, 26, 27, 35, 6, 15, 36, 25, 37,
+	-51, -47, -48, 6, 7, -49, 25, -43, -69, 38,
+	-65, -69, 29, -12, -69, -69, -69, -70, -72, 51,
+	-70, -33, 38, 34, 25, 25, -45, -38, -25, 36,
+	-81, 32, 31, -9, -9, -88, 27, 53, 46, -36,
+	-37, -48, 56, -36, -27, 70, -27, 70, -27, 70,
+	69, -45, -73, 78, -72, 78, -2, -80, -1, -30,
+	6, 7, 70, -33, -57, 37, -23, 41, 41, -35,
+	-47, 37, 26, -27, -27, -88, 29, 6, -56, -46,
+	40, 78, -34, -1, -27, -70, -76, 7, 40, -27,
+	70, 69, -27, -27, 28, -49, -38, -26, -50, 37,
+	-51, -27, 26, 37, 10, 27, 27, -27, 28, -65,
+	-68, -67, -83, -57, 11, 12, 28, -18, -63, 36,
+	-81, 28, -9, -55, -54, -55, -70, -71, -31, -45,
+	-27, 74, 74, 37, 26, 29, 55, 37, -38, -38,
+	37, 37, 77, -25, -9, -9, 37, -89, 56, 30,
+	28, -11, 41, 39, 39, 39, 39, 25, 38, 39,
+	-41, -75, 41, -2, 29, 27, 37, 29, 56, -9,
+	39, -9, -40, 40, 78, -1, -41, 41, 41, -79,
+	-78, -27, 7, 25, 38, 39, -9, 37, -47, -48,
+	-25, -25, -9, -44, -18, 27, 39, 26, 6, 7,
+	-83, -9, 37, -86, 57, -38, -66, -66, 39, -38,
+	-38, 40, 29, 31, -90, 22, -90, 36, 53, -90,
+	-37, -11, -9, -9, -90, -27, -27, -73, 41, -25,
+	-1, -11, 25, -1, -41, 41, 41, 26, 29, -27,
+	-27, 25, 28, -38, -67, -69, -69, 6, -61, -59,
+	-27, -57, -70, -64, 36, -70, -1, -38, -38, -9,
+	-9, -9, -9, -84, 79, -84, 39, 29, 29, -27,
+	41, -78, -27, 7, 39, -27, -9, -82, -81, -82,
+	-69, 37, 26, 27, -27, 37, -86, 41, -20, 50,
+	37, -9, -9, 25, -1, 25, 33, 27, -82, -59,
+	-39, -38, 29, 27, -62, -60, -85, -58, 75, 76,
+	-9, -27, -27, -38, -38, 28, 9, -38, -39, 37,
+	26, -27, -85, -9, 28, -60, 27, -27, -9, -39,
+	27, 28, -39, -9, 28, -9,
+}
+
+var yyDef = [...]int16{
+	0, -2, 10, 6, 1, 11, 14, 15, 21, 22,
+	0, 24, 173, 0, 0, 0, 0, 0, 0, 80,
+	26, 27, 156, 35, 189, 0, 0, 0, 0, 0,
+	0, 31, 32, 33, 34, 36, 37, 0, 0, 0,
+	0, 0, 0, 159, 191, 38, 39, 202, 0, 0,
+	0, 0, 0, 208, 209, 10, 211, 212, 213, 214,
+	0, 227, 228, 229, 230, 231, 232, 223, 224, 0,
+	193, 194, 195, 0, 12, 3, 4, 5, 23, 28,
+	29, 30, 35, 0, 0, 0, 0, 16, 0, 19,
+	201, 196, 197, 198, 199, 200, 49, 50, 52, 55,
+	56, 0, 81, 85, 268, 255, 256, 258, 259, 260,
+	0, 262, 263, 0, 0, 81, 102, 87, 102, 0,
+	190, 102, 102, 102, 137, 137, 239, 285, 0, 0,
+	112, 268, 0, 0, 6, 173, 0, 164, 0, 0,
+	170, 171, 160, 0, 0, 0, 0, 203, 204, 205,
+	206, 207, 146, 141, 143, 10, 239, 283, 210, 222,
+	235, 236, 0, 0, 0, 243, 0, 215, 0, 233,
+	0, 0, 6, 7, 13, 174, 175, 176, 275, 47,
+	277, 157, 172, 0, 10, 0, 137, 0, 0, 0,
+	0, 69, 0, 0, 0, 0, 261, 0, 0, 266,
+	0, 249, 247, 268, 258, 253, 0, 71, 87, 109,
+	0, 112, 0, 192, 44, 44, 137, 122, 138, 0,
+	123, 46, 0, 0, 0, 0, 0, 292, 274, 0,
+	291, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	182, 0, 0, 0, 0, 0, 0, 0, 217, 218,
+	0, 0, 140, 0, 147, 0, 0, 10, 150, 0,
+	-2, -2, -2, 145, 284, 237, 0, 241, 242, 244,
+	0, 216, 0, 225, 0, 2, 0, 0, 48, 278,
+	10, 0, 0, 0, 20, 18, 51, 53, 0, 59,
+	60, 0, 57, 0, 0, 86, 82, 254, 257, 264,
+	0, 269, 0, 267, 0, 0, 0, 270, 0, 83,
+	0, 104, 0, 109, 110, 111, 0, 77, 88, 91,
+	78, 0, 158, 124, 45, 124, 121, 139, 240, 0,
+	289, 286, 287, 290, 0, 0, 0, 0, 113, 114,
+	8, 8, 0, 174, 165, 166, 8, 184, 0, 0,
+	0, 188, 8, 40, 42, 41, 43, 0, 0, 221,
+	144, 146, 148, 0, 151, 0, 238, 10, 0, 234,
+	0, 25, 276, 10, 0, 0, 281, 177, 17, 0,
+	63, 65, 66, 0, 0, 0, 70, 265, 250, 248,
+	251, 252, 72, 73, 75, 0, 103, 109, 102, 102,
+	0, 76, 89, 0, 92, 79, 137, 137, 288, 293,
+	271, 10, 0, 0, 0, 9, 0, 0, 0, 180,
+	183, 185, 186, 187, 180, 219, 0, 142, 149, 0,
+	245, 0, 0, 0, 282, 279, 54, 0, 0, 61,
+	0, 0, 0, 84, 105, 112, 112, 102, 0, 93,
+	0, 0, 119, 125, 91, 120, 0, 272, 115, 178,
+	162, 0, 167, 0, 181, 0, 0, 152, 10, 226,
+	280, 64, 67, 68, 0, 58, 74, 106, 116, 107,
+	112, 90, 0, 0, 0, 126, 134, 273, 161, 0,
+	163, 168, 169, 0, 246, 0, 0, 0, 108, 94,
+	95, 99, 0, 0, 0, 128, 0, 134, 135, 136,
+	179, 220, 62, 117, 118, 0, 101, 100, 97, 127,
+	134, 0, 0, 96, 0, 129, 0, 0, 98, 130,
+	0, 0, 132, 131, 0, 133,
+}
+
+var yyTok1 = [...]int8{
+	1,
+}
+
+var yyTok2 = [...]int8{
+	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
+	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
+	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
+	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
+	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
+	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
+	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
+	72, 73, 74, 75, 76, 77, 78, 79, 80,
+}
+
+var yyTok3 = [...]int8{
+	0,
+}
+
+var yyErrorMessages = [...]struct {
+	state int
+	token int
+	msg   string
+}{}
+
+//line yaccpar:1
+
+/*	parser for yacc output	*/
+
+var (
+	yyDebug        = 0
+	yyErrorVerbose = false
+)
+
+type yyLexer interface {
+	Lex(lval *yySymType) int
+	Error(s string)
+}
+
+type yyParser interface {
+	Parse(yyLexer) int
+	Lookahead() int
+}
+
+type yyParserImpl struct {
+	lval  yySymType
+	stack [yyInitialStackSize]yySymType
+	char  int
+}
+
+func (p *yyParserImpl) Lookahead() int {
+	return p.char
+}
+
+func yyNewParser() yyParser {
+	return &yyParserImpl{}
+}
+
+const yyFlag = -1000
+
+func yyTokname(c int) string {
+	if c >= 1 && c-1 < len(yyToknames) {
+		if yyToknames[c-1] != "" {
+			return yyToknames[c-1]
+		}
+	}
+	return __yyfmt__.Sprintf("tok-%v", c)
+}
+
+func yyStatname(s int) string {
+	if s >= 0 && s < len(yyStatenames) {
+		if yyStatenames[s] != "" {
+			return yyStatenames[s]
+		}
+	}
+	return __yyfmt__.Sprintf("state-%v", s)
+}
+
+func yyErrorMessage(state, lookAhead int) string {
+	const TOKSTART = 4
+
+	if !yyErrorVerbose {
+		return "syntax error"
+	}
+
+	for _, e := range yyErrorMessages {
+		if e.state == state && e.token == lookAhead {
+			return "syntax error: " + e.msg
+		}
+	}
+
+	res := "syntax error: unexpected " + yyTokname(lookAhead)
+
+	// To match Bison, suggest at most four expected tokens.
+	expected := make([]int, 0, 4)
+
+	// Look for shiftable tokens.
+	base := int(yyPact[state])
+	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+	}
+
+	if yyDef[state] == -2 {
+		i := 0
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
+			i += 2
+		}
+
+		// Look for tokens that we accept or reduce.
+		for i += 2; yyExca[i] >= 0; i += 2 {
+			tok := int(yyExca[i])
+			if tok < TOKSTART || yyExca[i+1] == 0 {
+				continue
+			}
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+
+		// If the default action is to accept or reduce, give up.
+		if yyExca[i+1] != 0 {
+			return res
+		}
+	}
+
+	for i, tok := range expected {
+		if i == 0 {
+			res += ", expecting "
+		} else {
+			res += " or "
+		}
+		res += yyTokname(tok)
+	}
+	return res
+}
+
+func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
+	token = 0
+	char = lex.Lex(lval)
+	if char <= 0 {
+		token = int(yyTok1[0])
+		goto out
+	}
+	if char < len(yyTok1) {
+		token = int(yyTok1[char])
+		goto out
+	}
+	if char >= yyPrivate {
+		if char < yyPrivate+len(yyTok2) {
+			token = int(yyTok2[char-yyPrivate])
+			goto out
+		}
+	}
+	for i := 0; i < len(yyTok3); i += 2 {
+		token = int(yyTok3[i+0])
+		if token == char {
+			token = int(yyTok3[i+1])
+			goto out
+		}
+	}
+
+out:
+	if token == 0 {
+		token = int(yyTok2[1]) /* unknown char */
+	}
+	if yyDebug >= 3 {
+		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
+	}
+	return char, token
+}
+
+func yyParse(yylex yyLexer) int {
+	return yyNewParser().Parse(yylex)
+}
+
+func (yyrcvr *yyParserImpl) Parse(yylex yyLexer) int {
+	var yyn int
+	var yyVAL yySymType
+	var yyDollar []yySymType
+	_ = yyDollar // silence set and not used
+	yyS := yyrcvr.stack[:]
+
+	Nerrs := 0   /* number of errors */
+	Errflag := 0 /* error recovery flag */
+	yystate := 0
+	yyrcvr.char = -1
+	yytoken := -1 // yyrcvr.char translated into internal numbering
+	defer func() {
+		// Make sure we report no lookahead when not parsing.
+		yystate = -1
+		yyrcvr.char = -1
+		yytoken = -1
+	}()
+	yyp := -1
+	goto yystack
+
+ret0:
+	return 0
+
+ret1:
+	return 1
+
+yystack:
+	/* put a state and value onto the stack */
+	if yyDebug >= 4 {
+		__yyfmt__.Printf("char %v in %v\n", yyTokname(yytoken), yyStatname(yystate))
+	}
+
+	yyp++
+	if yyp >= len(yyS) {
+		nyys := make([]yySymType, len(yyS)*2)
+		copy(nyys, yyS)
+		yyS = nyys
+	}
+	yyS[yyp] = yyVAL
+	yyS[yyp].yys = yystate
+
+yynewstate:
+	yyn = int(yyPact[yystate])
+	if yyn <= yyFlag {
+		goto yydefault /* simple state */
+	}
+	if yyrcvr.char < 0 {
+		yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
+	}
+	yyn += yytoken
+	if yyn < 0 || yyn >= yyLast {
+		goto yydefault
+	}
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
+		yyrcvr.char = -1
+		yytoken = -1
+		yyVAL = yyrcvr.lval
+		yystate = yyn
+		if Errflag > 0 {
+			Errflag--
+		}
+		goto yystack
+	}
+
+yydefault:
+	/* default state action */
+	yyn = int(yyDef[yystate])
+	if yyn == -2 {
+		if yyrcvr.char < 0 {
+			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
+		}
+
+		/* look through exception table */
+		xi := 0
+		for {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
+				break
+			}
+			xi += 2
+		}
+		for xi += 2; ; xi += 2 {
+			yyn = int(yyExca[xi+0])
+			if yyn < 0 || yyn == yytoken {
+				break
+			}
+		}
+		yyn = int(yyExca[xi+1])
+		if yyn < 0 {
+			goto ret0
+		}
+	}
+	if yyn == 0 {
+		/* error ... attempt to resume parsing */
+		switch Errflag {
+		case 0: /* brand new error */
+			yylex.Error(yyErrorMessage(yystate, yytoken))
+			Nerrs++
+			if yyDebug >= 1 {
+				__yyfmt__.Printf("%s", yyStatname(yystate))
+				__yyfmt__.Printf(" saw %s\n", yyTokname(yytoken))
+			}
+			fallthrough
+
+		case 1, 2: /* incompletely recovered error ... try again */
+			Errflag = 3
+
+			/* find a state where "error" is a legal shift action */
+			for yyp >= 0 {
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
+				if yyn >= 0 && yyn < yyLast {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
+						goto yystack
+					}
+				}
+
+				/* the current p has no shift on "error", pop stack */
+				if yyDebug >= 2 {
+					__yyfmt__.Printf("error recovery pops state %d\n", yyS[yyp].yys)
+				}
+				yyp--
+			}
+			/* there is no state on the stack with an error shift ... abort */
+			goto ret1
+
+		case 3: /* no shift yet; clobber input char */
+			if yyDebug >= 2 {
+				__yyfmt__.Printf("error recovery discards %s\n", yyTokname(yytoken))
+			}
+			if yytoken == yyEofCode {
+				goto ret1
+			}
+			yyrcvr.char = -1
+			yytoken = -1
+			goto yynewstate /* try again in the same state */
+		}
+	}
+
+	/* reduction by production yyn */
+	if yyDebug >= 2 {
+		__yyfmt__.Printf("reduce %v in:\n\t%v\n", yyn, yyStatname(yystate))
+	}
+
+	yynt := yyn
+	yypt := yyp
+	_ = yypt // guard against "declared and not used"
+
+	yyp -= int(yyR2[yyn])
+	// yyp is now the index of $0. Perform the default action. Iff the
+	// reduced production is ε, $1 is possibly out of range.
+	if yyp+1 >= len(yyS) {
+		nyys := make([]yySymType, len(yyS)*2)
+		copy(nyys, yyS)
+		yyS = nyys
+	}
+	yyVAL = yyS[yyp+1]
+
+	/* consult goto table to find next state */
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
+	yyj := yyg + yyS[yyp].yys + 1
+
+	if yyj >= yyLast {
+		yystate = int(yyAct[yyg])
+	} else {
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
+		}
+	}
+	// dummy call; replaced with literal code
+	switch yynt {
+
+	case 1:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:140
+		{
+			lex(yylex).file = &ast.File{Stats: nestPackages(yyDollar[2].stats)}
+		}
+	case 2:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:144
+		{
+			lex(yylex).expr = yyDollar[3].expr
+		}
+	case 10:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:163
+		{
+			yyVAL.stats = nil
+		}
+	case 11:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:167
+		{
+			yyVAL.stats = []ast.Stat{yyDollar[1].stat}
+		}
+	case 12:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:171
+		{
+			yyVAL.stats = yyDollar[1].stats
+		}
+	case 13:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:175
+		{
+			yyVAL.stats = append(yyDollar[1].stats, yyDollar[3].stat)
+		}
+	case 16:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:185
+		{
+			yyVAL.stat = &ast.PackageClause{Package: yyDollar[1].item.pos, Name: yyDollar[2].expr}
+		}
+	case 17:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:189
+		{
+			yyVAL.stat = &ast.PackageClause{Package: yyDollar[1].item.pos, Name: yyDollar[2].expr, Lbrace: yyDollar[3].item.pos, Stats: nestPackages(yyDollar[4].stats), Rbrace: yyDollar[5].item.pos}
+		}
+	case 18:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:193
+		{
+			yyVAL.stat = &ast.ObjectDef{Package: yyDollar[1].item.pos, Object: yyDollar[2].item.pos, Name: yyDollar[3].ident, Template: yyDollar[4].tmpl}
+		}
+	case 19:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:199
+		{
+			yyVAL.expr = yyDollar[1].ident
+		}
+	case 20:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:203
+		{
+			yyVAL.expr = &ast.Select{X: yyDollar[1].expr, Sel: yyDollar[3].ident}
+		}
+	case 23:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:211
+		{
+			if !setModifiers(yyDollar[2].stat, yyDollar[1].prefix.annots, yyDollar[1].prefix.mods) {
+				lex(yylex).errorf(yyDollar[2].stat.Pos(), "modifiers are not allowed here")
+			}
+			yyVAL.stat = yyDollar[2].stat
+		}
+	case 24:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:218
+		{
+			yyVAL.stat = yyDollar[1].expr
+		}
+	case 25:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:222
+		{
+			param := exprToParam(&ast.Typed{X: yyDollar[1].expr, Colon: yyDollar[2].item.pos, Type: yyDollar[3].chain.typ()})
+			if param == nil {
+				lex(yylex).errorf(yyDollar[1].expr.Pos(), "illegal start of anonymous function parameters")
+				param = &ast.Param{Name: &ast.Ident{NamePos: yyDollar[1].expr.Pos(), Name: "_"}}
+			}
+			yyVAL.stat = &ast.Function{Params: []*ast.Param{param}, Arrow: yyDollar[4].item.pos, Body: yyDollar[5].expr}
+		}
+	case 26:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:234
+		{
+			yyVAL.prefix = &defPrefix{annots: []*ast.Annotation{yyDollar[1].annot}}
+		}
+	case 27:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:238
+		{
+			yyVAL.prefix = &defPrefix{mods: []*ast.Modifier{yyDollar[1].mod}}
+		}
+	case 28:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:242
+		{
+			yyDollar[1].prefix.annots = append(yyDollar[1].prefix.annots, yyDollar[2].annot)
+			yyVAL.prefix = yyDollar[1].prefix
+		}
+	case 29:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:247
+		{
+			yyDollar[1].prefix.mods = append(yyDollar[1].prefix.mods, yyDollar[2].mod)
+			yyVAL.prefix = yyDollar[1].prefix
+		}
+	case 30:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:252
+		{
+			yyVAL.prefix = yyDollar[1].prefix
+		}
+	case 32:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:259
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 33:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:263
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 34:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:267
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 35:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:271
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 36:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:275
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 37:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:279
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 38:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:285
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 39:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:289
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw}
+		}
+	case 40:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:293
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw, Qual: yyDollar[3].ident, Rbrack: yyDollar[4].item.pos}
+		}
+	case 41:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:297
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw, Qual: yyDollar[3].ident, Rbrack: yyDollar[4].item.pos}
+		}
+	case 42:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:301
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw, Qual: &ast.This{ThisPos: yyDollar[3].item.pos}, Rbrack: yyDollar[4].item.pos}
+		}
+	case 43:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:305
+		{
+			yyVAL.mod = &ast.Modifier{ModPos: yyDollar[1].item.pos, Name: yyDollar[1].item.raw, Qual: &ast.This{ThisPos: yyDollar[3].item.pos}, Rbrack: yyDollar[4].item.pos}
+		}
+	case 44:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:311
+		{
+			yyVAL.mods = nil
+		}
+	case 45:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:315
+		{
+			yyVAL.mods = []*ast.Modifier{yyDollar[1].mod}
+		}
+	case 46:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:321
+		{
+			yyVAL.annot = &ast.Annotation{At: yyDollar[1].item.pos, Init: &ast.Init{Type: yyDollar[2].typ, Args: yyDollar[3].argss}}
+		}
+	case 47:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:327
+		{
+			yyVAL.annots = []*ast.Annotation{yyDollar[1].annot}
+		}
+	case 48:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:331
+		{
+			yyVAL.annots = append(yyDollar[1].annots, yyDollar[2].annot)
+		}
+	case 49:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:339
+		{
+			yyVAL.stat = &ast.Import{Import: yyDollar[1].item.pos, Importers: yyDollar[2].importers}
+		}
+	case 50:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:345
+		{
+			yyVAL.importers = []*ast.Importer{yyDollar[1].importer}
+		}
+	case 51:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:349
+		{
+			yyVAL.importers = append(yyDollar[1].importers, yyDollar[3].importer)
+		}
+	case 52:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:355
+		{
+			path, name := splitImportPath(yyDollar[1].expr)
+			switch {
+			case name == nil:
+				lex(yylex).errorf(yyDollar[1].expr.End(), "expected '.'")
+				path, name = yyDollar[1].expr, &ast.Ident{NamePos: yyDollar[1].expr.End()}
+			case path == nil:
+				lex(yylex).errorf(name.Pos(), "import of a single name %s", name.Name)
+				path = name
+			}
+			yyVAL.importer = &ast.Importer{Path: path, Selectors: []*ast.ImportSelector{{Name: name}}}
+		}
+	case 53:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:368
+		{
+			yyVAL.importer = &ast.Importer{Path: yyDollar[1].expr, Selectors: []*ast.ImportSelector{{Name: lex(yylex).ident(yyDollar[3].item)}}}
+		}
+	case 54:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:372
+		{
+			yyVAL.importer = &ast.Importer{Path: yyDollar[1].expr, Lbrace: yyDollar[3].item.pos, Selectors: yyDollar[4].selectors, Rbrace: yyDollar[5].item.pos}
+		}
+	case 55:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:378
+		{
+			yyVAL.expr = yyDollar[1].ident
+		}
+	case 56:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:382
+		{
+			yyVAL.expr = &ast.This{ThisPos: yyDollar[1].item.pos}
+		}
+	case 57:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:386
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{SuperPos: yyDollar[1].item.pos}, Sel: yyDollar[3].ident}
+		}
+	case 58:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:390
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{SuperPos: yyDollar[1].item.pos, Mix: yyDollar[3].ident, Rbrack: yyDollar[4].item.pos}, Sel: yyDollar[6].ident}
+		}
+	case 59:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:394
+		{
+			yyVAL.expr = &ast.Select{X: yyDollar[1].expr, Sel: yyDollar[3].ident}
+		}
+	case 60:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:398
+		{
+			yyVAL.expr = &ast.This{Qual: lex(yylex).qualifier(yyDollar[1].expr), ThisPos: yyDollar[3].item.pos}
+		}
+	case 61:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:402
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier(yyDollar[1].expr), SuperPos: yyDollar[3].item.pos}, Sel: yyDollar[5].ident}
+		}
+	case 62:
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line parser.go.y:406
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier(yyDollar[1].expr), SuperPos: yyDollar[3].item.pos, Mix: yyDollar[5].ident, Rbrack: yyDollar[6].item.pos}, Sel: yyDollar[8].ident}
+		}
+	case 63:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:412
+		{
+			yyVAL.selectors = []*ast.ImportSelector{yyDollar[1].selector}
+		}
+	case 64:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:416
+		{
+			yyVAL.selectors = append(yyDollar[1].selectors, yyDollar[3].selector)
+		}
+	case 65:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:422
+		{
+			yyVAL.selector = &ast.ImportSelector{Name: yyDollar[1].ident}
+		}
+	case 66:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:426
+		{
+			yyVAL.selector = &ast.ImportSelector{Name: lex(yylex).ident(yyDollar[1].item)}
+		}
+	case 67:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:430
+		{
+			yyVAL.selector = &ast.ImportSelector{Name: yyDollar[1].ident, Arrow: yyDollar[2].item.pos, Rename: yyDollar[3].ident}
+		}
+	case 68:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:434
+		{
+			yyVAL.selector = &ast.ImportSelector{Name: yyDollar[1].ident, Arrow: yyDollar[2].item.pos, Rename: lex(yylex).ident(yyDollar[3].item)}
+		}
+	case 69:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:442
+		{
+			yyVAL.stat = &ast.ValDef{ValPos: yyDollar[1].item.pos, Keyword: yyDollar[1].item.raw, Pats: yyDollar[2].pats, Type: yyDollar[3].typ}
+		}
+	case 70:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:446
+		{
+			yyVAL.stat = &ast.ValDef{ValPos: yyDollar[1].item.pos, Keyword: yyDollar[1].item.raw, Pats: yyDollar[2].pats, Type: yyDollar[3].typ, Rhs: yyDollar[5].expr}
+		}
+	case 71:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:450
+		{
+			yyVAL.stat = &ast.ValDef{ValPos: yyDollar[1].item.pos, Keyword: yyDollar[1].item.raw, Pats: yyDollar[2].pats, Type: yyDollar[3].typ}
+		}
+	case 72:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:454
+		{
+			yyVAL.stat = &ast.ValDef{ValPos: yyDollar[1].item.pos, Keyword: yyDollar[1].item.raw, Pats: yyDollar[2].pats, Type: yyDollar[3].typ, Rhs: yyDollar[5].expr}
+		}
+	case 73:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:458
+		{
+			end := yyDollar[2].ident.End()
+			switch {
+			case yyDollar[5].typ != nil:
+				end = yyDollar[5].typ.End()
+			case len(yyDollar[4].clauses) > 0:
+				end = yyDollar[4].clauses[len(yyDollar[4].clauses)-1].End()
+			case len(yyDollar[3].tparams) > 0:
+				end = yyDollar[3].tparams[len(yyDollar[3].tparams)-1].End() + 1
+			}
+			yyVAL.stat = &ast.DefDef{Def: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Params: yyDollar[4].clauses, ResultType: yyDollar[5].typ, EndPos: end}
+		}
+	case 74:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:471
+		{
+			yyVAL.stat = &ast.DefDef{Def: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Params: yyDollar[4].clauses, ResultType: yyDollar[5].typ, Rhs: yyDollar[7].expr, EndPos: yyDollar[7].expr.End()}
+		}
+	case 75:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:475
+		{
+			yyVAL.stat = &ast.DefDef{Def: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Params: yyDollar[4].clauses, Rhs: yyDollar[5].expr, Procedure: true, EndPos: yyDollar[5].expr.End()}
+		}
+	case 76:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:479
+		{
+			yyVAL.stat = &ast.DefDef{Def: yyDollar[1].item.pos, Name: lex(yylex).ident(yyDollar[2].item), Params: yyDollar[3].clauses, Rhs: yyDollar[5].expr, EndPos: yyDollar[5].expr.End()}
+		}
+	case 77:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:483
+		{
+			yyVAL.stat = &ast.DefDef{Def: yyDollar[1].item.pos, Name: lex(yylex).ident(yyDollar[2].item), Params: yyDollar[3].clauses, Rhs: yyDollar[4].expr, Procedure: true, EndPos: yyDollar[4].expr.End()}
+		}
+	case 78:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:487
+		{
+			end := yyDollar[2].ident.End()
+			switch {
+			case yyDollar[4].bounds.hi != nil:
+				end = yyDollar[4].bounds.hi.End()
+			case yyDollar[4].bounds.lo != nil:
+				end = yyDollar[4].bounds.lo.End()
+			case len(yyDollar[3].tparams) > 0:
+				end = yyDollar[3].tparams[len(yyDollar[3].tparams)-1].End() + 1
+			}
+			yyVAL.stat = &ast.TypeDef{TypePos: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Lo: yyDollar[4].bounds.lo, Hi: yyDollar[4].bounds.hi, EndPos: end}
+		}
+	case 79:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:500
+		{
+			yyVAL.stat = &ast.TypeDef{TypePos: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Rhs: yyDollar[5].typ, EndPos: yyDollar[5].typ.End()}
+		}
+	case 81:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:507
+		{
+			yyVAL.typ = nil
+		}
+	case 82:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:511
+		{
+			yyVAL.typ = yyDollar[2].typ
+		}
+	case 83:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:517
+		{
+			yyVAL.typ = nil
+		}
+	case 84:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:521
+		{
+			yyVAL.typ = yyDollar[2].typ
+		}
+	case 85:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:527
+		{
+			yyVAL.pats = []ast.Pattern{yyDollar[1].pat}
+		}
+	case 86:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:531
+		{
+			yyVAL.pats = append(yyDollar[1].pats, yyDollar[3].pat)
+		}
+	case 87:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:537
+		{
+			yyVAL.clauses = nil
+		}
+	case 88:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:541
+		{
+			yyVAL.clauses = append(yyDollar[1].clauses, yyDollar[2].clause)
+		}
+	case 89:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:547
+		{
+			yyVAL.clause = &ast.ParamClause{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos}
+		}
+	case 90:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:551
+		{
+			yyVAL.clause = &ast.ParamClause{Lparen: yyDollar[1].item.pos, Params: yyDollar[3].params, Rparen: yyDollar[4].item.pos}
+			if yyDollar[2].item != nil {
+				yyVAL.clause.Implicit = yyDollar[2].item.pos
+			}
+		}
+	case 91:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:560
+		{
+			yyVAL.item = nil
+		}
+	case 93:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:567
+		{
+			yyVAL.params = []*ast.Param{yyDollar[1].param}
+		}
+	case 94:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:571
+		{
+			yyVAL.params = append(yyDollar[1].params, yyDollar[3].param)
+		}
+	case 95:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:577
+		{
+			yyVAL.param = &ast.Param{Name: yyDollar[1].ident, Type: yyDollar[3].typ}
+		}
+	case 96:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:581
+		{
+			yyVAL.param = &ast.Param{Name: yyDollar[1].ident, Type: yyDollar[3].typ, Default: yyDollar[5].expr}
+		}
+	case 97:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:585
+		{
+			yyVAL.param = &ast.Param{Annotations: yyDollar[1].annots, Name: yyDollar[2].ident, Type: yyDollar[4].typ}
+		}
+	case 98:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:589
+		{
+			yyVAL.param = &ast.Param{Annotations: yyDollar[1].annots, Name: yyDollar[2].ident, Type: yyDollar[4].typ, Default: yyDollar[6].expr}
+		}
+	case 100:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:596
+		{
+			yyVAL.typ = &ast.ByNameType{Arrow: yyDollar[1].item.pos, Type: yyDollar[2].typ}
+		}
+	case 101:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:600
+		{
+			yyVAL.typ = &ast.RepeatedType{Type: yyDollar[1].typ, Star: yyDollar[2].item.pos}
+		}
+	case 102:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:606
+		{
+			yyVAL.tparams = nil
+		}
+	case 103:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:610
+		{
+			yyVAL.tparams = yyDollar[2].tparams
+		}
+	case 104:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:616
+		{
+			yyVAL.tparams = []*ast.TypeParam{yyDollar[1].tparam}
+		}
+	case 105:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:620
+		{
+			yyVAL.tparams = append(yyDollar[1].tparams, yyDollar[3].tparam)
+		}
+	case 106:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:626
+		{
+			yyVAL.tparam = lex(yylex).typeParam(nil, yyDollar[1].item, lex(yylex).ident(yyDollar[2].item), yyDollar[3].tparams, yyDollar[4].tbounds)
+		}
+	case 107:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:630
+		{
+			yyVAL.tparam = lex(yylex).typeParam(nil, yyDollar[1].item, lex(yylex).ident(yyDollar[2].item), yyDollar[3].tparams, yyDollar[4].tbounds)
+		}
+	case 108:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:634
+		{
+			yyVAL.tparam = lex(yylex).typeParam(yyDollar[1].annots, yyDollar[2].item, lex(yylex).ident(yyDollar[3].item), yyDollar[4].tparams, yyDollar[5].tbounds)
+		}
+	case 109:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:640
+		{
+			yyVAL.item = nil
+		}
+	case 112:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:648
+		{
+			yyVAL.bounds = bounds{}
+		}
+	case 113:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:652
+		{
+			yyVAL.bounds = bounds{lo: yyDollar[2].typ}
+		}
+	case 114:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:656
+		{
+			yyVAL.bounds = bounds{hi: yyDollar[2].typ}
+		}
+	case 115:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:660
+		{
+			yyVAL.bounds = bounds{lo: yyDollar[2].typ, hi: yyDollar[4].typ}
+		}
+	case 116:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:666
+		{
+			yyVAL.tbounds = tparamBounds{bounds: yyDollar[1].bounds}
+		}
+	case 117:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:670
+		{
+			yyDollar[1].tbounds.views = append(yyDollar[1].tbounds.views, yyDollar[3].typ)
+			yyVAL.tbounds = yyDollar[1].tbounds
+		}
+	case 118:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:675
+		{
+			yyDollar[1].tbounds.contexts = append(yyDollar[1].tbounds.contexts, yyDollar[3].typ)
+			yyVAL.tbounds = yyDollar[1].tbounds
+		}
+	case 119:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:684
+		{
+			yyVAL.stat = &ast.ClassDef{Class: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, CtorModifiers: yyDollar[4].mods, Params: yyDollar[5].clauses, Template: yyDollar[6].tmpl}
+		}
+	case 120:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:688
+		{
+			yyVAL.stat = &ast.ClassDef{Modifiers: lex(yylex).caseModifier(yyDollar[1].item), Class: yyDollar[1].item.end - 5, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, CtorModifiers: yyDollar[4].mods, Params: yyDollar[5].clauses, Template: yyDollar[6].tmpl}
+		}
+	case 121:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:692
+		{
+			yyVAL.stat = &ast.TraitDef{Trait: yyDollar[1].item.pos, Name: yyDollar[2].ident, TypeParams: yyDollar[3].tparams, Template: yyDollar[4].tmpl}
+		}
+	case 122:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:696
+		{
+			yyVAL.stat = &ast.ObjectDef{Object: yyDollar[1].item.pos, Name: yyDollar[2].ident, Template: yyDollar[3].tmpl}
+		}
+	case 123:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:700
+		{
+			yyVAL.stat = &ast.ObjectDef{Modifiers: lex(yylex).caseModifier(yyDollar[1].item), Object: yyDollar[1].item.end - 6, Name: yyDollar[2].ident, Template: yyDollar[3].tmpl}
+		}
+	case 124:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:706
+		{
+			yyVAL.clauses = nil
+		}
+	case 125:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:710
+		{
+			yyVAL.clauses = append(yyDollar[1].clauses, yyDollar[2].clause)
+		}
+	case 126:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:716
+		{
+			yyVAL.clause = &ast.ParamClause{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos}
+		}
+	case 127:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:720
+		{
+			yyVAL.clause = &ast.ParamClause{Lparen: yyDollar[1].item.pos, Params: yyDollar[3].params, Rparen: yyDollar[4].item.pos}
+			if yyDollar[2].item != nil {
+				yyVAL.clause.Implicit = yyDollar[2].item.pos
+			}
+		}
+	case 128:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:729
+		{
+			yyVAL.params = []*ast.Param{yyDollar[1].param}
+		}
+	case 129:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:733
+		{
+			yyVAL.params = append(yyDollar[1].params, yyDollar[3].param)
+		}
+	case 130:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:739
+		{
+			yyVAL.param = classParam(nil, yyDollar[1].item, yyDollar[2].ident, yyDollar[4].typ, nil)
+		}
+	case 131:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:743
+		{
+			yyVAL.param = classParam(nil, yyDollar[1].item, yyDollar[2].ident, yyDollar[4].typ, yyDollar[6].expr)
+		}
+	case 132:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:747
+		{
+			yyVAL.param = classParam(yyDollar[1].prefix, yyDollar[2].item, yyDollar[3].ident, yyDollar[5].typ, nil)
+		}
+	case 133:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:751
+		{
+			yyVAL.param = classParam(yyDollar[1].prefix, yyDollar[2].item, yyDollar[3].ident, yyDollar[5].typ, yyDollar[7].expr)
+		}
+	case 134:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:757
+		{
+			yyVAL.item = nil
+		}
+	case 137:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:765
+		{
+			yyVAL.tmpl = &ast.Template{}
+		}
+	case 139:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:770
+		{
+			yyDollar[2].tmpl.Extends = yyDollar[1].item.pos
+			yyVAL.tmpl = yyDollar[2].tmpl
+		}
+	case 140:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:777
+		{
+			yyDollar[2].tmpl.Parents = yyDollar[1].inits
+			yyVAL.tmpl = yyDollar[2].tmpl
+		}
+	case 142:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:783
+		{
+			yyDollar[4].tmpl.EarlyDefs = yyDollar[1].tmpl.Stats
+			yyDollar[4].tmpl.Parents = yyDollar[3].inits
+			yyVAL.tmpl = yyDollar[4].tmpl
+		}
+	case 143:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:791
+		{
+			yyVAL.inits = []*ast.Init{yyDollar[1].init}
+		}
+	case 144:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:795
+		{
+			yyVAL.inits = append(yyDollar[1].inits, &ast.Init{Type: yyDollar[3].typ})
+		}
+	case 145:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:801
+		{
+			yyVAL.init = &ast.Init{Type: yyDollar[1].typ, Args: yyDollar[2].argss}
+		}
+	case 146:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:807
+		{
+			yyVAL.tmpl = &ast.Template{}
+		}
+	case 148:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:814
+		{
+			yyVAL.tmpl = &ast.Template{Lbrace: yyDollar[1].item.pos, Stats: yyDollar[2].stats, Rbrace: yyDollar[3].item.pos}
+		}
+	case 149:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:818
+		{
+			yyVAL.tmpl = &ast.Template{Lbrace: yyDollar[1].item.pos, Self: yyDollar[2].self, Stats: yyDollar[3].stats, Rbrace: yyDollar[4].item.pos}
+		}
+	case 151:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:827
+		{
+			yyVAL.self = &ast.SelfType{Name: yyDollar[1].ident, Arrow: yyDollar[2].item.pos}
+		}
+	case 152:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:831
+		{
+			yyVAL.self = &ast.SelfType{Name: yyDollar[1].ident, Type: yyDollar[3].chain.typ(), Arrow: yyDollar[4].item.pos}
+		}
+	case 153:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:837
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 154:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:841
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 155:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:845
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 157:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:854
+		{
+			params, lparen, rparen, ok := exprToParams(yyDollar[1].expr)
+			if p, isParen := lex(yylex).parens[yyDollar[1].expr]; isParen && ok {
+				lparen, rparen = p[0], p[1]
+			}
+			if !ok {
+				lex(yylex).errorf(yyDollar[1].expr.Pos(), "illegal start of anonymous function parameters")
+			}
+			yyVAL.expr = &ast.Function{Lparen: lparen, Params: params, Rparen: rparen, Arrow: yyDollar[2].item.pos, Body: yyDollar[3].expr}
+		}
+	case 158:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:865
+		{
+			yyVAL.expr = &ast.Function{Implicit: yyDollar[1].item.pos, Params: []*ast.Param{{Name: lex(yylex).ident(yyDollar[2].item)}}, Arrow: yyDollar[3].item.pos, Body: yyDollar[4].expr}
+		}
+	case 159:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:871
+		{
+			yyVAL.expr = nil
+		}
+	case 161:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:878
+		{
+			yyVAL.expr = &ast.If{If: yyDollar[1].item.pos, Cond: yyDollar[3].expr, Then: yyDollar[6].expr, Else: yyDollar[7].expr}
+		}
+	case 162:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:882
+		{
+			yyVAL.expr = &ast.While{While: yyDollar[1].item.pos, Cond: yyDollar[3].expr, Body: yyDollar[6].expr}
+		}
+	case 163:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:886
+		{
+			yyVAL.expr = &ast.DoWhile{Do: yyDollar[1].item.pos, Body: yyDollar[2].expr, Cond: yyDollar[6].expr, EndPos: yyDollar[7].item.end}
+		}
+	case 164:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:890
+		{
+			yyVAL.expr = &ast.Try{Try: yyDollar[1].item.pos, Body: yyDollar[2].expr}
+		}
+	case 165:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:894
+		{
+			yyVAL.expr = &ast.Try{Try: yyDollar[1].item.pos, Body: yyDollar[2].expr, FinallyPos: yyDollar[3].item.pos, Finally: yyDollar[4].expr}
+		}
+	case 166:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:898
+		{
+			yyVAL.expr = lex(yylex).tryExpr(yyDollar[1].item, yyDollar[2].expr, yyDollar[3].item, yyDollar[4].expr, nil, nil)
+		}
+	case 167:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:902
+		{
+			yyVAL.expr = lex(yylex).tryExpr(yyDollar[1].item, yyDollar[2].expr, yyDollar[3].item, yyDollar[4].expr, yyDollar[5].item, yyDollar[6].expr)
+		}
+	case 168:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:906
+		{
+			yyVAL.expr = lex(yylex).forExpr(yyDollar[1].item, yyDollar[3].enums, yyDollar[6].item, yyDollar[7].expr)
+		}
+	case 169:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:910
+		{
+			yyVAL.expr = lex(yylex).forExpr(yyDollar[1].item, yyDollar[3].enums, yyDollar[6].item, yyDollar[7].expr)
+		}
+	case 170:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:914
+		{
+			yyVAL.expr = &ast.Throw{Throw: yyDollar[1].item.pos, X: yyDollar[2].expr}
+		}
+	case 171:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:918
+		{
+			yyVAL.expr = &ast.Return{Return: yyDollar[1].item.pos, X: yyDollar[2].expr}
+		}
+	case 172:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:922
+		{
+			yyVAL.expr = &ast.Assign{X: yyDollar[1].expr, TokPos: yyDollar[2].item.pos, Rhs: yyDollar[3].expr}
+		}
+	case 174:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:927
+		{
+			yyVAL.expr = &ast.Typed{X: yyDollar[1].expr, Colon: yyDollar[2].item.pos, Type: yyDollar[3].chain.typ()}
+		}
+	case 175:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:931
+		{
+			star := lex(yylex).ident(yyDollar[3].item)
+			yyVAL.expr = &ast.Typed{X: yyDollar[1].expr, Colon: yyDollar[2].item.pos, Type: &ast.RepeatedType{Type: &ast.Ident{NamePos: star.NamePos, Name: "_"}, Star: star.NamePos + 1}}
+		}
+	case 176:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:936
+		{
+			yyVAL.expr = &ast.Annotated{X: yyDollar[1].expr, Colon: yyDollar[2].item.pos, Annotations: yyDollar[3].annots}
+		}
+	case 177:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:940
+		{
+			yyVAL.expr = &ast.Match{X: yyDollar[1].expr, Match: yyDollar[2].item.pos, Lbrace: yyDollar[3].item.pos, Cases: yyDollar[4].cases, Rbrace: yyDollar[5].item.pos}
+		}
+	case 178:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:946
+		{
+			yyVAL.expr = nil
+		}
+	case 179:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:950
+		{
+			yyVAL.expr = yyDollar[2].expr
+		}
+	case 180:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:956
+		{
+			yyVAL.item = nil
+		}
+	case 182:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:963
+		{
+			yyVAL.enums = []ast.Enumerator{yyDollar[1].enum}
+		}
+	case 183:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:967
+		{
+			yyVAL.enums = append(yyDollar[1].enums, yyDollar[3].enum)
+		}
+	case 184:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:971
+		{
+			yyVAL.enums = yyDollar[1].enums
+		}
+	case 185:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:975
+		{
+			yyVAL.enums = append(yyDollar[1].enums, &ast.Guard{If: yyDollar[2].item.pos, Cond: yyDollar[3].expr})
+		}
+	case 186:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:981
+		{
+			yyVAL.enum = &ast.Generator{Pat: yyDollar[1].pat, Arrow: yyDollar[2].item.pos, Rhs: yyDollar[3].expr}
+		}
+	case 187:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:985
+		{
+			yyVAL.enum = &ast.ValueEnum{Pat: yyDollar[1].pat, TokPos: yyDollar[2].item.pos, Rhs: yyDollar[3].expr}
+		}
+	case 188:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:989
+		{
+			yyVAL.enum = &ast.Guard{If: yyDollar[1].item.pos, Cond: yyDollar[2].expr}
+		}
+	case 189:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:995
+		{
+			yyVAL.expr = yyDollar[1].chain.expr()
+		}
+	case 190:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:999
+		{
+			yyVAL.expr = &ast.PostfixApply{X: yyDollar[1].chain.expr(), Op: yyDollar[2].ident}
+		}
+	case 191:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1005
+		{
+			yyVAL.chain = newChain(yyDollar[1].expr)
+		}
+	case 192:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1009
+		{
+			yyVAL.chain = yyDollar[1].chain.add(yyDollar[2].ident, yyDollar[3].expr)
+		}
+	case 193:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1016
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 194:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1020
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 195:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1024
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 197:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1031
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 198:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1035
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 199:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1039
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 200:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1043
+		{
+			yyVAL.ident = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 203:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1053
+		{
+			if lit, ok := yyDollar[2].expr.(*ast.Literal); ok && lit.Kind <= ast.DoubleLit && lit.ValuePos == yyDollar[1].item.end {
+				yyVAL.expr = &ast.Literal{ValuePos: yyDollar[1].item.pos, Kind: lit.Kind, Value: "-" + lit.Value}
+			} else {
+				yyVAL.expr = &ast.PrefixApply{Op: lex(yylex).ident(yyDollar[1].item), X: yyDollar[2].expr}
+			}
+		}
+	case 204:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1061
+		{
+			yyVAL.expr = &ast.PrefixApply{Op: lex(yylex).ident(yyDollar[1].item), X: yyDollar[2].expr}
+		}
+	case 205:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1065
+		{
+			yyVAL.expr = &ast.PrefixApply{Op: lex(yylex).ident(yyDollar[1].item), X: yyDollar[2].expr}
+		}
+	case 206:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1069
+		{
+			yyVAL.expr = &ast.PrefixApply{Op: lex(yylex).ident(yyDollar[1].item), X: yyDollar[2].expr}
+		}
+	case 207:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1075
+		{
+			yyVAL.expr = &ast.New{New: yyDollar[1].item.pos, Template: yyDollar[2].tmpl}
+		}
+	case 210:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1081
+		{
+			yyVAL.expr = &ast.PostfixApply{X: yyDollar[1].expr, Op: lex(yylex).ident(yyDollar[2].item)}
+		}
+	case 213:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1089
+		{
+			yyVAL.expr = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 214:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1093
+		{
+			yyVAL.expr = lex(yylex).interpolation(yyDollar[1].item)
+		}
+	case 215:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1097
+		{
+			yyVAL.expr = &ast.Tuple{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos}
+		}
+	case 216:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1101
+		{
+			if _, ok := yyDollar[2].exprs[0].(*ast.Assign); len(yyDollar[2].exprs) == 1 && !ok {
+				yyVAL.expr = yyDollar[2].exprs[0]
+				lex(yylex).parens[yyVAL.expr] = [2]ast.Pos{yyDollar[1].item.pos, yyDollar[3].item.pos}
+			} else {
+				yyVAL.expr = &ast.Tuple{Lparen: yyDollar[1].item.pos, Elts: yyDollar[2].exprs, Rparen: yyDollar[3].item.pos}
+			}
+		}
+	case 217:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1110
+		{
+			yyVAL.expr = &ast.Select{X: yyDollar[1].expr, Sel: yyDollar[3].ident}
+		}
+	case 218:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1114
+		{
+			yyVAL.expr = &ast.This{Qual: lex(yylex).qualifier(yyDollar[1].expr), ThisPos: yyDollar[3].item.pos}
+		}
+	case 219:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:1118
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier(yyDollar[1].expr), SuperPos: yyDollar[3].item.pos}, Sel: yyDollar[5].ident}
+		}
+	case 220:
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line parser.go.y:1122
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier(yyDollar[1].expr), SuperPos: yyDollar[3].item.pos, Mix: yyDollar[5].ident, Rbrack: yyDollar[6].item.pos}, Sel: yyDollar[8].ident}
+		}
+	case 221:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1126
+		{
+			yyVAL.expr = &ast.TypeApply{Fun: yyDollar[1].expr, Lbrack: yyDollar[2].item.pos, Targs: yyDollar[3].types, Rbrack: yyDollar[4].item.pos}
+		}
+	case 222:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1130
+		{
+			yyVAL.expr = &ast.Apply{Fun: yyDollar[1].expr, Args: yyDollar[2].args}
+		}
+	case 223:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1136
+		{
+			yyVAL.expr = yyDollar[1].ident
+		}
+	case 224:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1140
+		{
+			yyVAL.expr = &ast.This{ThisPos: yyDollar[1].item.pos}
+		}
+	case 225:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1144
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{SuperPos: yyDollar[1].item.pos}, Sel: yyDollar[3].ident}
+		}
+	case 226:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:1148
+		{
+			yyVAL.expr = &ast.Select{X: &ast.Super{SuperPos: yyDollar[1].item.pos, Mix: yyDollar[3].ident, Rbrack: yyDollar[4].item.pos}, Sel: yyDollar[6].ident}
+		}
+	case 227:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1154
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 228:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1158
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 229:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1162
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 230:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1166
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 231:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1170
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 232:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1174
+		{
+			yyVAL.expr = lex(yylex).literal(yyDollar[1].item)
+		}
+	case 233:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1180
+		{
+			yyVAL.exprs = []ast.Expr{yyDollar[1].expr}
+		}
+	case 234:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1184
+		{
+			yyVAL.exprs = append(yyDollar[1].exprs, yyDollar[3].expr)
+		}
+	case 236:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1191
+		{
+			yyVAL.args = &ast.ArgList{Lparen: yyDollar[1].expr.Pos(), Args: []ast.Expr{yyDollar[1].expr}, Rparen: yyDollar[1].expr.End() - 1}
+		}
+	case 237:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1197
+		{
+			yyVAL.args = &ast.ArgList{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos}
+		}
+	case 238:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1201
+		{
+			yyVAL.args = &ast.ArgList{Lparen: yyDollar[1].item.pos, Args: yyDollar[2].exprs, Rparen: yyDollar[3].item.pos}
+		}
+	case 239:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:1207
+		{
+			yyVAL.argss = nil
+		}
+	case 240:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1211
+		{
+			yyVAL.argss = append(yyDollar[1].argss, yyDollar[2].args)
+		}
+	case 241:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1217
+		{
+			yyVAL.expr = &ast.Block{Lbrace: yyDollar[1].item.pos, Stats: lex(yylex).blockStats(yyDollar[2].stats), Rbrace: yyDollar[3].item.pos}
+		}
+	case 242:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1221
+		{
+			yyVAL.expr = &ast.PartialFunction{Lbrace: yyDollar[1].item.pos, Cases: yyDollar[2].cases, Rbrace: yyDollar[3].item.pos}
+		}
+	case 243:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1227
+		{
+			yyVAL.cases = []*ast.CaseClause{yyDollar[1].caseClause}
+		}
+	case 244:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1231
+		{
+			yyVAL.cases = append(yyDollar[1].cases, yyDollar[2].caseClause)
+		}
+	case 245:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1237
+		{
+			yyVAL.caseClause = &ast.CaseClause{Case: yyDollar[1].item.pos, Pat: yyDollar[2].pat, Arrow: yyDollar[3].item.pos, Body: yyDollar[4].stats}
+		}
+	case 246:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:1241
+		{
+			yyVAL.caseClause = &ast.CaseClause{Case: yyDollar[1].item.pos, Pat: yyDollar[2].pat, Guard: yyDollar[4].expr, Arrow: yyDollar[5].item.pos, Body: yyDollar[6].stats}
+		}
+	case 248:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1250
+		{
+			if alt, ok := yyDollar[1].pat.(*ast.Alternative); ok {
+				alt.Alts = append(alt.Alts, yyDollar[3].pat)
+				yyVAL.pat = alt
+			} else {
+				yyVAL.pat = &ast.Alternative{Alts: []ast.Pattern{yyDollar[1].pat, yyDollar[3].pat}}
+			}
+		}
+	case 249:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1261
+		{
+			yyVAL.pats = []ast.Pattern{yyDollar[1].pat}
+		}
+	case 250:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1265
+		{
+			yyVAL.pats = append(yyDollar[1].pats, yyDollar[3].pat)
+		}
+	case 251:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1271
+		{
+			yyVAL.pat = &ast.TypedPattern{X: lex(yylex).ident(yyDollar[1].item), Colon: yyDollar[2].item.pos, Type: yyDollar[3].chain.typ()}
+		}
+	case 252:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1275
+		{
+			yyVAL.pat = &ast.TypedPattern{X: lex(yylex).ident(yyDollar[1].item), Colon: yyDollar[2].item.pos, Type: yyDollar[3].chain.typ()}
+		}
+	case 254:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1282
+		{
+			yyVAL.pat = &ast.Bind{Name: lex(yylex).ident(yyDollar[1].item), At: yyDollar[2].item.pos, Pat: yyDollar[3].chain.pattern()}
+		}
+	case 255:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1286
+		{
+			yyVAL.pat = yyDollar[1].chain.pattern()
+		}
+	case 256:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1292
+		{
+			yyVAL.chain = newChain(yyDollar[1].pat)
+		}
+	case 257:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1296
+		{
+			yyVAL.chain = yyDollar[1].chain.add(lex(yylex).ident(yyDollar[2].item), yyDollar[3].pat)
+		}
+	case 258:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1302
+		{
+			yyVAL.pat = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 259:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1306
+		{
+			yyVAL.pat = &ast.SeqWildcard{Underscore: yyDollar[1].item.pos}
+		}
+	case 260:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1310
+		{
+			yyVAL.pat = yyDollar[1].expr.(*ast.Literal)
+		}
+	case 261:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1314
+		{
+			yyVAL.pat = lex(yylex).negative(yyDollar[1].item, yyDollar[2].item)
+		}
+	case 262:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1318
+		{
+			yyVAL.pat = lex(yylex).interpolatedPattern(yyDollar[1].item)
+		}
+	case 263:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1322
+		{
+			yyVAL.pat = yyDollar[1].expr.(ast.Pattern)
+		}
+	case 264:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1326
+		{
+			yyVAL.pat = &ast.ExtractorPattern{Fun: yyDollar[1].expr, Lparen: yyDollar[2].item.pos, Rparen: yyDollar[3].item.pos}
+		}
+	case 265:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1330
+		{
+			yyVAL.pat = &ast.ExtractorPattern{Fun: yyDollar[1].expr, Lparen: yyDollar[2].item.pos, Args: yyDollar[3].pats, Rparen: yyDollar[4].item.pos}
+		}
+	case 266:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1334
+		{
+			yyVAL.pat = &ast.TuplePattern{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos}
+		}
+	case 267:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1338
+		{
+			if len(yyDollar[2].pats) == 1 {
+				yyVAL.pat = yyDollar[2].pats[0]
+			} else {
+				yyVAL.pat = &ast.TuplePattern{Lparen: yyDollar[1].item.pos, Elts: yyDollar[2].pats, Rparen: yyDollar[3].item.pos}
+			}
+		}
+	case 268:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1348
+		{
+			yyVAL.expr = lex(yylex).ident(yyDollar[1].item)
+		}
+	case 269:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1352
+		{
+			yyVAL.expr = &ast.Select{X: yyDollar[1].expr, Sel: yyDollar[3].ident}
+		}
+	case 270:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1356
+		{
+			yyVAL.expr = &ast.Select{X: &ast.This{ThisPos: yyDollar[1].item.pos}, Sel: yyDollar[3].ident}
+		}
+	case 271:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1364
+		{
+			params, lparen, rparen := typeToParams(yyDollar[1].chain.typ())
+			yyVAL.typ = &ast.FunctionType{Lparen: lparen, Params: params, Rparen: rparen, Arrow: yyDollar[2].item.pos, Result: yyDollar[3].typ}
+		}
+	case 272:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1369
+		{
+			yyVAL.typ = &ast.FunctionType{Lparen: yyDollar[1].item.pos, Rparen: yyDollar[2].item.pos, Arrow: yyDollar[3].item.pos, Result: yyDollar[4].typ}
+		}
+	case 273:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:1373
+		{
+			yyVAL.typ = &ast.ExistentialType{Type: yyDollar[1].chain.typ(), ForSome: yyDollar[2].item.pos, Lbrace: yyDollar[3].item.pos, Decls: yyDollar[4].stats, Rbrace: yyDollar[5].item.pos}
+		}
+	case 274:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1377
+		{
+			yyVAL.typ = yyDollar[1].chain.typ()
+		}
+	case 275:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1383
+		{
+			yyVAL.chain = newChain(yyDollar[1].typ)
+		}
+	case 276:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1387
+		{
+			yyVAL.chain = yyDollar[1].chain.add(lex(yylex).ident(yyDollar[2].item), yyDollar[3].typ)
+		}
+	case 278:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1394
+		{
+			yyVAL.typ = &ast.CompoundType{Types: append([]ast.Type{yyDollar[1].typ}, yyDollar[2].types...)}
+		}
+	case 279:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1398
+		{
+			yyVAL.typ = &ast.CompoundType{Types: []ast.Type{yyDollar[1].typ}, Lbrace: yyDollar[2].item.pos, Decls: yyDollar[3].stats, Rbrace: yyDollar[4].item.pos}
+		}
+	case 280:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:1402
+		{
+			yyVAL.typ = &ast.CompoundType{Types: append([]ast.Type{yyDollar[1].typ}, yyDollar[2].types...), Lbrace: yyDollar[3].item.pos, Decls: yyDollar[4].stats, Rbrace: yyDollar[5].item.pos}
+		}
+	case 281:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1408
+		{
+			yyVAL.types = []ast.Type{yyDollar[2].typ}
+		}
+	case 282:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1412
+		{
+			yyVAL.types = append(yyDollar[1].types, yyDollar[3].typ)
+		}
+	case 284:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1419
+		{
+			yyVAL.typ = &ast.AnnotatedType{Type: yyDollar[1].typ, Annotations: yyDollar[2].annots}
+		}
+	case 285:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1425
+		{
+			yyVAL.typ = yyDollar[1].expr.(ast.Type)
+		}
+	case 286:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1429
+		{
+			yyVAL.typ = &ast.SingletonType{Ref: yyDollar[1].expr, TypePos: yyDollar[3].item.pos}
+		}
+	case 287:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1433
+		{
+			yyVAL.typ = &ast.SingletonType{Ref: &ast.This{ThisPos: yyDollar[1].item.pos}, TypePos: yyDollar[3].item.pos}
+		}
+	case 288:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:1437
+		{
+			yyVAL.typ = &ast.AppliedType{Type: yyDollar[1].typ, Lbrack: yyDollar[2].item.pos, Args: yyDollar[3].types, Rbrack: yyDollar[4].item.pos}
+		}
+	case 289:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1441
+		{
+			yyVAL.typ = &ast.Projection{X: yyDollar[1].typ, Sel: yyDollar[3].ident}
+		}
+	case 290:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1445
+		{
+			if len(yyDollar[2].types) == 1 {
+				yyVAL.typ = yyDollar[2].types[0]
+			} else {
+				yyVAL.typ = &ast.TupleType{Lparen: yyDollar[1].item.pos, Elts: yyDollar[2].types, Rparen: yyDollar[3].item.pos}
+			}
+		}
+	case 291:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:1453
+		{
+			yyVAL.typ = &ast.WildcardType{Underscore: yyDollar[1].item.pos, Lo: yyDollar[2].bounds.lo, Hi: yyDollar[2].bounds.hi}
+		}
+	case 292:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:1459
+		{
+			yyVAL.types = []ast.Type{yyDollar[1].typ}
+		}
+	case 293:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:1463
+		{
+			yyVAL.types = append(yyDollar[1].types, yyDollar[3].typ)
+		}
+	}
+	goto yystack /* stack new state and value */
+}
@@ -1,5 +1,1467 @@
 %{
 package parser
 
+import "github.com/sundargates/scalaparser/ast"
+
+// The grammar follows the syntax summary of the Scala 2.13 language
+// specification. Where the summary is not LALR(1) the grammar accepts a
+// superset and the actions check the rest:
+//   - infix expressions, types and patterns are parsed as flat chains and
+//     precedence is applied afterwards, see infixChain,
+//   - the parameters of anonymous functions are parsed as expressions and
+//     converted when "=>" is seen,
+//   - chained package clauses are nested by nestPackages.
+// yyLex resolves the spots that need a second token of lookahead.
+
+type bounds struct {
+	lo, hi ast.Type
+}
+
+type tparamBounds struct {
+	bounds
+	views, contexts []ast.Type
+}
+
+func lex(yylex yyLexer) *yyLex {
+	return yylex.(*yyLex)
+}
 %}
 
+%union {
+	item       *item
+	expr       ast.Expr
+	exprs      []ast.Expr
+	stat       ast.Stat
+	stats      []ast.Stat
+	typ        ast.Type
+	types      []ast.Type
+	pat        ast.Pattern
+	pats       []ast.Pattern
+	ident      *ast.Ident
+	args       *ast.ArgList
+	argss      []*ast.ArgList
+	caseClause *ast.CaseClause
+	cases      []*ast.CaseClause
+	enum       ast.Enumerator
+	enums      []ast.Enumerator
+	mod        *ast.Modifier
+	mods       []*ast.Modifier
+	annot      *ast.Annotation
+	annots     []*ast.Annotation
+	param      *ast.Param
+	params     []*ast.Param
+	clause     *ast.ParamClause
+	clauses    []*ast.ParamClause
+	tparam     *ast.TypeParam
+	tparams    []*ast.TypeParam
+	tmpl       *ast.Template
+	init       *ast.Init
+	inits      []*ast.Init
+	importer   *ast.Importer
+	importers  []*ast.Importer
+	selector   *ast.ImportSelector
+	selectors  []*ast.ImportSelector
+	chain      *infixChain
+	self       *ast.SelfType
+	bounds     bounds
+	tbounds    tparamBounds
+	prefix     *defPrefix
+}
+
+%token <item> tSTART_FILE tSTART_EXPR
+%token <item> tIDENT tUSCORE tUSCORE_STAR tSTAR tPIPE tPLUS tMINUS tBANG tTILDE
+%token <item> tNUMBER tSTRING tCHAR tSYMBOL tBOOLEAN tNULL tINTERP
+%token <item> tNL tNLS tSEMI tDOT tCOMMA tCOLON tEQ tARROW tLARROW
+%token <item> tSUBTYPE tSUPERTYPE tVIEWBOUND tHASH tAT
+%token <item> tLPAREN tRPAREN tLBRACK tRBRACK tLBRACE tRBRACE
+%token <item> tABSTRACT tCASE tCASECLASS tCASEOBJECT tCATCH tCLASS tDEF tDO
+%token <item> tELSE tEXTENDS tFINAL tFINALLY tFOR tFORSOME tIF tIMPLICIT
+%token <item> tIMPORT tLAZY tMATCH tNEW tOBJECT tOVERRIDE tPACKAGE tPRIVATE
+%token <item> tPROTECTED tRETURN tSEALED tSUPER tTHIS tTHROW tTRAIT tTRY
+%token <item> tTYPE tVAL tVAR tWHILE tWITH tYIELD
+
+// Shift/reduce conflicts are resolved in favour of shifting: an else,
+// catch or finally belongs to the innermost expression, and a type,
+// annotation or parameter list extends as far as possible. Rules that
+// should yield to a shift are marked %prec pLOW.
+//
+// The remaining reduce/reduce conflicts are between the name of a self
+// type and a template statement starting with the same name; yacc picks
+// the earlier rule, so "{ self =>" opening a template body is a self type.
+%nonassoc pLOW
+%right tELSE tCATCH tFINALLY tIF tWHILE tIMPLICIT tDOT tHASH tLBRACK
+%right tLPAREN tLBRACE tAT tWITH tSUBTYPE tIDENT tARROW tFORSOME
+
+%type <stats> stat_seq template_stats
+%type <stat> top_stat stat def tmpl_def package_clause import
+%type <expr> expr expr1 postfix_expr prefix_expr simple_expr simple_expr1
+%type <expr> literal path stable_id block_expr opt_expr else_opt
+%type <expr> import_path qual_id
+%type <exprs> exprs
+%type <chain> infix_expr infix_type infix_pattern
+%type <ident> id plain_id op_id self_name
+%type <args> paren_args argument_exprs
+%type <argss> paren_argss
+%type <cases> case_clauses
+%type <caseClause> case_clause
+%type <enums> enumerators
+%type <enum> enumerator
+%type <typ> typ param_type compound_type annot_type simple_type opt_type_ann opt_result_type
+%type <types> types with_types
+%type <pat> pattern pattern1 pattern2 simple_pattern
+%type <pats> patterns pattern2s
+%type <mod> modifier access_modifier
+%type <mods> opt_access_modifier
+%type <annot> annotation
+%type <annots> annotations
+%type <prefix> prefix
+%type <param> param class_param
+%type <params> params class_params
+%type <clause> param_clause class_param_clause
+%type <clauses> param_clauses class_param_clauses
+%type <tparam> tparam
+%type <tparams> tparams opt_tparams
+%type <tmpl> opt_template class_template template_body opt_template_body
+%type <init> constr
+%type <inits> parents
+%type <importer> importer
+%type <importers> importers
+%type <selector> selector
+%type <selectors> selectors
+%type <self> self_type
+%type <bounds> type_bounds
+%type <tbounds> tparam_bounds
+%type <item> opt_variance opt_yield opt_valvar opt_implicit
+
+%%
+
+top:
+	tSTART_FILE stat_seq
+	{
+		lex(yylex).file = &ast.File{Stats: nestPackages($2)}
+	}
+|	tSTART_EXPR opt_seps expr opt_seps
+	{
+		lex(yylex).expr = $3
+	}
+
+sep:
+	tSEMI
+|	tNL
+|	tNLS
+
+opt_seps:
+	/* empty */
+|	opt_seps sep
+
+opt_nl:
+	/* empty */
+|	tNL
+
+stat_seq:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	top_stat
+	{
+		$$ = []ast.Stat{$1}
+	}
+|	stat_seq sep
+	{
+		$$ = $1
+	}
+|	stat_seq sep top_stat
+	{
+		$$ = append($1, $3)
+	}
+
+top_stat:
+	stat
+|	package_clause
+
+package_clause:
+	tPACKAGE qual_id
+	{
+		$$ = &ast.PackageClause{Package: $1.pos, Name: $2}
+	}
+|	tPACKAGE qual_id tLBRACE stat_seq tRBRACE
+	{
+		$$ = &ast.PackageClause{Package: $1.pos, Name: $2, Lbrace: $3.pos, Stats: nestPackages($4), Rbrace: $5.pos}
+	}
+|	tPACKAGE tOBJECT id opt_template
+	{
+		$$ = &ast.ObjectDef{Package: $1.pos, Object: $2.pos, Name: $3, Template: $4}
+	}
+
+qual_id:
+	id
+	{
+		$$ = $1
+	}
+|	qual_id tDOT id
+	{
+		$$ = &ast.Select{X: $1, Sel: $3}
+	}
+
+stat:
+	import
+|	def
+|	prefix def
+	{
+		if !setModifiers($2, $1.annots, $1.mods) {
+			lex(yylex).errorf($2.Pos(), "modifiers are not allowed here")
+		}
+		$$ = $2
+	}
+|	expr
+	{
+		$$ = $1
+	}
+|	postfix_expr tCOLON infix_type tARROW expr
+	{
+		param := exprToParam(&ast.Typed{X: $1, Colon: $2.pos, Type: $3.typ()})
+		if param == nil {
+			lex(yylex).errorf($1.Pos(), "illegal start of anonymous function parameters")
+			param = &ast.Param{Name: &ast.Ident{NamePos: $1.Pos(), Name: "_"}}
+		}
+		$$ = &ast.Function{Params: []*ast.Param{param}, Arrow: $4.pos, Body: $5}
+	}
+
+// prefix is the annotations and modifiers in front of a definition.
+prefix:
+	annotation
+	{
+		$$ = &defPrefix{annots: []*ast.Annotation{$1}}
+	}
+|	modifier
+	{
+		$$ = &defPrefix{mods: []*ast.Modifier{$1}}
+	}
+|	prefix annotation
+	{
+		$1.annots = append($1.annots, $2)
+		$$ = $1
+	}
+|	prefix modifier
+	{
+		$1.mods = append($1.mods, $2)
+		$$ = $1
+	}
+|	prefix tNL
+	{
+		$$ = $1
+	}
+
+modifier:
+	access_modifier
+|	tABSTRACT
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tFINAL
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tSEALED
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tIMPLICIT
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tLAZY
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tOVERRIDE
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+
+access_modifier:
+	tPRIVATE
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tPROTECTED
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw}
+	}
+|	tPRIVATE tLBRACK id tRBRACK
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw, Qual: $3, Rbrack: $4.pos}
+	}
+|	tPROTECTED tLBRACK id tRBRACK
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw, Qual: $3, Rbrack: $4.pos}
+	}
+|	tPRIVATE tLBRACK tTHIS tRBRACK
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw, Qual: &ast.This{ThisPos: $3.pos}, Rbrack: $4.pos}
+	}
+|	tPROTECTED tLBRACK tTHIS tRBRACK
+	{
+		$$ = &ast.Modifier{ModPos: $1.pos, Name: $1.raw, Qual: &ast.This{ThisPos: $3.pos}, Rbrack: $4.pos}
+	}
+
+opt_access_modifier:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	access_modifier
+	{
+		$$ = []*ast.Modifier{$1}
+	}
+
+annotation:
+	tAT simple_type paren_argss
+	{
+		$$ = &ast.Annotation{At: $1.pos, Init: &ast.Init{Type: $2, Args: $3}}
+	}
+
+annotations:
+	annotation
+	{
+		$$ = []*ast.Annotation{$1}
+	}
+|	annotations annotation
+	{
+		$$ = append($1, $2)
+	}
+
+/* Imports */
+
+import:
+	tIMPORT importers
+	{
+		$$ = &ast.Import{Import: $1.pos, Importers: $2}
+	}
+
+importers:
+	importer
+	{
+		$$ = []*ast.Importer{$1}
+	}
+|	importers tCOMMA importer
+	{
+		$$ = append($1, $3)
+	}
+
+importer:
+	import_path
+	{
+		path, name := splitImportPath($1)
+		switch {
+		case name == nil:
+			lex(yylex).errorf($1.End(), "expected '.'")
+			path, name = $1, &ast.Ident{NamePos: $1.End()}
+		case path == nil:
+			lex(yylex).errorf(name.Pos(), "import of a single name %s", name.Name)
+			path = name
+		}
+		$$ = &ast.Importer{Path: path, Selectors: []*ast.ImportSelector{{Name: name}}}
+	}
+|	import_path tDOT tUSCORE
+	{
+		$$ = &ast.Importer{Path: $1, Selectors: []*ast.ImportSelector{{Name: lex(yylex).ident($3)}}}
+	}
+|	import_path tDOT tLBRACE selectors tRBRACE
+	{
+		$$ = &ast.Importer{Path: $1, Lbrace: $3.pos, Selectors: $4, Rbrace: $5.pos}
+	}
+
+import_path:
+	plain_id
+	{
+		$$ = $1
+	}
+|	tTHIS
+	{
+		$$ = &ast.This{ThisPos: $1.pos}
+	}
+|	tSUPER tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{SuperPos: $1.pos}, Sel: $3}
+	}
+|	tSUPER tLBRACK id tRBRACK tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{SuperPos: $1.pos, Mix: $3, Rbrack: $4.pos}, Sel: $6}
+	}
+|	import_path tDOT id
+	{
+		$$ = &ast.Select{X: $1, Sel: $3}
+	}
+|	import_path tDOT tTHIS
+	{
+		$$ = &ast.This{Qual: lex(yylex).qualifier($1), ThisPos: $3.pos}
+	}
+|	import_path tDOT tSUPER tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier($1), SuperPos: $3.pos}, Sel: $5}
+	}
+|	import_path tDOT tSUPER tLBRACK id tRBRACK tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier($1), SuperPos: $3.pos, Mix: $5, Rbrack: $6.pos}, Sel: $8}
+	}
+
+selectors:
+	selector
+	{
+		$$ = []*ast.ImportSelector{$1}
+	}
+|	selectors tCOMMA selector
+	{
+		$$ = append($1, $3)
+	}
+
+selector:
+	id
+	{
+		$$ = &ast.ImportSelector{Name: $1}
+	}
+|	tUSCORE
+	{
+		$$ = &ast.ImportSelector{Name: lex(yylex).ident($1)}
+	}
+|	id tARROW id
+	{
+		$$ = &ast.ImportSelector{Name: $1, Arrow: $2.pos, Rename: $3}
+	}
+|	id tARROW tUSCORE
+	{
+		$$ = &ast.ImportSelector{Name: $1, Arrow: $2.pos, Rename: lex(yylex).ident($3)}
+	}
+
+/* Definitions */
+
+def:
+	tVAL pattern2s opt_type_ann
+	{
+		$$ = &ast.ValDef{ValPos: $1.pos, Keyword: $1.raw, Pats: $2, Type: $3}
+	}
+|	tVAL pattern2s opt_type_ann tEQ expr
+	{
+		$$ = &ast.ValDef{ValPos: $1.pos, Keyword: $1.raw, Pats: $2, Type: $3, Rhs: $5}
+	}
+|	tVAR pattern2s opt_type_ann
+	{
+		$$ = &ast.ValDef{ValPos: $1.pos, Keyword: $1.raw, Pats: $2, Type: $3}
+	}
+|	tVAR pattern2s opt_type_ann tEQ expr
+	{
+		$$ = &ast.ValDef{ValPos: $1.pos, Keyword: $1.raw, Pats: $2, Type: $3, Rhs: $5}
+	}
+|	tDEF id opt_tparams param_clauses opt_result_type
+	{
+		end := $2.End()
+		switch {
+		case $5 != nil:
+			end = $5.End()
+		case len($4) > 0:
+			end = $4[len($4)-1].End()
+		case len($3) > 0:
+			end = $3[len($3)-1].End() + 1
+		}
+		$$ = &ast.DefDef{Def: $1.pos, Name: $2, TypeParams: $3, Params: $4, ResultType: $5, EndPos: end}
+	}
+|	tDEF id opt_tparams param_clauses opt_result_type tEQ expr
+	{
+		$$ = &ast.DefDef{Def: $1.pos, Name: $2, TypeParams: $3, Params: $4, ResultType: $5, Rhs: $7, EndPos: $7.End()}
+	}
+|	tDEF id opt_tparams param_clauses block_expr
+	{
+		$$ = &ast.DefDef{Def: $1.pos, Name: $2, TypeParams: $3, Params: $4, Rhs: $5, Procedure: true, EndPos: $5.End()}
+	}
+|	tDEF tTHIS param_clauses tEQ expr
+	{
+		$$ = &ast.DefDef{Def: $1.pos, Name: lex(yylex).ident($2), Params: $3, Rhs: $5, EndPos: $5.End()}
+	}
+|	tDEF tTHIS param_clauses block_expr
+	{
+		$$ = &ast.DefDef{Def: $1.pos, Name: lex(yylex).ident($2), Params: $3, Rhs: $4, Procedure: true, EndPos: $4.End()}
+	}
+|	tTYPE id opt_tparams type_bounds
+	{
+		end := $2.End()
+		switch {
+		case $4.hi != nil:
+			end = $4.hi.End()
+		case $4.lo != nil:
+			end = $4.lo.End()
+		case len($3) > 0:
+			end = $3[len($3)-1].End() + 1
+		}
+		$$ = &ast.TypeDef{TypePos: $1.pos, Name: $2, TypeParams: $3, Lo: $4.lo, Hi: $4.hi, EndPos: end}
+	}
+|	tTYPE id opt_tparams tEQ typ
+	{
+		$$ = &ast.TypeDef{TypePos: $1.pos, Name: $2, TypeParams: $3, Rhs: $5, EndPos: $5.End()}
+	}
+|	tmpl_def
+
+opt_type_ann:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tCOLON typ
+	{
+		$$ = $2
+	}
+
+opt_result_type:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tCOLON typ
+	{
+		$$ = $2
+	}
+
+pattern2s:
+	pattern2
+	{
+		$$ = []ast.Pattern{$1}
+	}
+|	pattern2s tCOMMA pattern2
+	{
+		$$ = append($1, $3)
+	}
+
+param_clauses:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	param_clauses param_clause
+	{
+		$$ = append($1, $2)
+	}
+
+param_clause:
+	tLPAREN tRPAREN
+	{
+		$$ = &ast.ParamClause{Lparen: $1.pos, Rparen: $2.pos}
+	}
+|	tLPAREN opt_implicit params tRPAREN
+	{
+		$$ = &ast.ParamClause{Lparen: $1.pos, Params: $3, Rparen: $4.pos}
+		if $2 != nil {
+			$$.Implicit = $2.pos
+		}
+	}
+
+opt_implicit:
+	/* empty */ %prec pLOW
+	{
+		$$ = nil
+	}
+|	tIMPLICIT
+
+params:
+	param
+	{
+		$$ = []*ast.Param{$1}
+	}
+|	params tCOMMA param
+	{
+		$$ = append($1, $3)
+	}
+
+param:
+	id tCOLON param_type
+	{
+		$$ = &ast.Param{Name: $1, Type: $3}
+	}
+|	id tCOLON param_type tEQ expr
+	{
+		$$ = &ast.Param{Name: $1, Type: $3, Default: $5}
+	}
+|	annotations id tCOLON param_type
+	{
+		$$ = &ast.Param{Annotations: $1, Name: $2, Type: $4}
+	}
+|	annotations id tCOLON param_type tEQ expr
+	{
+		$$ = &ast.Param{Annotations: $1, Name: $2, Type: $4, Default: $6}
+	}
+
+param_type:
+	typ
+|	tARROW typ
+	{
+		$$ = &ast.ByNameType{Arrow: $1.pos, Type: $2}
+	}
+|	typ tSTAR
+	{
+		$$ = &ast.RepeatedType{Type: $1, Star: $2.pos}
+	}
+
+opt_tparams:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tLBRACK tparams tRBRACK
+	{
+		$$ = $2
+	}
+
+tparams:
+	tparam
+	{
+		$$ = []*ast.TypeParam{$1}
+	}
+|	tparams tCOMMA tparam
+	{
+		$$ = append($1, $3)
+	}
+
+tparam:
+	opt_variance tIDENT opt_tparams tparam_bounds
+	{
+		$$ = lex(yylex).typeParam(nil, $1, lex(yylex).ident($2), $3, $4)
+	}
+|	opt_variance tUSCORE opt_tparams tparam_bounds
+	{
+		$$ = lex(yylex).typeParam(nil, $1, lex(yylex).ident($2), $3, $4)
+	}
+|	annotations opt_variance tIDENT opt_tparams tparam_bounds
+	{
+		$$ = lex(yylex).typeParam($1, $2, lex(yylex).ident($3), $4, $5)
+	}
+
+opt_variance:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tPLUS
+|	tMINUS
+
+type_bounds:
+	/* empty */ %prec pLOW
+	{
+		$$ = bounds{}
+	}
+|	tSUPERTYPE typ %prec pLOW
+	{
+		$$ = bounds{lo: $2}
+	}
+|	tSUBTYPE typ
+	{
+		$$ = bounds{hi: $2}
+	}
+|	tSUPERTYPE typ tSUBTYPE typ
+	{
+		$$ = bounds{lo: $2, hi: $4}
+	}
+
+tparam_bounds:
+	type_bounds
+	{
+		$$ = tparamBounds{bounds: $1}
+	}
+|	tparam_bounds tVIEWBOUND typ
+	{
+		$1.views = append($1.views, $3)
+		$$ = $1
+	}
+|	tparam_bounds tCOLON typ
+	{
+		$1.contexts = append($1.contexts, $3)
+		$$ = $1
+	}
+
+/* Templates */
+
+tmpl_def:
+	tCLASS id opt_tparams opt_access_modifier class_param_clauses opt_template
+	{
+		$$ = &ast.ClassDef{Class: $1.pos, Name: $2, TypeParams: $3, CtorModifiers: $4, Params: $5, Template: $6}
+	}
+|	tCASECLASS id opt_tparams opt_access_modifier class_param_clauses opt_template
+	{
+		$$ = &ast.ClassDef{Modifiers: lex(yylex).caseModifier($1), Class: $1.end - 5, Name: $2, TypeParams: $3, CtorModifiers: $4, Params: $5, Template: $6}
+	}
+|	tTRAIT id opt_tparams opt_template
+	{
+		$$ = &ast.TraitDef{Trait: $1.pos, Name: $2, TypeParams: $3, Template: $4}
+	}
+|	tOBJECT id opt_template
+	{
+		$$ = &ast.ObjectDef{Object: $1.pos, Name: $2, Template: $3}
+	}
+|	tCASEOBJECT id opt_template
+	{
+		$$ = &ast.ObjectDef{Modifiers: lex(yylex).caseModifier($1), Object: $1.end - 6, Name: $2, Template: $3}
+	}
+
+class_param_clauses:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	class_param_clauses class_param_clause
+	{
+		$$ = append($1, $2)
+	}
+
+class_param_clause:
+	tLPAREN tRPAREN
+	{
+		$$ = &ast.ParamClause{Lparen: $1.pos, Rparen: $2.pos}
+	}
+|	tLPAREN opt_implicit class_params tRPAREN
+	{
+		$$ = &ast.ParamClause{Lparen: $1.pos, Params: $3, Rparen: $4.pos}
+		if $2 != nil {
+			$$.Implicit = $2.pos
+		}
+	}
+
+class_params:
+	class_param
+	{
+		$$ = []*ast.Param{$1}
+	}
+|	class_params tCOMMA class_param
+	{
+		$$ = append($1, $3)
+	}
+
+class_param:
+	opt_valvar id tCOLON param_type
+	{
+		$$ = classParam(nil, $1, $2, $4, nil)
+	}
+|	opt_valvar id tCOLON param_type tEQ expr
+	{
+		$$ = classParam(nil, $1, $2, $4, $6)
+	}
+|	prefix opt_valvar id tCOLON param_type
+	{
+		$$ = classParam($1, $2, $3, $5, nil)
+	}
+|	prefix opt_valvar id tCOLON param_type tEQ expr
+	{
+		$$ = classParam($1, $2, $3, $5, $7)
+	}
+
+opt_valvar:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tVAL
+|	tVAR
+
+opt_template:
+	/* empty */
+	{
+		$$ = &ast.Template{}
+	}
+|	template_body
+|	tEXTENDS class_template
+	{
+		$2.Extends = $1.pos
+		$$ = $2
+	}
+
+class_template:
+	parents opt_template_body
+	{
+		$2.Parents = $1
+		$$ = $2
+	}
+|	template_body
+|	template_body tWITH parents opt_template_body
+	{
+		$4.EarlyDefs = $1.Stats
+		$4.Parents = $3
+		$$ = $4
+	}
+
+parents:
+	constr
+	{
+		$$ = []*ast.Init{$1}
+	}
+|	parents tWITH annot_type
+	{
+		$$ = append($1, &ast.Init{Type: $3})
+	}
+
+constr:
+	annot_type paren_argss
+	{
+		$$ = &ast.Init{Type: $1, Args: $2}
+	}
+
+opt_template_body:
+	/* empty */
+	{
+		$$ = &ast.Template{}
+	}
+|	template_body
+
+template_body:
+	tLBRACE template_stats tRBRACE
+	{
+		$$ = &ast.Template{Lbrace: $1.pos, Stats: $2, Rbrace: $3.pos}
+	}
+|	tLBRACE self_type template_stats tRBRACE
+	{
+		$$ = &ast.Template{Lbrace: $1.pos, Self: $2, Stats: $3, Rbrace: $4.pos}
+	}
+
+template_stats:
+	stat_seq
+
+self_type:
+	self_name tARROW
+	{
+		$$ = &ast.SelfType{Name: $1, Arrow: $2.pos}
+	}
+|	self_name tCOLON infix_type tARROW
+	{
+		$$ = &ast.SelfType{Name: $1, Type: $3.typ(), Arrow: $4.pos}
+	}
+
+self_name:
+	tIDENT
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tUSCORE
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tTHIS
+	{
+		$$ = lex(yylex).ident($1)
+	}
+
+/* Expressions */
+
+expr:
+	expr1
+|	postfix_expr tARROW expr
+	{
+		params, lparen, rparen, ok := exprToParams($1)
+		if p, isParen := lex(yylex).parens[$1]; isParen && ok {
+			lparen, rparen = p[0], p[1]
+		}
+		if !ok {
+			lex(yylex).errorf($1.Pos(), "illegal start of anonymous function parameters")
+		}
+		$$ = &ast.Function{Lparen: lparen, Params: params, Rparen: rparen, Arrow: $2.pos, Body: $3}
+	}
+|	tIMPLICIT tIDENT tARROW expr
+	{
+		$$ = &ast.Function{Implicit: $1.pos, Params: []*ast.Param{{Name: lex(yylex).ident($2)}}, Arrow: $3.pos, Body: $4}
+	}
+
+opt_expr:
+	/* empty */ %prec pLOW
+	{
+		$$ = nil
+	}
+|	expr
+
+expr1:
+	tIF tLPAREN expr tRPAREN opt_nl expr else_opt
+	{
+		$$ = &ast.If{If: $1.pos, Cond: $3, Then: $6, Else: $7}
+	}
+|	tWHILE tLPAREN expr tRPAREN opt_nl expr
+	{
+		$$ = &ast.While{While: $1.pos, Cond: $3, Body: $6}
+	}
+|	tDO expr opt_seps tWHILE tLPAREN expr tRPAREN
+	{
+		$$ = &ast.DoWhile{Do: $1.pos, Body: $2, Cond: $6, EndPos: $7.end}
+	}
+|	tTRY expr %prec pLOW
+	{
+		$$ = &ast.Try{Try: $1.pos, Body: $2}
+	}
+|	tTRY expr tFINALLY expr
+	{
+		$$ = &ast.Try{Try: $1.pos, Body: $2, FinallyPos: $3.pos, Finally: $4}
+	}
+|	tTRY expr tCATCH expr %prec pLOW
+	{
+		$$ = lex(yylex).tryExpr($1, $2, $3, $4, nil, nil)
+	}
+|	tTRY expr tCATCH expr tFINALLY expr
+	{
+		$$ = lex(yylex).tryExpr($1, $2, $3, $4, $5, $6)
+	}
+|	tFOR tLPAREN enumerators tRPAREN opt_nl opt_yield expr
+	{
+		$$ = lex(yylex).forExpr($1, $3, $6, $7)
+	}
+|	tFOR tLBRACE enumerators tRBRACE opt_nl opt_yield expr
+	{
+		$$ = lex(yylex).forExpr($1, $3, $6, $7)
+	}
+|	tTHROW expr
+	{
+		$$ = &ast.Throw{Throw: $1.pos, X: $2}
+	}
+|	tRETURN opt_expr
+	{
+		$$ = &ast.Return{Return: $1.pos, X: $2}
+	}
+|	postfix_expr tEQ expr
+	{
+		$$ = &ast.Assign{X: $1, TokPos: $2.pos, Rhs: $3}
+	}
+|	postfix_expr
+|	postfix_expr tCOLON infix_type
+	{
+		$$ = &ast.Typed{X: $1, Colon: $2.pos, Type: $3.typ()}
+	}
+|	postfix_expr tCOLON tUSCORE_STAR
+	{
+		star := lex(yylex).ident($3)
+		$$ = &ast.Typed{X: $1, Colon: $2.pos, Type: &ast.RepeatedType{Type: &ast.Ident{NamePos: star.NamePos, Name: "_"}, Star: star.NamePos + 1}}
+	}
+|	postfix_expr tCOLON annotations
+	{
+		$$ = &ast.Annotated{X: $1, Colon: $2.pos, Annotations: $3}
+	}
+|	postfix_expr tMATCH tLBRACE case_clauses tRBRACE
+	{
+		$$ = &ast.Match{X: $1, Match: $2.pos, Lbrace: $3.pos, Cases: $4, Rbrace: $5.pos}
+	}
+
+else_opt:
+	/* empty */ %prec pLOW
+	{
+		$$ = nil
+	}
+|	tELSE expr
+	{
+		$$ = $2
+	}
+
+opt_yield:
+	/* empty */
+	{
+		$$ = nil
+	}
+|	tYIELD
+
+enumerators:
+	enumerator
+	{
+		$$ = []ast.Enumerator{$1}
+	}
+|	enumerators sep enumerator
+	{
+		$$ = append($1, $3)
+	}
+|	enumerators sep %prec pLOW
+	{
+		$$ = $1
+	}
+|	enumerators tIF postfix_expr
+	{
+		$$ = append($1, &ast.Guard{If: $2.pos, Cond: $3})
+	}
+
+enumerator:
+	pattern1 tLARROW expr
+	{
+		$$ = &ast.Generator{Pat: $1, Arrow: $2.pos, Rhs: $3}
+	}
+|	pattern1 tEQ expr
+	{
+		$$ = &ast.ValueEnum{Pat: $1, TokPos: $2.pos, Rhs: $3}
+	}
+|	tIF postfix_expr
+	{
+		$$ = &ast.Guard{If: $1.pos, Cond: $2}
+	}
+
+postfix_expr:
+	infix_expr
+	{
+		$$ = $1.expr()
+	}
+|	infix_expr op_id
+	{
+		$$ = &ast.PostfixApply{X: $1.expr(), Op: $2}
+	}
+
+infix_expr:
+	prefix_expr
+	{
+		$$ = newChain($1)
+	}
+|	infix_expr op_id prefix_expr
+	{
+		$$ = $1.add($2, $3)
+	}
+
+// plain_id is an identifier that cannot start a prefix expression.
+plain_id:
+	tIDENT
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tSTAR
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tPIPE
+	{
+		$$ = lex(yylex).ident($1)
+	}
+
+op_id:
+	plain_id
+|	tPLUS
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tMINUS
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tBANG
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tTILDE
+	{
+		$$ = lex(yylex).ident($1)
+	}
+
+id:
+	op_id
+
+prefix_expr:
+	simple_expr
+|	tMINUS simple_expr
+	{
+		if lit, ok := $2.(*ast.Literal); ok && lit.Kind <= ast.DoubleLit && lit.ValuePos == $1.end {
+			$$ = &ast.Literal{ValuePos: $1.pos, Kind: lit.Kind, Value: "-" + lit.Value}
+		} else {
+			$$ = &ast.PrefixApply{Op: lex(yylex).ident($1), X: $2}
+		}
+	}
+|	tPLUS simple_expr
+	{
+		$$ = &ast.PrefixApply{Op: lex(yylex).ident($1), X: $2}
+	}
+|	tBANG simple_expr
+	{
+		$$ = &ast.PrefixApply{Op: lex(yylex).ident($1), X: $2}
+	}
+|	tTILDE simple_expr
+	{
+		$$ = &ast.PrefixApply{Op: lex(yylex).ident($1), X: $2}
+	}
+
+simple_expr:
+	tNEW class_template
+	{
+		$$ = &ast.New{New: $1.pos, Template: $2}
+	}
+|	block_expr
+|	simple_expr1
+|	simple_expr1 tUSCORE
+	{
+		$$ = &ast.PostfixApply{X: $1, Op: lex(yylex).ident($2)}
+	}
+
+simple_expr1:
+	literal
+|	path
+|	tUSCORE
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tINTERP
+	{
+		$$ = lex(yylex).interpolation($1)
+	}
+|	tLPAREN tRPAREN
+	{
+		$$ = &ast.Tuple{Lparen: $1.pos, Rparen: $2.pos}
+	}
+|	tLPAREN exprs tRPAREN
+	{
+		if _, ok := $2[0].(*ast.Assign); len($2) == 1 && !ok {
+			$$ = $2[0]
+			lex(yylex).parens[$$] = [2]ast.Pos{$1.pos, $3.pos}
+		} else {
+			$$ = &ast.Tuple{Lparen: $1.pos, Elts: $2, Rparen: $3.pos}
+		}
+	}
+|	simple_expr tDOT id
+	{
+		$$ = &ast.Select{X: $1, Sel: $3}
+	}
+|	simple_expr tDOT tTHIS
+	{
+		$$ = &ast.This{Qual: lex(yylex).qualifier($1), ThisPos: $3.pos}
+	}
+|	simple_expr tDOT tSUPER tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier($1), SuperPos: $3.pos}, Sel: $5}
+	}
+|	simple_expr tDOT tSUPER tLBRACK id tRBRACK tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{Qual: lex(yylex).qualifier($1), SuperPos: $3.pos, Mix: $5, Rbrack: $6.pos}, Sel: $8}
+	}
+|	simple_expr tLBRACK types tRBRACK
+	{
+		$$ = &ast.TypeApply{Fun: $1, Lbrack: $2.pos, Targs: $3, Rbrack: $4.pos}
+	}
+|	simple_expr1 argument_exprs
+	{
+		$$ = &ast.Apply{Fun: $1, Args: $2}
+	}
+
+path:
+	plain_id
+	{
+		$$ = $1
+	}
+|	tTHIS
+	{
+		$$ = &ast.This{ThisPos: $1.pos}
+	}
+|	tSUPER tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{SuperPos: $1.pos}, Sel: $3}
+	}
+|	tSUPER tLBRACK id tRBRACK tDOT id
+	{
+		$$ = &ast.Select{X: &ast.Super{SuperPos: $1.pos, Mix: $3, Rbrack: $4.pos}, Sel: $6}
+	}
+
+literal:
+	tNUMBER
+	{
+		$$ = lex(yylex).literal($1)
+	}
+|	tSTRING
+	{
+		$$ = lex(yylex).literal($1)
+	}
+|	tCHAR
+	{
+		$$ = lex(yylex).literal($1)
+	}
+|	tSYMBOL
+	{
+		$$ = lex(yylex).literal($1)
+	}
+|	tBOOLEAN
+	{
+		$$ = lex(yylex).literal($1)
+	}
+|	tNULL
+	{
+		$$ = lex(yylex).literal($1)
+	}
+
+exprs:
+	expr
+	{
+		$$ = []ast.Expr{$1}
+	}
+|	exprs tCOMMA expr
+	{
+		$$ = append($1, $3)
+	}
+
+argument_exprs:
+	paren_args
+|	block_expr
+	{
+		$$ = &ast.ArgList{Lparen: $1.Pos(), Args: []ast.Expr{$1}, Rparen: $1.End() - 1}
+	}
+
+paren_args:
+	tLPAREN tRPAREN
+	{
+		$$ = &ast.ArgList{Lparen: $1.pos, Rparen: $2.pos}
+	}
+|	tLPAREN exprs tRPAREN
+	{
+		$$ = &ast.ArgList{Lparen: $1.pos, Args: $2, Rparen: $3.pos}
+	}
+
+paren_argss:
+	/* empty */ %prec pLOW
+	{
+		$$ = nil
+	}
+|	paren_argss paren_args
+	{
+		$$ = append($1, $2)
+	}
+
+block_expr:
+	tLBRACE stat_seq tRBRACE
+	{
+		$$ = &ast.Block{Lbrace: $1.pos, Stats: lex(yylex).blockStats($2), Rbrace: $3.pos}
+	}
+|	tLBRACE case_clauses tRBRACE
+	{
+		$$ = &ast.PartialFunction{Lbrace: $1.pos, Cases: $2, Rbrace: $3.pos}
+	}
+
+case_clauses:
+	case_clause
+	{
+		$$ = []*ast.CaseClause{$1}
+	}
+|	case_clauses case_clause
+	{
+		$$ = append($1, $2)
+	}
+
+case_clause:
+	tCASE pattern tARROW stat_seq
+	{
+		$$ = &ast.CaseClause{Case: $1.pos, Pat: $2, Arrow: $3.pos, Body: $4}
+	}
+|	tCASE pattern tIF postfix_expr tARROW stat_seq
+	{
+		$$ = &ast.CaseClause{Case: $1.pos, Pat: $2, Guard: $4, Arrow: $5.pos, Body: $6}
+	}
+
+/* Patterns */
+
+pattern:
+	pattern1
+|	pattern tPIPE pattern1
+	{
+		if alt, ok := $1.(*ast.Alternative); ok {
+			alt.Alts = append(alt.Alts, $3)
+			$$ = alt
+		} else {
+			$$ = &ast.Alternative{Alts: []ast.Pattern{$1, $3}}
+		}
+	}
+
+patterns:
+	pattern
+	{
+		$$ = []ast.Pattern{$1}
+	}
+|	patterns tCOMMA pattern
+	{
+		$$ = append($1, $3)
+	}
+
+pattern1:
+	tIDENT tCOLON infix_type
+	{
+		$$ = &ast.TypedPattern{X: lex(yylex).ident($1), Colon: $2.pos, Type: $3.typ()}
+	}
+|	tUSCORE tCOLON infix_type
+	{
+		$$ = &ast.TypedPattern{X: lex(yylex).ident($1), Colon: $2.pos, Type: $3.typ()}
+	}
+|	pattern2
+
+pattern2:
+	tIDENT tAT infix_pattern
+	{
+		$$ = &ast.Bind{Name: lex(yylex).ident($1), At: $2.pos, Pat: $3.pattern()}
+	}
+|	infix_pattern
+	{
+		$$ = $1.pattern()
+	}
+
+infix_pattern:
+	simple_pattern
+	{
+		$$ = newChain($1)
+	}
+|	infix_pattern tIDENT simple_pattern
+	{
+		$$ = $1.add(lex(yylex).ident($2), $3)
+	}
+
+simple_pattern:
+	tUSCORE
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	tUSCORE_STAR
+	{
+		$$ = &ast.SeqWildcard{Underscore: $1.pos}
+	}
+|	literal
+	{
+		$$ = $1.(*ast.Literal)
+	}
+|	tMINUS tNUMBER
+	{
+		$$ = lex(yylex).negative($1, $2)
+	}
+|	tINTERP
+	{
+		$$ = lex(yylex).interpolatedPattern($1)
+	}
+|	stable_id
+	{
+		$$ = $1.(ast.Pattern)
+	}
+|	stable_id tLPAREN tRPAREN
+	{
+		$$ = &ast.ExtractorPattern{Fun: $1, Lparen: $2.pos, Rparen: $3.pos}
+	}
+|	stable_id tLPAREN patterns tRPAREN
+	{
+		$$ = &ast.ExtractorPattern{Fun: $1, Lparen: $2.pos, Args: $3, Rparen: $4.pos}
+	}
+|	tLPAREN tRPAREN
+	{
+		$$ = &ast.TuplePattern{Lparen: $1.pos, Rparen: $2.pos}
+	}
+|	tLPAREN patterns tRPAREN
+	{
+		if len($2) == 1 {
+			$$ = $2[0]
+		} else {
+			$$ = &ast.TuplePattern{Lparen: $1.pos, Elts: $2, Rparen: $3.pos}
+		}
+	}
+
+stable_id:
+	tIDENT
+	{
+		$$ = lex(yylex).ident($1)
+	}
+|	stable_id tDOT id
+	{
+		$$ = &ast.Select{X: $1, Sel: $3}
+	}
+|	tTHIS tDOT id
+	{
+		$$ = &ast.Select{X: &ast.This{ThisPos: $1.pos}, Sel: $3}
+	}
+
+/* Types */
+
+typ:
+	infix_type tARROW typ
+	{
+		params, lparen, rparen := typeToParams($1.typ())
+		$$ = &ast.FunctionType{Lparen: lparen, Params: params, Rparen: rparen, Arrow: $2.pos, Result: $3}
+	}
+|	tLPAREN tRPAREN tARROW typ
+	{
+		$$ = &ast.FunctionType{Lparen: $1.pos, Rparen: $2.pos, Arrow: $3.pos, Result: $4}
+	}
+|	infix_type tFORSOME tLBRACE stat_seq tRBRACE
+	{
+		$$ = &ast.ExistentialType{Type: $1.typ(), ForSome: $2.pos, Lbrace: $3.pos, Decls: $4, Rbrace: $5.pos}
+	}
+|	infix_type %prec pLOW
+	{
+		$$ = $1.typ()
+	}
+
+infix_type:
+	compound_type
+	{
+		$$ = newChain($1)
+	}
+|	infix_type tIDENT compound_type
+	{
+		$$ = $1.add(lex(yylex).ident($2), $3)
+	}
+
+compound_type:
+	annot_type %prec pLOW
+|	annot_type with_types %prec pLOW
+	{
+		$$ = &ast.CompoundType{Types: append([]ast.Type{$1}, $2...)}
+	}
+|	annot_type tLBRACE stat_seq tRBRACE
+	{
+		$$ = &ast.CompoundType{Types: []ast.Type{$1}, Lbrace: $2.pos, Decls: $3, Rbrace: $4.pos}
+	}
+|	annot_type with_types tLBRACE stat_seq tRBRACE
+	{
+		$$ = &ast.CompoundType{Types: append([]ast.Type{$1}, $2...), Lbrace: $3.pos, Decls: $4, Rbrace: $5.pos}
+	}
+
+with_types:
+	tWITH annot_type
+	{
+		$$ = []ast.Type{$2}
+	}
+|	with_types tWITH annot_type
+	{
+		$$ = append($1, $3)
+	}
+
+annot_type:
+	simple_type %prec pLOW
+|	simple_type annotations %prec pLOW
+	{
+		$$ = &ast.AnnotatedType{Type: $1, Annotations: $2}
+	}
+
+simple_type:
+	stable_id %prec pLOW
+	{
+		$$ = $1.(ast.Type)
+	}
+|	stable_id tDOT tTYPE
+	{
+		$$ = &ast.SingletonType{Ref: $1, TypePos: $3.pos}
+	}
+|	tTHIS tDOT tTYPE
+	{
+		$$ = &ast.SingletonType{Ref: &ast.This{ThisPos: $1.pos}, TypePos: $3.pos}
+	}
+|	simple_type tLBRACK types tRBRACK
+	{
+		$$ = &ast.AppliedType{Type: $1, Lbrack: $2.pos, Args: $3, Rbrack: $4.pos}
+	}
+|	simple_type tHASH id
+	{
+		$$ = &ast.Projection{X: $1, Sel: $3}
+	}
+|	tLPAREN types tRPAREN
+	{
+		if len($2) == 1 {
+			$$ = $2[0]
+		} else {
+			$$ = &ast.TupleType{Lparen: $1.pos, Elts: $2, Rparen: $3.pos}
+		}
+	}
+|	tUSCORE type_bounds
+	{
+		$$ = &ast.WildcardType{Underscore: $1.pos, Lo: $2.lo, Hi: $2.hi}
+	}
+
+types:
+	typ
+	{
+		$$ = []ast.Type{$1}
+	}
+|	types tCOMMA typ
+	{
+		$$ = append($1, $3)
+	}
+
+%%
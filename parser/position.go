@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position is a human readable source location. Line and Column are
+// 1-based; Column counts bytes like go/token does.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// LineEnding is the line terminator style of a source file.
+type LineEnding int
+
+const (
+	NoLineEnding     LineEnding = iota // the input is a single line
+	LF                                 // "\n"
+	CRLF                               // "\r\n"
+	CR                                 // a lone "\r"
+	MixedLineEndings                   // more than one of the above
+)
+
+func (e LineEnding) String() string {
+	switch e {
+	case NoLineEnding:
+		return "none"
+	case LF:
+		return "LF"
+	case CRLF:
+		return "CRLF"
+	case CR:
+		return "CR"
+	case MixedLineEndings:
+		return "mixed"
+	}
+	return fmt.Sprintf("LineEnding(%d)", int(e))
+}
+
+// Terminator returns the text to write at the end of a line so that the
+// original style is kept. Files without a single dominant style get "\n".
+func (e LineEnding) Terminator() string {
+	switch e {
+	case CRLF:
+		return crlf
+	case CR:
+		return carriageReturn
+	}
+	return lineFeed
+}
+
+// DetectLineEnding reports the line terminator style used by src.
+func DetectLineEnding(src string) LineEnding {
+	res := NoLineEnding
+	for i := 0; i < len(src); i++ {
+		var found LineEnding
+		switch src[i] {
+		case '\n':
+			found = LF
+		case '\r':
+			found = CR
+			if strings.HasPrefix(src[i:], crlf) {
+				found = CRLF
+				i++
+			}
+		default:
+			continue
+		}
+		if res != NoLineEnding && res != found {
+			return MixedLineEndings
+		}
+		res = found
+	}
+	return res
+}
+
+// LineEnding reports the line terminator style of the lexer's input.
+func (l *lexer) LineEnding() LineEnding {
+	return DetectLineEnding(l.input)
+}
+
+// Position converts a byte offset, such as Token.Pos, into a line and
+// column. "\r\n", "\n" and a lone "\r" each end exactly one line.
+func (l *lexer) Position(offset int) Position {
+	if l.lineStarts == nil {
+		l.lineStarts = lineStarts(l.input)
+	}
+	line := sort.SearchInts(l.lineStarts, offset+1) - 1
+	return Position{Offset: offset, Line: line + 1, Column: offset - l.lineStarts[line] + 1}
+}
+
+// lineStarts returns the offsets at which each line of src begins.
+func lineStarts(src string) []int {
+	res := []int{0}
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '\r':
+			if strings.HasPrefix(src[i:], crlf) {
+				i++
+			}
+			res = append(res, i+1)
+		case '\n':
+			res = append(res, i+1)
+		}
+	}
+	return res
+}
@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encodings understood by DecodeSource.
+const (
+	AutoEncoding = "auto"
+	UTF8         = "utf-8"
+	UTF16        = "utf-16" // big endian unless a BOM says otherwise
+	UTF16LE      = "utf-16le"
+	UTF16BE      = "utf-16be"
+	ISO88591     = "iso-8859-1"
+	Windows1252  = "windows-1252"
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+var encodingAliases = map[string]string{
+	"":             AutoEncoding,
+	"auto":         AutoEncoding,
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"utf-16":       UTF16,
+	"utf-16le":     UTF16LE,
+	"utf-16be":     UTF16BE,
+	"iso-8859-1":   ISO88591,
+	"iso8859-1":    ISO88591,
+	"latin1":       ISO88591,
+	"latin-1":      ISO88591,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+}
+
+// cp1252 maps bytes 0x80-0x9F of windows-1252 to their runes; the rest of
+// the code page agrees with ISO-8859-1. Unassigned bytes map to U+FFFD.
+var cp1252 = [32]rune{
+	'€', '\uFFFD', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\uFFFD', 'Ž', '\uFFFD',
+	'\uFFFD', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\uFFFD', 'ž', 'Ÿ',
+}
+
+// Source is a source file transcoded to the UTF-8 text the lexer expects.
+// It remembers where each rune came from in the raw bytes, so that
+// positions can be reported against the original file.
+type Source struct {
+	Text     string // UTF-8 text without byte-order mark
+	Encoding string // encoding the raw bytes were decoded from
+	BOM      bool   // whether the raw bytes started with a byte-order mark
+
+	bomLen int
+	// parallel offsets of rune starts in Text and in the raw bytes; both
+	// are nil when the raw bytes were UTF-8 already
+	textOffsets []int
+	rawOffsets  []int
+}
+
+// DecodeSource transcodes raw to UTF-8. A byte-order mark always wins
+// and is stripped; otherwise encoding names the legacy encoding of raw,
+// with "auto" (or "") meaning UTF-8.
+func DecodeSource(raw []byte, encoding string) (*Source, error) {
+	enc, ok := encodingAliases[strings.ToLower(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	src := &Source{Encoding: enc}
+	switch {
+	case bytes.HasPrefix(raw, utf8BOM):
+		src.Encoding, src.bomLen = UTF8, len(utf8BOM)
+	case bytes.HasPrefix(raw, utf16LEBOM):
+		src.Encoding, src.bomLen = UTF16LE, len(utf16LEBOM)
+	case bytes.HasPrefix(raw, utf16BEBOM):
+		src.Encoding, src.bomLen = UTF16BE, len(utf16BEBOM)
+	case enc == AutoEncoding:
+		src.Encoding = UTF8
+	case enc == UTF16:
+		src.Encoding = UTF16BE
+	}
+	src.BOM = src.bomLen > 0
+	body := raw[src.bomLen:]
+	var err error
+	switch src.Encoding {
+	case UTF8:
+		err = src.decodeUTF8(body)
+	case UTF16LE, UTF16BE:
+		err = src.decodeUTF16(body)
+	default:
+		src.decodeSingleByte(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func (s *Source) decodeUTF8(body []byte) error {
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("invalid UTF-8 at byte offset %d", s.bomLen+i)
+		}
+		i += size
+	}
+	s.Text = string(body)
+	return nil
+}
+
+func (s *Source) decodeUTF16(body []byte) error {
+	if len(body)%2 != 0 {
+		return fmt.Errorf("odd number of bytes in %s input", s.Encoding)
+	}
+	units := make([]uint16, len(body)/2)
+	for i := range units {
+		if s.Encoding == UTF16LE {
+			units[i] = uint16(body[2*i]) | uint16(body[2*i+1])<<8
+		} else {
+			units[i] = uint16(body[2*i])<<8 | uint16(body[2*i+1])
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		width := 1
+		if utf16.IsSurrogate(r) {
+			if i+1 == len(units) {
+				return fmt.Errorf("truncated surrogate pair at byte offset %d", s.bomLen+2*i)
+			}
+			r = utf16.DecodeRune(r, rune(units[i+1]))
+			if r == utf8.RuneError {
+				return fmt.Errorf("invalid surrogate pair at byte offset %d", s.bomLen+2*i)
+			}
+			width = 2
+		}
+		s.mark(b.Len(), s.bomLen+2*i)
+		b.WriteRune(r)
+		i += width - 1
+	}
+	s.Text = b.String()
+	return nil
+}
+
+func (s *Source) decodeSingleByte(body []byte) {
+	var b strings.Builder
+	for i, c := range body {
+		r := rune(c)
+		if s.Encoding == Windows1252 && c >= 0x80 && c < 0xA0 {
+			r = cp1252[c-0x80]
+		}
+		s.mark(b.Len(), s.bomLen+i)
+		b.WriteRune(r)
+	}
+	s.Text = b.String()
+}
+
+func (s *Source) mark(textOffset, rawOffset int) {
+	s.textOffsets = append(s.textOffsets, textOffset)
+	s.rawOffsets = append(s.rawOffsets, rawOffset)
+}
+
+// RawOffset maps a byte offset in Text, such as Token.Pos, back to the
+// offset of the same character in the raw bytes.
+func (s *Source) RawOffset(offset int) int {
+	if s.textOffsets == nil {
+		return s.bomLen + offset
+	}
+	i := sort.SearchInts(s.textOffsets, offset+1) - 1
+	if i < 0 {
+		return s.bomLen
+	}
+	if offset >= len(s.Text) {
+		// one past the end maps to one past the raw end
+		last := len(s.rawOffsets) - 1
+		return s.rawOffsets[last] + s.rawWidth()
+	}
+	return s.rawOffsets[i]
+}
+
+// rawWidth is the number of raw bytes of the last decoded character.
+func (s *Source) rawWidth() int {
+	switch s.Encoding {
+	case UTF16LE, UTF16BE:
+		_, size := utf8.DecodeLastRuneInString(s.Text)
+		if size == 4 {
+			return 4
+		}
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,61 @@
+package parser
+
+import "testing"
+
+var decodeSourceTests = []struct {
+	raw      []byte
+	encoding string
+	text     string
+	detected string
+	bom      bool
+}{
+	{[]byte("val a"), "", "val a", UTF8, false},
+	{[]byte("\xEF\xBB\xBFval a"), "", "val a", UTF8, true},
+	{[]byte("\xFF\xFEv\x00\xe9\x00"), "", "vé", UTF16LE, true},
+	{[]byte("\xFE\xFF\x00v\x00\xe9"), "latin1", "vé", UTF16BE, true},
+	{[]byte("\x00v\xD8\x3D\xDE\x00"), "utf-16", "v😀", UTF16BE, false},
+	{[]byte("caf\xe9"), "ISO-8859-1", "café", ISO88591, false},
+	{[]byte("\x93q\x94"), "cp1252", "“q”", Windows1252, false},
+}
+
+func TestDecodeSource(t *testing.T) {
+	for _, test := range decodeSourceTests {
+		src, err := DecodeSource(test.raw, test.encoding)
+		if err != nil {
+			t.Errorf("DecodeSource(%q, %q) failed: %s", test.raw, test.encoding, err)
+			continue
+		}
+		if src.Text != test.text || src.Encoding != test.detected || src.BOM != test.bom {
+			t.Errorf("DecodeSource(%q, %q) = %q %s %t, Expected = %q %s %t", test.raw, test.encoding,
+				src.Text, src.Encoding, src.BOM, test.text, test.detected, test.bom)
+		}
+	}
+	if _, err := DecodeSource([]byte("caf\xe9"), ""); err == nil {
+		t.Errorf("DecodeSource accepted invalid UTF-8")
+	}
+	if _, err := DecodeSource([]byte("a"), "ebcdic"); err == nil {
+		t.Errorf("DecodeSource accepted an unknown encoding")
+	}
+}
+
+func TestRawOffset(t *testing.T) {
+	// "é x" in UTF-16LE with a BOM; x is the third character
+	src, err := DecodeSource([]byte("\xFF\xFE\xe9\x00 \x00x\x00"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := Lexer(src.Text).LexTillDone()
+	if len(tokens) != 2 {
+		t.Fatalf("Lex(%q) = %s, Expected 2 tokens", src.Text, tokens)
+	}
+	if offset := src.RawOffset(tokens[1].Pos); offset != 6 {
+		t.Errorf("RawOffset(%d) = %d, Expected = 6", tokens[1].Pos, offset)
+	}
+	if offset := src.RawOffset(len(src.Text)); offset != 8 {
+		t.Errorf("RawOffset(end) = %d, Expected = 8", offset)
+	}
+	src, _ = DecodeSource([]byte("\xEF\xBB\xBFab"), "")
+	if offset := src.RawOffset(1); offset != 4 {
+		t.Errorf("RawOffset(1) = %d, Expected = 4", offset)
+	}
+}
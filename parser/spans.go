@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// A TokenSpan is the extent of a token in the source text. The extent of
+// a string or character literal includes its quotes. Besides the tokens
+// the parsers read, the spans of a text include its trivia: WHITESPACE,
+// which includes line breaks, COMMENT, SHEBANG for a script header, and
+// ERROR for text that is not a token.
+type TokenSpan struct {
+	Typ TokenType
+	Pos ast.Pos
+	End ast.Pos
+}
+
+// IsTrivia reports whether s is trivia, which the parsers skip.
+func (s TokenSpan) IsTrivia() bool {
+	switch s.Typ {
+	case WHITESPACE, COMMENT, SHEBANG, ERROR:
+		return true
+	}
+	return false
+}
+
+// ParseFileSpans is like ParseFile, but also returns the spans of the
+// tokens and trivia of src, which cover src without gaps or overlaps.
+func ParseFileSpans(name, src string) (*ast.File, []TokenSpan, error) {
+	p := newParser(name, src, 0)
+	f := p.parseFile()
+	return f, spans(src, 0, p.items), p.errors.Err()
+}
+
+// ParseBlockSpans parses the block expression src[start:end], a block or
+// an anonymous function in braces, which is returned as an *ast.Block or
+// an *ast.PartialFunction with positions in src. It also returns the
+// spans of src[start:end]. It fails if the block does not extend from
+// start to end.
+func ParseBlockSpans(name, src string, start, end int) (ast.Expr, []TokenSpan, error) {
+	p := newParser(name, src[:end], start)
+	var x ast.Expr
+	if p.tok.is(L_CURLY) {
+		x = p.parseBlockExpr()
+		p.expectEnd("block")
+	} else {
+		p.errorExpected("{")
+	}
+	return x, spans(src[:end], start, p.items), p.errors.Err()
+}
+
+// ParseTemplateBodySpans parses the template body src[start:end], the
+// self type and members of a class, trait or object in braces, and
+// returns them as an *ast.Template without parents, with positions in
+// src. It also returns the spans of src[start:end]. It fails if the body
+// does not extend from start to end.
+func ParseTemplateBodySpans(name, src string, start, end int) (*ast.Template, []TokenSpan, error) {
+	p := newParser(name, src[:end], start)
+	tmpl := &ast.Template{}
+	if p.tok.is(L_CURLY) {
+		p.parseTemplateBody(tmpl)
+		p.expectEnd("template body")
+	} else {
+		p.errorExpected("{")
+	}
+	return tmpl, spans(src[:end], start, p.items), p.errors.Err()
+}
+
+// spans returns the spans of src[offset:], whose tokens are items.
+func spans(src string, offset int, items []*item) []TokenSpan {
+	var list []TokenSpan
+	pos := offset
+	for _, it := range items {
+		if it.is(NEWLINE) || it.is(NEWLINES) || it.is(EOF) || it.pos.Offset() < pos {
+			continue
+		}
+		list = trivia(list, src, pos, it.pos.Offset())
+		list = append(list, TokenSpan{Typ: it.tok.Typ, Pos: it.pos, End: it.end})
+		pos = it.end.Offset()
+	}
+	return trivia(list, src, pos, len(src))
+}
+
+// trivia appends the spans of src[start:end], which holds no tokens the
+// parsers read, to list.
+func trivia(list []TokenSpan, src string, start, end int) []TokenSpan {
+	for i := start; i < end; {
+		rest := src[i:end]
+		typ := COMMENT
+		var n int
+		switch {
+		case strings.IndexByte(whitespace, rest[0]) >= 0:
+			typ = WHITESPACE
+			n = len(rest) - len(strings.TrimLeft(rest, whitespace))
+		case strings.HasPrefix(rest, linecomment):
+			n = strings.IndexAny(rest, newline)
+			if n < 0 {
+				n = len(rest)
+			}
+		case strings.HasPrefix(rest, spancomment):
+			n = blockCommentLen(rest)
+		case i == 0 && strings.HasPrefix(rest, shebang):
+			typ = SHEBANG
+			n = strings.IndexAny(rest, newline)
+			if n < 0 {
+				n = len(rest)
+			}
+		default:
+			typ = ERROR
+			n = strings.IndexAny(rest[1:], whitespace+"/") + 1
+			if n == 0 {
+				n = len(rest)
+			}
+		}
+		list = append(list, TokenSpan{Typ: typ, Pos: ast.PosOf(i), End: ast.PosOf(i + n)})
+		i += n
+	}
+	return list
+}
@@ -12,8 +12,14 @@ func dummy() {
 }
 
 func lexStart(l *lexer) *Token {
-	l.acceptRun(whitespaceSansNewline)
+	if l.pos == 0 && strings.HasPrefix(l.input, shebang) {
+		return lexShebang(l)
+	}
+	l.acceptRunFunc(isWhitespace)
 	l.ignore()
+	if isUsingDirective(l.input[l.pos:]) && inDirectiveHeader(l) {
+		return lexUsingDirective(l)
+	}
 	if strings.HasPrefix(l.input[l.pos:], linecomment) {
 		return lexLineComment(l)
 	}
@@ -33,14 +39,21 @@ func lexStart(l *lexer) *Token {
 			l.skip()
 			return lexCharacterLiteral(l)
 		}
+		if l.dialect == Scala3 {
+			return lexQuote(l)
+		}
 		// TODO(sundaram): handle error case
 		// consume single quote
 		l.next()
 		lexPlainId(l)
 		return l.emit(SYMBOL, lexStart)
 	}
-	if l.accept(letter) {
-		l.backup()
+	if l.dialect == Scala3 && strings.HasPrefix(l.input[l.pos:], splice) {
+		l.pos += len(splice)
+		l.regionStack = append(l.regionStack, L_CURLY)
+		return l.emit(SPLICE, lexStart)
+	}
+	if isLetter(l.peek()) {
 		return lexLetter(l)
 	}
 	if l.accept(num) {
@@ -58,10 +71,10 @@ func lexStart(l *lexer) *Token {
 			l.regionStack = append(l.regionStack, tokenType)
 		case R_PAREN, R_BRACKET, R_CURLY:
 			if len(l.regionStack) == 0 {
-				return l.emitError("closing paren found without a matching opening bracket", l.val())
+				return l.emitErrorf("closing paren found without a matching opening bracket: %q", l.val())
 			}
 			if false == isMatchingParen(l.regionStack[len(l.regionStack)-1], tokenType) {
-				return l.emitError("Unmatched paren type", l.val())
+				return l.emitErrorf("Unmatched paren type: %q", l.val())
 			}
 			l.regionStack = l.regionStack[:len(l.regionStack)-1]
 		}
@@ -79,14 +92,17 @@ func lexStart(l *lexer) *Token {
 	if l.accept(backtick) {
 		return lexStringIdIn(l)
 	}
-	if l.accept(newline) {
+	if l.acceptNewline() {
 		return lexNewline(l)
 	}
-	if isOpOrDelim(l.input[l.pos:]) {
-		return lexOpDelim(l)
+	if l.accept(comma) {
+		return l.emit(OPORDELIM, lexStart)
 	}
-	if isOperatorCharacter(l.peek()) {
+	if IsOperatorChar(l.peek()) {
 		lexOp(l)
+		if isReservedOp(l.val()) {
+			return l.emit(OPORDELIM, lexStart)
+		}
 		return l.emit(IDENTIFIER, lexStart)
 	}
 	if l.peek() == eof {
@@ -94,18 +110,24 @@ func lexStart(l *lexer) *Token {
 	}
 
 	// leave the curren token and start from the next
-	res := l.emitError("unknown lexemes left: ", l.input[l.pos:])
+	res := l.emitErrorf("unknown lexemes left: %q", l.input[l.pos:])
 	return res
 }
 
-func lexOpDelim(l *lexer) *Token {
-	for _, od := range opDelims {
-		if strings.HasPrefix(l.input[l.pos:], od) {
-			l.pos += len(od)
-			return l.emit(OPORDELIM, lexStart)
-		}
-	}
-	return l.emitError("Unexpected extry into lexOpDelim")
+// lexQuote lexes a Scala 3 quote: '{ ... } quotes an expression, '[ ... ]
+// a type and 'x an identifier. The opening brace or bracket is part of the
+// token and starts a region like any other.
+func lexQuote(l *lexer) *Token {
+	l.next() // consume single quote
+	if l.accept("{") {
+		l.regionStack = append(l.regionStack, L_CURLY)
+		return l.emit(QUOTE, lexStart)
+	}
+	if l.accept("[") {
+		l.regionStack = append(l.regionStack, L_BRACKET)
+		return l.emit(TYPE_QUOTE, lexStart)
+	}
+	return l.emit(QUOTE, lexStart)
 }
 
 func lexLineComment(l *lexer) *Token {
@@ -114,8 +136,35 @@ func lexLineComment(l *lexer) *Token {
 	return l.emit(COMMENT, lexStart)
 }
 
+// lexShebang lexes the "#!" interpreter line a script may start with.
+func lexShebang(l *lexer) *Token {
+	l.acceptRunAllBut(newline)
+	return l.emit(SHEBANG, lexStart)
+}
+
+// inDirectiveHeader reports whether only a shebang, comments and other
+// directives precede the current position. scala-cli ignores "//> using"
+// lines anywhere else.
+func inDirectiveHeader(l *lexer) bool {
+	return l.lastToken == nil || l.lastToken.Typ == SHEBANG || l.lastToken.Typ == USING_DIRECTIVE
+}
+
+// lexUsingDirective lexes a whole "//> using key values" line. The key
+// and values are parsed on demand by ParseUsingDirective.
+func lexUsingDirective(l *lexer) *Token {
+	l.acceptRunAllBut(newline)
+	return l.emit(USING_DIRECTIVE, lexStart)
+}
+
 func lexOp(l *lexer) {
-	l.acceptRunAllBut(whitespace + alphaLower + alphaUpper + num + paren + delim + newline)
+	for IsOperatorChar(l.peek()) {
+		// a comment ends the operator run
+		rest := l.input[l.pos:]
+		if l.pos > l.start && (strings.HasPrefix(rest, linecomment) || strings.HasPrefix(rest, spancomment)) {
+			return
+		}
+		l.next()
+	}
 }
 
 func lexSpanComment(l *lexer, level int) *Token {
@@ -139,10 +188,15 @@ func lexSpanComment(l *lexer, level int) *Token {
 }
 
 func lexNewline(l *lexer) *Token {
-	l.accept(whitespaceSansNewline)
+	l.acceptRunFunc(isWhitespace)
 	// check if the next line is a blank line
-	blankLine := l.accept(newline)
-	l.acceptRun(whitespace)
+	blankLine := l.acceptNewline()
+	for blankLine {
+		l.acceptRunFunc(isWhitespace)
+		if !l.acceptNewline() {
+			break
+		}
+	}
 	l.ignore()
 	if shouldIntroduceNewLine(l) {
 		if blankLine {
@@ -153,25 +207,80 @@ func lexNewline(l *lexer) *Token {
 	return lexStart(l)
 }
 
+// shouldIntroduceNewLine implements the rules of SLS 1.2: a line ending is
+// treated as a NEWLINE token if the token before it can terminate a
+// statement, the token after it can begin one and the line ending appears
+// in a region where newlines are enabled.
+func shouldIntroduceNewLine(l *lexer) bool {
+	if l.lastToken == nil || !canTerminateStatement(l.lastToken) {
+		return false
+	}
+	if len(l.regionStack) > 0 && l.regionStack[len(l.regionStack)-1] != L_CURLY {
+		return false
+	}
+	// comments are skipped; the next real token decides
+	i := 0
+	next, err := l.lookAhead(i)
+	for err == nil && next != nil && next.Typ == COMMENT {
+		i++
+		next, err = l.lookAhead(i)
+	}
+	if err != nil || next == nil || !canBeginStatement(next) {
+		return false
+	}
+	if next.Typ == CASE {
+		// case only begins a statement when followed by class or object
+		after, err := l.lookAhead(i + 1)
+		return err == nil && after != nil && (after.Typ == CLASS || after.Typ == OBJECT)
+	}
+	return true
+}
+
+func canTerminateStatement(t *Token) bool {
+	switch t.Typ {
+	case IDENTIFIER, SYMBOL, NUMBER, BOOLEAN, CHARACTER, STRING,
+		THIS, NULL, TRUE, FALSE, RETURN, TYPE,
+		R_PAREN, R_BRACKET, R_CURLY:
+		return true
+	}
+	return false
+}
+
+func canBeginStatement(t *Token) bool {
+	switch t.Typ {
+	case EOF, ERROR, NEWLINE, NEWLINES, SEMICOLON, DOT,
+		L_BRACKET, R_PAREN, R_BRACKET, R_CURLY,
+		CATCH, ELSE, EXTENDS, FINALLY, FORSOME, MATCH, WITH, YIELD:
+		return false
+	case OPORDELIM:
+		switch t.Val {
+		case ",", ":", "=", "=>", "<-", "<:", "<%", ">:", "#":
+			return false
+		}
+	}
+	return true
+}
+
 func lexPlainId(l *lexer) error {
-	if isOperatorCharacter(l.peek()) {
+	if IsOperatorChar(l.peek()) {
 		lexOp(l)
 		return nil
 	}
-	if l.accept(letter) {
-		l.acceptRun(letterNum)
-		if l.lastAccepted() == '_' {
-			// after '_' we could have optional op characters
+	if isLetter(l.peek()) {
+		l.acceptRunFunc(isLetterOrDigit)
+		if strings.HasSuffix(l.val(), "_") && l.val() != "_" {
+			// after '_' we could have optional op characters; a lone
+			// '_' is a wildcard, so in _: and _* the op is a token of
+			// its own
 			lexOp(l)
 		}
 		return nil
 	}
-	return fmt.Errorf("Unexpected entry into lexPlainId %s", l.peek())
+	return fmt.Errorf("Unexpected entry into lexPlainId %q", l.peek())
 }
 
 func lexLetter(l *lexer) *Token {
-	if l.accept(letter) {
-		l.backup()
+	if isLetter(l.peek()) {
 		err := lexPlainId(l)
 		if err == nil {
 			if isBoolean(l.val()) {
@@ -185,13 +294,11 @@ func lexLetter(l *lexer) *Token {
 		return l.emitError(err.Error())
 	}
 	return l.emitError("unknown entry into lexLetter")
-	// TODO(sundarama): Change this to emit error
-	return nil
 }
 
 func lexNumber(l *lexer) *Token {
 	if !l.scanNumber() {
-		return l.emitError("bad number syntax: %q", l.input[l.start:l.pos])
+		return l.emitErrorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
 	return l.emit(NUMBER, lexStart)
 }
@@ -256,10 +363,10 @@ func lexStringIdIn(l *lexer) *Token {
 	// 	lexStringBackslash(l)
 	// 	return lexStringIdIn(l)
 	// }
-	if l.peek() == '`' {
-		return l.emit(IDENTIFIER, lexIgnoreNextCharacter)
+	if l.accept(backtick) {
+		return l.emit(IDENTIFIER, lexStart)
 	}
-	return l.emitError("Unknown state within lexStringIdIn" + l.input[l.pos:])
+	return l.emitErrorf("Unknown state within lexStringIdIn: %q", l.input[l.pos:])
 }
 
 func lexMultiLineStringIn(l *lexer) *Token {
@@ -285,7 +392,7 @@ func lexCharacterLiteral(l *lexer) *Token {
 		l.next()
 		return res
 	}
-	return l.emitError("consumed open single quote; expected end single quote; but found", l.val())
+	return l.emitErrorf("consumed open single quote; expected end single quote; but found %q", l.val())
 }
 
 // TODO turn \[A-Z] into char code
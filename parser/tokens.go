@@ -6,16 +6,23 @@ const eof = -1
 
 const (
 	semicolon             = ";"
-	newline               = "\n"
+	lineFeed              = "\n"
+	carriageReturn        = "\r"
+	crlf                  = carriageReturn + lineFeed
+	newline               = lineFeed + carriageReturn
 	space                 = " "
 	tab                   = "\t"
-	whitespaceSansNewline = space + tab
+	formFeed              = "\f"
+	whitespaceSansNewline = space + tab + formFeed
 	whitespace            = whitespaceSansNewline + newline
 	quote                 = "\""
 	singlequote           = "'"
 	multilinequote        = "\"\"\""
 	backslash             = "\\"
 	linecomment           = "//"
+	usingdirective        = "//> using"
+	shebang               = "#!"
+	splice                = "${"
 	spancomment           = "/*"
 	alphaLower            = "abcdefghijklmnopqrstuvwxyz"
 	alphaUpper            = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -28,9 +35,11 @@ const (
 	paren                 = "()[]{}"
 	delim                 = "`'\".;,"
 	backtick              = "`"
+	comma                 = ","
+	opChars               = "!#%&*+-/:<=>?@\\^|~"
 )
 
-var opDelims = [...]string{"=>", "<-", ">:", "<:", "<%", "-", ",", "#", "@", ":", "*", "=", "|", "~", "!", "+", "-"}
+var reservedOps = [...]string{"=>", "<-", ">:", "<:", "<%", "#", "@", ":", "=", "\u21D2", "\u2190"}
 var keywords = [...]string{"abstract", "case", "catch", "class", "def", "do", "else", "extends", "final", "finally", "for", "forSome", "if", "implicit", "import", "lazy", "match", "new", "null", "object", "override", "package", "private", "protected", "return", "sealed", "super", "this", "throw", "trait", "try", "type", "val", "var", "while", "with", "yield"}
 
 const (
@@ -97,8 +106,18 @@ const (
 	WHILE
 	WITH
 	YIELD
+	// added after the keywords, so that the values above stay the same
+	SHEBANG
+	USING_DIRECTIVE
+	// Scala 3 metaprogramming
+	QUOTE
+	TYPE_QUOTE
+	SPLICE
 )
 
+// lastTokenType is the TokenType with the highest value.
+const lastTokenType = SPLICE
+
 var parenToTokenType = map[string]TokenType{
 	"(": L_PAREN,
 	")": R_PAREN,
@@ -121,7 +140,7 @@ var keywordsToTokenType = map[string]TokenType{
 	"final":     FINAL,
 	"finally":   FINALLY,
 	"for":       FOR,
-	"forsome":   FORSOME,
+	"forSome":   FORSOME,
 	"if":        IF,
 	"implicit":  IMPLICIT,
 	"import":    IMPORT,
@@ -174,6 +193,10 @@ func (i TokenType) String() string {
 		return "WHITESPACE"
 	case COMMENT:
 		return "COMMENT"
+	case SHEBANG:
+		return "SHEBANG"
+	case USING_DIRECTIVE:
+		return "USING_DIRECTIVE"
 	case NEWLINE:
 		return "NEWLINE"
 	case NEWLINES:
@@ -197,6 +220,12 @@ func (i TokenType) String() string {
 		return "["
 	case R_BRACKET:
 		return "]"
+	case QUOTE:
+		return "QUOTE"
+	case TYPE_QUOTE:
+		return "TYPE_QUOTE"
+	case SPLICE:
+		return "SPLICE"
 	// langauge keywords: return "// langauge keywords"
 	case ABSTRACT:
 		return "ABSTRACT"
@@ -214,6 +243,8 @@ func (i TokenType) String() string {
 		return "ELSE"
 	case EXTENDS:
 		return "EXTENDS"
+	case FALSE:
+		return "FALSE"
 	case FINAL:
 		return "FINAL"
 	case FINALLY:
@@ -258,6 +289,8 @@ func (i TokenType) String() string {
 		return "THROW"
 	case TRAIT:
 		return "TRAIT"
+	case TRUE:
+		return "TRUE"
 	case TRY:
 		return "TRY"
 	case TYPE:
@@ -0,0 +1,169 @@
+package printer
+
+import (
+	"strings"
+
+	"github.com/sundargates/scalaparser/ast"
+)
+
+// A CommentedNode bundles an AST node and the comments attached to it,
+// as by ast.NewCommentMap, so that Fprint prints the comments too.
+type CommentedNode struct {
+	Node     ast.Node
+	Comments ast.CommentMap
+}
+
+func (n *CommentedNode) Pos() ast.Pos { return n.Node.Pos() }
+func (n *CommentedNode) End() ast.Pos { return n.Node.End() }
+
+// take returns the groups of list that are not printed yet, and marks
+// them as printed.
+func (p *printer) take(list []*ast.CommentGroup) []*ast.CommentGroup {
+	res := p.unprinted(list)
+	for _, g := range res {
+		p.printed[g] = true
+	}
+	return res
+}
+
+// leading prints the leading comments of n, set apart by blank lines as
+// groups are. A group ends with a line break unless it is a single block
+// comment on one line that is not a doc comment.
+func (p *printer) leading(n ast.Node) {
+	c := p.comments[n]
+	if c == nil {
+		return
+	}
+	broke := false // the last group ended with a line break
+	for _, g := range p.take(c.Leading) {
+		if broke {
+			p.buf.WriteByte('\n')
+			p.newline()
+		}
+		p.group(g)
+		p.pendingNewline = false
+		first := g.List[0]
+		broke = len(g.List) > 1 || first.IsLine() || first.IsDoc() || strings.Contains(first.Text, "\n")
+		if !broke {
+			p.buf.WriteByte(' ')
+		}
+	}
+	if broke {
+		p.newline()
+	}
+}
+
+// trailing prints the trailing comments of n, preceded by its inner
+// comments that have not been printed inside it.
+func (p *printer) trailing(n ast.Node) {
+	c := p.comments[n]
+	if c == nil {
+		return
+	}
+	p.trail(p.take(c.Inner))
+	p.trail(p.take(c.Trailing))
+}
+
+// trail prints groups after the text on the current line.
+func (p *printer) trail(groups []*ast.CommentGroup) {
+	for _, g := range groups {
+		if !p.pendingNewline {
+			p.buf.WriteByte(' ')
+		}
+		p.group(g)
+	}
+}
+
+// inner prints the inner comments of n on lines of their own, after a
+// line break unless first is set.
+func (p *printer) inner(n ast.Node, first bool) {
+	c := p.comments[n]
+	if c == nil {
+		return
+	}
+	for _, g := range p.take(c.Inner) {
+		if !first {
+			p.newline()
+		}
+		first = false
+		p.group(g)
+	}
+}
+
+// hasInner reports whether n has inner comments that are not printed
+// yet.
+func (p *printer) hasInner(n ast.Node) bool {
+	c := p.comments[n]
+	return c != nil && len(p.unprinted(c.Inner)) > 0
+}
+
+// hasLeading reports whether n has leading comments that are not
+// printed yet.
+func (p *printer) hasLeading(n ast.Node) bool {
+	c := p.comments[n]
+	return c != nil && len(p.unprinted(c.Leading)) > 0
+}
+
+// unprinted returns the groups of list that are not printed yet.
+func (p *printer) unprinted(list []*ast.CommentGroup) []*ast.CommentGroup {
+	var res []*ast.CommentGroup
+	for _, g := range list {
+		if !p.printed[g] {
+			res = append(res, g)
+		}
+	}
+	return res
+}
+
+// group prints the comment group g. A line comment leaves a line break
+// pending: the next text printed starts a new line.
+func (p *printer) group(g *ast.CommentGroup) {
+	if p.pendingNewline {
+		p.newline()
+	}
+	for i, c := range g.List {
+		if i > 0 {
+			if g.List[i-1].IsLine() {
+				p.newline()
+			} else {
+				p.buf.WriteByte(' ')
+			}
+		}
+		p.buf.WriteString(p.reindent(c.Text))
+	}
+	p.pendingNewline = g.List[len(g.List)-1].IsLine()
+}
+
+// reindent indents the lines after the first of a block comment whose
+// lines start with * to the current indentation, plus one space, or two
+// for a doc comment, as in Scaladoc.
+func (p *printer) reindent(text string) string {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(strings.TrimLeft(line, " \t"), "*") {
+			return text
+		}
+	}
+	indent := strings.Repeat(" ", p.indent*p.indentWidth+1)
+	if strings.HasPrefix(text, "/**") {
+		indent += " "
+	}
+	for i, line := range lines[1:] {
+		lines[i+1] = indent + strings.TrimLeft(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// rest prints the comments of the nodes under node that were not printed
+// with them, each on a line of its own.
+func (p *printer) rest(node ast.Node) {
+	for _, g := range p.comments.Filter(node).Comments() {
+		if !p.printed[g] {
+			p.printed[g] = true
+			if p.buf.Len() > 0 {
+				p.newline()
+			}
+			p.group(g)
+		}
+	}
+}
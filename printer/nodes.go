@@ -0,0 +1,1305 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// Precedence levels of expressions, from loosest to tightest binding. An
+// infix operation whose operator has precedence p (see
+// parser.OperatorPrecedence) is at level precInfix+p.
+const (
+	precExpr    = iota // Expr: functions, control structures, assignments, ascriptions, matches
+	precPostfix        // PostfixExpr
+	precInfix          // InfixExpr
+	precPrefix  = precInfix + parser.SpecialPrecedence + 1
+	precSimple  = precPrefix + 1
+)
+
+// Precedence levels of patterns.
+const (
+	patAlt    = iota // Pattern: p1 | p2
+	patTyped         // Pattern1: x: T
+	patBind          // Pattern2: x @ p
+	patInfix         // Pattern3: an infix pattern at level patInfix+p
+	patSimple = patInfix + parser.SpecialPrecedence + 1
+)
+
+// Precedence levels of types.
+const (
+	typeAny      = iota // Type: function, existential, by-name and wildcard types
+	typeInfix           // InfixType
+	typeCompound        // CompoundType: T with U { ... }
+	typeAnnot           // AnnotType: T @a
+	typeSimple          // SimpleType
+)
+
+// node prints any node, choosing the expression syntax for nodes such as
+// identifiers that may be expressions, patterns and types alike.
+func (p *printer) node(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.File:
+		p.stats(n.Stats, true)
+		p.inner(n, len(n.Stats) == 0)
+	case ast.Stat:
+		p.stat(n, true)
+	case ast.Pattern:
+		p.pattern(n, patAlt)
+	case ast.Type:
+		p.typ(n, typeAny)
+	case ast.Enumerator:
+		p.enumerator(n)
+	case *ast.ArgList:
+		p.argList(n)
+	case *ast.CaseClause:
+		p.caseClause(n)
+	case *ast.Modifier:
+		p.modifier(n)
+	case *ast.Init:
+		p.init(n)
+	case *ast.Annotation:
+		p.annotation(n)
+	case *ast.Param:
+		p.param(n)
+	case *ast.ParamClause:
+		p.paramClause(n)
+	case *ast.TypeParam:
+		p.typeParam(n)
+	case *ast.Importer:
+		p.importer(n)
+	case *ast.ImportSelector:
+		p.importSelector(n)
+	case *ast.Template:
+		p.template(n, "extends")
+	case *ast.SelfType:
+		p.selfType(n)
+	default:
+		panic(fmt.Sprintf("printer: unexpected node type %T", n))
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Identifiers
+
+func (p *printer) ident(x *ast.Ident) {
+	p.leading(x)
+	defer p.trailing(x)
+	if x.Backquoted || !parser.IsIdentifier(x.Name) {
+		p.write("`" + x.Name + "`")
+		return
+	}
+	p.write(x.Name)
+}
+
+// name prints the name of a definition; the name this of a constructor
+// or a self type is not backquoted.
+func (p *printer) name(x *ast.Ident) {
+	if x.Name == "this" && !x.Backquoted {
+		p.write("this")
+		return
+	}
+	p.ident(x)
+}
+
+// ----------------------------------------------------------------------------
+// Statements
+
+// stats prints a statement sequence, one statement per line. Members of
+// files, packages and templates are set apart by blank lines when they
+// span several lines or start a new group of imports or definitions.
+func (p *printer) stats(list []ast.Stat, members bool) {
+	leading := make([]string, len(list))
+	lines := make([]string, len(list))
+	comment := make([]bool, len(list)) // the line ends in a line comment
+	for i, stat := range list {
+		last := i == len(list)-1 && !members
+		leading[i] = p.render(func() { p.leading(stat) })
+		lines[i] = p.render(func() { p.stat(stat, last) })
+		comment[i] = p.renderedLine
+	}
+	for i, line := range lines {
+		if i > 0 {
+			if members && (strings.Contains(lines[i-1], "\n") || strings.Contains(line, "\n") || group(list[i-1]) != group(list[i])) {
+				p.buf.WriteByte('\n')
+			}
+			p.newline()
+		}
+		p.buf.WriteString(leading[i])
+		p.buf.WriteString(line)
+		p.pendingNewline = comment[i]
+		// A statement starting with a brace would otherwise be taken
+		// as a block argument of the previous one.
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "{") {
+			p.write(";")
+		}
+	}
+}
+
+// group classifies members for blank line separation.
+func group(stat ast.Stat) int {
+	switch stat.(type) {
+	case *ast.PackageClause:
+		return 0
+	case *ast.Import:
+		return 1
+	}
+	return 2
+}
+
+// stat prints a statement. An anonymous function as a statement extends
+// to the end of its block, so it is parenthesized unless it is the last
+// statement of a block.
+func (p *printer) stat(s ast.Stat, last bool) {
+	p.leading(s)
+	defer p.trailing(s)
+	switch s := s.(type) {
+	case *ast.Function:
+		if last {
+			p.function(s)
+		} else {
+			p.write("(")
+			p.function(s)
+			p.write(")")
+		}
+	case ast.Expr:
+		p.expr(s, precExpr)
+	case *ast.BadStat:
+		p.write("BadStat")
+	case *ast.ValDef:
+		p.mods(s.Annotations, s.Modifiers)
+		p.write(s.Keyword + " ")
+		for i, pat := range s.Pats {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.pattern(pat, patBind)
+		}
+		if s.Type != nil {
+			p.write(": ")
+			p.typ(s.Type, typeAny)
+		}
+		if s.Rhs != nil {
+			p.write(" = ")
+			p.expr(s.Rhs, precExpr)
+		}
+	case *ast.DefDef:
+		p.mods(s.Annotations, s.Modifiers)
+		p.write("def ")
+		p.name(s.Name)
+		p.typeParams(s.TypeParams)
+		for _, clause := range s.Params {
+			p.paramClause(clause)
+		}
+		if s.ResultType != nil {
+			p.write(": ")
+			p.typ(s.ResultType, typeAny)
+		}
+		switch rhs := s.Rhs.(type) {
+		case nil:
+		case *ast.Block:
+			if s.Procedure && s.ResultType == nil {
+				p.write(" ")
+				p.expr1(rhs)
+				break
+			}
+			p.write(" = ")
+			p.expr(s.Rhs, precExpr)
+		default:
+			p.write(" = ")
+			p.expr(s.Rhs, precExpr)
+		}
+	case *ast.TypeDef:
+		p.mods(s.Annotations, s.Modifiers)
+		p.write("type ")
+		p.ident(s.Name)
+		p.typeParams(s.TypeParams)
+		p.bounds(s.Lo, s.Hi)
+		if s.Rhs != nil {
+			p.write(" = ")
+			p.typ(s.Rhs, typeAny)
+		}
+	case *ast.ClassDef:
+		p.mods(s.Annotations, s.Modifiers)
+		p.write("class ")
+		p.ident(s.Name)
+		p.typeParams(s.TypeParams)
+		for _, annot := range s.CtorAnnotations {
+			p.write(" ")
+			p.annotation(annot)
+			if len(annot.Init.Args) == 0 {
+				// a constructor annotation takes the first argument list
+				p.write("()")
+			}
+		}
+		for _, mod := range s.CtorModifiers {
+			p.write(" ")
+			p.modifier(mod)
+		}
+		if len(s.CtorAnnotations)+len(s.CtorModifiers) > 0 && len(s.Params) > 0 {
+			p.write(" ")
+		}
+		for _, clause := range s.Params {
+			p.paramClause(clause)
+		}
+		p.template(s.Template, "extends")
+	case *ast.TraitDef:
+		p.mods(s.Annotations, s.Modifiers)
+		p.write("trait ")
+		p.ident(s.Name)
+		p.typeParams(s.TypeParams)
+		p.template(s.Template, "extends")
+	case *ast.ObjectDef:
+		p.mods(s.Annotations, s.Modifiers)
+		if s.Package.IsValid() {
+			p.write("package ")
+		}
+		p.write("object ")
+		p.ident(s.Name)
+		p.template(s.Template, "extends")
+	case *ast.Import:
+		p.write("import ")
+		for i, imp := range s.Importers {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.importer(imp)
+		}
+	case *ast.PackageClause:
+		p.write("package ")
+		p.expr(s.Name, precSimple)
+		if s.Lbrace.IsValid() {
+			p.write(" ")
+			p.body(s, nil, s.Stats)
+			return
+		}
+		if len(s.Stats) > 0 {
+			if inner, ok := s.Stats[0].(*ast.PackageClause); !ok || inner.Lbrace.IsValid() {
+				p.buf.WriteByte('\n')
+			}
+			p.newline()
+			p.stats(s.Stats, true)
+		}
+		p.inner(s, false)
+	default:
+		panic(fmt.Sprintf("printer: unexpected statement type %T", s))
+	}
+}
+
+// mods prints the annotations and modifiers of a definition, each
+// followed by a space.
+func (p *printer) mods(annots []*ast.Annotation, mods []*ast.Modifier) {
+	for _, annot := range annots {
+		p.annotation(annot)
+		p.write(" ")
+	}
+	for _, mod := range mods {
+		p.modifier(mod)
+		p.write(" ")
+	}
+}
+
+func (p *printer) modifier(x *ast.Modifier) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.write(x.Name)
+	switch q := x.Qual.(type) {
+	case *ast.Ident:
+		p.write("[")
+		p.ident(q)
+		p.write("]")
+	case *ast.This:
+		p.write("[")
+		p.expr(q, precSimple)
+		p.write("]")
+	}
+}
+
+func (p *printer) annotation(x *ast.Annotation) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.write("@")
+	p.init(x.Init)
+}
+
+// init prints a constructor invocation. Its arguments are always
+// parenthesized, as braces would start a template body.
+func (p *printer) init(x *ast.Init) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.typ(x.Type, typeSimple)
+	for _, args := range x.Args {
+		p.write("(")
+		p.exprList(args.Args)
+		p.write(")")
+	}
+}
+
+func (p *printer) typeParams(list []*ast.TypeParam) {
+	if len(list) == 0 {
+		return
+	}
+	p.write("[")
+	for i, tp := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.typeParam(tp)
+	}
+	p.write("]")
+}
+
+func (p *printer) typeParam(x *ast.TypeParam) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.mods(x.Annotations, nil)
+	p.write(x.Variance)
+	p.ident(x.Name)
+	p.typeParams(x.TypeParams)
+	p.bounds(x.Lo, x.Hi)
+	for _, t := range x.ViewBounds {
+		p.write(" <% ")
+		p.typ(t, typeInfix)
+	}
+	for _, t := range x.ContextBounds {
+		p.write(": ")
+		p.typ(t, typeInfix)
+	}
+}
+
+// bounds prints the bounds >: lo <: hi, leaving out those that are nil.
+func (p *printer) bounds(lo, hi ast.Type) {
+	if lo != nil {
+		p.write(" >: ")
+		p.typ(lo, typeInfix)
+	}
+	if hi != nil {
+		p.write(" <: ")
+		p.typ(hi, typeInfix)
+	}
+}
+
+func (p *printer) paramClause(x *ast.ParamClause) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.write("(")
+	if x.Implicit.IsValid() {
+		p.write("implicit ")
+	}
+	for i, param := range x.Params {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.param(param)
+	}
+	p.write(")")
+}
+
+func (p *printer) param(x *ast.Param) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.mods(x.Annotations, x.Modifiers)
+	if x.Keyword != "" {
+		p.write(x.Keyword + " ")
+	}
+	p.ident(x.Name)
+	if x.Type != nil {
+		p.write(": ")
+		p.typ(x.Type, typeAny)
+	}
+	if x.Default != nil {
+		p.write(" = ")
+		p.expr(x.Default, precExpr)
+	}
+}
+
+func (p *printer) importer(x *ast.Importer) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.expr(x.Path, precSimple)
+	p.write(".")
+	if len(x.Selectors) == 1 {
+		if sel := x.Selectors[0]; sel.Rename == nil && sel.Type == nil {
+			p.importSelector(sel)
+			return
+		}
+	}
+	p.write("{")
+	for i, sel := range x.Selectors {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.importSelector(sel)
+	}
+	p.write("}")
+}
+
+func (p *printer) importSelector(x *ast.ImportSelector) {
+	p.leading(x)
+	defer p.trailing(x)
+	if x.Given.IsValid() || x.Name == nil {
+		p.write("given")
+		if x.Type != nil {
+			p.write(" ")
+			p.typ(x.Type, typeInfix)
+		}
+		return
+	}
+	if x.IsWildcard() {
+		p.write(x.Name.Name)
+		return
+	}
+	p.ident(x.Name)
+	if x.Rename != nil {
+		p.write(" => ")
+		p.ident(x.Rename)
+	}
+}
+
+// template prints the parents and body of a class, trait or object,
+// introduced by keyword, or of a new expression, where keyword is "".
+func (p *printer) template(x *ast.Template, keyword string) {
+	if len(x.EarlyDefs) > 0 || len(x.Parents) > 0 {
+		if keyword != "" {
+			p.write(" " + keyword)
+		}
+		p.write(" ")
+		if len(x.EarlyDefs) > 0 {
+			p.body(nil, nil, x.EarlyDefs)
+			p.write(" with ")
+		}
+		for i, parent := range x.Parents {
+			if i > 0 {
+				p.write(" with ")
+			}
+			p.init(parent)
+		}
+	}
+	if x.Lbrace.IsValid() || x.Self != nil || len(x.Stats) > 0 {
+		if keyword != "" || len(x.EarlyDefs) > 0 || len(x.Parents) > 0 {
+			p.write(" ")
+		}
+		p.body(x, x.Self, x.Stats)
+	}
+}
+
+// body prints a brace-enclosed member list with an optional self type,
+// followed by the inner comments of n.
+func (p *printer) body(n ast.Node, self *ast.SelfType, stats []ast.Stat) {
+	p.write("{")
+	if self != nil {
+		p.write(" ")
+		p.selfType(self)
+	}
+	if len(stats) > 0 || p.hasInner(n) {
+		p.indent++
+		p.newline()
+		p.stats(stats, true)
+		p.inner(n, len(stats) == 0)
+		p.indent--
+		p.newline()
+	}
+	p.write("}")
+}
+
+func (p *printer) selfType(x *ast.SelfType) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.name(x.Name)
+	if x.Type != nil {
+		p.write(": ")
+		p.typ(x.Type, typeInfix)
+	}
+	p.write(" =>")
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+
+// exprPrec returns the precedence level of x.
+func exprPrec(x ast.Expr) int {
+	switch x := x.(type) {
+	case *ast.Function, *ast.If, *ast.While, *ast.DoWhile, *ast.For, *ast.Try,
+		*ast.Throw, *ast.Return, *ast.Assign, *ast.Typed, *ast.Annotated, *ast.Match:
+		return precExpr
+	case *ast.InfixApply:
+		return precInfix + parser.OperatorPrecedence(x.Op.Name)
+	case *ast.PrefixApply:
+		return precPrefix
+	}
+	// postfix operations print their own parentheses
+	return precSimple
+}
+
+// expr prints x, parenthesized if it binds looser than prec.
+func (p *printer) expr(x ast.Expr, prec int) {
+	if exprPrec(x) < prec {
+		p.write("(")
+		p.expr1(x)
+		p.write(")")
+		return
+	}
+	p.expr1(x)
+}
+
+// prefix prints x as the prefix of a selection, application or type
+// application. A new expression is parenthesized there, as new C.x
+// means new (C.x).
+func (p *printer) prefix(x ast.Expr) {
+	if _, ok := x.(*ast.New); ok {
+		p.write("(")
+		p.expr1(x)
+		p.write(")")
+		return
+	}
+	p.expr(x, precSimple)
+}
+
+func (p *printer) expr1(x ast.Expr) {
+	p.leading(x)
+	defer p.trailing(x)
+	switch x := x.(type) {
+	case *ast.Ident:
+		p.ident(x)
+	case *ast.Select:
+		p.prefix(x.X)
+		p.write(".")
+		p.ident(x.Sel)
+	case *ast.This:
+		if x.Qual != nil {
+			p.ident(x.Qual)
+			p.write(".")
+		}
+		p.write("this")
+	case *ast.Super:
+		if x.Qual != nil {
+			p.ident(x.Qual)
+			p.write(".")
+		}
+		p.write("super")
+		if x.Mix != nil {
+			p.write("[")
+			p.ident(x.Mix)
+			p.write("]")
+		}
+	case *ast.Literal:
+		p.write(x.Value)
+	case *ast.Interpolation:
+		args := make([]string, len(x.Args))
+		for i, arg := range x.Args {
+			if id, ok := arg.(*ast.Ident); ok && !id.Backquoted && isSpliceName(id.Name, x.Parts[i+1]) {
+				args[i] = "$" + id.Name
+				continue
+			}
+			args[i] = p.splice(func() { p.stat(arg, true) })
+		}
+		p.interpolation(x.Id, x.Parts, args)
+	case *ast.BadExpr:
+		p.write("BadExpr")
+	case *ast.Apply:
+		p.prefix(x.Fun)
+		p.argList(x.Args)
+	case *ast.TypeApply:
+		p.prefix(x.Fun)
+		p.typeArgs(x.Targs)
+	case *ast.InfixApply:
+		prec := precInfix + parser.OperatorPrecedence(x.Op.Name)
+		right := parser.IsRightAssociative(x.Op.Name)
+		p.operand(x.X, prec, right, true)
+		p.write(" ")
+		p.ident(x.Op)
+		p.typeArgs(x.Targs)
+		p.write(" ")
+		if t, ok := x.Y.(*ast.Tuple); ok {
+			p.expr1(t)
+		} else {
+			p.operand(x.Y, prec, right, false)
+		}
+	case *ast.PrefixApply:
+		p.ident(x.Op)
+		if lit, ok := x.X.(*ast.Literal); ok {
+			if c, _ := utf8.DecodeRuneInString(lit.Value); parser.IsOperatorChar(c) {
+				// - -1 would be read as an infix operation
+				p.write("(")
+				p.expr1(lit)
+				p.write(")")
+				break
+			}
+			if x.Op.Name == "-" && lit.Kind <= ast.DoubleLit {
+				// -1 would be a negative literal
+				p.write(" ")
+			}
+		}
+		p.expr(x.X, precSimple)
+	case *ast.PostfixApply:
+		// A postfix operator at the end of a line would take the next
+		// line as its right operand.
+		p.write("(")
+		p.expr(x.X, precInfix)
+		p.write(" ")
+		p.ident(x.Op)
+		p.write(")")
+	case *ast.Assign:
+		p.expr(x.X, precSimple)
+		p.write(" = ")
+		p.expr(x.Rhs, precExpr)
+	case *ast.Typed:
+		p.expr(x.X, precPostfix)
+		p.write(": ")
+		p.typ(x.Type, typeAny)
+	case *ast.Annotated:
+		p.expr(x.X, precPostfix)
+		p.write(":")
+		for _, annot := range x.Annotations {
+			p.write(" ")
+			p.annotation(annot)
+		}
+	case *ast.Tuple:
+		p.write("(")
+		p.exprList(x.Elts)
+		p.write(")")
+	case *ast.Block:
+		p.block(x, x.Stats)
+	case *ast.Function:
+		p.function(x)
+	case *ast.PartialFunction:
+		p.cases(x, x.Cases)
+	case *ast.If:
+		p.write("if (")
+		p.expr(x.Cond, precExpr)
+		p.write(") ")
+		if x.Else != nil && danglingIf(x.Then) {
+			p.write("{")
+			p.indent++
+			p.newline()
+			p.expr(x.Then, precExpr)
+			p.indent--
+			p.newline()
+			p.write("}")
+		} else {
+			p.expr(x.Then, precExpr)
+		}
+		if x.Else != nil {
+			p.write(" else ")
+			p.expr(x.Else, precExpr)
+		}
+	case *ast.While:
+		p.write("while (")
+		p.expr(x.Cond, precExpr)
+		p.write(") ")
+		p.expr(x.Body, precExpr)
+	case *ast.DoWhile:
+		p.write("do ")
+		p.expr(x.Body, precExpr)
+		p.write(" while (")
+		p.expr(x.Cond, precExpr)
+		p.write(")")
+	case *ast.For:
+		p.write("for (")
+		for i, enum := range x.Enums {
+			if i > 0 {
+				p.write("; ")
+			}
+			p.enumerator(enum)
+		}
+		p.write(") ")
+		if x.Yield.IsValid() {
+			p.write("yield ")
+		}
+		p.expr(x.Body, precExpr)
+	case *ast.Try:
+		p.write("try ")
+		p.expr(x.Body, precExpr)
+		if len(x.Cases) > 0 {
+			p.write(" catch ")
+			p.cases(x, x.Cases)
+		} else if x.Catch != nil {
+			p.write(" catch ")
+			p.expr(x.Catch, precExpr)
+		}
+		if x.Finally != nil {
+			p.write(" finally ")
+			p.expr(x.Finally, precExpr)
+		}
+	case *ast.Throw:
+		p.write("throw ")
+		p.expr(x.X, precExpr)
+	case *ast.Return:
+		p.write("return")
+		if x.X != nil {
+			p.write(" ")
+			p.expr(x.X, precExpr)
+		}
+	case *ast.Match:
+		p.expr(x.X, precPostfix)
+		p.write(" match ")
+		p.cases(x, x.Cases)
+	case *ast.New:
+		p.write("new")
+		if len(x.Template.EarlyDefs) == 0 && len(x.Template.Parents) == 0 {
+			p.write(" ")
+		}
+		p.template(x.Template, "")
+	case *ast.Quote:
+		p.write("'")
+		switch body := x.Body.(type) {
+		case *ast.Block:
+			p.block(body, body.Stats)
+		case ast.Expr:
+			p.write("{ ")
+			p.expr(body, precExpr)
+			p.write(" }")
+		case ast.Type:
+			p.write("[")
+			p.typ(body, typeAny)
+			p.write("]")
+		}
+	case *ast.Splice:
+		p.write("${ ")
+		p.expr(x.X, precExpr)
+		p.write(" }")
+	default:
+		panic(fmt.Sprintf("printer: unexpected expression type %T", x))
+	}
+}
+
+// operand prints an operand of an infix operation at level prec whose
+// operator is right associative if right. An operand that is itself an
+// operation at the same level needs no parentheses only on the side the
+// operators associate to.
+func (p *printer) operand(x ast.Expr, prec int, right, left bool) {
+	if prec == precInfix+parser.LetterPrecedence && exprPrec(x) > prec && isPlaceholderFunc(x) {
+		p.write("(")
+		p.expr1(x)
+		p.write(")")
+		return
+	}
+	if y, ok := x.(*ast.InfixApply); ok && exprPrec(y) == prec {
+		if parser.IsRightAssociative(y.Op.Name) == right && left != right {
+			p.expr1(x)
+			return
+		}
+		p.write("(")
+		p.expr1(x)
+		p.write(")")
+		return
+	}
+	p.expr(x, prec+1)
+}
+
+// isPlaceholderFunc reports whether x is an operation with a placeholder
+// "_" among its operands, such as _ + 1. The parentheses around it are
+// kept in an operand of an alphanumeric operator, as xs map _ + 1 would
+// make the whole operation the anonymous function; a symbolic operator
+// is taken to continue it, as in _ * 2 + 1.
+func isPlaceholderFunc(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.InfixApply:
+		return hasPlaceholder(x.X) || hasPlaceholder(x.Y)
+	case *ast.PrefixApply:
+		return hasPlaceholder(x.X)
+	}
+	return false
+}
+
+// hasPlaceholder reports whether x has a placeholder "_" that is not
+// enclosed in parentheses, braces or an argument list of its own.
+func hasPlaceholder(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name == "_" && !x.Backquoted
+	case *ast.Select:
+		return hasPlaceholder(x.X)
+	case *ast.Apply:
+		return hasPlaceholder(x.Fun)
+	case *ast.TypeApply:
+		return hasPlaceholder(x.Fun)
+	case *ast.InfixApply:
+		return hasPlaceholder(x.X) || hasPlaceholder(x.Y)
+	case *ast.PrefixApply:
+		return hasPlaceholder(x.X)
+	}
+	return false
+}
+
+// danglingIf reports whether an else following x would be taken as the
+// else branch of an if nested at the end of x.
+func danglingIf(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.If:
+		if x.Else == nil {
+			return true
+		}
+		return danglingIf(x.Else)
+	case *ast.While:
+		return danglingIf(x.Body)
+	case *ast.For:
+		return danglingIf(x.Body)
+	case *ast.Function:
+		return danglingIf(x.Body)
+	case *ast.Assign:
+		return danglingIf(x.Rhs)
+	case *ast.Throw:
+		return danglingIf(x.X)
+	case *ast.Return:
+		return x.X != nil && danglingIf(x.X)
+	}
+	return false
+}
+
+func (p *printer) exprList(list []ast.Expr) {
+	for i, x := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(x, precExpr)
+	}
+}
+
+// argList prints an argument list. A single block or partial function
+// argument is passed in braces, as in xs.map { x => ... }.
+func (p *printer) argList(x *ast.ArgList) {
+	p.leading(x)
+	defer p.trailing(x)
+	if len(x.Args) == 1 {
+		switch arg := x.Args[0].(type) {
+		case *ast.Block, *ast.PartialFunction:
+			p.write(" ")
+			p.expr1(arg)
+			return
+		}
+	}
+	p.write("(")
+	p.exprList(x.Args)
+	p.write(")")
+}
+
+func (p *printer) typeArgs(list []ast.Type) {
+	if len(list) == 0 {
+		return
+	}
+	p.write("[")
+	p.typeList(list)
+	p.write("]")
+}
+
+// block prints the block n. A block made of an anonymous function is
+// printed as { params => stats }.
+func (p *printer) block(n ast.Node, stats []ast.Stat) {
+	if len(stats) == 0 && !p.hasInner(n) {
+		p.write("{}")
+		return
+	}
+	p.write("{")
+	if len(stats) == 1 {
+		if f, ok := stats[0].(*ast.Function); ok {
+			p.write(" ")
+			p.params(f)
+			p.write(" =>")
+			stats = []ast.Stat{f.Body}
+			if body, ok := f.Body.(*ast.Block); ok && !body.Lbrace.IsValid() {
+				stats = body.Stats
+			}
+		}
+	}
+	p.indent++
+	p.newline()
+	p.stats(stats, false)
+	p.inner(n, len(stats) == 0)
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+func (p *printer) function(x *ast.Function) {
+	p.params(x)
+	p.write(" => ")
+	p.expr(x.Body, precExpr)
+}
+
+// params prints the parameters of an anonymous function.
+func (p *printer) params(x *ast.Function) {
+	if x.Implicit.IsValid() {
+		p.write("implicit ")
+	}
+	if len(x.Params) == 1 {
+		if param := x.Params[0]; param.Type == nil && len(param.Annotations)+len(param.Modifiers) == 0 {
+			p.ident(param.Name)
+			return
+		}
+	}
+	p.write("(")
+	for i, param := range x.Params {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.param(param)
+	}
+	p.write(")")
+}
+
+// cases prints case clauses in braces, followed by the inner comments
+// of n.
+func (p *printer) cases(n ast.Node, list []*ast.CaseClause) {
+	p.write("{")
+	p.indent++
+	for _, c := range list {
+		p.newline()
+		p.caseClause(c)
+	}
+	p.inner(n, false)
+	p.indent--
+	p.newline()
+	p.write("}")
+}
+
+func (p *printer) caseClause(x *ast.CaseClause) {
+	p.leading(x)
+	defer p.trailing(x)
+	p.write("case ")
+	p.pattern(x.Pat, patAlt)
+	if x.Guard != nil {
+		p.write(" if ")
+		p.expr(x.Guard, precPostfix)
+	}
+	p.write(" =>")
+	switch {
+	case len(x.Body) == 0:
+	case len(x.Body) == 1 && !p.hasLeading(x.Body[0]):
+		p.write(" ")
+		p.stat(x.Body[0], true)
+	default:
+		p.indent++
+		p.newline()
+		p.stats(x.Body, false)
+		p.indent--
+	}
+}
+
+func (p *printer) enumerator(x ast.Enumerator) {
+	p.leading(x)
+	defer p.trailing(x)
+	switch x := x.(type) {
+	case *ast.Generator:
+		p.pattern(x.Pat, patTyped)
+		p.write(" <- ")
+		p.expr(x.Rhs, precExpr)
+	case *ast.ValueEnum:
+		p.pattern(x.Pat, patTyped)
+		p.write(" = ")
+		p.expr(x.Rhs, precExpr)
+	case *ast.Guard:
+		p.write("if ")
+		p.expr(x.Cond, precPostfix)
+	default:
+		panic(fmt.Sprintf("printer: unexpected enumerator type %T", x))
+	}
+}
+
+// interpolation prints an interpolated string from its parts and its
+// printed arguments, in triple quotes if any of them spans lines or holds
+// an unescaped quote.
+func (p *printer) interpolation(id *ast.Ident, parts, args []string) {
+	quote := `"`
+	for _, s := range append(parts[:len(parts):len(parts)], args...) {
+		if strings.Contains(s, "\n") || strings.Contains(strings.Replace(s, `\"`, "", -1), `"`) {
+			quote = `"""`
+		}
+	}
+	p.ident(id)
+	// the text of the string is written as is
+	p.buf.WriteString(quote + parts[0])
+	for i, arg := range args {
+		p.buf.WriteString(arg + parts[i+1])
+	}
+	p.buf.WriteString(quote)
+}
+
+// isSpliceName reports whether an argument name can be spliced as $name
+// into an interpolated string in front of the text next.
+func isSpliceName(name, next string) bool {
+	if !parser.IsIdentifier(name) || strings.ContainsAny(name, "$") {
+		return false
+	}
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			return false
+		}
+	}
+	c, _ := utf8.DecodeRuneInString(next)
+	return !(unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '$')
+}
+
+// ----------------------------------------------------------------------------
+// Patterns
+
+func patternPrec(x ast.Pattern) int {
+	switch x := x.(type) {
+	case *ast.Alternative:
+		return patAlt
+	case *ast.TypedPattern:
+		return patTyped
+	case *ast.Bind:
+		return patBind
+	case *ast.InfixPattern:
+		return patInfix + parser.OperatorPrecedence(x.Op.Name)
+	}
+	return patSimple
+}
+
+func (p *printer) pattern(x ast.Pattern, prec int) {
+	if patternPrec(x) < prec {
+		p.write("(")
+		p.pattern1(x)
+		p.write(")")
+		return
+	}
+	p.pattern1(x)
+}
+
+func (p *printer) pattern1(x ast.Pattern) {
+	p.leading(x)
+	defer p.trailing(x)
+	switch x := x.(type) {
+	case *ast.Ident, *ast.Select, *ast.Literal, *ast.Quote:
+		p.expr1(x.(ast.Expr))
+	case *ast.BadPattern:
+		p.write("BadPattern")
+	case *ast.Bind:
+		p.ident(x.Name)
+		p.write(" @ ")
+		p.pattern(x.Pat, patInfix)
+	case *ast.TypedPattern:
+		p.ident(x.X)
+		p.write(": ")
+		if t, ok := x.Type.(*ast.InfixType); ok && t.Op.Name == "|" {
+			// | would separate alternatives
+			p.write("(")
+			p.typ1(t)
+			p.write(")")
+			break
+		}
+		p.typ(x.Type, typeInfix)
+	case *ast.ExtractorPattern:
+		p.expr(x.Fun, precSimple)
+		p.typeArgs(x.Targs)
+		p.write("(")
+		p.patternList(x.Args)
+		p.write(")")
+	case *ast.SeqWildcard:
+		p.write("_*")
+	case *ast.TuplePattern:
+		p.write("(")
+		p.patternList(x.Elts)
+		p.write(")")
+	case *ast.Alternative:
+		for i, alt := range x.Alts {
+			if i > 0 {
+				p.write(" | ")
+			}
+			p.pattern(alt, patTyped)
+		}
+	case *ast.InfixPattern:
+		prec := patInfix + parser.OperatorPrecedence(x.Op.Name)
+		right := parser.IsRightAssociative(x.Op.Name)
+		p.patternOperand(x.X, prec, right, true)
+		p.write(" ")
+		p.ident(x.Op)
+		p.write(" ")
+		p.patternOperand(x.Y, prec, right, false)
+	case *ast.InterpolatedPattern:
+		args := make([]string, len(x.Args))
+		for i, arg := range x.Args {
+			if id, ok := arg.(*ast.Ident); ok && !id.Backquoted && id.IsVariable() && isSpliceName(id.Name, x.Parts[i+1]) {
+				args[i] = "$" + id.Name
+				continue
+			}
+			args[i] = p.splice(func() { p.pattern(arg, patAlt) })
+		}
+		p.interpolation(x.Id, x.Parts, args)
+	default:
+		panic(fmt.Sprintf("printer: unexpected pattern type %T", x))
+	}
+}
+
+func (p *printer) patternOperand(x ast.Pattern, prec int, right, left bool) {
+	if y, ok := x.(*ast.InfixPattern); ok && patternPrec(y) == prec {
+		if parser.IsRightAssociative(y.Op.Name) == right && left != right {
+			p.pattern1(x)
+			return
+		}
+		p.write("(")
+		p.pattern1(x)
+		p.write(")")
+		return
+	}
+	p.pattern(x, prec+1)
+}
+
+func (p *printer) patternList(list []ast.Pattern) {
+	for i, x := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.pattern(x, patAlt)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Types
+
+func typePrec(x ast.Type) int {
+	switch x := x.(type) {
+	case *ast.FunctionType, *ast.ExistentialType, *ast.ByNameType, *ast.WildcardType, *ast.RepeatedType:
+		return typeAny
+	case *ast.InfixType:
+		return typeInfix
+	case *ast.CompoundType:
+		if len(x.Types) == 1 && !x.Lbrace.IsValid() {
+			return typePrec(x.Types[0])
+		}
+		return typeCompound
+	case *ast.AnnotatedType:
+		return typeAnnot
+	}
+	return typeSimple
+}
+
+func (p *printer) typ(x ast.Type, prec int) {
+	if typePrec(x) < prec {
+		p.write("(")
+		p.typ1(x)
+		p.write(")")
+		return
+	}
+	p.typ1(x)
+}
+
+func (p *printer) typ1(x ast.Type) {
+	p.leading(x)
+	defer p.trailing(x)
+	switch x := x.(type) {
+	case *ast.Ident, *ast.Select:
+		p.expr1(x.(ast.Expr))
+	case *ast.BadType:
+		p.write("BadType")
+	case *ast.SingletonType:
+		p.expr(x.Ref, precSimple)
+		p.write(".type")
+	case *ast.Projection:
+		p.typ(x.X, typeSimple)
+		p.write("#")
+		p.ident(x.Sel)
+	case *ast.AppliedType:
+		p.typ(x.Type, typeSimple)
+		p.typeArgs(x.Args)
+	case *ast.FunctionType:
+		if len(x.Params) == 1 && typePrec(x.Params[0]) >= typeInfix {
+			if _, ok := x.Params[0].(*ast.TupleType); !ok {
+				p.typ(x.Params[0], typeInfix)
+				p.write(" => ")
+				p.typ(x.Result, typeAny)
+				return
+			}
+		}
+		p.write("(")
+		p.typeList(x.Params)
+		p.write(") => ")
+		p.typ(x.Result, typeAny)
+	case *ast.ByNameType:
+		p.write("=> ")
+		p.typ(x.Type, typeAny)
+	case *ast.RepeatedType:
+		p.typ(x.Type, typeInfix)
+		p.write("*")
+	case *ast.TupleType:
+		p.write("(")
+		p.typeList(x.Elts)
+		p.write(")")
+	case *ast.CompoundType:
+		for i, t := range x.Types {
+			if i > 0 {
+				p.write(" with ")
+			}
+			p.typ(t, typeAnnot)
+		}
+		if x.Lbrace.IsValid() {
+			if len(x.Types) > 0 {
+				p.write(" ")
+			}
+			p.refinement(x.Decls)
+		}
+	case *ast.ExistentialType:
+		p.typ(x.Type, typeInfix)
+		p.write(" forSome ")
+		p.refinement(x.Decls)
+	case *ast.InfixType:
+		right := parser.IsRightAssociative(x.Op.Name)
+		p.typeOperand(x.X, x.Op.Name, !right)
+		p.write(" ")
+		p.ident(x.Op)
+		p.write(" ")
+		p.typeOperand(x.Y, x.Op.Name, right)
+	case *ast.AnnotatedType:
+		p.typ(x.Type, typeSimple)
+		for _, annot := range x.Annotations {
+			p.write(" ")
+			p.annotation(annot)
+		}
+	case *ast.WildcardType:
+		p.write("_")
+		p.bounds(x.Lo, x.Hi)
+	case *ast.Splice:
+		p.expr1(x)
+	default:
+		panic(fmt.Sprintf("printer: unexpected type %T", x))
+	}
+}
+
+// typeOperand prints an operand of the infix type operator op. Infix
+// types bind alike in Scala 2 but by precedence in Scala 3, so a nested
+// infix type goes without parentheses only if it has the same operator
+// and sits on the side it associates to.
+func (p *printer) typeOperand(x ast.Type, op string, assocSide bool) {
+	if y, ok := x.(*ast.InfixType); ok {
+		if assocSide && y.Op.Name == op {
+			p.typ1(x)
+			return
+		}
+		p.write("(")
+		p.typ1(x)
+		p.write(")")
+		return
+	}
+	p.typ(x, typeCompound)
+}
+
+func (p *printer) typeList(list []ast.Type) {
+	for i, t := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.typ(t, typeAny)
+	}
+}
+
+// refinement prints the declarations of a refinement or existential
+// clause on one line.
+func (p *printer) refinement(decls []ast.Stat) {
+	if len(decls) == 0 {
+		p.write("{}")
+		return
+	}
+	p.write("{ ")
+	for i, decl := range decls {
+		if i > 0 {
+			p.write("; ")
+		}
+		p.stat(decl, false)
+	}
+	p.write(" }")
+}
@@ -0,0 +1,119 @@
+// Package printer implements printing of AST nodes as Scala source.
+//
+// The printer does not reproduce the original layout of the source; it
+// lays out the tree in a fixed style. It adds the parentheses that the
+// precedence and associativity of operators require, backquotes names
+// that are not plain identifiers, such as keywords, and separates and
+// terminates statements so that the output parses back to the same tree.
+package printer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+// A Config controls the output of Fprint.
+type Config struct {
+	Indent int // number of spaces per indentation level; 2 if 0
+}
+
+// Fprint "pretty-prints" an AST node to output using the default
+// configuration. Nodes containing syntax errors print their Bad
+// placeholders as BadExpr, BadPattern, BadType or BadStat.
+func Fprint(output io.Writer, node ast.Node) error {
+	return (&Config{}).Fprint(output, node)
+}
+
+// Fprint "pretty-prints" an AST node to output for a given
+// configuration cfg. A *ast.File is terminated by a newline. If node is
+// a *CommentedNode, its comments are printed with it: each next to the
+// node it is attached to where the layout allows, and otherwise at the
+// end.
+func (cfg *Config) Fprint(output io.Writer, node ast.Node) error {
+	p := &printer{indentWidth: cfg.Indent, printed: map[*ast.CommentGroup]bool{}}
+	if p.indentWidth <= 0 {
+		p.indentWidth = 2
+	}
+	if n, ok := node.(*CommentedNode); ok {
+		node = n.Node
+		p.comments = n.Comments
+	}
+	p.node(node)
+	p.rest(node)
+	if _, ok := node.(*ast.File); ok && p.buf.Len() > 0 {
+		p.buf.WriteByte('\n')
+	}
+	_, err := output.Write(p.buf.Bytes())
+	return err
+}
+
+// String returns the source of node as printed by Fprint.
+func String(node ast.Node) string {
+	var buf bytes.Buffer
+	Fprint(&buf, node)
+	return buf.String()
+}
+
+type printer struct {
+	buf         bytes.Buffer
+	indent      int // current indentation level
+	indentWidth int
+
+	comments       ast.CommentMap
+	printed        map[*ast.CommentGroup]bool
+	pendingNewline bool // a line comment was printed last
+	renderedLine   bool // the last render ended in a line comment
+}
+
+// write appends s to the output. It separates s from the preceding text
+// by a space if they would otherwise run together into one operator, as
+// in x_+ : Int or - -x. After a line comment, s starts a new line.
+func (p *printer) write(s string) {
+	if p.pendingNewline && s != "" {
+		p.newline()
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		return
+	}
+	last, _ := utf8.DecodeLastRune(p.buf.Bytes())
+	first, _ := utf8.DecodeRuneInString(s)
+	if p.buf.Len() > 0 && parser.IsOperatorChar(last) && parser.IsOperatorChar(first) {
+		p.buf.WriteByte(' ')
+	}
+	p.buf.WriteString(s)
+}
+
+// newline starts a new line at the current indentation.
+func (p *printer) newline() {
+	p.pendingNewline = false
+	p.buf.WriteByte('\n')
+	p.buf.WriteString(strings.Repeat(" ", p.indent*p.indentWidth))
+}
+
+// render returns what f prints, starting at the current indentation,
+// without adding it to the output. If it ends in a line comment,
+// renderedLine is set.
+func (p *printer) render(f func()) string {
+	saved, pending := p.buf, p.pendingNewline
+	p.buf, p.pendingNewline = bytes.Buffer{}, false
+	f()
+	s := p.buf.String()
+	p.buf, p.renderedLine, p.pendingNewline = saved, p.pendingNewline, pending
+	return s
+}
+
+// splice returns what f prints as a splice ${...} of an interpolated
+// string.
+func (p *printer) splice(f func()) string {
+	s := p.render(f)
+	if p.renderedLine {
+		s += "\n"
+	}
+	return "${" + s + "}"
+}
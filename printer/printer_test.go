@@ -0,0 +1,432 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sundargates/scalaparser/ast"
+	"github.com/sundargates/scalaparser/ast/astutil"
+	"github.com/sundargates/scalaparser/parser"
+)
+
+var posType = reflect.TypeOf(ast.NoPos)
+
+// equal reports the first difference between the trees a and b, ignoring
+// positions, or "" if they are alike.
+func equal(a, b reflect.Value, path string) string {
+	if a.Type() != b.Type() {
+		return fmt.Sprintf("%s: %s vs %s", path, a.Type(), b.Type())
+	}
+	switch a.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return fmt.Sprintf("%s: nil vs non-nil", path)
+			}
+			return ""
+		}
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			return fmt.Sprintf("%s: %s vs %s", path, a.Elem().Type(), b.Elem().Type())
+		}
+		return equal(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if a.Type().Field(i).Type == posType {
+				continue
+			}
+			if d := equal(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name); d != "" {
+				return d
+			}
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s: %d vs %d elements", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if d := equal(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			return fmt.Sprintf("%s: %v vs %v", path, a.Interface(), b.Interface())
+		}
+	}
+	return ""
+}
+
+func diff(a, b ast.Node) string {
+	return equal(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem(), fmt.Sprintf("%T", a))
+}
+
+var roundTripFiles = []string{
+	`package a.b
+package c
+
+import x.y.{z => w, q => _, _}
+import m.{given Ordering[Int], *}, n.` + "`type`" + `
+
+@deprecated("x") private[c] abstract class C[+T <: AnyRef : Ordering, F[_]] @inject() protected (val x: Int = 1, ys: String*)(implicit z: => Int)
+    extends { val early = 1 } with B(1)(2) with D { self: E with G { def g: Int } =>
+  type U >: Null <: AnyRef
+  type V = Map[K, Int] forSome { type K }
+  type W = A Either B#C
+  def f[A <% B](a: A): (A, Int) => B#C = {
+    var i: Int @unchecked = -a
+    i = 1
+    (a: @switch) match {
+      case p @ Some(q: Int) | None if q > 0 => a op[Int] b
+      case List(xs @ _*) => xs.length
+      case h :: t => s"$h"
+      case s"a$b" => ()
+      case (x, y) => this.x
+    }
+    while (i > 0) i -= 1
+    do i += 1 while (i < 0)
+    for (x <- xs; y = x; if y > 0) yield (x, y)
+    try throw new E() catch { case _: Throwable => } finally return
+    if (a) b else c
+    new { def x = 1 }
+    (x: Int) => (x toString)
+    xs.map { case 1 => 2 }
+    super[T].f(C.this.x)
+    g[Int](1: Int)
+  }
+  val v: x.type = x
+  val t: (Int, String) = null
+  val u: (=> Int) => Map[_ <: A, _] = null
+}
+trait T extends A
+object O
+`,
+	`object Main extends App {
+  import Main.this.f, Main.super[App].args
+  def this(x: Int) = this()
+  def this(x: String) { this() }
+  def run() { println(1) }
+  val n = -(-1)
+  val o = new { val a = 1 }
+  val ` + "`yield`" + ` = 1
+  val xs = List(1, 2, 3) map (_ * 2) filter { x => x > 2 }
+  val ys = xs.foldLeft(0) { (acc, x) =>
+    val y = acc + x
+    y * 2
+  }
+  println(s"total: ${xs.sum + 1} of ${xs.size}")
+  val f: Int => Int => Int = a => b => a - (b - 1)
+  val g = (a: Int, b: Int) => a :: b :: Nil
+  val h = ((a :: b) :: c) ++ d
+  val k = -(-x) + !b + ~(a & b) + - 1
+  val m = (a + b) * c / (d % e) max f
+  implicit val ord: Ordering[Int] = Ordering.Int
+  lazy val l = if (a) if (b) c else d else e
+  val n = if (a) { if (b) c } else d
+  x.y = z
+  arr(0) = 1
+  f(x = 1, ys: _*)
+  case class P(x: Int, y: Int) extends Q
+  sealed trait R
+  final case object S extends R
+  def proc(): Unit = {}
+  def +(that: P): P = P(x + that.x)
+  def unary_- : P = this
+  xs foreach println
+}
+`,
+	`package object p {
+  type F[A] = A => A
+  val stringly = """multi
+line"""
+  val interp = s"""a "quoted" $x"""
+  val esc = s"tab\t${a}b"
+  val sym = 'sym
+  val ch = 'c'
+  val num = 0x1fL + 1.5e3 + -2
+}
+
+package q {
+  package r {
+    class S
+  }
+}
+`,
+	`class Matcher {
+  def m(x: Any) = x match {
+    case a | b if a == b =>
+      val c = a
+      c
+    case Seq(1, rest @ _*) => rest
+    case (a, (b, c)) :: tail => tail
+    case p.Q(r) => r
+    case ` + "`v`" + ` => v
+    case -1 => 0
+    case _: String | _: Int => 1
+    case x @ (_: Int | _: Long) => x
+  }
+  val u: Int | Long = 1
+  val pf: PartialFunction[Int, Int] = {
+    case 1 => 1
+  }
+  for {
+    (a, b) <- pairs
+    if a < b
+    c = a * b
+  } println(c)
+  { println("block") }
+  { x }
+}
+`,
+}
+
+func TestRoundTrip(t *testing.T) {
+	for i, src := range roundTripFiles {
+		f1, err := parser.ParseFile("a.scala", src)
+		if err != nil {
+			t.Errorf("%d: ParseFile: %v", i, err)
+			continue
+		}
+		out1 := String(f1)
+		f2, err := parser.ParseFile("a.scala", out1)
+		if err != nil {
+			t.Errorf("%d: ParseFile of printed source: %v\n%s", i, err, out1)
+			continue
+		}
+		if d := diff(f1, f2); d != "" {
+			t.Errorf("%d: printed source parses differently: %s\n%s", i, d, out1)
+			continue
+		}
+		if out2 := String(f2); out2 != out1 {
+			t.Errorf("%d: printing is not idempotent:\n%s\nthen\n%s", i, out1, out2)
+		}
+	}
+}
+
+func ident(name string) *ast.Ident { return &ast.Ident{Name: name} }
+
+func infix(x ast.Expr, op string, y ast.Expr) *ast.InfixApply {
+	return &ast.InfixApply{X: x, Op: ident(op), Y: y}
+}
+
+var printTests = []struct {
+	node     ast.Node
+	expected string
+}{
+	// precedence and associativity
+	{infix(infix(ident("a"), "+", ident("b")), "*", ident("c")), "(a + b) * c"},
+	{infix(ident("a"), "+", infix(ident("b"), "*", ident("c"))), "a + b * c"},
+	{infix(infix(ident("a"), "-", ident("b")), "-", ident("c")), "a - b - c"},
+	{infix(ident("a"), "-", infix(ident("b"), "-", ident("c"))), "a - (b - c)"},
+	{infix(ident("a"), "::", infix(ident("b"), "::", ident("c"))), "a :: b :: c"},
+	{infix(infix(ident("a"), "::", ident("b")), "::", ident("c")), "(a :: b) :: c"},
+	{infix(infix(ident("a"), "+:", ident("b")), "+", ident("c")), "(a +: b) + c"},
+	{infix(infix(ident("a"), "max", ident("b")), "||", ident("c")), "(a max b) || c"},
+	{infix(ident("a"), "+=", infix(ident("b"), "+", ident("c"))), "a += b + c"},
+	{&ast.Select{X: infix(ident("a"), "+", ident("b")), Sel: ident("abs")}, "(a + b).abs"},
+	{&ast.Select{X: &ast.New{Template: &ast.Template{Parents: []*ast.Init{{Type: ident("C")}}}}, Sel: ident("x")}, "(new C).x"},
+	{&ast.PrefixApply{Op: ident("-"), X: &ast.Literal{Kind: ast.IntLit, Value: "1"}}, "- 1"},
+	{&ast.PrefixApply{Op: ident("-"), X: &ast.PrefixApply{Op: ident("-"), X: ident("x")}}, "-(-x)"},
+	{&ast.PostfixApply{X: infix(ident("a"), "+", ident("b")), Op: ident("toList")}, "(a + b toList)"},
+	{&ast.Typed{X: &ast.Function{Params: []*ast.Param{{Name: ident("x")}}, Body: ident("x")}, Type: ident("F")}, "(x => x): F"},
+	{&ast.Apply{Fun: &ast.If{Cond: ident("c"), Then: ident("f"), Else: ident("g")}, Args: &ast.ArgList{Args: []ast.Expr{ident("x")}}},
+		"(if (c) f else g)(x)"},
+	{&ast.If{Cond: ident("a"), Then: &ast.If{Cond: ident("b"), Then: ident("c")}, Else: ident("d")},
+		"if (a) {\n  if (b) c\n} else d"},
+	{&ast.TypedPattern{X: ident("x"), Type: &ast.InfixType{X: ident("A"), Op: ident("|"), Y: ident("B")}}, "x: (A | B)"},
+	// backquotes
+	{ident("type"), "`type`"},
+	{ident("yield"), "`yield`"},
+	{ident("true"), "`true`"},
+	{ident("=>"), "`=>`"},
+	{ident("a b"), "`a b`"},
+	{&ast.Ident{Name: "x", Backquoted: true}, "`x`"},
+	{&ast.Select{X: ident("x"), Sel: ident("match")}, "x.`match`"},
+	{ident("::"), "::"},
+	// types
+	{&ast.FunctionType{Params: []ast.Type{&ast.FunctionType{Params: []ast.Type{ident("A")}, Result: ident("B")}}, Result: ident("C")}, "(A => B) => C"},
+	{&ast.FunctionType{Params: []ast.Type{ident("A")}, Result: &ast.FunctionType{Params: []ast.Type{ident("B")}, Result: ident("C")}}, "A => B => C"},
+	{&ast.FunctionType{Params: []ast.Type{&ast.TupleType{Elts: []ast.Type{ident("A"), ident("B")}}}, Result: ident("C")}, "((A, B)) => C"},
+	{&ast.FunctionType{Params: []ast.Type{&ast.ByNameType{Type: ident("A")}}, Result: ident("C")}, "(=> A) => C"},
+	{&ast.AppliedType{Type: ident("F"), Args: []ast.Type{&ast.WildcardType{Hi: ident("A")}}}, "F[_ <: A]"},
+	{&ast.CompoundType{Types: []ast.Type{&ast.InfixType{X: ident("A"), Op: ident("Or"), Y: ident("B")}, ident("C")}}, "(A Or B) with C"},
+	{&ast.InfixType{X: ident("A"), Op: ident("::"), Y: &ast.InfixType{X: ident("B"), Op: ident("::"), Y: ident("C")}}, "A :: B :: C"},
+	{&ast.InfixType{X: ident("A"), Op: ident("+"), Y: &ast.InfixType{X: ident("B"), Op: ident("*"), Y: ident("C")}}, "A + (B * C)"},
+	// patterns
+	{&ast.Bind{Name: ident("x"), Pat: &ast.Alternative{Alts: []ast.Pattern{ident("A"), ident("B")}}}, "x @ (A | B)"},
+	{&ast.InfixPattern{X: &ast.InfixPattern{X: ident("a"), Op: ident("::"), Y: ident("b")}, Op: ident("::"), Y: ident("c")}, "(a :: b) :: c"},
+	// statements
+	{&ast.DefDef{Name: ident("+"), ResultType: ident("Int")}, "def + : Int"},
+	{&ast.ValDef{Keyword: "val", Pats: []ast.Pattern{ident("x_+")}, Type: ident("Int")}, "val x_+ : Int"},
+	{&ast.Block{Stats: []ast.Stat{&ast.Function{Params: []*ast.Param{{Name: ident("x")}}, Body: ident("x")}, ident("y")}},
+		"{\n  (x => x)\n  y\n}"},
+	{&ast.Block{Stats: []ast.Stat{ident("f"), &ast.Block{Stats: []ast.Stat{ident("x")}}}}, "{\n  f;\n  {\n    x\n  }\n}"},
+	{&ast.Interpolation{Id: ident("s"), Parts: []string{"", "x", ""}, Args: []ast.Expr{ident("a"), ident("b")}}, `s"${a}x$b"`},
+}
+
+func TestPrint(t *testing.T) {
+	for _, test := range printTests {
+		if s := String(test.node); s != test.expected {
+			t.Errorf("String(%T) = %q, Expected = %q", test.node, s, test.expected)
+		}
+	}
+}
+
+// exprTests are printed from their parsed source, which they should
+// reproduce.
+var exprTests = []string{
+	"xs map (_ + 1)",
+	"list sortWith (_ < _)",
+	"List(1, 2, 3) map (_ * 2) filter (_ > 2)",
+	"xs map (-_)",
+	"xs filter (_.size > 0)",
+	"xs.filter(_.size > 0)",
+	"_ * 2 + 1",
+	"_ max 1 min 2",
+	"f((a = b))",
+	"f(a = b)",
+	"(a = b)",
+}
+
+func TestPrintExpr(t *testing.T) {
+	for _, src := range exprTests {
+		x, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Errorf("ParseExpr(%q): %v", src, err)
+			continue
+		}
+		if s := String(x); s != src {
+			t.Errorf("String(%q) = %q, Expected = %q", src, s, src)
+		}
+	}
+}
+
+const layoutInput = `package a
+import b.c
+import d.e
+object O { val x = 1; val y = 2; def f(x: Int) = { val y = x; y } ; class C }
+`
+
+const layoutOutput = `package a
+
+import b.c
+import d.e
+
+object O {
+  val x = 1
+  val y = 2
+
+  def f(x: Int) = {
+    val y = x
+    y
+  }
+
+  class C
+}
+`
+
+func TestLayout(t *testing.T) {
+	f, err := parser.ParseFile("a.scala", layoutInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := String(f); s != layoutOutput {
+		t.Errorf("String = \n%s\nExpected = \n%s", s, layoutOutput)
+	}
+	var buf bytes.Buffer
+	if err := (&Config{Indent: 4}).Fprint(&buf, f.Stats[0].(*ast.PackageClause).Stats[2]); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "object O {\n    val x = 1\n    val y = 2\n\n    def f(x: Int) = {\n        val y = x\n        y\n    }\n\n    class C\n}" {
+		t.Errorf("Indent 4: %q", s)
+	}
+}
+
+const commentsInput = `// Copyright
+
+/** Package doc */
+package a
+import b.c // why
+/** A class.
+  * More.
+  */
+class C(x: Int /* the x */) extends B {
+  // first
+  def f = x
+  val z = { // in block
+    1
+    // end of block
+  }
+  def g(v: Int) = v match {
+    case 1 => "one" // first case
+    case 2 =>
+      // second case
+      "two"
+    // no more cases
+  }
+  // end of class
+}
+// end of file
+`
+
+const commentsOutput = `// Copyright
+
+/** Package doc */
+package a
+
+import b.c // why
+
+/** A class.
+  * More.
+  */
+class C(x: Int /* the x */) extends B {
+  // first
+  def f = y
+
+  val z = {
+    // in block
+    1
+    // end of block
+  }
+
+  def g(v: Int) = v match {
+    case 1 => "one" // first case
+    case 2 =>
+      // second case
+      "two"
+    // no more cases
+  }
+  // end of class
+}
+// end of file
+`
+
+func TestComments(t *testing.T) {
+	f, err := parser.ParseFile("a.scala", commentsInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmap := ast.NewCommentMap(commentsInput, f, f.Comments)
+	// Rewrite x to y in f, keeping the comments of x.
+	astutil.Apply(f, func(c *astutil.Cursor) bool {
+		if id, ok := c.Node().(*ast.Ident); ok && id.Name == "x" && c.Name() == "Rhs" {
+			c.Replace(cmap.Update(id, &ast.Ident{Name: "y"}))
+		}
+		return true
+	}, nil)
+	s := String(&CommentedNode{Node: f, Comments: cmap})
+	if s != commentsOutput {
+		t.Errorf("String = \n%s\nExpected = \n%s", s, commentsOutput)
+	}
+	g, err := parser.ParseFile("a.scala", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Comments) != len(f.Comments) {
+		t.Errorf("len(Comments) = %d after printing, Expected = %d", len(g.Comments), len(f.Comments))
+	}
+	if s := String(&CommentedNode{Node: g, Comments: ast.NewCommentMap(s, g, g.Comments)}); s != commentsOutput {
+		t.Errorf("String(String) = \n%s\nExpected = \n%s", s, commentsOutput)
+	}
+
+}